@@ -37,7 +37,7 @@ func TestCalendarInvite(t *testing.T) {
 	err = gCal.CreateEvent(newEvent)
 	require.Nil(t, err)
 
-	err = gCal.InviteToEvent(eventID, os.Getenv("TEST_EMAIL"), "Test User")
+	err = gCal.InviteToEvent(eventID, os.Getenv("TEST_EMAIL"), "Test User", pizza.RSVPAccepted)
 	require.Nil(t, err)
 
 	event, err := gCal.GetEvent(eventID)
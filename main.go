@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	// the docker image has no timezone database of its own
+	_ "time/tzdata"
 
 	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
 )
@@ -13,7 +15,7 @@ import (
 func main() {
 	args := os.Args
 	if len(args) < 2 {
-		fmt.Println("command required: [run, edit, patch]")
+		fmt.Println("command required: [run, edit, patch, backup, restore, export, import, blackout]")
 		os.Exit(1)
 	}
 	var err error
@@ -22,8 +24,18 @@ func main() {
 		pizza.Run(os.Args[2:])
 	case "patch":
 		pizza.Patch(os.Args[2:])
+	case "backup":
+		pizza.Backup(os.Args[2:])
+	case "restore":
+		pizza.Restore(os.Args[2:])
+	case "export":
+		pizza.Export(os.Args[2:])
+	case "import":
+		pizza.Import(os.Args[2:])
+	case "blackout":
+		pizza.Blackouts(os.Args[2:])
 	default:
-		err = errors.New("command must be one of [run, edit, patch]")
+		err = errors.New("command must be one of [run, edit, patch, backup, restore, export, import, blackout]")
 	}
 	if err != nil {
 		fmt.Println(err.Error())
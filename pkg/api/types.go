@@ -10,10 +10,19 @@ import (
 )
 
 type Friday struct {
-	ID        string    `jsonapi:"primary,friday"`
-	StartTime time.Time `jsonapi:"attr,start_time"`
-	Details   string    `jsonapi:"attr,details"`
-	Guests    []*Guest  `jsonapi:"relation,guests"`
+	ID               string    `jsonapi:"primary,friday"`
+	StartTime        time.Time `jsonapi:"attr,start_time"`
+	DurationMinutes  int       `jsonapi:"attr,duration_minutes,omitempty"`
+	Details          string    `jsonapi:"attr,details"`
+	WaitlistPosition int       `jsonapi:"attr,waitlist_position,omitempty"`
+	RSVPOpensAt      time.Time `jsonapi:"attr,rsvp_opens_at,omitempty"`
+	RSVPClosesAt     time.Time `jsonapi:"attr,rsvp_closes_at,omitempty"`
+	DeclineClosesAt  time.Time `jsonapi:"attr,decline_closes_at,omitempty"`
+	Guests           []*Guest  `jsonapi:"relation,guests"`
+	Waitlist         []*Guest  `jsonapi:"relation,waitlist,omitempty"`
+	Series           *Series   `jsonapi:"relation,series,omitempty"`
+	Venue            *Venue    `jsonapi:"relation,venue,omitempty"`
+	Hosts            []*Guest  `jsonapi:"relation,hosts,omitempty"`
 }
 
 func (f *Friday) JSONAPILinks() *jsonapi.Links {
@@ -22,9 +31,45 @@ func (f *Friday) JSONAPILinks() *jsonapi.Links {
 	}
 }
 
+// Series is a named schedule of events. Rule is empty for a series whose events are all scheduled by hand.
+type Series struct {
+	ID          string `jsonapi:"primary,series"`
+	Name        string `jsonapi:"attr,name"`
+	Description string `jsonapi:"attr,description"`
+	Group       string `jsonapi:"attr,group,omitempty"`
+	MaxGuests   int    `jsonapi:"attr,max_guests"`
+	Rule        string `jsonapi:"attr,rule,omitempty"`
+	CalendarID  string `jsonapi:"attr,calendar_id,omitempty"`
+}
+
+func (s *Series) JSONAPILinks() *jsonapi.Links {
+	return &jsonapi.Links{
+		"self": fmt.Sprintf("/api/series/%s", s.ID),
+	}
+}
+
+// Venue is a place that hosts the party. Capacity is the default max_guests of the fridays held there.
+type Venue struct {
+	ID       string `jsonapi:"primary,venue"`
+	Name     string `jsonapi:"attr,name"`
+	Address  string `jsonapi:"attr,address,omitempty"`
+	Capacity int    `jsonapi:"attr,capacity"`
+	Notes    string `jsonapi:"attr,notes,omitempty"`
+	Ovens    int    `jsonapi:"attr,ovens"`
+}
+
+func (v *Venue) JSONAPILinks() *jsonapi.Links {
+	return &jsonapi.Links{
+		"self": fmt.Sprintf("/api/venue/%s", v.ID),
+	}
+}
+
+// Guest is a friend on a guest list. Status is their RSVP, which is accepted, tentative or waitlisted, and is empty
+// outside of guest lists.
 type Guest struct {
-	ID   string `jsonapi:"primary,guest"`
-	Name string `jsonapi:"attr,name,omitempty"`
+	ID     string `jsonapi:"primary,guest"`
+	Name   string `jsonapi:"attr,name,omitempty"`
+	Status string `jsonapi:"attr,status,omitempty"`
 }
 
 func (g *Guest) JSONAPILinks() *jsonapi.Links {
@@ -49,6 +94,102 @@ func (p *GuestProfile) JSONAPILinks() *jsonapi.Links {
 	}
 }
 
+type AuditEntry struct {
+	ID     string    `jsonapi:"primary,audit"`
+	Time   time.Time `jsonapi:"attr,time"`
+	Actor  string    `jsonapi:"attr,actor"`
+	Action string    `jsonapi:"attr,action"`
+	Friday string    `jsonapi:"attr,friday,omitempty"`
+	Target string    `jsonapi:"attr,target"`
+	Before string    `jsonapi:"attr,before"`
+	After  string    `jsonapi:"attr,after"`
+	Source string    `jsonapi:"attr,source"`
+}
+
+// Recurrence is the RRULE that fridays are scheduled by and the IDs of its upcoming fridays
+type Recurrence struct {
+	ID       string   `jsonapi:"primary,recurrence"`
+	Rule     string   `jsonapi:"attr,rule"`
+	Upcoming []string `jsonapi:"attr,upcoming"`
+}
+
+func (r *Recurrence) JSONAPILinks() *jsonapi.Links {
+	return &jsonapi.Links{
+		"self": "/api/recurrence",
+	}
+}
+
+func UnmarshalRecurrence(r io.Reader) (*Recurrence, error) {
+	recurrence := &Recurrence{}
+	if err := jsonapi.UnmarshalPayload(r, recurrence); err != nil {
+		return nil, err
+	}
+	return recurrence, nil
+}
+
+// AutoEnable is the scheduler that creates and enables upcoming fridays. Enabled is whether the deployment runs it
+// at all, and hosts pause and resume it with Paused.
+type AutoEnable struct {
+	ID            string `jsonapi:"primary,auto_enable"`
+	Enabled       bool   `jsonapi:"attr,enabled"`
+	Paused        bool   `jsonapi:"attr,paused"`
+	LeadTimeHours int    `jsonapi:"attr,lead_time_hours"`
+}
+
+func (a *AutoEnable) JSONAPILinks() *jsonapi.Links {
+	return &jsonapi.Links{
+		"self": "/api/auto-enable",
+	}
+}
+
+func UnmarshalAutoEnable(r io.Reader) (*AutoEnable, error) {
+	autoEnable := &AutoEnable{}
+	if err := jsonapi.UnmarshalPayload(r, autoEnable); err != nil {
+		return nil, err
+	}
+	return autoEnable, nil
+}
+
+func UnmarshalSeries(r io.Reader) (*Series, error) {
+	series := &Series{}
+	if err := jsonapi.UnmarshalPayload(r, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func UnmarshalManySeries(r io.Reader) ([]*Series, error) {
+	payload, err := jsonapi.UnmarshalManyPayload(r, reflect.TypeOf(new(Series)))
+	if err != nil {
+		return nil, err
+	}
+	series := make([]*Series, len(payload))
+	for i, s := range payload {
+		series[i] = s.(*Series)
+	}
+	return series, nil
+}
+
+func UnmarshalVenue(r io.Reader) (*Venue, error) {
+	venue := &Venue{}
+	if err := jsonapi.UnmarshalPayload(r, venue); err != nil {
+		return nil, err
+	}
+	return venue, nil
+}
+
+func UnmarshalVenues(r io.Reader) ([]*Venue, error) {
+	payload, err := jsonapi.UnmarshalManyPayload(r, reflect.TypeOf(new(Venue)))
+	if err != nil {
+		return nil, err
+	}
+	venues := make([]*Venue, len(payload))
+	for i, v := range payload {
+		venues[i] = v.(*Venue)
+	}
+	return venues, nil
+}
+
 func UnmarshalFriday(r io.Reader) (*Friday, error) {
 	friday := &Friday{}
 	if err := jsonapi.UnmarshalPayload(r, friday); err != nil {
@@ -68,3 +209,15 @@ func UnmarshalFridays(r io.Reader) ([]*Friday, error) {
 	}
 	return fridays, nil
 }
+
+func UnmarshalAuditEntries(r io.Reader) ([]*AuditEntry, error) {
+	payload, err := jsonapi.UnmarshalManyPayload(r, reflect.TypeOf(new(AuditEntry)))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*AuditEntry, len(payload))
+	for i, e := range payload {
+		entries[i] = e.(*AuditEntry)
+	}
+	return entries, nil
+}
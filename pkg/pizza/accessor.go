@@ -1,17 +1,24 @@
 package pizza
 
 import (
+	"errors"
+	"slices"
 	"time"
 
 	"github.com/mpoegel/rsvp.pizza/pkg/types"
 )
 
+var (
+	ErrFridayIsFull = errors.New("friday is full")
+)
+
 type Accessor interface {
 	CreateTables() error
 
 	GetFriendByID(ID string) (Friend, error)
 	GetFriendByEmail(email string) (Friend, error)
 	AddFriend(email, name string) error
+	ListFriends() ([]Friend, error)
 
 	GetUpcomingFridays(daysAhead int) ([]Friday, error)
 	GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error)
@@ -22,11 +29,58 @@ type Accessor interface {
 	GetFriday(date time.Time) (Friday, error)
 	RemoveFriday(date time.Time) error
 	UpdateFriday(friday Friday) error
-	AddFriendToFriday(email string, friday Friday) error
+	// AddFriendToFriday accepts the friend's RSVP, or returns ErrFridayIsFull when the friday has no room left
+	AddFriendToFriday(email string, friday Friday, createdBy string) error
 	RemoveFriendFromFriday(email string, date time.Time) error
+	// SetFridayHosts replaces who hosts the friday, where an empty host leaves it without an owner. Emails of people
+	// who are not friends are skipped.
+	SetFridayHosts(date time.Time, host string, coHosts []string) error
+	// AddPlusOne brings a guest without an account along with their sponsor and returns the ID of the plus-one, or
+	// ErrFridayIsFull when the friday has no room left
+	AddPlusOne(date time.Time, plusOne PlusOne) (int64, error)
+	RemovePlusOne(date time.Time, ID int64) error
+	AddFriendToWaitlist(email string, date time.Time) error
+	// AddFriendAsTentative marks the friend as a maybe, which gives up their spot or place in line if they had one
+	AddFriendAsTentative(email string, date time.Time) error
+	// PromoteFromWaitlist accepts waitlisted friends in order until the friday is full and returns their emails
+	PromoteFromWaitlist(date time.Time) ([]string, error)
+	GetRSVPs(date time.Time) ([]RSVP, error)
 
 	GetPreferences(email string) (Preferences, error)
 	SetPreferences(email string, preferences Preferences) error
+
+	// GetCalendarFeed returns sql.ErrNoRows when the friend has never had a calendar feed
+	GetCalendarFeed(email string) (CalendarFeed, error)
+	// GetCalendarFeedByToken returns sql.ErrNoRows when no feed has the token, e.g. because it was rotated
+	GetCalendarFeedByToken(token string) (CalendarFeed, error)
+	// SetCalendarFeed creates the friend's calendar feed or replaces it
+	SetCalendarFeed(feed CalendarFeed) error
+
+	AddAuditEntry(entry AuditEntry) error
+	ListAuditEntries(filter AuditFilter) ([]AuditEntry, error)
+
+	ListSeries() ([]Series, error)
+	GetSeries(ID int64) (Series, error)
+	// AddSeries returns the ID of the new series
+	AddSeries(series Series) (int64, error)
+	UpdateSeries(series Series) error
+
+	// ListVenues returns the venues ordered by name
+	ListVenues() ([]Venue, error)
+	GetVenue(ID int64) (Venue, error)
+	// AddVenue returns the ID of the new venue
+	AddVenue(venue Venue) (int64, error)
+	UpdateVenue(venue Venue) error
+
+	// ListBlackouts returns the blackouts ordered by day
+	ListBlackouts() ([]Blackout, error)
+	// AddBlackout replaces the reason when the day is already blacked out
+	AddBlackout(blackout Blackout) error
+	RemoveBlackout(day string) error
+
+	// GetSetting returns sql.ErrNoRows when the setting has never been set
+	GetSetting(name string) (string, error)
+	SetSetting(name, value string) error
 }
 
 type Friend struct {
@@ -35,13 +89,175 @@ type Friend struct {
 	Name  string
 }
 
+// Friday is identified by Date, its default start time, so fridays of different series cannot start at the same
+// time. Start and Duration are only set when the host has changed when the party starts or how long it lasts.
+// Tentative guests are maybes who do not take up a spot, in the order they answered.
 type Friday struct {
 	Date      time.Time
+	SeriesID  int64
+	Start     time.Time
+	Duration  time.Duration
 	Group     *string
 	Details   *string
 	Guests    []string
+	Waitlist  []string
+	Tentative []string
 	MaxGuests int
 	Enabled   bool
+	// VenueID is where the party is, which is zero when the host has not picked a venue
+	VenueID int64
+	// Host is the email of the friend who owns the friday, which is empty when nobody does yet. CoHosts are the
+	// emails of the friends who can edit it too, ordered by email.
+	Host    string
+	CoHosts []string
+	// PlusOnes are the guests without an account, in the order they were added. They count toward MaxGuests.
+	PlusOnes []PlusOne
+	// Policy is when guests can RSVP and decline, which is the policy of the deployment when it is nil
+	Policy *RSVPPolicy
+	// Sequence counts the updates of the friday, so that calendars can tell which version of the event is newest
+	Sequence int
+}
+
+// StartsAt is when the party starts
+func (f Friday) StartsAt() time.Time {
+	if f.Start.IsZero() {
+		return f.Date
+	}
+	return f.Start
+}
+
+// EndsAt is when the party ends, EventDuration after it starts unless the host changed it
+func (f Friday) EndsAt() time.Time {
+	if f.Duration <= 0 {
+		return f.StartsAt().Add(EventDuration)
+	}
+	return f.StartsAt().Add(f.Duration)
+}
+
+// IsHost reports whether the email owns or co-hosts the friday
+func (f Friday) IsHost(email string) bool {
+	return len(email) > 0 && (f.Host == email || slices.Contains(f.CoHosts, email))
+}
+
+// GuestCount is how many guests are coming, plus-ones included
+func (f Friday) GuestCount() int {
+	return len(f.Guests) + len(f.PlusOnes)
+}
+
+// Hosts are the emails of everyone who hosts the friday, with its owner first
+func (f Friday) Hosts() []string {
+	if len(f.Host) == 0 {
+		return f.CoHosts
+	}
+	return append([]string{f.Host}, f.CoHosts...)
+}
+
+// PlusOne is a guest without an account, brought along by the friend whose email is Sponsor. Email is empty unless the
+// sponsor wants them to get a calendar invite.
+type PlusOne struct {
+	ID      int64
+	Name    string
+	Email   string
+	Sponsor string
+}
+
+// DefaultSeriesID is the series of the fridays that were scheduled before there were series, which is scheduled by the
+// configured recurrence
+const DefaultSeriesID = 0
+
+// Series is a named schedule of events, like Pizza Friday, which has its own recurrence and calendar. New events of
+// the series start out with its Group and MaxGuests. An empty CalendarID uses the configured calendar.
+type Series struct {
+	ID          int64
+	Name        string
+	Description string
+	Group       *string
+	MaxGuests   int
+	Recurrence  string
+	CalendarID  string
+}
+
+// Venue is a place that hosts the party. Capacity is the default MaxGuests of the fridays held there, where zero
+// leaves MaxGuests as it is.
+type Venue struct {
+	ID       int64
+	Name     string
+	Address  string
+	Capacity int
+	Notes    string
+	Ovens    int
+}
+
+// BlackoutDayFormat is how the day of a blackout is written
+const BlackoutDayFormat = time.DateOnly
+
+// Blackout is a day without pizza. Day is a date in the timezone of the deployment, written in BlackoutDayFormat.
+type Blackout struct {
+	Day    string
+	Reason string
+}
+
+const (
+	// FridayHostOwner and FridayCoHost are the roles of the hosts of a friday
+	FridayHostOwner = "owner"
+	FridayCoHost    = "cohost"
+)
+
+const (
+	RSVPAccepted   = "accepted"
+	RSVPDeclined   = "declined"
+	RSVPWaitlisted = "waitlisted"
+	RSVPTentative  = "tentative"
+)
+
+// RSVP is a single guest's response to a Friday. CreatedBy is empty unless the RSVP was made on the guest's behalf,
+// e.g. as a plus-one.
+type RSVP struct {
+	Friday    time.Time
+	Email     string
+	Name      string
+	Status    string
+	CreatedAt time.Time
+	CreatedBy string
+}
+
+const (
+	AuditSourceWeb       = "web"
+	AuditSourceAPI       = "api"
+	AuditSourceSync      = "sync"
+	AuditSourceScheduler = "scheduler"
+)
+
+// AuditEntry records a single change to a Friday or its guest list. Actor is the email of whoever made the change, or
+// empty when the change was made automatically.
+type AuditEntry struct {
+	ID     int64
+	Time   time.Time
+	Actor  string
+	Action string
+	Friday time.Time
+	Target string
+	Before string
+	After  string
+	Source string
+}
+
+// AuditFilter narrows down the audit entries to list, newest first. Zero values match everything.
+type AuditFilter struct {
+	Friday time.Time
+	Target string
+	Limit  int
+	Offset int
+}
+
+// CalendarFeed is a friend's personal iCalendar subscription, found by its unguessable Token. Groups are the friend's
+// groups when the feed was last saved, because the feed is read without logging in. OpenFridays also lists the
+// enabled fridays of those groups that the friend has not answered.
+type CalendarFeed struct {
+	Email       string
+	Token       string
+	Groups      []string
+	OpenFridays bool
 }
 
 type Preferences struct {
@@ -49,4 +265,6 @@ type Preferences struct {
 	Cheese   []types.Cheese
 	Sauce    []types.Sauce
 	Doneness types.Doneness
+	// Timezone is the IANA name of the zone that fridays are shown in, or empty for the timezone of the deployment
+	Timezone string `json:",omitempty"`
 }
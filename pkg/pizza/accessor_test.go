@@ -0,0 +1,754 @@
+package pizza_test
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/mpoegel/rsvp.pizza/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forEachAccessor runs the test against every store that rsvp.pizza can keep its data in, each of them empty, so that
+// they all behave the same. The PostgreSQL database is the one in PIZZA_TEST_POSTGRES_DSN, which is skipped when it
+// is not set outside of CI.
+func forEachAccessor(t *testing.T, test func(t *testing.T, accessor pizza.Accessor)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, pizza.NewMemoryAccessor())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, newTestSQLAccessor(t, filepath.Join(t.TempDir(), "pizza.db")))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		test(t, newTestPostgresAccessor(t))
+	})
+}
+
+func TestAccessor_GetFriendByEmail(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "test"))
+
+		// WHEN
+		friend, err := accessor.GetFriendByEmail("foo@bar.com")
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, "1", friend.ID)
+		assert.Equal(t, "test", friend.Name)
+		assert.Equal(t, "foo@bar.com", friend.Email)
+
+		// WHEN
+		friend, err = accessor.GetFriendByID("1")
+		assert.Nil(t, err)
+		assert.Equal(t, "1", friend.ID)
+		assert.Equal(t, "test", friend.Name)
+		assert.Equal(t, "foo@bar.com", friend.Email)
+
+		// WHEN
+		friend, err = accessor.GetFriendByEmail("bar@bar.com")
+
+		// THEN
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, len(friend.Name))
+	})
+}
+
+func TestAccessor_ListFriends(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "foo"))
+		require.Nil(t, accessor.AddFriend("bar@bar.com", "bar"))
+
+		// WHEN
+		friends, err := accessor.ListFriends()
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, []pizza.Friend{
+			{ID: "1", Email: "foo@bar.com", Name: "foo"},
+			{ID: "2", Email: "bar@bar.com", Name: "bar"},
+		}, friends)
+	})
+}
+
+func TestAccessor_GetUpcomingFridays(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		// postgres stores timestamps with microsecond precision
+		// added out of order so the result has to be sorted by start time
+		friday1 := time.Now().AddDate(0, 0, 7).UTC().Truncate(time.Microsecond)
+		friday2 := time.Now().AddDate(0, 0, 14).UTC().Truncate(time.Microsecond)
+		require.Nil(t, accessor.AddFriday(friday2))
+		require.Nil(t, accessor.AddFriday(friday1))
+		require.Nil(t, accessor.AddFriday(time.Now().AddDate(0, 0, -2)))
+		require.Nil(t, accessor.AddFriday(time.Now().Add(23*time.Hour)))
+
+		// WHEN
+		fridays, err := accessor.GetUpcomingFridaysAfter(time.Now().UTC().AddDate(0, 0, 1), 30)
+		assert.Nil(t, err)
+		assert.NotNil(t, fridays)
+		require.Equal(t, 2, len(fridays))
+		assert.True(t, friday1.Equal(fridays[0].Date))
+		assert.True(t, friday2.Equal(fridays[1].Date))
+	})
+}
+
+func TestAccessor_ListFridays(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		f2 := time.Date(2023, 12, 29, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		require.Nil(t, accessor.AddFriday(f2))
+
+		// WHEN
+		fridays, err := accessor.ListFridays()
+
+		// THEN
+		assert.Nil(t, err)
+		require.NotNil(t, fridays)
+		require.Equal(t, 2, len(fridays))
+		assert.Equal(t, f1, fridays[0].Date)
+		assert.Equal(t, f2, fridays[1].Date)
+	})
+}
+
+func TestAccessor_RemoveFriday(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		f2 := time.Date(2023, 12, 29, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		require.Nil(t, accessor.AddFriday(f2))
+
+		// WHEN
+		err := accessor.RemoveFriday(f1)
+		fridays, err2 := accessor.ListFridays()
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		require.NotNil(t, fridays)
+		require.Equal(t, 1, len(fridays))
+		assert.Equal(t, f2, fridays[0].Date)
+	})
+}
+
+func TestAccessor_FridayTimes(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+
+		// WHEN
+		friday, err := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.True(t, friday.Start.IsZero())
+		assert.Equal(t, time.Duration(0), friday.Duration)
+		assert.True(t, f1.Equal(friday.StartsAt()))
+		assert.True(t, f1.Add(pizza.EventDuration).Equal(friday.EndsAt()))
+
+		// WHEN
+		friday.Start = f1.Add(time.Hour)
+		friday.Duration = 150 * time.Minute
+		err = accessor.UpdateFriday(friday)
+		friday, err2 := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		assert.True(t, f1.Add(time.Hour).Equal(friday.StartsAt()))
+		assert.True(t, f1.Add(210*time.Minute).Equal(friday.EndsAt()))
+
+		// WHEN
+		friday.Start = time.Time{}
+		friday.Duration = 0
+		err = accessor.UpdateFriday(friday)
+		friday, err2 = accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		assert.True(t, friday.Start.IsZero())
+		assert.Equal(t, time.Duration(0), friday.Duration)
+	})
+}
+
+func TestAccessor_AddAndRemoveFriendFriday(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+
+		// WHEN
+		assert.Nil(t, accessor.AddFriendToFriday("foo", pizza.Friday{Date: f1}, ""))
+		assert.Nil(t, accessor.AddFriendToFriday("bar", pizza.Friday{Date: f1}, ""))
+		friday, err := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(friday.Guests))
+		assert.Equal(t, "foo", friday.Guests[0])
+		assert.Equal(t, "bar", friday.Guests[1])
+
+		// WHEN
+		assert.Nil(t, accessor.RemoveFriendFromFriday("foo", f1))
+		friday, err = accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(friday.Guests))
+		assert.Equal(t, "bar", friday.Guests[0])
+	})
+}
+
+func TestAccessor_GetRSVPs(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "Foo"))
+
+		// WHEN
+		assert.Nil(t, accessor.AddFriendToFriday("foo@bar.com", pizza.Friday{Date: f1}, ""))
+		assert.Nil(t, accessor.AddFriendToFriday("baz@bar.com", pizza.Friday{Date: f1}, "foo@bar.com"))
+		assert.Nil(t, accessor.RemoveFriendFromFriday("foo@bar.com", f1))
+		rsvps, err := accessor.GetRSVPs(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		require.Equal(t, 2, len(rsvps))
+		assert.Equal(t, "foo@bar.com", rsvps[0].Email)
+		assert.Equal(t, "Foo", rsvps[0].Name)
+		assert.Equal(t, pizza.RSVPDeclined, rsvps[0].Status)
+		assert.Equal(t, "", rsvps[0].CreatedBy)
+		assert.Equal(t, "baz@bar.com", rsvps[1].Email)
+		assert.Equal(t, pizza.RSVPAccepted, rsvps[1].Status)
+		assert.Equal(t, "foo@bar.com", rsvps[1].CreatedBy)
+
+		// WHEN
+		assert.Nil(t, accessor.AddFriendToFriday("foo@bar.com", pizza.Friday{Date: f1}, ""))
+		friday, err := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"baz@bar.com", "foo@bar.com"}, friday.Guests)
+	})
+}
+
+func TestAccessor_AddFriendToFridayConcurrently(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: f1, MaxGuests: 5, Enabled: true}))
+
+		// WHEN
+		numGuests := 50
+		errs := make(chan error, numGuests)
+		wg := sync.WaitGroup{}
+		for i := 0; i < numGuests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- accessor.AddFriendToFriday(fmt.Sprintf("guest%d@bar.com", i), pizza.Friday{Date: f1}, "")
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		// THEN
+		numAccepted := 0
+		numFull := 0
+		for err := range errs {
+			if err == nil {
+				numAccepted++
+			} else {
+				assert.Equal(t, pizza.ErrFridayIsFull, err)
+				numFull++
+			}
+		}
+		assert.Equal(t, 5, numAccepted)
+		assert.Equal(t, numGuests-5, numFull)
+		friday, err := accessor.GetFriday(f1)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(friday.Guests))
+	})
+}
+
+func TestAccessor_Waitlist(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: f1, MaxGuests: 1, Enabled: true}))
+		require.Nil(t, accessor.AddFriendToFriday("foo", pizza.Friday{Date: f1}, ""))
+
+		// WHEN
+		assert.Equal(t, pizza.ErrFridayIsFull, accessor.AddFriendToFriday("bar", pizza.Friday{Date: f1}, ""))
+		assert.Nil(t, accessor.AddFriendToWaitlist("bar", f1))
+		assert.Nil(t, accessor.AddFriendToWaitlist("baz", f1))
+		assert.Nil(t, accessor.AddFriendToWaitlist("foo", f1))
+		friday, err := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"foo"}, friday.Guests)
+		assert.Equal(t, []string{"bar", "baz"}, friday.Waitlist)
+
+		// WHEN
+		promoted, err := accessor.PromoteFromWaitlist(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Empty(t, promoted)
+
+		// WHEN
+		assert.Nil(t, accessor.RemoveFriendFromFriday("foo", f1))
+		promoted, err = accessor.PromoteFromWaitlist(f1)
+		friday, err2 := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		assert.Equal(t, []string{"bar"}, promoted)
+		assert.Equal(t, []string{"bar"}, friday.Guests)
+		assert.Equal(t, []string{"baz"}, friday.Waitlist)
+	})
+}
+
+func TestAccessor_AuditLog(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		f2 := time.Date(2023, 12, 29, 17, 30, 0, 0, loc)
+		now := time.Date(2023, 12, 20, 12, 0, 0, 0, loc)
+
+		// WHEN
+		assert.Nil(t, accessor.AddAuditEntry(pizza.AuditEntry{Time: now, Actor: "foo", Action: "rsvp", Friday: f1,
+			Target: "foo", After: pizza.RSVPAccepted, Source: pizza.AuditSourceWeb}))
+		assert.Nil(t, accessor.AddAuditEntry(pizza.AuditEntry{Time: now, Actor: "host", Action: "remove", Friday: f1,
+			Target: "foo", Before: pizza.RSVPAccepted, After: pizza.RSVPDeclined, Source: pizza.AuditSourceWeb}))
+		assert.Nil(t, accessor.AddAuditEntry(pizza.AuditEntry{Time: now, Actor: "bar", Action: "rsvp", Friday: f2,
+			Target: "bar", After: pizza.RSVPAccepted, Source: pizza.AuditSourceAPI}))
+		assert.Nil(t, accessor.AddAuditEntry(pizza.AuditEntry{Time: now, Actor: "host", Action: "restore",
+			Source: pizza.AuditSourceWeb}))
+		all, err := accessor.ListAuditEntries(pizza.AuditFilter{})
+
+		// THEN
+		assert.Nil(t, err)
+		require.Equal(t, 4, len(all))
+		assert.Equal(t, "restore", all[0].Action)
+		assert.True(t, all[0].Friday.IsZero())
+		assert.Equal(t, "rsvp", all[3].Action)
+		assert.True(t, now.Equal(all[3].Time))
+		assert.True(t, f1.Equal(all[3].Friday))
+
+		// WHEN
+		entries, err := accessor.ListAuditEntries(pizza.AuditFilter{Friday: f1, Target: "foo"})
+
+		// THEN
+		assert.Nil(t, err)
+		require.Equal(t, 2, len(entries))
+		assert.Equal(t, "host", entries[0].Actor)
+		assert.Equal(t, pizza.RSVPAccepted, entries[0].Before)
+		assert.Equal(t, pizza.RSVPDeclined, entries[0].After)
+		assert.Equal(t, "foo", entries[1].Actor)
+
+		// WHEN
+		entries, err = accessor.ListAuditEntries(pizza.AuditFilter{Limit: 2, Offset: 1})
+
+		// THEN
+		assert.Nil(t, err)
+		require.Equal(t, 2, len(entries))
+		assert.Equal(t, all[1].ID, entries[0].ID)
+		assert.Equal(t, all[2].ID, entries[1].ID)
+	})
+}
+
+func TestAccessor_Preferences(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "Foo"))
+		prefs := pizza.Preferences{
+			Toppings: []types.Topping{types.Pepperoni, types.Basil},
+			Cheese:   []types.Cheese{types.Ricotta},
+			Doneness: types.Well_Done,
+		}
+
+		// WHEN
+		empty, err := accessor.GetPreferences("foo@bar.com")
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, pizza.Preferences{}, empty)
+
+		// WHEN
+		assert.Nil(t, accessor.SetPreferences("foo@bar.com", prefs))
+		saved, err := accessor.GetPreferences("foo@bar.com")
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, prefs, saved)
+
+		// WHEN
+		_, err = accessor.GetPreferences("bar@bar.com")
+
+		// THEN
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAccessor_Settings(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+
+		// WHEN
+		_, missingErr := accessor.GetSetting("recurrence")
+		err := accessor.SetSetting("recurrence", "first")
+		err2 := accessor.SetSetting("recurrence", "second")
+		value, getErr := accessor.GetSetting("recurrence")
+
+		// THEN
+		assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		assert.Nil(t, getErr)
+		assert.Equal(t, "second", value)
+	})
+}
+
+func TestAccessor_Series(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		group := "book club"
+		friday := time.Date(2025, time.March, 6, 19, 0, 0, 0, time.UTC)
+
+		// WHEN
+		_, missingErr := accessor.GetSeries(1)
+		ID, err := accessor.AddSeries(pizza.Series{
+			Name:        "Pizza Thursday",
+			Description: "Pizza and books",
+			Group:       &group,
+			MaxGuests:   6,
+			Recurrence:  "DTSTART:20250102T190000 RRULE:FREQ=WEEKLY;BYDAY=TH",
+		})
+		_, dupErr := accessor.AddSeries(pizza.Series{Name: "Pizza Thursday"})
+		series, getErr := accessor.GetSeries(ID)
+		series.MaxGuests = 8
+		series.CalendarID = "books@group.calendar.google.com"
+		updateErr := accessor.UpdateSeries(series)
+		all, listErr := accessor.ListSeries()
+		require.Nil(t, accessor.AddFriday(friday))
+		saved, err2 := accessor.GetFriday(friday)
+		require.Nil(t, err2)
+		saved.SeriesID = ID
+		require.Nil(t, accessor.UpdateFriday(saved))
+		saved, err2 = accessor.GetFriday(friday)
+
+		// THEN
+		assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+		assert.Nil(t, err)
+		assert.NotNil(t, dupErr)
+		assert.Nil(t, getErr)
+		assert.Equal(t, "Pizza Thursday", series.Name)
+		assert.Equal(t, "Pizza and books", series.Description)
+		require.NotNil(t, series.Group)
+		assert.Equal(t, group, *series.Group)
+		assert.Nil(t, updateErr)
+		assert.Nil(t, listErr)
+		require.Len(t, all, 1)
+		assert.Equal(t, series, all[0])
+		assert.Equal(t, 8, all[0].MaxGuests)
+		assert.Nil(t, err2)
+		assert.Equal(t, ID, saved.SeriesID)
+	})
+}
+
+func TestAccessor_Venues(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		friday := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+
+		// WHEN
+		_, missingErr := accessor.GetVenue(1)
+		ID, err := accessor.AddVenue(pizza.Venue{
+			Name:     "Matt's",
+			Address:  "1 Main St",
+			Capacity: 8,
+			Notes:    "ring the bell",
+			Ovens:    1,
+		})
+		otherID, err1 := accessor.AddVenue(pizza.Venue{Name: "Allie's", Capacity: 12, Ovens: 2})
+		_, dupErr := accessor.AddVenue(pizza.Venue{Name: "Matt's"})
+		venue, getErr := accessor.GetVenue(ID)
+		venue.Ovens = 2
+		updateErr := accessor.UpdateVenue(venue)
+		all, listErr := accessor.ListVenues()
+		require.Nil(t, accessor.AddFriday(friday))
+		saved, err2 := accessor.GetFriday(friday)
+		require.Nil(t, err2)
+		saved.VenueID = otherID
+		require.Nil(t, accessor.UpdateFriday(saved))
+		saved, err2 = accessor.GetFriday(friday)
+
+		// THEN
+		assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+		assert.Nil(t, err)
+		assert.Nil(t, err1)
+		assert.NotNil(t, dupErr)
+		assert.Nil(t, getErr)
+		assert.Equal(t, pizza.Venue{ID: ID, Name: "Matt's", Address: "1 Main St", Capacity: 8, Notes: "ring the bell",
+			Ovens: 2}, venue)
+		assert.Nil(t, updateErr)
+		assert.Nil(t, listErr)
+		require.Len(t, all, 2)
+		// ordered by name
+		assert.Equal(t, "Allie's", all[0].Name)
+		assert.Equal(t, venue, all[1])
+		assert.Nil(t, err2)
+		assert.Equal(t, otherID, saved.VenueID)
+	})
+}
+
+func TestAccessor_FridayHosts(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		friday := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "foo"))
+		require.Nil(t, accessor.AddFriend("bar@bar.com", "bar"))
+		require.Nil(t, accessor.AddFriend("baz@bar.com", "baz"))
+		require.Nil(t, accessor.AddFriday(friday))
+
+		// WHEN
+		unowned, err := accessor.GetFriday(friday)
+		err1 := accessor.SetFridayHosts(friday, "foo@bar.com", []string{"baz@bar.com", "bar@bar.com", "nobody@bar.com"})
+		owned, err2 := accessor.GetFriday(friday)
+		err3 := accessor.SetFridayHosts(friday, "", []string{"foo@bar.com"})
+		handedOver, err4 := accessor.GetFriday(friday)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, "", unowned.Host)
+		assert.Equal(t, []string{}, unowned.CoHosts)
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Equal(t, "foo@bar.com", owned.Host)
+		// ordered by email and without anyone who is not a friend
+		assert.Equal(t, []string{"bar@bar.com", "baz@bar.com"}, owned.CoHosts)
+		assert.True(t, owned.IsHost("baz@bar.com"))
+		assert.False(t, owned.IsHost("nobody@bar.com"))
+		assert.Nil(t, err3)
+		assert.Nil(t, err4)
+		assert.Equal(t, "", handedOver.Host)
+		assert.Equal(t, []string{"foo@bar.com"}, handedOver.CoHosts)
+	})
+}
+
+func TestAccessor_PlusOnes(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		friday := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+		require.Nil(t, accessor.AddFriend("foo@bar.com", "foo"))
+		require.Nil(t, accessor.AddFriday(friday))
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: friday, MaxGuests: 2, Enabled: true}))
+		f, err := accessor.GetFriday(friday)
+		require.Nil(t, err)
+		require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", f, ""))
+
+		// WHEN
+		_, noSponsorErr := accessor.AddPlusOne(friday, pizza.PlusOne{Name: "Sam", Sponsor: "nobody@bar.com"})
+		ID, err := accessor.AddPlusOne(friday,
+			pizza.PlusOne{Name: "Alex", Email: "alex@work.com", Sponsor: "foo@bar.com"})
+		_, fullErr := accessor.AddPlusOne(friday, pizza.PlusOne{Name: "Sam", Sponsor: "foo@bar.com"})
+		rsvpErr := accessor.AddFriendToFriday("bar@bar.com", f, "")
+		require.Nil(t, accessor.AddFriendToWaitlist("bar@bar.com", friday))
+		full, err1 := accessor.PromoteFromWaitlist(friday)
+		saved, err2 := accessor.GetFriday(friday)
+		removeErr := accessor.RemovePlusOne(friday, ID)
+		promoted, err3 := accessor.PromoteFromWaitlist(friday)
+		removed, err4 := accessor.GetFriday(friday)
+
+		// THEN
+		assert.NotNil(t, noSponsorErr)
+		assert.Nil(t, err)
+		// plus-ones count toward the max guests
+		assert.ErrorIs(t, fullErr, pizza.ErrFridayIsFull)
+		assert.ErrorIs(t, rsvpErr, pizza.ErrFridayIsFull)
+		assert.Nil(t, err1)
+		assert.Empty(t, full)
+		assert.Nil(t, err2)
+		assert.Equal(t, []pizza.PlusOne{{ID: ID, Name: "Alex", Email: "alex@work.com", Sponsor: "foo@bar.com"}},
+			saved.PlusOnes)
+		assert.Equal(t, 2, saved.GuestCount())
+		assert.Nil(t, removeErr)
+		assert.Nil(t, err3)
+		assert.Equal(t, []string{"bar@bar.com"}, promoted)
+		assert.Nil(t, err4)
+		assert.Empty(t, removed.PlusOnes)
+		assert.Equal(t, []string{"foo@bar.com", "bar@bar.com"}, removed.Guests)
+	})
+}
+
+func TestAccessor_Tentative(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		friday := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+		require.Nil(t, accessor.AddFriday(friday))
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: friday, MaxGuests: 1, Enabled: true}))
+		f, err := accessor.GetFriday(friday)
+		require.Nil(t, err)
+		require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", f, ""))
+		require.Nil(t, accessor.AddFriendToWaitlist("bar@bar.com", friday))
+
+		// WHEN
+		missingErr := accessor.AddFriendAsTentative("foo@bar.com", friday.Add(time.Hour))
+		err1 := accessor.AddFriendAsTentative("baz@bar.com", friday)
+		err2 := accessor.AddFriendAsTentative("foo@bar.com", friday)
+		promoted, err3 := accessor.PromoteFromWaitlist(friday)
+		saved, err4 := accessor.GetFriday(friday)
+
+		// THEN
+		assert.NotNil(t, missingErr)
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		// maybes do not take up a spot
+		assert.Nil(t, err3)
+		assert.Equal(t, []string{"bar@bar.com"}, promoted)
+		assert.Nil(t, err4)
+		assert.Equal(t, []string{"bar@bar.com"}, saved.Guests)
+		assert.Empty(t, saved.Waitlist)
+		assert.Equal(t, []string{"baz@bar.com", "foo@bar.com"}, saved.Tentative)
+
+		// WHEN
+		fullErr := accessor.AddFriendToFriday("baz@bar.com", saved, "")
+		require.Nil(t, accessor.RemoveFriendFromFriday("bar@bar.com", friday))
+		err1 = accessor.AddFriendToFriday("baz@bar.com", saved, "")
+		saved, err2 = accessor.GetFriday(friday)
+
+		// THEN
+		assert.ErrorIs(t, fullErr, pizza.ErrFridayIsFull)
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Equal(t, []string{"baz@bar.com"}, saved.Guests)
+		assert.Equal(t, []string{"foo@bar.com"}, saved.Tentative)
+	})
+}
+
+func TestAccessor_CalendarFeed(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		friday := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+		require.Nil(t, accessor.AddFriday(friday))
+		feed := pizza.CalendarFeed{Email: "foo@bar.com", Token: "abc", Groups: []string{"pizza"}}
+
+		// WHEN
+		_, missingErr := accessor.GetCalendarFeed(feed.Email)
+		err1 := accessor.SetCalendarFeed(feed)
+		byEmail, err2 := accessor.GetCalendarFeed(feed.Email)
+		byToken, err3 := accessor.GetCalendarFeedByToken("abc")
+		err4 := accessor.SetCalendarFeed(pizza.CalendarFeed{Email: feed.Email, Token: "xyz", OpenFridays: true})
+		_, rotatedErr := accessor.GetCalendarFeedByToken("abc")
+		rotated, err5 := accessor.GetCalendarFeedByToken("xyz")
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: friday, MaxGuests: 10, Enabled: true}))
+		require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: friday, MaxGuests: 10, Enabled: false}))
+		saved, err6 := accessor.GetFriday(friday)
+
+		// THEN
+		assert.ErrorIs(t, missingErr, sql.ErrNoRows)
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Equal(t, feed, byEmail)
+		assert.Nil(t, err3)
+		assert.Equal(t, feed, byToken)
+		// a new token replaces the old one
+		assert.Nil(t, err4)
+		assert.ErrorIs(t, rotatedErr, sql.ErrNoRows)
+		assert.Nil(t, err5)
+		assert.Equal(t, pizza.CalendarFeed{Email: feed.Email, Token: "xyz", Groups: []string{}, OpenFridays: true},
+			rotated)
+		// every update of the friday is a new version of its event
+		assert.Nil(t, err6)
+		assert.Equal(t, 2, saved.Sequence)
+	})
+}
+
+func TestAccessor_Blackouts(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+
+		// WHEN
+		err1 := accessor.AddBlackout(pizza.Blackout{Day: "2025-12-26", Reason: "Boxing Day"})
+		err2 := accessor.AddBlackout(pizza.Blackout{Day: "2025-12-25", Reason: "Christmas"})
+		err3 := accessor.AddBlackout(pizza.Blackout{Day: "2025-12-26", Reason: "Second Christmas"})
+		added, listErr := accessor.ListBlackouts()
+		removeErr := accessor.RemoveBlackout("2025-12-25")
+		removed, _ := accessor.ListBlackouts()
+
+		// THEN
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Nil(t, err3)
+		assert.Nil(t, listErr)
+		assert.Equal(t, []pizza.Blackout{
+			{Day: "2025-12-25", Reason: "Christmas"},
+			{Day: "2025-12-26", Reason: "Second Christmas"},
+		}, added)
+		assert.Nil(t, removeErr)
+		assert.Equal(t, []pizza.Blackout{{Day: "2025-12-26", Reason: "Second Christmas"}}, removed)
+	})
+}
+
+func TestAccessor_RSVPPolicy(t *testing.T) {
+	forEachAccessor(t, func(t *testing.T, accessor pizza.Accessor) {
+		// GIVEN
+		loc, _ := time.LoadLocation("America/New_York")
+		f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+		require.Nil(t, accessor.AddFriday(f1))
+		policy := pizza.RSVPPolicy{OpensBefore: 48 * time.Hour, ClosesBefore: 90 * time.Minute}
+
+		// WHEN
+		friday, err := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, friday.Policy)
+
+		// WHEN
+		friday.Policy = &policy
+		err = accessor.UpdateFriday(friday)
+		friday, err2 := accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		require.NotNil(t, friday.Policy)
+		assert.Equal(t, policy, *friday.Policy)
+
+		// WHEN
+		friday.Policy = nil
+		err = accessor.UpdateFriday(friday)
+		friday, err2 = accessor.GetFriday(f1)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Nil(t, err2)
+		assert.Nil(t, friday.Policy)
+	})
+}
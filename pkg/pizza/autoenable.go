@@ -0,0 +1,215 @@
+package pizza
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	jsonapi "github.com/hashicorp/jsonapi"
+	api "github.com/mpoegel/rsvp.pizza/pkg/api"
+)
+
+const (
+	// autoEnableSetting is the kill switch of the scheduled enabling of fridays, which is paused while the setting
+	// is autoEnablePaused
+	autoEnableSetting = "auto_enable"
+	autoEnablePaused  = "paused"
+	autoEnableRunning = "running"
+)
+
+// autoEnableIsPaused reports whether a host stopped the scheduled enabling of fridays
+func (s *Server) autoEnableIsPaused() bool {
+	value, err := s.store.GetSetting(autoEnableSetting)
+	return err == nil && value == autoEnablePaused
+}
+
+// WatchAutoEnable enables the upcoming fridays periodically until the context is cancelled
+func (s *Server) WatchAutoEnable(ctx context.Context, period time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("[auto-enable] stopped")
+			return
+		case <-timer.C:
+		}
+		enabled := s.AutoEnable(time.Now())
+		slog.Info("[auto-enable] complete", "enabled", enabled)
+		timer.Reset(period)
+	}
+}
+
+// AutoEnable creates and enables the dates of every series that start within the lead time of now, and returns how
+// many it enabled. Dates that already exist are left as they are, so that a host can still disable one, and blacked
+// out days are skipped. Nothing is enabled while a host has paused the scheduler.
+func (s *Server) AutoEnable(now time.Time) int {
+	if s.autoEnableIsPaused() {
+		slog.Info("[auto-enable] paused")
+		s.autoEnablePausedMetric.Increment()
+		return 0
+	}
+	config := s.config.AutoEnable
+	blackouts := s.blackouts()
+	seen := make(map[int64]bool)
+	enabled := 0
+	for _, series := range s.allSeries() {
+		rec, ok := s.seriesRecurrence(series)
+		if !ok {
+			continue
+		}
+		for _, fridayTime := range rec.Between(now, now.Add(config.LeadTime)) {
+			// when two series share a start time, the date belongs to the first
+			if seen[fridayTime.Unix()] {
+				continue
+			}
+			seen[fridayTime.Unix()] = true
+			if _, err := s.store.GetFriday(fridayTime); err == nil {
+				continue
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				// the friday may well exist, so it is left for the next run rather than created again
+				slog.Error("[auto-enable] failed to get friday", "err", err, "friday", fridayTime)
+				s.autoEnableErrorMetric.Increment()
+				continue
+			}
+			friday := newSeriesFriday(series, fridayTime)
+			if _, blackedOut := blackouts[s.blackoutDay(friday)]; blackedOut {
+				continue
+			}
+			if err := s.autoEnableFriday(friday); err != nil {
+				slog.Error("[auto-enable] failed to enable friday", "err", err, "friday", fridayTime)
+				s.autoEnableErrorMetric.Increment()
+				continue
+			}
+			s.autoEnabledMetric.Increment()
+			enabled++
+		}
+	}
+	return enabled
+}
+
+// autoEnableFriday saves the new friday of a series with the defaults of the scheduler and creates its calendar
+// event, so that the event is there before the first RSVP
+func (s *Server) autoEnableFriday(friday Friday) error {
+	config := s.config.AutoEnable
+	if err := s.store.AddFriday(friday.Date); err != nil {
+		return err
+	}
+	saved, err := s.store.GetFriday(friday.Date)
+	if err != nil {
+		return err
+	}
+	before := fridaySettings(saved)
+	if friday.MaxGuests <= 0 {
+		friday.MaxGuests = saved.MaxGuests
+	}
+	if len(config.Group) > 0 {
+		friday.Group = &config.Group
+	}
+	if len(config.Details) > 0 {
+		friday.Details = &config.Details
+	}
+	if config.MaxGuests > 0 {
+		friday.MaxGuests = config.MaxGuests
+	}
+	friday.Enabled = true
+	if err = s.store.UpdateFriday(friday); err != nil {
+		return err
+	}
+
+	if s.config.Calendar.Enabled {
+		ID := strconv.FormatInt(friday.Date.Unix(), 10)
+		cal := s.calendarFor(friday)
+		if _, err = cal.GetEvent(ID); err == ErrEventNotFound {
+			err = cal.CreateEvent(s.newCalendarEvent(ID, friday))
+		} else if err == nil {
+			// the event may have been cancelled when the friday was last disabled
+			err = cal.ActivateEvent(ID)
+		}
+		if err != nil {
+			// the event is created with the first RSVP instead
+			slog.Warn("[auto-enable] failed to create calendar event", "err", err, "eventID", ID)
+		}
+	}
+
+	s.audit(AuditEntry{
+		Action: "enable",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(friday),
+		Source: AuditSourceScheduler,
+	})
+	slog.Info("[auto-enable] enabled friday", "friday", friday.Date)
+	return nil
+}
+
+func (s *Server) HandleAPIAutoEnable(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := s.CheckAuthorization(r)
+	if !ok {
+		WriteAPIErrorStatus(errors.New("not authorized"), http.StatusUnauthorized, w)
+		return
+	}
+
+	if r.Header.Get("Accept") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("must accept %s", jsonapi.MediaType), http.StatusNotAcceptable, w)
+		return
+	}
+
+	if !isAdmin(&accessToken.Claims) {
+		WriteAPIErrorStatus(errors.New("only hosts can see the auto-enable scheduler"), http.StatusForbidden, w)
+		return
+	}
+
+	paused := s.autoEnableIsPaused()
+	if r.Method == http.MethodPatch {
+		if r.Header.Get("Content-Type") != jsonapi.MediaType {
+			WriteAPIErrorStatus(fmt.Errorf("unsupported media type '%s'", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType, w)
+			return
+		}
+		payload, err := api.UnmarshalAutoEnable(r.Body)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		before, after := autoEnableRunning, autoEnableRunning
+		if paused {
+			before = autoEnablePaused
+		}
+		if payload.Paused {
+			after = autoEnablePaused
+		}
+		if err = s.store.SetSetting(autoEnableSetting, after); err != nil {
+			slog.Error("failed to save auto-enable", "error", err)
+			WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+			return
+		}
+		if before != after {
+			s.audit(AuditEntry{
+				Actor:  accessToken.Claims.Email,
+				Action: "auto-enable",
+				Before: before,
+				After:  after,
+				Source: AuditSourceAPI,
+			})
+		}
+		paused = payload.Paused
+	}
+
+	res := &api.AutoEnable{
+		ID:            "default",
+		Enabled:       s.config.AutoEnable.Enabled,
+		Paused:        paused,
+		LeadTimeHours: int(s.config.AutoEnable.LeadTime / time.Hour),
+	}
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(http.StatusOK)
+	if err := jsonapi.MarshalPayload(w, res); err != nil {
+		slog.Warn("api marshal payload", "error", err)
+		WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+	}
+}
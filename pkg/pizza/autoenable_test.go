@@ -0,0 +1,159 @@
+package pizza_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoEnable(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	config.AutoEnable = pizza.AutoEnableConfig{
+		Enabled:   true,
+		LeadTime:  21 * 24 * time.Hour,
+		Interval:  time.Hour,
+		Group:     "friends",
+		Details:   "bring a drink",
+		MaxGuests: 6,
+	}
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	enabledCounter := &pizza.MockCounterMetric{}
+	pausedCounter := &pizza.MockCounterMetric{}
+	counter := &pizza.MockCounterMetric{}
+	loc := mustLoadNY(t)
+
+	metrics.On("NewCounterMetric", "pizza_auto_enable", map[string]string{"result": "enabled"}).Return(enabledCounter)
+	metrics.On("NewCounterMetric", "pizza_auto_enable", map[string]string{"result": "paused"}).Return(pausedCounter)
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	enabledCounter.On("Increment").Return()
+	pausedCounter.On("Increment").Return()
+
+	now := time.Date(2025, time.March, 3, 12, 0, 0, 0, loc)
+	first := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	blackedOut := time.Date(2025, time.March, 14, 17, 30, 0, 0, loc)
+	disabled := time.Date(2025, time.March, 21, 17, 30, 0, 0, loc)
+	require.Nil(t, accessor.AddBlackout(pizza.Blackout{Day: "2025-03-14", Reason: "vacation"}))
+	// a host already decided on this one
+	require.Nil(t, accessor.AddFriday(disabled))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: disabled, MaxGuests: 10, Enabled: false}))
+	firstID := "1741386600"
+	calendar.On("GetEvent", firstID).Return(pizza.CalendarEvent{}, pizza.ErrEventNotFound).Once()
+	calendar.On("CreateEvent", mock.MatchedBy(func(event pizza.CalendarEvent) bool {
+		return event.Id == firstID && event.Summary == "Pizza Friday" && event.StartTime.Equal(first)
+	})).Return(nil).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+
+	// WHEN
+	enabled := server.AutoEnable(now)
+	friday, err := accessor.GetFriday(first)
+	_, blackedOutErr := accessor.GetFriday(blackedOut)
+	stillDisabled, err2 := accessor.GetFriday(disabled)
+	entries, err3 := accessor.ListAuditEntries(pizza.AuditFilter{Friday: first})
+
+	// THEN
+	assert.Equal(t, 1, enabled)
+	assert.Nil(t, err)
+	assert.True(t, friday.Enabled)
+	assert.Equal(t, 6, friday.MaxGuests)
+	require.NotNil(t, friday.Group)
+	assert.Equal(t, "friends", *friday.Group)
+	require.NotNil(t, friday.Details)
+	assert.Equal(t, "bring a drink", *friday.Details)
+	assert.NotNil(t, blackedOutErr)
+	assert.Nil(t, err2)
+	assert.False(t, stillDisabled.Enabled)
+	assert.Nil(t, err3)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "enable", entries[0].Action)
+	assert.Equal(t, pizza.AuditSourceScheduler, entries[0].Source)
+	enabledCounter.AssertNumberOfCalls(t, "Increment", 1)
+
+	// WHEN
+	// the next run has nothing left to do
+	again := server.AutoEnable(now)
+	require.Nil(t, accessor.SetSetting("auto_enable", "paused"))
+	paused := server.AutoEnable(now.AddDate(0, 0, 7))
+
+	// THEN
+	assert.Equal(t, 0, again)
+	assert.Equal(t, 0, paused)
+	pausedCounter.AssertNumberOfCalls(t, "Increment", 1)
+	_, err = accessor.GetFriday(time.Date(2025, time.March, 28, 17, 30, 0, 0, loc))
+	assert.NotNil(t, err)
+	calendar.AssertExpectations(t)
+}
+
+func TestAutoEnable_LookupFails(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	config.AutoEnable = pizza.AutoEnableConfig{Enabled: true, LeadTime: 21 * 24 * time.Hour, Interval: time.Hour}
+	accessor := &pizza.MockAccessor{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	errorCounter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", "pizza_auto_enable", map[string]string{"result": "failed"}).Return(errorCounter)
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	errorCounter.On("Increment").Return()
+	accessor.On("GetSetting", mock.Anything).Return("", sql.ErrNoRows)
+	accessor.On("ListBlackouts").Return(nil, nil)
+	accessor.On("ListSeries").Return(nil, nil)
+	accessor.On("GetFriday", mock.Anything).Return(pizza.Friday{}, errors.New("database is locked"))
+
+	server, err := pizza.NewServer(config, accessor, nil, &pizza.MockAuthenticator{}, metrics)
+	require.Nil(t, err)
+
+	// WHEN
+	enabled := server.AutoEnable(time.Date(2025, time.March, 3, 12, 0, 0, 0, mustLoadNY(t)))
+
+	// THEN
+	// fridays that may exist are not created again
+	assert.Equal(t, 0, enabled)
+	accessor.AssertNotCalled(t, "AddFriday", mock.Anything)
+	errorCounter.AssertNumberOfCalls(t, "Increment", 3)
+}
+
+func TestWatchAutoEnable(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+	server, err := pizza.NewServer(config, pizza.NewMemoryAccessor(), nil, &pizza.MockAuthenticator{}, metrics)
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// WHEN
+	go func() {
+		server.WatchAutoEnable(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+
+	// THEN
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the auto-enable scheduler did not stop")
+	}
+}
@@ -0,0 +1,224 @@
+package pizza
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+var ErrSnapshotTooNew = errors.New("snapshot schema is newer than this version of rsvp.pizza")
+
+// Backuper is implemented by accessors that can snapshot their database while it is in use
+type Backuper interface {
+	Backup(file string) error
+}
+
+// sqliteBackup copies the main database of src into dest with SQLite's online backup API
+func sqliteBackup(ctx context.Context, dest, src *sql.DB) error {
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			destSQLite, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("destination is not a sqlite database")
+			}
+			srcSQLite, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("source is not a sqlite database")
+			}
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			// copy all pages in one step, which sqlite restarts if the source is written to meanwhile
+			if _, err = backup.Step(-1); err != nil {
+				backup.Close()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// Backup writes a consistent snapshot of the database to the file without blocking the server. The file is only
+// replaced once the snapshot is complete and has the same schema version as the database.
+func (a *SQLAccessor) Backup(file string) error {
+	version, err := a.Migrator().Version()
+	if err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	os.Remove(tmpFile)
+	dest, err := sql.Open("sqlite3", tmpFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+	if err = sqliteBackup(context.Background(), dest, a.db); err != nil {
+		dest.Close()
+		return err
+	}
+	snapshotVersion, err := NewMigrator(dest, SQLiteMigrations).Version()
+	dest.Close()
+	if err != nil {
+		return err
+	}
+	if snapshotVersion != version {
+		return fmt.Errorf("snapshot has schema version %d but the database has %d", snapshotVersion, version)
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// Restore replaces the whole database with the snapshot in the file. Snapshots from older versions are migrated
+// after they are restored, but snapshots with a schema newer than the latest migration are refused.
+func (a *SQLAccessor) Restore(file string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+	src, err := sql.Open("sqlite3", "file:"+file+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	migrator := NewMigrator(src, SQLiteMigrations)
+	version, err := migrator.Version()
+	if err != nil {
+		return fmt.Errorf("snapshot has no schema version: %w", err)
+	}
+	if version > migrator.Latest() {
+		return fmt.Errorf("%w: %d > %d", ErrSnapshotTooNew, version, migrator.Latest())
+	}
+	if err = sqliteBackup(context.Background(), a.db, src); err != nil {
+		return err
+	}
+	if version < migrator.Latest() {
+		slog.Info("migrating restored snapshot", "from", version, "to", migrator.Latest())
+		return a.PatchTables()
+	}
+	return nil
+}
+
+const snapshotPrefix = "pizza-"
+const snapshotTimeFormat = "20060102T150405Z"
+
+// TakeSnapshot backs up to a new timestamped file in the directory and then deletes all but the newest retain
+// snapshots. A retain of zero keeps every snapshot.
+func TakeSnapshot(backuper Backuper, dir string, retain int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, snapshotPrefix+now.UTC().Format(snapshotTimeFormat)+".db")
+	if err := backuper.Backup(file); err != nil {
+		return "", err
+	}
+	if retain <= 0 {
+		return file, nil
+	}
+	snapshots, err := filepath.Glob(filepath.Join(dir, snapshotPrefix+"*.db"))
+	if err != nil {
+		return file, err
+	}
+	// the timestamps sort in the order the snapshots were taken
+	slices.Sort(snapshots)
+	for len(snapshots) > retain {
+		if err = os.Remove(snapshots[0]); err != nil {
+			return file, err
+		}
+		snapshots = snapshots[1:]
+	}
+	return file, nil
+}
+
+// WatchBackups snapshots the database periodically until the context is cancelled. Nothing is snapshotted without a
+// positive period, which would otherwise snapshot and rotate the files in a loop.
+func (s *Server) WatchBackups(ctx context.Context, backuper Backuper, period time.Duration) {
+	if period <= 0 {
+		slog.Warn("[backup] scheduled backups need a positive interval", "interval", period)
+		return
+	}
+	timer := time.NewTimer(period)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("[backup] stopped")
+			return
+		case <-timer.C:
+		}
+		file, err := TakeSnapshot(backuper, s.config.Backup.Dir, s.config.Backup.Retain, time.Now())
+		if err != nil {
+			slog.Error("[backup] failed to snapshot database", "err", err, "dir", s.config.Backup.Dir)
+		} else {
+			slog.Info("[backup] snapshot complete", "file", file)
+		}
+		timer.Reset(period)
+	}
+}
+
+func openBackupAccessor(config Config) *SQLAccessor {
+	if len(config.PostgresDSN) > 0 || config.UseMemoryStore {
+		slog.Error("backups are only supported for the sqlite database, use pg_dump for postgres")
+		os.Exit(1)
+	}
+	accessor, err := NewSQLAccessor(config.DBFile, true)
+	if err != nil {
+		slog.Error("sql accessor init failure", "error", err)
+		os.Exit(1)
+	}
+	return accessor
+}
+
+func Backup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("out", "", "file to write the snapshot to")
+	fs.Parse(args)
+	if len(*out) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	accessor := openBackupAccessor(LoadConfigEnv())
+	defer accessor.Close()
+	if err := accessor.Backup(*out); err != nil {
+		slog.Error("backup failed", "error", err, "out", *out)
+		os.Exit(1)
+	}
+	slog.Info("backup complete", "out", *out)
+}
+
+func Restore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("in", "", "snapshot file to restore")
+	fs.Parse(args)
+	if len(*in) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	accessor := openBackupAccessor(LoadConfigEnv())
+	defer accessor.Close()
+	if err := accessor.Restore(*in); err != nil {
+		slog.Error("restore failed", "error", err, "in", *in)
+		os.Exit(1)
+	}
+	slog.Info("restore complete", "in", *in)
+}
@@ -0,0 +1,161 @@
+package pizza_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLAccessor(t *testing.T, file string) *pizza.SQLAccessor {
+	accessor, err := pizza.NewSQLAccessor(file, true)
+	require.Nil(t, err)
+	t.Cleanup(func() { accessor.Close() })
+	require.Nil(t, accessor.CreateTables())
+	return accessor
+}
+
+func TestSqlAccessor_BackupAndRestore(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	accessor := newTestSQLAccessor(t, filepath.Join(dir, "pizza.db"))
+	require.Nil(t, accessor.AddFriend("foo@bar.com", "test"))
+	snapshot := filepath.Join(dir, "snapshot.db")
+
+	// WHEN
+	err := accessor.Backup(snapshot)
+
+	// THEN
+	assert.Nil(t, err)
+	_, err = os.Stat(snapshot + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	// WHEN
+	restored := newTestSQLAccessor(t, filepath.Join(dir, "restored.db"))
+	err = restored.Restore(snapshot)
+	friend, err2 := restored.GetFriendByEmail("foo@bar.com")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, "test", friend.Name)
+	version, err := restored.Migrator().Version()
+	assert.Nil(t, err)
+	assert.Equal(t, len(pizza.SQLiteMigrations), version)
+}
+
+func TestSqlAccessor_RestoreTooNew(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot.db")
+	newer := newTestSQLAccessor(t, snapshot)
+	require.Nil(t, newer.AddFriend("foo@bar.com", "test"))
+	db := openTestDBFile(t, snapshot)
+	_, err := db.Exec("UPDATE app_versions SET version = 99 WHERE name = 'schema'")
+	require.Nil(t, err)
+	accessor := newTestSQLAccessor(t, filepath.Join(dir, "pizza.db"))
+
+	// WHEN
+	err = accessor.Restore(snapshot)
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrSnapshotTooNew)
+	_, err = accessor.GetFriendByEmail("foo@bar.com")
+	assert.NotNil(t, err)
+}
+
+func TestTakeSnapshot_Retain(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	accessor := newTestSQLAccessor(t, filepath.Join(dir, "pizza.db"))
+	backupDir := filepath.Join(dir, "backups")
+	start := time.Date(2025, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	// WHEN
+	files := []string{}
+	for i := range 4 {
+		file, err := pizza.TakeSnapshot(accessor, backupDir, 2, start.Add(time.Duration(i)*time.Hour))
+		require.Nil(t, err)
+		files = append(files, file)
+	}
+	snapshots, err := filepath.Glob(filepath.Join(backupDir, "*"))
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, files[2:], snapshots)
+	assert.Equal(t, filepath.Join(backupDir, "pizza-20250307T150000Z.db"), files[3])
+}
+
+func TestWatchBackups(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	accessor := newTestSQLAccessor(t, filepath.Join(dir, "pizza.db"))
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	config.Backup.Dir = filepath.Join(dir, "backups")
+	config.Backup.Retain = 2
+	metrics := &pizza.MockMetricsRegistry{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(&pizza.MockCounterMetric{})
+	server, err := pizza.NewServer(config, accessor, nil, &pizza.MockAuthenticator{}, metrics)
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// WHEN
+	go func() {
+		server.WatchBackups(ctx, accessor, 10*time.Millisecond)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		snapshots, err := filepath.Glob(filepath.Join(config.Backup.Dir, "*"))
+		return err == nil && len(snapshots) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	// THEN
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backups did not stop")
+	}
+}
+
+func TestWatchBackups_ZeroInterval(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	accessor := newTestSQLAccessor(t, filepath.Join(dir, "pizza.db"))
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	config.Backup.Dir = filepath.Join(dir, "backups")
+	config.Backup.Interval = 0
+	metrics := &pizza.MockMetricsRegistry{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(&pizza.MockCounterMetric{})
+	server, err := pizza.NewServer(config, accessor, nil, &pizza.MockAuthenticator{}, metrics)
+	require.Nil(t, err)
+	done := make(chan struct{})
+
+	// WHEN
+	go func() {
+		server.WatchBackups(context.Background(), accessor, config.Backup.Interval)
+		close(done)
+	}()
+
+	// THEN
+	// the backups are off rather than taken in a loop
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the backups did not stop")
+	}
+	snapshots, err := filepath.Glob(filepath.Join(config.Backup.Dir, "*"))
+	assert.Nil(t, err)
+	assert.Empty(t, snapshots)
+}
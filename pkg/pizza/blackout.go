@@ -0,0 +1,232 @@
+package pizza
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxBlackoutDays is the longest event that an ICS import turns into blackouts, so that a mistaken event does not
+// black out years of pizza
+const maxBlackoutDays = 366
+
+// ParseICSBlackouts reads the events of an iCalendar file, like exported national holidays or a vacation calendar, as
+// one blackout for every day in loc that an event covers, with the summary of the event as the reason. Cancelled
+// events are skipped and recurring events only black out their first day.
+func ParseICSBlackouts(r io.Reader, loc *time.Location) ([]Blackout, error) {
+	events, err := readICalComponents(r, "VEVENT")
+	if err != nil {
+		return nil, err
+	}
+	reasons := make(map[string][]string)
+	days := make([]string, 0)
+	for _, event := range events {
+		if status, ok := event.Get("STATUS"); ok && strings.EqualFold(status.Value, "CANCELLED") {
+			continue
+		}
+		summary := ""
+		if property, ok := event.Get("SUMMARY"); ok {
+			summary = strings.TrimSpace(unescapeICalText(property.Value))
+		}
+		if _, ok := event.Get("RRULE"); ok {
+			slog.Warn("only the first day of a recurring event is blacked out", "event", summary)
+		}
+		eventDays, err := icalEventDays(event, loc)
+		if err != nil {
+			return nil, fmt.Errorf("event %q: %w", summary, err)
+		}
+		for _, day := range eventDays {
+			if _, ok := reasons[day]; !ok {
+				days = append(days, day)
+			}
+			if len(summary) > 0 && !slices.Contains(reasons[day], summary) {
+				reasons[day] = append(reasons[day], summary)
+			}
+		}
+	}
+	result := make([]Blackout, 0, len(days))
+	for _, day := range days {
+		result = append(result, Blackout{Day: day, Reason: strings.Join(reasons[day], ", ")})
+	}
+	return result, nil
+}
+
+// icalEventDays lists the days in loc from the start of the event until its end. The end of an all-day event is
+// the day after its last day, and an event without an end lasts for a day when it is all-day and an instant otherwise.
+func icalEventDays(event icalComponent, loc *time.Location) ([]string, error) {
+	startProp, ok := event.Get("DTSTART")
+	if !ok {
+		return nil, errors.New("no DTSTART")
+	}
+	start, allDay, err := parseICalEventTime(startProp, loc)
+	if err != nil {
+		return nil, fmt.Errorf("DTSTART: %w", err)
+	}
+	end := start
+	if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if endProp, ok := event.Get("DTEND"); ok {
+		if end, _, err = parseICalEventTime(endProp, loc); err != nil {
+			return nil, fmt.Errorf("DTEND: %w", err)
+		}
+	}
+	if allDay || end.After(start) {
+		// the end is exclusive
+		end = end.Add(-time.Nanosecond)
+	}
+	if end.Before(start) {
+		return nil, errors.New("ends before it starts")
+	}
+
+	days := make([]string, 0)
+	first := start.In(loc)
+	last := end.In(loc).Format(BlackoutDayFormat)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); ; day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(BlackoutDayFormat))
+		if day.Format(BlackoutDayFormat) == last {
+			return days, nil
+		}
+		if len(days) >= maxBlackoutDays {
+			return nil, fmt.Errorf("lasts more than %d days", maxBlackoutDays)
+		}
+	}
+}
+
+// parseICalEventTime reads a DTSTART or DTEND, which is all-day when it is a DATE. Times in an unknown TZID, like the
+// Windows zone names of some exports, are read in loc.
+func parseICalEventTime(property icalProperty, loc *time.Location) (time.Time, bool, error) {
+	allDay := property.Params["VALUE"] == "DATE" || len(property.Value) == len(icalDateFormat)
+	propLoc := loc
+	if tzid, ok := property.Params["TZID"]; ok {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			propLoc = tz
+		} else {
+			slog.Warn("unknown timezone in calendar, using the deployment timezone", "tzid", tzid)
+		}
+	}
+	if allDay {
+		// all-day events are the same day everywhere
+		propLoc = loc
+	}
+	t, err := parseICalTime(property.Value, propLoc)
+	return t, allDay, err
+}
+
+// ImportBlackouts saves the blackouts, replacing the reason of days that are already blacked out
+func ImportBlackouts(accessor Accessor, blackouts []Blackout) error {
+	for _, blackout := range blackouts {
+		if err := accessor.AddBlackout(blackout); err != nil {
+			return fmt.Errorf("blackout %s: %w", blackout.Day, err)
+		}
+	}
+	return nil
+}
+
+// blackouts maps each blacked out day to its reason
+func (s *Server) blackouts() map[string]string {
+	result := make(map[string]string)
+	blackouts, err := s.store.ListBlackouts()
+	if err != nil {
+		slog.Error("failed to list blackouts", "error", err)
+		return result
+	}
+	for _, blackout := range blackouts {
+		result[blackout.Day] = blackout.Reason
+	}
+	return result
+}
+
+// blackoutDay is the day of the friday as blackouts are written
+func (s *Server) blackoutDay(friday Friday) string {
+	return friday.Date.In(s.loc).Format(BlackoutDayFormat)
+}
+
+// isBlackedOut reports whether there is a blackout on the day of the friday and why
+func (s *Server) isBlackedOut(friday Friday) (string, bool) {
+	reason, ok := s.blackouts()[s.blackoutDay(friday)]
+	return reason, ok
+}
+
+const blackoutUsage = `usage: rsvp.pizza blackout [-in FILE.ics | -add DAY [-reason REASON] | -remove DAY | -list]
+
+Days are written as 2006-01-02 in the timezone of the deployment.
+`
+
+func Blackouts(args []string) {
+	fs := flag.NewFlagSet("blackout", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), blackoutUsage)
+		fs.PrintDefaults()
+	}
+	in := fs.String("in", "", "iCalendar file whose events are blacked out")
+	add := fs.String("add", "", "day to black out")
+	reason := fs.String("reason", "", "why there is no pizza on the added day")
+	remove := fs.String("remove", "", "day that is no longer blacked out")
+	list := fs.Bool("list", false, "list the blacked out days")
+	fs.Parse(args)
+
+	config := LoadConfigEnv()
+	loc, err := config.Location()
+	if err != nil {
+		slog.Error("invalid timezone", "error", err)
+		os.Exit(1)
+	}
+	accessor, err := NewAccessor(config, true)
+	if err != nil {
+		slog.Error("accessor init failure", "error", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case len(*in) > 0:
+		f, err := os.Open(*in)
+		if err != nil {
+			slog.Error("could not open calendar", "error", err, "in", *in)
+			os.Exit(1)
+		}
+		blackouts, err := ParseICSBlackouts(f, loc)
+		f.Close()
+		if err != nil {
+			slog.Error("could not read calendar", "error", err, "in", *in)
+			os.Exit(1)
+		}
+		if err = ImportBlackouts(accessor, blackouts); err != nil {
+			slog.Error("blackout import failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("blackout import complete", "days", len(blackouts))
+	case len(*add) > 0:
+		if _, err := time.ParseInLocation(BlackoutDayFormat, *add, loc); err != nil {
+			slog.Error("invalid day", "error", err, "day", *add)
+			os.Exit(2)
+		}
+		if err = accessor.AddBlackout(Blackout{Day: *add, Reason: *reason}); err != nil {
+			slog.Error("failed to add blackout", "error", err)
+			os.Exit(1)
+		}
+	case len(*remove) > 0:
+		if err = accessor.RemoveBlackout(*remove); err != nil {
+			slog.Error("failed to remove blackout", "error", err)
+			os.Exit(1)
+		}
+	case *list:
+		blackouts, err := accessor.ListBlackouts()
+		if err != nil {
+			slog.Error("failed to list blackouts", "error", err)
+			os.Exit(1)
+		}
+		for _, blackout := range blackouts {
+			fmt.Printf("%s\t%s\n", blackout.Day, blackout.Reason)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
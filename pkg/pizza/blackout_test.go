@@ -0,0 +1,75 @@
+package pizza_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseICSBlackouts(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20251225",
+		"DTEND;VALUE=DATE:20251227",
+		"SUMMARY:Christmas\\, and Boxing Day",
+		"BEGIN:VALARM",
+		"SUMMARY:reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20251226",
+		"SUMMARY:Office",
+		"  closed",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=\"Europe/London\":20260102T030000",
+		"DTEND;TZID=\"Europe/London\":20260102T040000",
+		"SUMMARY:Late flight",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20260109",
+		"STATUS:CANCELLED",
+		"SUMMARY:Cancelled trip",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	// WHEN
+	blackouts, err := pizza.ParseICSBlackouts(strings.NewReader(ics), loc)
+
+	// THEN
+	require.Nil(t, err)
+	assert.Equal(t, []pizza.Blackout{
+		{Day: "2025-12-25", Reason: "Christmas, and Boxing Day"},
+		{Day: "2025-12-26", Reason: "Christmas, and Boxing Day, Office closed"},
+		// 3am in London is still the day before in New York
+		{Day: "2026-01-01", Reason: "Late flight"},
+	}, blackouts)
+}
+
+func TestParseICSBlackouts_Invalid(t *testing.T) {
+	loc := mustLoadNY(t)
+	cases := map[string]string{
+		"missing end":   "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20251225\n",
+		"missing start": "BEGIN:VEVENT\nSUMMARY:nothing\nEND:VEVENT\n",
+		"bad line":      "BEGIN:VEVENT\nDTSTART\nEND:VEVENT\n",
+		"backwards":     "BEGIN:VEVENT\nDTSTART:20251225T100000Z\nDTEND:20251224T100000Z\nEND:VEVENT\n",
+		"too long":      "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20250101\nDTEND;VALUE=DATE:20270101\nEND:VEVENT\n",
+	}
+	for name, ics := range cases {
+		t.Run(name, func(t *testing.T) {
+			// WHEN
+			_, err := pizza.ParseICSBlackouts(strings.NewReader(ics), loc)
+
+			// THEN
+			assert.NotNil(t, err)
+		})
+	}
+}
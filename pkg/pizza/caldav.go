@@ -0,0 +1,448 @@
+package pizza
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const DefaultCalDAVTimeout = 5 * time.Second
+
+var (
+	// ErrEventChanged is returned when the event changed on the server while it was being updated
+	ErrEventChanged = errors.New("event changed on the server")
+
+	// errEventUnchanged tells updateCalDAVEvent that there is nothing to save
+	errEventUnchanged = errors.New("event unchanged")
+)
+
+// CalDAVCalendar keeps the events in a CalDAV calendar collection, like a calendar of Nextcloud, as one iCalendar
+// resource per event that is named after the event ID. The Google specific settings of an event, like Locked, are not
+// kept, and neither are the alarms that were added to an event on the server once it is updated.
+type CalDAVCalendar struct {
+	client   *http.Client
+	url      *url.URL
+	username string
+	password string
+	loc      *time.Location
+}
+
+// NewCalDAVCalendar uses the calendar collection at rawURL, logging in with the username and password when the
+// username is not empty. Times without a timezone are read in loc.
+func NewCalDAVCalendar(rawURL, username, password string, loc *time.Location) (*CalDAVCalendar, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("caldav url '%s' must be absolute", rawURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &CalDAVCalendar{
+		client:   &http.Client{Timeout: DefaultCalDAVTimeout},
+		url:      u,
+		username: username,
+		password: password,
+		loc:      loc,
+	}, nil
+}
+
+// WithCalendarID uses the same login on another calendar, which is either the URL of its collection or the name of a
+// collection next to this one
+func (c *CalDAVCalendar) WithCalendarID(ID string) Calendar {
+	other := *c
+	if u, err := url.Parse(ID); err == nil && u.IsAbs() {
+		other.url = u
+	} else {
+		other.url = c.url.ResolveReference(&url.URL{Path: "../" + url.PathEscape(ID)})
+	}
+	if !strings.HasSuffix(other.url.Path, "/") {
+		other.url.Path += "/"
+	}
+	return &other
+}
+
+func (c *CalDAVCalendar) eventURL(eventID string) string {
+	return c.url.ResolveReference(&url.URL{Path: url.PathEscape(eventID) + ".ics"}).String()
+}
+
+func (c *CalDAVCalendar) do(method, target string, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.client.Do(req)
+}
+
+// getCalDAVEvent returns the VEVENT of the event with the ETag of its resource
+func (c *CalDAVCalendar) getCalDAVEvent(eventID string) (icalComponent, string, error) {
+	res, err := c.do(http.MethodGet, c.eventURL(eventID), nil, nil)
+	if err != nil {
+		return icalComponent{}, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return icalComponent{}, "", ErrEventNotFound
+	} else if res.StatusCode != http.StatusOK {
+		return icalComponent{}, "", fmt.Errorf("caldav get %s: %s", eventID, res.Status)
+	}
+	events, err := readICalComponents(res.Body, "VEVENT")
+	if err != nil {
+		return icalComponent{}, "", err
+	}
+	if len(events) == 0 {
+		return icalComponent{}, "", ErrEventNotFound
+	}
+	return events[0], res.Header.Get("ETag"), nil
+}
+
+// putCalDAVEvent saves the event, which must not exist yet when etag is empty and must not have changed since it was
+// read otherwise
+func (c *CalDAVCalendar) putCalDAVEvent(eventID string, event icalComponent, etag string) error {
+	event.Set(icalProperty{Name: "DTSTAMP", Value: icalUTCTime(time.Now())})
+	body := &bytes.Buffer{}
+	if err := writeICalendar(body, nil, event); err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if len(etag) > 0 {
+		header.Set("If-Match", etag)
+	} else {
+		header.Set("If-None-Match", "*")
+	}
+	res, err := c.do(http.MethodPut, c.eventURL(eventID), body.Bytes(), header)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusPreconditionFailed {
+		return ErrEventChanged
+	} else if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent &&
+		res.StatusCode != http.StatusOK {
+		return fmt.Errorf("caldav put %s: %s", eventID, res.Status)
+	}
+	return nil
+}
+
+// updateCalDAVEvent changes the event and saves it
+func (c *CalDAVCalendar) updateCalDAVEvent(eventID string, update func(*icalComponent) error) error {
+	event, etag, err := c.getCalDAVEvent(eventID)
+	if err != nil {
+		return err
+	}
+	if err = update(&event); err == errEventUnchanged {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return c.putCalDAVEvent(eventID, event, etag)
+}
+
+func (c *CalDAVCalendar) CreateEvent(newEvent CalendarEvent) error {
+	event := icalComponent{Name: "VEVENT"}
+	event.Set(icalProperty{Name: "UID", Value: newEvent.Id})
+	event.Set(icalProperty{Name: "DTSTART", Value: icalUTCTime(newEvent.StartTime)})
+	event.Set(icalProperty{Name: "DTEND", Value: icalUTCTime(newEvent.EndTime)})
+	event.Set(icalProperty{Name: "SUMMARY", Value: escapeICalText(newEvent.Summary)})
+	if len(newEvent.Description) > 0 {
+		event.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(newEvent.Description)})
+	}
+	if len(newEvent.Location) > 0 {
+		event.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(newEvent.Location)})
+	}
+	if len(newEvent.Status) > 0 {
+		event.Set(icalProperty{Name: "STATUS", Value: strings.ToUpper(newEvent.Status)})
+	}
+	if len(newEvent.Visibility) > 0 && newEvent.Visibility != "default" {
+		event.Set(icalProperty{Name: "CLASS", Value: strings.ToUpper(newEvent.Visibility)})
+	}
+	attendees := make([]icalProperty, len(newEvent.Attendees))
+	for i, attendee := range newEvent.Attendees {
+		attendees[i] = calDAVAttendee(attendee.Email, "", attendee.ResponseStatus)
+	}
+	event.SetAll("ATTENDEE", attendees)
+	return c.putCalDAVEvent(newEvent.Id, event, "")
+}
+
+func (c *CalDAVCalendar) GetEvent(eventID string) (CalendarEvent, error) {
+	event, _, err := c.getCalDAVEvent(eventID)
+	if err != nil {
+		return CalendarEvent{}, err
+	}
+	return c.calDAVEventToEvent(event), nil
+}
+
+func (c *CalDAVCalendar) UpdateEvent(updated CalendarEvent) error {
+	return c.updateCalDAVEvent(updated.Id, func(event *icalComponent) error {
+		event.Set(icalProperty{Name: "DTSTART", Value: icalUTCTime(updated.StartTime)})
+		event.Set(icalProperty{Name: "DTEND", Value: icalUTCTime(updated.EndTime)})
+		event.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(updated.Location)})
+		if len(updated.Description) > 0 {
+			event.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(updated.Description)})
+		}
+		return nil
+	})
+}
+
+func (c *CalDAVCalendar) InviteToEvent(eventID, email, name, status string) error {
+	return c.updateCalDAVEvent(eventID, func(event *icalComponent) error {
+		// new guests still have to answer the invite, unless they only said maybe
+		responseStatus := "needsAction"
+		if status == RSVPTentative {
+			responseStatus = "tentative"
+		}
+		attendees := event.GetAll("ATTENDEE")
+		found := false
+		for i, attendee := range attendees {
+			current := calDAVResponseStatus(attendee)
+			if calDAVAttendeeEmail(attendee) != email || current == "declined" {
+				continue
+			}
+			// guests who were a maybe and are now coming have accepted, which needs no answer from them
+			if current == "tentative" && status == RSVPAccepted {
+				responseStatus = "accepted"
+			} else if (current == "tentative") == (status == RSVPTentative) {
+				slog.Info("already invited", "email", email, "eventID", eventID)
+				return errEventUnchanged
+			}
+			if cn, ok := attendee.Params["CN"]; ok {
+				name = cn
+			}
+			attendees[i] = calDAVAttendee(email, name, responseStatus)
+			found = true
+		}
+		if !found {
+			attendees = slices.DeleteFunc(attendees, func(attendee icalProperty) bool {
+				return calDAVAttendeeEmail(attendee) == email
+			})
+			attendees = append(attendees, calDAVAttendee(email, name, responseStatus))
+		}
+		event.SetAll("ATTENDEE", attendees)
+		return nil
+	})
+}
+
+func (c *CalDAVCalendar) DeclineEvent(eventID, email string) error {
+	return c.updateCalDAVEvent(eventID, func(event *icalComponent) error {
+		attendees := event.GetAll("ATTENDEE")
+		i := slices.IndexFunc(attendees, func(attendee icalProperty) bool {
+			return calDAVAttendeeEmail(attendee) == email
+		})
+		if i < 0 {
+			return ErrNotInvited
+		}
+		attendees[i] = calDAVAttendee(email, attendees[i].Params["CN"], "declined")
+		event.SetAll("ATTENDEE", attendees)
+		return nil
+	})
+}
+
+func (c *CalDAVCalendar) ListEvents(numEvents int) ([]CalendarEvent, error) {
+	return c.listCalDAVEvents(time.Now(), time.Time{}, numEvents)
+}
+
+func (c *CalDAVCalendar) ListEventsBetween(start, end time.Time, numEvents int) ([]CalendarEvent, error) {
+	return c.listCalDAVEvents(start, end, numEvents)
+}
+
+// CancelEvent keeps the event as cancelled, so that guests see that it is off and so that it can be activated again
+func (c *CalDAVCalendar) CancelEvent(eventID string) error {
+	return c.updateCalDAVEvent(eventID, func(event *icalComponent) error {
+		event.Set(icalProperty{Name: "STATUS", Value: "CANCELLED"})
+		return nil
+	})
+}
+
+func (c *CalDAVCalendar) ActivateEvent(eventID string) error {
+	return c.updateCalDAVEvent(eventID, func(event *icalComponent) error {
+		event.Set(icalProperty{Name: "STATUS", Value: "CONFIRMED"})
+		return nil
+	})
+}
+
+// calDAVQuery is a calendar-query REPORT for the events within a time range, where an empty end is open
+type calDAVQuery struct {
+	XMLName xml.Name `xml:"C:calendar-query"`
+	DAV     string   `xml:"xmlns:D,attr"`
+	CalDAV  string   `xml:"xmlns:C,attr"`
+	Prop    struct {
+		ETag         struct{} `xml:"D:getetag"`
+		CalendarData struct{} `xml:"C:calendar-data"`
+	} `xml:"D:prop"`
+	Filter struct {
+		Calendar struct {
+			Name  string `xml:"name,attr"`
+			Event struct {
+				Name      string `xml:"name,attr"`
+				TimeRange struct {
+					Start string `xml:"start,attr"`
+					End   string `xml:"end,attr,omitempty"`
+				} `xml:"C:time-range"`
+			} `xml:"C:comp-filter"`
+		} `xml:"C:comp-filter"`
+	} `xml:"C:filter"`
+}
+
+// calDAVMultistatus is the response to a REPORT, with the iCalendar resource of every event
+type calDAVMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// listCalDAVEvents returns the first numEvents events from start until end ordered by when they start, leaving out
+// cancelled events
+func (c *CalDAVCalendar) listCalDAVEvents(start, end time.Time, numEvents int) ([]CalendarEvent, error) {
+	query := calDAVQuery{DAV: "DAV:", CalDAV: "urn:ietf:params:xml:ns:caldav"}
+	query.Filter.Calendar.Name = "VCALENDAR"
+	query.Filter.Calendar.Event.Name = "VEVENT"
+	query.Filter.Calendar.Event.TimeRange.Start = icalUTCTime(start)
+	if !end.IsZero() {
+		query.Filter.Calendar.Event.TimeRange.End = icalUTCTime(end)
+	}
+	body, err := xml.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+	header.Set("Depth", "1")
+	res, err := c.do("REPORT", c.url.String(), append([]byte(xml.Header), body...), header)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("caldav report: %s", res.Status)
+	}
+	multistatus := calDAVMultistatus{}
+	if err = xml.NewDecoder(res.Body).Decode(&multistatus); err != nil {
+		return nil, err
+	}
+
+	result := make([]CalendarEvent, 0)
+	for _, response := range multistatus.Responses {
+		for _, propstat := range response.Propstat {
+			if len(propstat.Prop.CalendarData) == 0 {
+				continue
+			}
+			events, err := readICalComponents(strings.NewReader(propstat.Prop.CalendarData), "VEVENT")
+			if err != nil {
+				slog.Warn("could not read calendar event", "error", err, "href", response.Href)
+				continue
+			}
+			for _, event := range events {
+				if e := c.calDAVEventToEvent(event); e.Status != "cancelled" {
+					result = append(result, e)
+				}
+			}
+		}
+	}
+	slices.SortFunc(result, func(a, b CalendarEvent) int { return a.StartTime.Compare(b.StartTime) })
+	if len(result) > numEvents {
+		result = result[:numEvents]
+	}
+	return result, nil
+}
+
+func (c *CalDAVCalendar) calDAVEventToEvent(event icalComponent) CalendarEvent {
+	result := CalendarEvent{Status: "confirmed", Visibility: "default"}
+	if property, ok := event.Get("UID"); ok {
+		result.Id = property.Value
+	}
+	if property, ok := event.Get("DTSTART"); ok {
+		start, _, err := parseICalEventTime(property, c.loc)
+		if err != nil {
+			slog.Error("could not parse event start time", "eventID", result.Id, "time", property.Value)
+		}
+		result.StartTime = start
+	}
+	if property, ok := event.Get("DTEND"); ok {
+		end, _, err := parseICalEventTime(property, c.loc)
+		if err != nil {
+			slog.Error("could not parse event end time", "eventID", result.Id, "time", property.Value)
+		}
+		result.EndTime = end
+	}
+	if property, ok := event.Get("SUMMARY"); ok {
+		result.Summary = unescapeICalText(property.Value)
+	}
+	if property, ok := event.Get("DESCRIPTION"); ok {
+		result.Description = unescapeICalText(property.Value)
+	}
+	if property, ok := event.Get("LOCATION"); ok {
+		result.Location = unescapeICalText(property.Value)
+	}
+	if property, ok := event.Get("STATUS"); ok {
+		result.Status = strings.ToLower(property.Value)
+	}
+	if property, ok := event.Get("CLASS"); ok {
+		result.Visibility = strings.ToLower(property.Value)
+	}
+	for _, attendee := range event.GetAll("ATTENDEE") {
+		result.Attendees = append(result.Attendees, CalendarAttendee{
+			Email:          calDAVAttendeeEmail(attendee),
+			ResponseStatus: calDAVResponseStatus(attendee),
+		})
+	}
+	return result
+}
+
+// calDAVAttendee is the ATTENDEE of the guest, whose response status is written as its PARTSTAT, e.g. needsAction as
+// NEEDS-ACTION
+func calDAVAttendee(email, name, responseStatus string) icalProperty {
+	partStat := "NEEDS-ACTION"
+	if responseStatus != "needsAction" && len(responseStatus) > 0 {
+		partStat = strings.ToUpper(responseStatus)
+	}
+	attendee := icalProperty{
+		Name:   "ATTENDEE",
+		Params: map[string]string{"PARTSTAT": partStat, "RSVP": "TRUE"},
+		Value:  "mailto:" + email,
+	}
+	if len(name) > 0 {
+		attendee.Params["CN"] = name
+	}
+	return attendee
+}
+
+// calDAVAttendeeEmail is the email of the ATTENDEE without its mailto
+func calDAVAttendeeEmail(attendee icalProperty) string {
+	value := attendee.Value
+	if len(value) >= len("mailto:") && strings.EqualFold(value[:len("mailto:")], "mailto:") {
+		value = value[len("mailto:"):]
+	}
+	return strings.ToLower(value)
+}
+
+// calDAVResponseStatus reads the PARTSTAT of the ATTENDEE as the response status of a Google calendar attendee
+func calDAVResponseStatus(attendee icalProperty) string {
+	switch partStat := strings.ToUpper(attendee.Params["PARTSTAT"]); partStat {
+	case "", "NEEDS-ACTION":
+		return "needsAction"
+	default:
+		return strings.ToLower(partStat)
+	}
+}
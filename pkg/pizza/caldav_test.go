@@ -0,0 +1,346 @@
+package pizza_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// calDAVStandIn is a CalDAV server that keeps its iCalendar resources in memory, which is just enough of Nextcloud
+// to test the calendar without a network
+type calDAVStandIn struct {
+	mu        sync.Mutex
+	resources map[string]string
+	etags     map[string]string
+	nextETag  int
+}
+
+func newCalDAVStandIn(t *testing.T) (*calDAVStandIn, *httptest.Server) {
+	standIn := &calDAVStandIn{resources: make(map[string]string), etags: make(map[string]string)}
+	ts := httptest.NewServer(standIn)
+	t.Cleanup(ts.Close)
+	return standIn, ts
+}
+
+var calDAVStartPattern = regexp.MustCompile(`DTSTART(;[^:]*)?:(\d{8}T\d{6}Z?)`)
+
+func (s *calDAVStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, ok := r.BasicAuth(); !ok || username != "pizza" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+
+	switch r.Method {
+	case http.MethodGet:
+		resource, ok := s.resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", s.etags[r.URL.Path])
+		w.Header().Set("Content-Type", "text/calendar")
+		io.WriteString(w, resource)
+	case http.MethodPut:
+		_, exists := s.resources[r.URL.Path]
+		if (r.Header.Get("If-None-Match") == "*" && exists) ||
+			(len(r.Header.Get("If-Match")) > 0 && r.Header.Get("If-Match") != s.etags[r.URL.Path]) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		s.put(r.URL.Path, string(body))
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case "REPORT":
+		query := struct {
+			TimeRange struct {
+				Start string `xml:"start,attr"`
+				End   string `xml:"end,attr"`
+			} `xml:"filter>comp-filter>comp-filter>time-range"`
+		}{}
+		if err := xml.Unmarshal(body, &query); err != nil || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav">`)
+		for path, resource := range s.resources {
+			match := calDAVStartPattern.FindStringSubmatch(resource)
+			if !strings.HasPrefix(path, r.URL.Path) || match == nil || match[2] < query.TimeRange.Start ||
+				(len(query.TimeRange.End) > 0 && match[2] >= query.TimeRange.End) {
+				continue
+			}
+			fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:getetag>%s</d:getetag>`+
+				`<cal:calendar-data>`, path, s.etags[path])
+			xml.EscapeText(w, []byte(resource))
+			io.WriteString(w, `</cal:calendar-data></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+		}
+		io.WriteString(w, `</d:multistatus>`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *calDAVStandIn) put(path, resource string) {
+	s.nextETag++
+	s.resources[path] = resource
+	s.etags[path] = fmt.Sprintf(`"%d"`, s.nextETag)
+}
+
+func (s *calDAVStandIn) resource(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resources[path]
+}
+
+func TestCalDAVCalendar(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	standIn, ts := newCalDAVStandIn(t)
+	calendar, err := pizza.NewCalDAVCalendar(ts.URL+"/calendars/pizza/friday", "pizza", "secret", loc)
+	require.Nil(t, err)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	event := pizza.CalendarEvent{
+		Id:          "1741386600",
+		Summary:     "Pizza Friday",
+		Description: "Welcome to Pizza Friday, where the oven is hot; bring a friend and a big appetite for pizza!",
+		Location:    "Matt's, 1 Main St",
+		StartTime:   start,
+		EndTime:     start.Add(4 * time.Hour),
+		Status:      "confirmed",
+		Visibility:  "private",
+	}
+
+	// WHEN
+	_, missingErr := calendar.GetEvent(event.Id)
+	err = calendar.CreateEvent(event)
+	createdAgainErr := calendar.CreateEvent(event)
+	err1 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	err2 := calendar.InviteToEvent(event.Id, "bar@bar.com", "Bar", pizza.RSVPTentative)
+	err3 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	saved, err4 := calendar.GetEvent(event.Id)
+
+	// THEN
+	assert.ErrorIs(t, missingErr, pizza.ErrEventNotFound)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, createdAgainErr, pizza.ErrEventChanged)
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, event.Id, saved.Id)
+	assert.Equal(t, event.Summary, saved.Summary)
+	assert.Equal(t, event.Description, saved.Description)
+	assert.Equal(t, event.Location, saved.Location)
+	assert.True(t, start.Equal(saved.StartTime))
+	assert.True(t, event.EndTime.Equal(saved.EndTime))
+	assert.Equal(t, "confirmed", saved.Status)
+	assert.Equal(t, "private", saved.Visibility)
+	assert.Equal(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "needsAction"},
+		{Email: "bar@bar.com", ResponseStatus: "tentative"},
+	}, saved.Attendees)
+	resource := standIn.resource("/calendars/pizza/friday/1741386600.ics")
+	assert.Contains(t, resource, "UID:1741386600\r\n")
+	assert.Contains(t, resource, "ATTENDEE;CN=Bar;PARTSTAT=TENTATIVE;RSVP=TRUE:mailto:bar@bar.com\r\n")
+	assert.Contains(t, resource, "LOCATION:Matt's\\, 1 Main St\r\n")
+
+	// WHEN
+	err1 = calendar.InviteToEvent(event.Id, "bar@bar.com", "Bar", pizza.RSVPAccepted)
+	err2 = calendar.DeclineEvent(event.Id, "foo@bar.com")
+	notInvitedErr := calendar.DeclineEvent(event.Id, "baz@bar.com")
+	moved := event
+	moved.StartTime = start.Add(time.Hour)
+	moved.EndTime = start.Add(3 * time.Hour)
+	moved.Location = "Baz's"
+	err3 = calendar.UpdateEvent(moved)
+	saved, err4 = calendar.GetEvent(event.Id)
+
+	// THEN
+	// maybes who are coming now have accepted
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.ErrorIs(t, notInvitedErr, pizza.ErrNotInvited)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "declined"},
+		{Email: "bar@bar.com", ResponseStatus: "accepted"},
+	}, saved.Attendees)
+	assert.True(t, moved.StartTime.Equal(saved.StartTime))
+	assert.True(t, moved.EndTime.Equal(saved.EndTime))
+	assert.Equal(t, "Baz's", saved.Location)
+	assert.Equal(t, event.Description, saved.Description)
+
+	// WHEN
+	err1 = calendar.CancelEvent(event.Id)
+	cancelled, err2 := calendar.GetEvent(event.Id)
+	err3 = calendar.ActivateEvent(event.Id)
+	activated, err4 := calendar.GetEvent(event.Id)
+
+	// THEN
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, "cancelled", cancelled.Status)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, "confirmed", activated.Status)
+}
+
+func TestCalDAVCalendar_ServerChanges(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	standIn, ts := newCalDAVStandIn(t)
+	calendar, err := pizza.NewCalDAVCalendar(ts.URL+"/calendars/pizza/friday/", "pizza", "secret", loc)
+	require.Nil(t, err)
+	// calendar apps write times in the timezone of the event and emails as they were typed
+	standIn.put("/calendars/pizza/friday/1741386600.ics", strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:1741386600",
+		"DTSTART;TZID=America/New_York:20250307T173000",
+		"DTEND;TZID=America/New_York:20250307T213000",
+		"SUMMARY:Pizza Friday",
+		"ATTENDEE;CN=\"Foo, Jr.\";PARTSTAT=ACCEPTED:MAILTO:Foo@Bar.com",
+		"ATTENDEE:mailto:bar@bar.com",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n"))
+
+	// WHEN
+	event, err := calendar.GetEvent("1741386600")
+	err1 := calendar.InviteToEvent("1741386600", "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	err2 := calendar.InviteToEvent("1741386600", "bar@bar.com", "Bar", pizza.RSVPTentative)
+	resource := standIn.resource("/calendars/pizza/friday/1741386600.ics")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2025, time.March, 7, 17, 30, 0, 0, loc).Equal(event.StartTime))
+	assert.Equal(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "accepted"},
+		{Email: "bar@bar.com", ResponseStatus: "needsAction"},
+	}, event.Attendees)
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Contains(t, resource, "ATTENDEE;CN=\"Foo, Jr.\";PARTSTAT=ACCEPTED:MAILTO:Foo@Bar.com\r\n")
+	assert.Contains(t, resource, "ATTENDEE;CN=Bar;PARTSTAT=TENTATIVE;RSVP=TRUE:mailto:bar@bar.com\r\n")
+	assert.Contains(t, resource, "DTSTART;TZID=America/New_York:20250307T173000\r\n")
+}
+
+func TestCalDAVCalendar_ListEvents(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	standIn, ts := newCalDAVStandIn(t)
+	calendar, err := pizza.NewCalDAVCalendar(ts.URL+"/calendars/pizza/friday/", "pizza", "secret", loc)
+	require.Nil(t, err)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	for i := range 4 {
+		eventStart := start.AddDate(0, 0, 7*(3-i))
+		require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{
+			Id:        fmt.Sprint(eventStart.Unix()),
+			Summary:   "Pizza Friday",
+			StartTime: eventStart,
+			EndTime:   eventStart.Add(4 * time.Hour),
+		}))
+	}
+	require.Nil(t, calendar.CancelEvent(fmt.Sprint(start.AddDate(0, 0, 7).Unix())))
+	upcoming := time.Now().Add(time.Hour)
+	require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{Id: "upcoming", StartTime: upcoming,
+		EndTime: upcoming.Add(time.Hour)}))
+	other := calendar.WithCalendarID("late-pizza")
+	require.Nil(t, other.CreateEvent(pizza.CalendarEvent{Id: "late", StartTime: start, EndTime: start.Add(time.Hour)}))
+
+	// WHEN
+	between, err1 := calendar.ListEventsBetween(start, start.AddDate(0, 0, 21), 10)
+	limited, err2 := calendar.ListEventsBetween(start, start.AddDate(0, 0, 21), 1)
+	events, err3 := calendar.ListEvents(10)
+
+	// THEN
+	// cancelled events are left out and the rest are in order of when they start
+	assert.Nil(t, err1)
+	require.Len(t, between, 2)
+	assert.True(t, start.Equal(between[0].StartTime))
+	assert.True(t, start.AddDate(0, 0, 14).Equal(between[1].StartTime))
+	assert.Nil(t, err2)
+	require.Len(t, limited, 1)
+	assert.Equal(t, between[0].Id, limited[0].Id)
+	assert.Nil(t, err3)
+	require.Len(t, events, 1)
+	assert.Equal(t, "upcoming", events[0].Id)
+	assert.NotEmpty(t, standIn.resource("/calendars/pizza/late-pizza/late.ics"))
+}
+
+func TestCalDAVCalendar_Unauthorized(t *testing.T) {
+	// GIVEN
+	_, ts := newCalDAVStandIn(t)
+	calendar, err := pizza.NewCalDAVCalendar(ts.URL+"/calendars/pizza/friday/", "pizza", "wrong", time.UTC)
+	require.Nil(t, err)
+
+	// WHEN
+	_, getErr := calendar.GetEvent("1741386600")
+	_, listErr := calendar.ListEvents(10)
+
+	// THEN
+	assert.NotNil(t, getErr)
+	assert.NotErrorIs(t, getErr, pizza.ErrEventNotFound)
+	assert.NotNil(t, listErr)
+}
+
+func TestNewCalendar(t *testing.T) {
+	// WHEN
+	cal, err := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{
+		Provider: pizza.CalendarProviderCalDAV,
+		URL:      "https://cloud.example.com/remote.php/dav/calendars/pizza/friday/",
+	}, time.UTC)
+	_, relativeErr := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{
+		Provider: pizza.CalendarProviderCalDAV,
+		URL:      "calendars/pizza/friday/",
+	}, time.UTC)
+	local, localErr := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{
+		Provider: pizza.CalendarProviderLocal,
+		DBFile:   filepath.Join(t.TempDir(), "calendar.db"),
+	}, time.UTC)
+	email, emailErr := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{
+		Provider: pizza.CalendarProviderEmail,
+		DBFile:   filepath.Join(t.TempDir(), "calendar.db"),
+		SMTP:     pizza.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "Pizza <pizza@example.com>"},
+	}, time.UTC)
+	_, noSMTPErr := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{
+		Provider: pizza.CalendarProviderEmail,
+		DBFile:   filepath.Join(t.TempDir(), "calendar.db"),
+	}, time.UTC)
+	_, unknownErr := pizza.NewCalendar(context.Background(), pizza.CalendarConfig{Provider: "outlook"}, time.UTC)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.IsType(t, &pizza.CalDAVCalendar{}, cal)
+	assert.NotNil(t, relativeErr)
+	assert.Nil(t, localErr)
+	assert.IsType(t, &pizza.LocalCalendar{}, local)
+	assert.Nil(t, emailErr)
+	assert.IsType(t, &pizza.EmailCalendar{}, email)
+	assert.NotNil(t, noSMTPErr)
+	assert.EqualError(t, unknownErr, "unknown calendar provider 'outlook'")
+}
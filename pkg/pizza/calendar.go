@@ -1,14 +1,53 @@
 package pizza
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
+const (
+	CalendarProviderGoogle = "google"
+	CalendarProviderCalDAV = "caldav"
+	CalendarProviderLocal  = "local"
+	CalendarProviderEmail  = "email"
+)
+
+// NewCalendar connects to the calendar of the provider in the config, where times are in loc
+func NewCalendar(ctx context.Context, config CalendarConfig, loc *time.Location) (Calendar, error) {
+	switch config.Provider {
+	case "", CalendarProviderGoogle:
+		googleCal, err := NewGoogleCalendar(config.CredentialFile, config.TokenFile, config.ID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		googleCal.Timezone = loc.String()
+		return googleCal, nil
+	case CalendarProviderCalDAV:
+		return NewCalDAVCalendar(config.URL, config.Username, config.Password, loc)
+	case CalendarProviderLocal:
+		return NewLocalCalendar(config.DBFile, config.ID, loc)
+	case CalendarProviderEmail:
+		local, err := NewLocalCalendar(config.DBFile, config.ID, loc)
+		if err != nil {
+			return nil, err
+		}
+		return NewEmailCalendar(local, config.SMTP, loc)
+	default:
+		return nil, fmt.Errorf("unknown calendar provider '%s'", config.Provider)
+	}
+}
+
 type Calendar interface {
 	CreateEvent(CalendarEvent) error
 	GetEvent(eventID string) (CalendarEvent, error)
-	InviteToEvent(eventID, email, name string) error
+	// UpdateEvent moves an existing event to the start and end time and the location of the given event, and replaces
+	// its description when the given one is not empty
+	UpdateEvent(CalendarEvent) error
+	// InviteToEvent invites the guest with the status of their RSVP, which is either RSVPAccepted or RSVPTentative. Guests
+	// who are already invited get the new status.
+	InviteToEvent(eventID, email, name, status string) error
 	DeclineEvent(eventID, email string) error
 	ListEvents(numEvents int) ([]CalendarEvent, error)
 	ListEventsBetween(start, end time.Time, numEvents int) ([]CalendarEvent, error)
@@ -16,6 +55,12 @@ type Calendar interface {
 	ActivateEvent(eventID string) error
 }
 
+// CalendarSwitcher is implemented by calendars that can work on another calendar of the same account, which is how
+// the events of a series with its own CalendarID are kept apart
+type CalendarSwitcher interface {
+	WithCalendarID(ID string) Calendar
+}
+
 type CalendarEvent struct {
 	AnyoneCanAddSelf      bool
 	Attendees             []CalendarAttendee
@@ -24,6 +69,7 @@ type CalendarEvent struct {
 	GuestsCanInviteOthers bool
 	GuestsCanModify       bool
 	Id                    string
+	Location              string
 	Locked                bool
 	StartTime             time.Time
 	Status                string
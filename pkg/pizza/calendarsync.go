@@ -0,0 +1,452 @@
+package pizza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CalendarSyncDBWins changes the calendar to match the guest lists, CalendarSyncCalendarWins changes the guest
+	// lists to match the calendar, and CalendarSyncReportOnly only logs and counts the differences
+	CalendarSyncDBWins       = "db"
+	CalendarSyncCalendarWins = "calendar"
+	CalendarSyncReportOnly   = "report"
+)
+
+const (
+	// DriftMissingAttendee is a guest of the friday who is not on its event
+	DriftMissingAttendee = "missing-attendee"
+	// DriftExtraAttendee is an attendee of the event who is not a guest of the friday, like one added in the calendar
+	DriftExtraAttendee = "extra-attendee"
+	// DriftDeletedEvent is an enabled friday with guests whose event was deleted or cancelled
+	DriftDeletedEvent = "deleted-event"
+	// DriftTime is an event that starts or ends at another time than its friday
+	DriftTime = "time"
+	// DriftDescription is an event whose description is not the description of its series
+	DriftDescription = "description"
+)
+
+var calendarDriftKinds = []string{
+	DriftMissingAttendee, DriftExtraAttendee, DriftDeletedEvent, DriftTime, DriftDescription,
+}
+
+// CalendarSyncConfig is how often the guest lists are compared with the calendar, and which side wins when they
+// differ. An empty Policy only reports the differences, and a zero Interval turns the sync off.
+type CalendarSyncConfig struct {
+	Policy   string        `yaml:"policy"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Validate checks that the Policy is one of the known policies
+func (c CalendarSyncConfig) Validate() error {
+	switch c.Policy {
+	case "", CalendarSyncDBWins, CalendarSyncCalendarWins, CalendarSyncReportOnly:
+		return nil
+	default:
+		return fmt.Errorf("unknown calendar sync policy '%s'", c.Policy)
+	}
+}
+
+// CalendarSyncSummary is what one run of the calendar sync found and did
+type CalendarSyncSummary struct {
+	// Fridays is how many fridays were compared with their events
+	Fridays int
+	// Answers is how many answers that guests gave in their calendar were applied to the guest lists
+	Answers int
+	// Drift counts the differences by their kind, of which Repaired were fixed by the policy and Failed could not be
+	Drift    map[string]int
+	Repaired int
+	Failed   int
+}
+
+// newCalendarDriftMetrics counts the differences found by the calendar sync by their kind and what became of them
+func newCalendarDriftMetrics(metricsReg MetricsRegistry) map[string]CounterMetric {
+	metrics := make(map[string]CounterMetric)
+	for _, kind := range calendarDriftKinds {
+		for _, result := range []string{"repaired", "failed", "reported"} {
+			metrics[kind+"/"+result] = metricsReg.NewCounterMetric("pizza_calendar_drift",
+				map[string]string{"kind": kind, "result": result})
+		}
+	}
+	return metrics
+}
+
+// WatchCalendar syncs the upcoming fridays with the calendar periodically until the context is cancelled, which also
+// keeps the credentials of the calendar renewed. Nothing is synced when the calendar is disabled or the period is not
+// positive.
+func (s *Server) WatchCalendar(ctx context.Context, period time.Duration) {
+	if !s.config.Calendar.Enabled {
+		slog.Info("[sync] the calendar is disabled, not syncing")
+		return
+	} else if period <= 0 {
+		slog.Info("[sync] the calendar sync is off")
+		return
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("[sync] calendar sync stopped")
+			return
+		case <-timer.C:
+		}
+		summary, err := s.SyncCalendar(ctx)
+		if errors.Is(err, context.Canceled) {
+			continue
+		} else if err != nil {
+			slog.Error("[sync] calendar sync failed", "err", err)
+			s.calendarSyncErrorMetric.Increment()
+			timer.Reset(1 * time.Minute)
+			continue
+		}
+		s.calendarSyncMetric.Increment()
+		slog.Info("[sync] calendar sync complete", "policy", s.calendarSyncPolicy(), "fridays", summary.Fridays,
+			"answers", summary.Answers, "drift", summary.Drift, "repaired", summary.Repaired, "failed", summary.Failed)
+		timer.Reset(period)
+	}
+}
+
+// SyncCalendar compares the upcoming fridays with their events once. The answers that guests gave in their calendar
+// are always applied to the guest lists, and the other differences are resolved by the policy of the sync.
+func (s *Server) SyncCalendar(ctx context.Context) (CalendarSyncSummary, error) {
+	summary := CalendarSyncSummary{Drift: make(map[string]int)}
+	fridays, err := s.store.GetUpcomingFridays(30)
+	if err != nil {
+		return summary, fmt.Errorf("failed to get upcoming fridays: %w", err)
+	}
+	for _, friday := range fridays {
+		if err = ctx.Err(); err != nil {
+			return summary, err
+		}
+		summary.Fridays++
+		s.syncFriday(friday, &summary)
+	}
+	return summary, nil
+}
+
+func (s *Server) calendarSyncPolicy() string {
+	if len(s.config.Calendar.Sync.Policy) == 0 {
+		return CalendarSyncReportOnly
+	}
+	return s.config.Calendar.Sync.Policy
+}
+
+// syncFriday compares the friday with its event
+func (s *Server) syncFriday(friday Friday, summary *CalendarSyncSummary) {
+	eventID := strconv.FormatInt(friday.Date.Unix(), 10)
+	cal := s.calendarFor(friday)
+	event, err := cal.GetEvent(eventID)
+	if err == ErrEventNotFound || (err == nil && event.Status == "cancelled") {
+		// events are only made with the first RSVP, so a friday without guests has none yet
+		if friday.Enabled && friday.GuestCount()+len(friday.Tentative) > 0 {
+			cancelled := err == nil
+			s.resolveDrift(summary, DriftDeletedEvent, eventID, "", func() error {
+				return s.restoreEvent(friday, eventID, cancelled)
+			}, func() error {
+				return s.disableDeletedFriday(friday)
+			})
+		}
+		return
+	} else if err != nil {
+		slog.Warn("[sync] failed to get calendar event", "err", err, "eventID", eventID)
+		return
+	}
+
+	if s.syncAnswers(friday, event, summary) {
+		// compare the guest lists as they are after the answers
+		if friday, err = s.store.GetFriday(friday.Date); err != nil {
+			slog.Error("[sync] failed to get friday", "err", err, "eventID", eventID)
+			return
+		}
+	}
+	s.syncEventDetails(friday, event, summary)
+	if s.syncAttendees(friday, event, summary) {
+		s.promoteWaitlist(friday, AuditSourceSync)
+	}
+}
+
+// syncAnswers applies the answers that guests gave to the invite in their calendar, and reports whether any of them
+// changed the guest lists
+func (s *Server) syncAnswers(friday Friday, event CalendarEvent, summary *CalendarSyncSummary) bool {
+	t := friday.Date.In(s.loc)
+	changed := false
+	removed := false
+	for _, attendee := range event.Attendees {
+		before := rsvpStatus(friday, attendee.Email)
+		switch {
+		case attendee.ResponseStatus == "declined" && (before == RSVPAccepted || before == RSVPTentative):
+			if err := s.store.RemoveFriendFromFriday(attendee.Email, t); err != nil {
+				slog.Error("[sync] failed to remove friend from friday after calendar decline", "err", err, "email", attendee.Email, "eventID", event.Id)
+				continue
+			}
+			s.syncAudit(t, attendee.Email, "decline", before, RSVPDeclined)
+			if before == RSVPAccepted {
+				removed = true
+				s.removePlusOnesOf(friday, attendee.Email, attendee.Email, AuditSourceSync)
+			}
+		case attendee.ResponseStatus == "tentative" && before == RSVPAccepted:
+			if err := s.store.AddFriendAsTentative(attendee.Email, t); err != nil {
+				slog.Error("[sync] failed to mark friend as tentative after calendar maybe", "err", err, "email", attendee.Email, "eventID", event.Id)
+				continue
+			}
+			removed = true
+			s.syncAudit(t, attendee.Email, "maybe", before, RSVPTentative)
+			s.removePlusOnesOf(friday, attendee.Email, attendee.Email, AuditSourceSync)
+		case attendee.ResponseStatus == "accepted" && before == RSVPTentative:
+			// maybes who accept in their calendar stay maybes when the friday has filled up since
+			if err := s.store.AddFriendToFriday(attendee.Email, friday, ""); err == ErrFridayIsFull {
+				slog.Info("[sync] friday is full for tentative friend", "email", attendee.Email, "eventID", event.Id)
+				continue
+			} else if err != nil {
+				slog.Error("[sync] failed to accept tentative friend after calendar accept", "err", err, "email", attendee.Email, "eventID", event.Id)
+				continue
+			}
+			s.syncAudit(t, attendee.Email, "rsvp", before, RSVPAccepted)
+		default:
+			continue
+		}
+		changed = true
+		summary.Answers++
+	}
+	if removed {
+		s.promoteWaitlist(friday, AuditSourceSync)
+	}
+	return changed
+}
+
+// syncEventDetails compares when the event is and its description with the friday. The description of a series is
+// shared by all of its fridays, so a description that was changed in the calendar is only reported.
+func (s *Server) syncEventDetails(friday Friday, event CalendarEvent, summary *CalendarSyncSummary) {
+	if !event.StartTime.Equal(friday.StartsAt()) || !event.EndTime.Equal(friday.EndsAt()) {
+		s.resolveDrift(summary, DriftTime, event.Id, "", func() error {
+			return s.calendarFor(friday).UpdateEvent(CalendarEvent{
+				Id:        event.Id,
+				StartTime: friday.StartsAt(),
+				EndTime:   friday.EndsAt(),
+				Location:  s.eventLocation(friday),
+			})
+		}, func() error {
+			return s.moveFridayToEvent(friday, event)
+		})
+	}
+	description := s.getSeries(friday.SeriesID).Description
+	if len(description) > 0 && event.Description != description {
+		s.resolveDrift(summary, DriftDescription, event.Id, "", func() error {
+			return s.calendarFor(friday).UpdateEvent(CalendarEvent{
+				Id:          event.Id,
+				StartTime:   friday.StartsAt(),
+				EndTime:     friday.EndsAt(),
+				Location:    s.eventLocation(friday),
+				Description: description,
+			})
+		}, nil)
+	}
+}
+
+// syncAttendees compares the guests of the friday with the attendees of the event in both directions, and reports
+// whether any guests were taken off the friday
+func (s *Server) syncAttendees(friday Friday, event CalendarEvent, summary *CalendarSyncSummary) bool {
+	t := friday.Date.In(s.loc)
+	attending := make(map[string]string)
+	for _, attendee := range event.Attendees {
+		attending[attendee.Email] = attendee.ResponseStatus
+	}
+	removed := false
+
+	// guests who are missing from the event
+	for _, email := range slices.Concat(friday.Guests, friday.Tentative) {
+		if _, ok := attending[email]; ok {
+			continue
+		}
+		status := rsvpStatus(friday, email)
+		s.resolveDrift(summary, DriftMissingAttendee, event.Id, email, func() error {
+			name := ""
+			if friend, err := s.store.GetFriendByEmail(email); err == nil {
+				name = friend.Name
+			}
+			return s.inviteToEvent(event.Id, friday, email, name, status)
+		}, func() error {
+			if err := s.store.RemoveFriendFromFriday(email, t); err != nil {
+				return err
+			}
+			s.reconcileAudit(t, email, "remove", status, RSVPDeclined)
+			if status == RSVPAccepted {
+				removed = true
+				s.removePlusOnesOf(friday, email, "", AuditSourceSync)
+			}
+			return nil
+		})
+	}
+	for _, plusOne := range friday.PlusOnes {
+		if _, ok := attending[plusOne.Email]; ok || len(plusOne.Email) == 0 {
+			continue
+		}
+		s.resolveDrift(summary, DriftMissingAttendee, event.Id, plusOne.Email, func() error {
+			return s.inviteToEvent(event.Id, friday, plusOne.Email, plusOne.Name, RSVPAccepted)
+		}, func() error {
+			removed = true
+			return s.removePlusOne(friday, plusOne, "", AuditSourceSync)
+		})
+	}
+
+	// attendees who were added to the event without an RSVP
+	for _, attendee := range event.Attendees {
+		if attendee.ResponseStatus == "declined" || len(rsvpStatus(friday, attendee.Email)) > 0 ||
+			slices.ContainsFunc(friday.PlusOnes, func(p PlusOne) bool { return p.Email == attendee.Email }) {
+			continue
+		}
+		s.resolveDrift(summary, DriftExtraAttendee, event.Id, attendee.Email, func() error {
+			return s.calendarFor(friday).DeclineEvent(event.Id, attendee.Email)
+		}, func() error {
+			return s.addCalendarAttendee(friday, attendee)
+		})
+	}
+	return removed
+}
+
+// resolveDrift counts the difference and repairs it with dbWins or calendarWins, depending on the policy. Differences
+// without a repair for the policy are only reported.
+func (s *Server) resolveDrift(summary *CalendarSyncSummary, kind, eventID, email string, dbWins, calendarWins func() error) {
+	summary.Drift[kind]++
+	var repair func() error
+	switch s.calendarSyncPolicy() {
+	case CalendarSyncDBWins:
+		repair = dbWins
+	case CalendarSyncCalendarWins:
+		repair = calendarWins
+	}
+	if repair == nil {
+		slog.Info("[sync] calendar drift", "kind", kind, "eventID", eventID, "email", email)
+		s.calendarDriftMetrics[kind+"/reported"].Increment()
+		return
+	}
+	if err := repair(); err != nil {
+		slog.Error("[sync] failed to repair calendar drift", "err", err, "kind", kind, "eventID", eventID,
+			"email", email)
+		summary.Failed++
+		s.calendarDriftMetrics[kind+"/failed"].Increment()
+		return
+	}
+	slog.Info("[sync] repaired calendar drift", "kind", kind, "policy", s.calendarSyncPolicy(), "eventID", eventID,
+		"email", email)
+	summary.Repaired++
+	s.calendarDriftMetrics[kind+"/repaired"].Increment()
+}
+
+// restoreEvent brings back the deleted or cancelled event of the friday, with all of its guests invited again
+func (s *Server) restoreEvent(friday Friday, eventID string, cancelled bool) error {
+	cal := s.calendarFor(friday)
+	if cancelled {
+		if err := cal.ActivateEvent(eventID); err != nil {
+			return err
+		}
+	} else if err := cal.CreateEvent(s.newCalendarEvent(eventID, friday)); err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, email := range slices.Concat(friday.Guests, friday.Tentative) {
+		name := ""
+		if friend, err := s.store.GetFriendByEmail(email); err == nil {
+			name = friend.Name
+		}
+		errs = append(errs, s.inviteToEvent(eventID, friday, email, name, rsvpStatus(friday, email)))
+	}
+	for _, plusOne := range friday.PlusOnes {
+		if len(plusOne.Email) > 0 {
+			errs = append(errs, s.inviteToEvent(eventID, friday, plusOne.Email, plusOne.Name, RSVPAccepted))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// disableDeletedFriday disables the friday whose event was deleted in the calendar
+func (s *Server) disableDeletedFriday(friday Friday) error {
+	before := fridaySettings(friday)
+	friday.Enabled = false
+	if err := s.store.UpdateFriday(friday); err != nil {
+		return err
+	}
+	s.audit(AuditEntry{
+		Action: "disable",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(friday),
+		Source: AuditSourceSync,
+	})
+	return nil
+}
+
+// moveFridayToEvent changes when the friday starts and how long it lasts to the time of its event
+func (s *Server) moveFridayToEvent(friday Friday, event CalendarEvent) error {
+	before := fridaySettings(friday)
+	friday.Start = event.StartTime.In(s.loc)
+	friday.Duration = event.EndTime.Sub(event.StartTime)
+	if err := s.store.UpdateFriday(friday); err != nil {
+		return err
+	}
+	s.audit(AuditEntry{
+		Action: "edit",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(friday),
+		Source: AuditSourceSync,
+	})
+	return nil
+}
+
+// addCalendarAttendee RSVPs the attendee who was added to the event in the calendar, making them a friend first when
+// they are not one yet. Attendees who said maybe become maybes, and the rest take a spot if there is one.
+func (s *Server) addCalendarAttendee(friday Friday, attendee CalendarAttendee) error {
+	t := friday.Date.In(s.loc)
+	if _, err := s.store.GetFriendByEmail(attendee.Email); err != nil {
+		name, _, _ := strings.Cut(attendee.Email, "@")
+		if err = s.store.AddFriend(attendee.Email, name); err != nil {
+			return err
+		}
+	}
+	after := RSVPAccepted
+	var err error
+	if attendee.ResponseStatus == "tentative" {
+		after = RSVPTentative
+		err = s.store.AddFriendAsTentative(attendee.Email, t)
+	} else {
+		err = s.store.AddFriendToFriday(attendee.Email, friday, "")
+	}
+	if err != nil {
+		return err
+	}
+	s.reconcileAudit(t, attendee.Email, "rsvp", "", after)
+	return nil
+}
+
+// syncAudit records a change that the guest made by answering the calendar invite
+func (s *Server) syncAudit(friday time.Time, email, action, before, after string) {
+	s.audit(AuditEntry{
+		Actor:  email,
+		Action: action,
+		Friday: friday,
+		Target: email,
+		Before: before,
+		After:  after,
+		Source: AuditSourceSync,
+	})
+}
+
+// reconcileAudit records a change to the guest list that the calendar sync made to match the calendar
+func (s *Server) reconcileAudit(friday time.Time, email, action, before, after string) {
+	s.audit(AuditEntry{
+		Action: action,
+		Friday: friday,
+		Target: email,
+		Before: before,
+		After:  after,
+		Source: AuditSourceSync,
+	})
+}
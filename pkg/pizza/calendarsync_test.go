@@ -0,0 +1,266 @@
+package pizza_test
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type calendarSyncTest struct {
+	server   *pizza.Server
+	accessor *pizza.MemoryAccessor
+	calendar *pizza.LocalCalendar
+	friday   time.Time
+	deleted  time.Time
+}
+
+func (c calendarSyncTest) eventID() string {
+	return strconv.FormatInt(c.friday.Unix(), 10)
+}
+
+// newCalendarSyncTest has a friday whose event drifted in every way from its guest list, and a friday with a guest
+// whose event is gone
+func newCalendarSyncTest(t *testing.T, policy string) calendarSyncTest {
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	config.Calendar.Sync.Policy = policy
+	return newCalendarSyncTestWith(t, config)
+}
+
+func newCalendarSyncTestWith(t *testing.T, config pizza.Config) calendarSyncTest {
+	accessor := pizza.NewMemoryAccessor()
+	calendar := newTestLocalCalendar(t, filepath.Join(t.TempDir(), "calendar.db"))
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+	loc := mustLoadNY(t)
+
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(loc)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 10, Enabled: true}))
+	friday, err := accessor.GetFriday(fridayTime)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriend("foo@bar.com", "Foo"))
+	require.Nil(t, accessor.AddFriend("bar@bar.com", "Bar"))
+	require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", friday, ""))
+	require.Nil(t, accessor.AddFriendToFriday("bar@bar.com", friday, ""))
+	eventID := strconv.FormatInt(fridayTime.Unix(), 10)
+	require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{
+		Id:          eventID,
+		Summary:     "Pizza Friday",
+		Description: "Bring a drink",
+		StartTime:   fridayTime.Add(time.Hour),
+		EndTime:     fridayTime.Add(5 * time.Hour),
+		Status:      "confirmed",
+	}))
+	require.Nil(t, calendar.InviteToEvent(eventID, "foo@bar.com", "Foo", pizza.RSVPAccepted))
+	require.Nil(t, calendar.InviteToEvent(eventID, "baz@bar.com", "Baz", pizza.RSVPAccepted))
+
+	deletedTime := fridayTime.AddDate(0, 0, 7)
+	require.Nil(t, accessor.AddFriday(deletedTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: deletedTime, MaxGuests: 10, Enabled: true}))
+	deleted, err := accessor.GetFriday(deletedTime)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", deleted, ""))
+
+	server, err := pizza.NewServer(config, accessor, calendar, &pizza.MockAuthenticator{}, metrics)
+	require.Nil(t, err)
+	return calendarSyncTest{
+		server:   server,
+		accessor: accessor,
+		calendar: calendar,
+		friday:   fridayTime,
+		deleted:  deletedTime,
+	}
+}
+
+func TestSyncCalendar_ReportOnly(t *testing.T) {
+	// GIVEN
+	c := newCalendarSyncTest(t, pizza.CalendarSyncReportOnly)
+
+	// WHEN
+	summary, err := c.server.SyncCalendar(context.Background())
+	friday, err1 := c.accessor.GetFriday(c.friday)
+	event, err2 := c.calendar.GetEvent(c.eventID())
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, pizza.CalendarSyncSummary{
+		Fridays: 2,
+		Drift: map[string]int{
+			pizza.DriftMissingAttendee: 1,
+			pizza.DriftExtraAttendee:   1,
+			pizza.DriftDeletedEvent:    1,
+			pizza.DriftTime:            1,
+			pizza.DriftDescription:     1,
+		},
+	}, summary)
+	assert.Nil(t, err1)
+	assert.ElementsMatch(t, []string{"foo@bar.com", "bar@bar.com"}, friday.Guests)
+	assert.True(t, c.friday.Equal(friday.StartsAt()))
+	assert.Nil(t, err2)
+	assert.Len(t, event.Attendees, 2)
+	assert.Equal(t, "Bring a drink", event.Description)
+}
+
+func TestSyncCalendar_DBWins(t *testing.T) {
+	// GIVEN
+	c := newCalendarSyncTest(t, pizza.CalendarSyncDBWins)
+
+	// WHEN
+	summary, err := c.server.SyncCalendar(context.Background())
+	event, err1 := c.calendar.GetEvent(c.eventID())
+	restored, err2 := c.calendar.GetEvent(strconv.FormatInt(c.deleted.Unix(), 10))
+	again, err3 := c.server.SyncCalendar(context.Background())
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, 5, summary.Repaired)
+	assert.Equal(t, 0, summary.Failed)
+	assert.Nil(t, err1)
+	assert.ElementsMatch(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "needsAction"},
+		{Email: "baz@bar.com", ResponseStatus: "declined"},
+		{Email: "bar@bar.com", ResponseStatus: "needsAction"},
+	}, event.Attendees)
+	assert.True(t, c.friday.Equal(event.StartTime))
+	assert.True(t, c.friday.Add(pizza.EventDuration).Equal(event.EndTime))
+	assert.Equal(t, "Welcome to Pizza Friday!", event.Description)
+	assert.Nil(t, err2)
+	assert.Equal(t, []pizza.CalendarAttendee{{Email: "foo@bar.com", ResponseStatus: "needsAction"}},
+		restored.Attendees)
+	// nothing is left to repair
+	assert.Nil(t, err3)
+	assert.Equal(t, pizza.CalendarSyncSummary{Fridays: 2, Drift: map[string]int{}}, again)
+}
+
+func TestSyncCalendar_CalendarWins(t *testing.T) {
+	// GIVEN
+	c := newCalendarSyncTest(t, pizza.CalendarSyncCalendarWins)
+
+	// WHEN
+	summary, err := c.server.SyncCalendar(context.Background())
+	friday, err1 := c.accessor.GetFriday(c.friday)
+	deleted, err2 := c.accessor.GetFriday(c.deleted)
+	baz, err3 := c.accessor.GetFriendByEmail("baz@bar.com")
+	again, err4 := c.server.SyncCalendar(context.Background())
+
+	// THEN
+	// the description of the series is not kept per friday, so it can only be reported
+	assert.Nil(t, err)
+	assert.Equal(t, 4, summary.Repaired)
+	assert.Equal(t, 0, summary.Failed)
+	assert.Nil(t, err1)
+	assert.ElementsMatch(t, []string{"foo@bar.com", "baz@bar.com"}, friday.Guests)
+	assert.True(t, c.friday.Add(time.Hour).Equal(friday.StartsAt()))
+	assert.True(t, c.friday.Add(5*time.Hour).Equal(friday.EndsAt()))
+	assert.Nil(t, err2)
+	assert.False(t, deleted.Enabled)
+	assert.Nil(t, err3)
+	assert.Equal(t, "baz", baz.Name)
+	assert.Nil(t, err4)
+	assert.Equal(t, pizza.CalendarSyncSummary{
+		Fridays: 2,
+		Drift:   map[string]int{pizza.DriftDescription: 1},
+	}, again)
+}
+
+func TestSyncCalendar_Answers(t *testing.T) {
+	// GIVEN
+	c := newCalendarSyncTest(t, pizza.CalendarSyncDBWins)
+	require.Nil(t, c.calendar.RespondToEvent(c.eventID(), "foo@bar.com", "declined"))
+
+	// WHEN
+	summary, err := c.server.SyncCalendar(context.Background())
+	friday, err1 := c.accessor.GetFriday(c.friday)
+	event, err2 := c.calendar.GetEvent(c.eventID())
+
+	// THEN
+	// guests who declined in their calendar are not invited again
+	assert.Nil(t, err)
+	assert.Equal(t, 1, summary.Answers)
+	assert.Nil(t, err1)
+	assert.Equal(t, []string{"bar@bar.com"}, friday.Guests)
+	assert.Nil(t, err2)
+	assert.Contains(t, event.Attendees, pizza.CalendarAttendee{Email: "foo@bar.com", ResponseStatus: "declined"})
+}
+
+func TestWatchCalendar(t *testing.T) {
+	// GIVEN
+	c := newCalendarSyncTest(t, pizza.CalendarSyncReportOnly)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// WHEN
+	go func() {
+		c.server.WatchCalendar(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+
+	// THEN
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the calendar sync did not stop")
+	}
+}
+
+func TestWatchCalendar_Off(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		disabled bool
+		period   time.Duration
+	}{
+		{"calendar disabled", true, time.Hour},
+		{"zero interval", false, 0},
+	} {
+		// GIVEN
+		config := pizza.LoadConfigEnv()
+		config.StaticDir = "../../static"
+		config.Calendar.Enabled = !tc.disabled
+		config.Calendar.Sync.Policy = pizza.CalendarSyncDBWins
+		c := newCalendarSyncTestWith(t, config)
+		done := make(chan struct{})
+
+		// WHEN
+		go func() {
+			c.server.WatchCalendar(context.Background(), tc.period)
+			close(done)
+		}()
+
+		// THEN
+		// the watch returns without repairing anything
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the calendar sync did not stop", tc.name)
+		}
+		event, err := c.calendar.GetEvent(c.eventID())
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, "Bring a drink", event.Description, tc.name)
+	}
+}
+
+func TestNewServer_CalendarSyncPolicy(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.Calendar.Sync.Policy = "google"
+	metrics := &pizza.MockMetricsRegistry{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(&pizza.MockCounterMetric{})
+
+	// WHEN
+	_, err := pizza.NewServer(config, pizza.NewMemoryAccessor(), nil, &pizza.MockAuthenticator{}, metrics)
+
+	// THEN
+	assert.EqualError(t, err, "unknown calendar sync policy 'google'")
+}
@@ -1,6 +1,7 @@
 package pizza
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -11,36 +12,76 @@ import (
 
 func init() {
 	// TODO set JSON logger
-	AllPatches = []func(*SQLAccessor) error{
-		func(*SQLAccessor) error { return nil },
-		Patch001,
-		Patch002,
-		Patch003,
-		Patch004,
-		Patch005,
-		Patch006,
-	}
 }
 
 type Config struct {
-	Port            int            `yaml:"port"`
-	StaticDir       string         `yaml:"staticDir"`
-	ReadTimeout     time.Duration  `yaml:"readTimeout"`
-	WriteTimeout    time.Duration  `yaml:"writeTimeout"`
-	ShutdownTimeout time.Duration  `yaml:"shutdownTimeout"`
-	Calendar        CalendarConfig `yaml:"calendar"`
-	MetricsPort     int            `yaml:"metricsPort"`
-	DBFile          string         `yaml:"dbFile"`
+	Port            int              `yaml:"port"`
+	StaticDir       string           `yaml:"staticDir"`
+	ReadTimeout     time.Duration    `yaml:"readTimeout"`
+	WriteTimeout    time.Duration    `yaml:"writeTimeout"`
+	ShutdownTimeout time.Duration    `yaml:"shutdownTimeout"`
+	Calendar        CalendarConfig   `yaml:"calendar"`
+	MetricsPort     int              `yaml:"metricsPort"`
+	DBFile          string           `yaml:"dbFile"`
+	PostgresDSN     string           `yaml:"postgresDSN"`
+	UseMemoryStore  bool             `yaml:"useMemoryStore"`
+	Backup          BackupConfig     `yaml:"backup"`
+	Recurrence      string           `yaml:"recurrence"`
+	Timezone        string           `yaml:"timezone"`
+	RSVP            RSVPPolicy       `yaml:"rsvp"`
+	AutoEnable      AutoEnableConfig `yaml:"autoEnable"`
 	OAuth2          OAuth2Config
 	UseFileAuth     bool   `yaml:"useFileAuth"`
 	FakeAuthFile    string `yaml:"fakeAuthFile"`
 }
 
+// CalendarConfig picks where the events are kept. The google Provider uses the CredentialFile and TokenFile, the
+// caldav Provider logs in to the calendar collection at URL with the Username and Password, and the local Provider
+// keeps them in the SQLite database DBFile. The email Provider keeps them in DBFile too and emails the invitations
+// through the SMTP server. ID is the calendar of the default series, and Sync is how the guest lists are kept in line
+// with the calendar.
 type CalendarConfig struct {
-	Enabled        bool   `yaml:"enabled"`
-	CredentialFile string `yaml:"credentialFile"`
-	TokenFile      string `yaml:"tokenFile"`
-	ID             string `yaml:"id"`
+	Enabled        bool               `yaml:"enabled"`
+	Provider       string             `yaml:"provider"`
+	CredentialFile string             `yaml:"credentialFile"`
+	TokenFile      string             `yaml:"tokenFile"`
+	ID             string             `yaml:"id"`
+	URL            string             `yaml:"url"`
+	Username       string             `yaml:"username"`
+	Password       string             `yaml:"password"`
+	DBFile         string             `yaml:"dbFile"`
+	SMTP           SMTPConfig         `yaml:"smtp"`
+	Sync           CalendarSyncConfig `yaml:"sync"`
+}
+
+// SMTPConfig is the mail server that invitations are sent through, which is logged in to when there is a Username.
+// From is the address that invitations are sent from, like "Pizza Friday <pizza@example.com>".
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// BackupConfig schedules snapshots of the SQLite database, which are disabled when Dir is empty. Only the newest
+// Retain snapshots are kept.
+type BackupConfig struct {
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"`
+	Retain   int           `yaml:"retain"`
+}
+
+// AutoEnableConfig schedules the creation of upcoming fridays, so that hosts do not have to enable each one. Every
+// Interval, the dates of each series that start within LeadTime are created and enabled with the Group, Details and
+// MaxGuests, which fall back to the defaults of the series when they are empty.
+type AutoEnableConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	LeadTime  time.Duration `yaml:"leadTime"`
+	Interval  time.Duration `yaml:"interval"`
+	Group     string        `yaml:"group"`
+	Details   string        `yaml:"details"`
+	MaxGuests int           `yaml:"maxGuests"`
 }
 
 type OAuth2Config struct {
@@ -51,6 +92,30 @@ type OAuth2Config struct {
 	Realm        string
 }
 
+// DefaultTimezone is where fridays are scheduled and shown unless the config has a Timezone
+const DefaultTimezone = "America/New_York"
+
+// defaultLocation is the DefaultTimezone, or UTC when the system has no timezone database
+func defaultLocation() *time.Location {
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Location loads the timezone of the deployment, which fridays are scheduled and shown in
+func (c Config) Location() (*time.Location, error) {
+	if len(c.Timezone) == 0 {
+		return defaultLocation(), nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
 func LoadConfig(filename string) (Config, error) {
 	config := Config{}
 	rawBytes, err := os.ReadFile(filename)
@@ -98,12 +163,50 @@ func LoadConfigEnv() Config {
 		ShutdownTimeout: time.Duration(loadIntEnv("SHUTDOWN_TIMEOUT", 5)) * time.Second,
 		Calendar: CalendarConfig{
 			Enabled:        loadBoolEnv("ENABLE_CALENDAR", true),
+			Provider:       loadStrEnv("CALENDAR_PROVIDER", CalendarProviderGoogle),
 			CredentialFile: loadStrEnv("CREDENTIAL_FILE", "credentials.json"),
 			TokenFile:      loadStrEnv("TOKEN_FILE", "token.json"),
 			ID:             loadStrEnv("CALENDAR_ID", "primary"),
+			URL:            loadStrEnv("CALDAV_URL", ""),
+			Username:       loadStrEnv("CALDAV_USERNAME", ""),
+			Password:       loadStrEnv("CALDAV_PASSWORD", ""),
+			DBFile:         loadStrEnv("CALENDAR_DBFILE", "calendar.db"),
+			SMTP: SMTPConfig{
+				Host:     loadStrEnv("SMTP_HOST", ""),
+				Port:     loadIntEnv("SMTP_PORT", 587),
+				Username: loadStrEnv("SMTP_USERNAME", ""),
+				Password: loadStrEnv("SMTP_PASSWORD", ""),
+				From:     loadStrEnv("SMTP_FROM", ""),
+			},
+			Sync: CalendarSyncConfig{
+				Policy:   loadStrEnv("CALENDAR_SYNC_POLICY", CalendarSyncReportOnly),
+				Interval: time.Duration(loadIntEnv("CALENDAR_SYNC_INTERVAL", 1)) * time.Hour,
+			},
+		},
+		MetricsPort:    loadIntEnv("METRICS_PORT", 5050),
+		DBFile:         loadStrEnv("DBFILE", "pizza.db"),
+		PostgresDSN:    loadStrEnv("POSTGRES_DSN", ""),
+		UseMemoryStore: loadBoolEnv("USE_MEMORY_STORE", false),
+		Backup: BackupConfig{
+			Dir:      loadStrEnv("BACKUP_DIR", ""),
+			Interval: time.Duration(loadIntEnv("BACKUP_INTERVAL", 24)) * time.Hour,
+			Retain:   loadIntEnv("BACKUP_RETAIN", 7),
+		},
+		Recurrence: loadStrEnv("RECURRENCE", DefaultRecurrence),
+		Timezone:   loadStrEnv("TIMEZONE", DefaultTimezone),
+		RSVP: RSVPPolicy{
+			OpensBefore:   time.Duration(loadIntEnv("RSVP_OPENS_HOURS", 31*24)) * time.Hour,
+			ClosesBefore:  time.Duration(loadIntEnv("RSVP_CLOSES_HOURS", 24)) * time.Hour,
+			DeclineCutoff: time.Duration(loadIntEnv("DECLINE_CUTOFF_HOURS", 24)) * time.Hour,
+		},
+		AutoEnable: AutoEnableConfig{
+			Enabled:   loadBoolEnv("AUTO_ENABLE", false),
+			LeadTime:  time.Duration(loadIntEnv("AUTO_ENABLE_LEAD_DAYS", 14)) * 24 * time.Hour,
+			Interval:  time.Duration(loadIntEnv("AUTO_ENABLE_INTERVAL", 1)) * time.Hour,
+			Group:     loadStrEnv("AUTO_ENABLE_GROUP", ""),
+			Details:   loadStrEnv("AUTO_ENABLE_DETAILS", ""),
+			MaxGuests: loadIntEnv("AUTO_ENABLE_MAX_GUESTS", 0),
 		},
-		MetricsPort: loadIntEnv("METRICS_PORT", 5050),
-		DBFile:      loadStrEnv("DBFILE", "pizza.db"),
 		OAuth2: OAuth2Config{
 			ClientID:     loadStrEnv("OAUTH2_CLIENT_ID", ""),
 			ClientSecret: loadStrEnv("OAUTH2_CLIENT_SECRET", ""),
@@ -0,0 +1,352 @@
+package pizza
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSMTPTimeout is how long sending a single email can take
+const DefaultSMTPTimeout = 10 * time.Second
+
+const (
+	// iMIP methods of the invitations, where a REQUEST adds or updates the event in the guest's calendar and a CANCEL
+	// takes it off
+	imipRequest = "REQUEST"
+	imipCancel  = "CANCEL"
+)
+
+// EmailCalendar keeps the events in a LocalCalendar and emails their attendees iCalendar invitations as described by
+// iMIP (RFC 6047), so that the parties show up in any calendar that reads email. Guests answer on the website, since
+// their replies to an invitation go to the mailbox of the sender and are never read.
+type EmailCalendar struct {
+	local  *LocalCalendar
+	config SMTPConfig
+	from   *mail.Address
+	loc    *time.Location
+}
+
+// NewEmailCalendar sends the invitations of the events in the local calendar through the SMTP server, where the times
+// in the emails are in loc
+func NewEmailCalendar(local *LocalCalendar, config SMTPConfig, loc *time.Location) (*EmailCalendar, error) {
+	if len(config.Host) == 0 {
+		return nil, errors.New("the email calendar needs an SMTP host")
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender '%s': %w", config.From, err)
+	}
+	return &EmailCalendar{local: local, config: config, from: from, loc: loc}, nil
+}
+
+// WithCalendarID is the calendar of the ID in the same local database, which sends its invitations the same way
+func (c *EmailCalendar) WithCalendarID(ID string) Calendar {
+	other := *c
+	other.local = c.local.WithCalendarID(ID).(*LocalCalendar)
+	return &other
+}
+
+func (c *EmailCalendar) CreateEvent(newEvent CalendarEvent) error {
+	if err := c.local.CreateEvent(newEvent); err != nil {
+		return err
+	}
+	return c.notifyAttendees(newEvent.Id, imipRequest, "Invitation")
+}
+
+func (c *EmailCalendar) GetEvent(eventID string) (CalendarEvent, error) {
+	return c.local.GetEvent(eventID)
+}
+
+func (c *EmailCalendar) UpdateEvent(updated CalendarEvent) error {
+	if err := c.local.UpdateEvent(updated); err != nil {
+		return err
+	}
+	return c.notifyAttendees(updated.Id, imipRequest, "Updated invitation")
+}
+
+// InviteToEvent emails the guest an invitation, unless they already have one with the same status
+func (c *EmailCalendar) InviteToEvent(eventID, email, name, status string) error {
+	before, _ := c.attendee(eventID, email)
+	if err := c.local.InviteToEvent(eventID, email, name, status); err != nil {
+		return err
+	}
+	after, err := c.attendee(eventID, email)
+	if err != nil {
+		return err
+	}
+	if after.ResponseStatus == before.ResponseStatus {
+		return nil
+	}
+	return c.notify(eventID, imipRequest, "Invitation", after)
+}
+
+// DeclineEvent emails the guest a cancellation, which takes the party off their calendar
+func (c *EmailCalendar) DeclineEvent(eventID, email string) error {
+	if err := c.local.DeclineEvent(eventID, email); err != nil {
+		return err
+	}
+	attendee, err := c.attendee(eventID, email)
+	if err != nil {
+		return err
+	}
+	return c.notify(eventID, imipCancel, "Declined", attendee)
+}
+
+func (c *EmailCalendar) ListEvents(numEvents int) ([]CalendarEvent, error) {
+	return c.local.ListEvents(numEvents)
+}
+
+func (c *EmailCalendar) ListEventsBetween(start, end time.Time, numEvents int) ([]CalendarEvent, error) {
+	return c.local.ListEventsBetween(start, end, numEvents)
+}
+
+func (c *EmailCalendar) CancelEvent(eventID string) error {
+	if err := c.local.CancelEvent(eventID); err != nil {
+		return err
+	}
+	return c.notifyAttendees(eventID, imipCancel, "Cancelled")
+}
+
+func (c *EmailCalendar) ActivateEvent(eventID string) error {
+	if err := c.local.ActivateEvent(eventID); err != nil {
+		return err
+	}
+	return c.notifyAttendees(eventID, imipRequest, "Updated invitation")
+}
+
+// attendee is the guest as they are invited to the event, which is ErrNotInvited when they are not
+func (c *EmailCalendar) attendee(eventID, email string) (localAttendee, error) {
+	attendees, err := c.local.namedAttendees(eventID)
+	if err != nil {
+		return localAttendee{}, err
+	}
+	for _, attendee := range attendees {
+		if attendee.Email == email {
+			return attendee, nil
+		}
+	}
+	return localAttendee{}, ErrNotInvited
+}
+
+// notifyAttendees emails every guest who has not declined the event, and returns the errors of the emails that
+// could not be sent
+func (c *EmailCalendar) notifyAttendees(eventID, method, subject string) error {
+	attendees, err := c.local.namedAttendees(eventID)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, attendee := range attendees {
+		if attendee.ResponseStatus == "declined" {
+			continue
+		}
+		if err = c.notify(eventID, method, subject, attendee); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notify emails the attendee the event with the iMIP method, where the subject is followed by the name of the event
+// and when it starts
+func (c *EmailCalendar) notify(eventID, method, subject string, attendee localAttendee) error {
+	event, err := c.local.GetEvent(eventID)
+	if err != nil {
+		return err
+	}
+	sequence, err := c.local.sequence(eventID)
+	if err != nil {
+		return err
+	}
+	when := event.StartTime.In(c.loc).Format("Mon Jan 2, 2006 3:04pm MST")
+	msg, err := c.imipMessage(method, fmt.Sprintf("%s: %s @ %s", subject, event.Summary, when), event, sequence,
+		attendee, time.Now())
+	if err != nil {
+		return err
+	}
+	if err = c.sendMail(attendee.Email, msg); err != nil {
+		return fmt.Errorf("failed to email %s: %w", attendee.Email, err)
+	}
+	return nil
+}
+
+// imipEvent is the VEVENT of the invitation of the attendee, who is the only attendee listed so that guests do not
+// see each other's emails
+func (c *EmailCalendar) imipEvent(method string, event CalendarEvent, sequence int, attendee localAttendee,
+	now time.Time) icalComponent {
+	status := "CONFIRMED"
+	if method == imipCancel || event.Status == "cancelled" {
+		status = "CANCELLED"
+	}
+	organizer := icalProperty{Name: "ORGANIZER", Params: map[string]string{}, Value: "mailto:" + c.from.Address}
+	if len(c.from.Name) > 0 {
+		organizer.Params["CN"] = c.from.Name
+	}
+	vevent := icalComponent{Name: "VEVENT", Properties: []icalProperty{
+		{Name: "UID", Value: event.Id + "@rsvp.pizza"},
+		{Name: "DTSTAMP", Value: icalUTCTime(now)},
+		{Name: "DTSTART", Value: icalUTCTime(event.StartTime)},
+		{Name: "DTEND", Value: icalUTCTime(event.EndTime)},
+		{Name: "SEQUENCE", Value: strconv.Itoa(sequence)},
+		{Name: "SUMMARY", Value: escapeICalText(event.Summary)},
+		{Name: "STATUS", Value: status},
+		organizer,
+		calDAVAttendee(attendee.Email, attendee.Name, attendee.ResponseStatus),
+	}}
+	if len(event.Description) > 0 {
+		vevent.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(event.Description)})
+	}
+	if len(event.Location) > 0 {
+		vevent.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(event.Location)})
+	}
+	return vevent
+}
+
+// imipMessage is the email of the invitation, with a plain text summary of the event and the iCalendar file of the
+// method as an attachment
+func (c *EmailCalendar) imipMessage(method, subject string, event CalendarEvent, sequence int,
+	attendee localAttendee, now time.Time) ([]byte, error) {
+	var ics bytes.Buffer
+	err := writeICalendar(&ics, []icalProperty{{Name: "METHOD", Value: method}},
+		c.imipEvent(method, event, sequence, attendee, now))
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	text, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(text)
+	if _, err = qp.Write([]byte(c.imipText(event))); err != nil {
+		return nil, err
+	}
+	if err = qp.Close(); err != nil {
+		return nil, err
+	}
+	attachment, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf(`text/calendar; charset=utf-8; method=%s; name="invite.ics"`, method)},
+		"Content-Disposition":       {`attachment; filename="invite.ics"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(ics.Bytes())
+	for len(encoded) > 76 {
+		fmt.Fprintf(attachment, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(attachment, "%s\r\n", encoded)
+	if err = parts.Close(); err != nil {
+		return nil, err
+	}
+
+	to := mail.Address{Name: attendee.Name, Address: attendee.Email}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", newMessageID(), c.fromDomain())
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// imipText describes the event for mail clients that do not show the invitation
+func (c *EmailCalendar) imipText(event CalendarEvent) string {
+	lines := []string{
+		event.Summary,
+		"When: " + event.StartTime.In(c.loc).Format("Mon Jan 2, 2006 3:04pm") + " - " +
+			event.EndTime.In(c.loc).Format("3:04pm MST"),
+	}
+	if len(event.Location) > 0 {
+		lines = append(lines, "Where: "+event.Location)
+	}
+	if event.Status == "cancelled" {
+		lines = append(lines, "This party has been cancelled.")
+	}
+	if len(event.Description) > 0 {
+		lines = append(lines, "", event.Description)
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func (c *EmailCalendar) fromDomain() string {
+	if i := strings.LastIndex(c.from.Address, "@"); i >= 0 {
+		return c.from.Address[i+1:]
+	}
+	return "rsvp.pizza"
+}
+
+func newMessageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sendMail sends the message to the recipient like smtp.SendMail does, but gives up after DefaultSMTPTimeout. The
+// connection is upgraded with STARTTLS when the server offers it, and logs in when there is a Username.
+func (c *EmailCalendar) sendMail(to string, msg []byte) error {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	conn, err := net.DialTimeout("tcp", addr, DefaultSMTPTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(DefaultSMTPTimeout))
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: c.config.Host}); err != nil {
+			return err
+		}
+	}
+	if len(c.config.Username) > 0 {
+		auth := smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(c.from.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,287 @@
+package pizza_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedMail struct {
+	From string
+	To   []string
+	Auth string
+	Data string
+}
+
+// smtpCapture is an SMTP server that keeps every message it is sent instead of delivering it
+type smtpCapture struct {
+	listener net.Listener
+	mu       sync.Mutex
+	mails    []capturedMail
+}
+
+func newSMTPCapture(t *testing.T) *smtpCapture {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	capture := &smtpCapture{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go capture.serve(conn)
+		}
+	}()
+	return capture
+}
+
+func (s *smtpCapture) config() pizza.SMTPConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return pizza.SMTPConfig{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Username: "pizza",
+		Password: "secret",
+		From:     "Pizza Friday <pizza@example.com>",
+	}
+}
+
+func (s *smtpCapture) Mails() []capturedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]capturedMail{}, s.mails...)
+}
+
+func (s *smtpCapture) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	var current capturedMail
+	reply("220 localhost ESMTP capture")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "HELO", "RSET", "NOOP":
+			reply("250 OK")
+		case "AUTH":
+			current.Auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			reply("235 Authenticated")
+		case "MAIL":
+			current.From = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			current.To = append(current.To, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.mails = append(s.mails, current)
+			s.mu.Unlock()
+			current = capturedMail{Auth: current.Auth}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// parseInvite is the subject and the unfolded iCalendar attachment of the email
+func parseInvite(t *testing.T, data string) (*mail.Message, string, string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	require.Nil(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.Nil(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.Nil(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		require.Nil(t, err, "the email has no invite.ics")
+		if part.FileName() != "invite.ics" {
+			continue
+		}
+		encoded, err := io.ReadAll(part)
+		require.Nil(t, err)
+		ics, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+		require.Nil(t, err)
+		return msg, subject, strings.ReplaceAll(string(ics), "\r\n ", "")
+	}
+}
+
+func TestEmailCalendar(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	capture := newSMTPCapture(t)
+	local := newTestLocalCalendar(t, filepath.Join(t.TempDir(), "calendar.db"))
+	calendar, err := pizza.NewEmailCalendar(local, capture.config(), loc)
+	require.Nil(t, err)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	event := pizza.CalendarEvent{
+		Id:          strconv.FormatInt(start.Unix(), 10),
+		Summary:     "Pizza Friday",
+		Description: "Welcome to Pizza Friday",
+		Location:    "Matt's, 1 Main St",
+		StartTime:   start,
+		EndTime:     start.Add(4 * time.Hour),
+		Status:      "confirmed",
+	}
+
+	// WHEN
+	err1 := calendar.CreateEvent(event)
+	err2 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	err3 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	saved, err4 := calendar.GetEvent(event.Id)
+
+	// THEN
+	// the guest is only emailed once for the same answer
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, []pizza.CalendarAttendee{{Email: "foo@bar.com", ResponseStatus: "needsAction"}}, saved.Attendees)
+	mails := capture.Mails()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "pizza@example.com", mails[0].From)
+	assert.Equal(t, []string{"foo@bar.com"}, mails[0].To)
+	auth, err := base64.StdEncoding.DecodeString(mails[0].Auth)
+	require.Nil(t, err)
+	assert.Equal(t, "\x00pizza\x00secret", string(auth))
+	msg, subject, ics := parseInvite(t, mails[0].Data)
+	assert.Equal(t, "Invitation: Pizza Friday @ Fri Mar 7, 2025 5:30pm EST", subject)
+	assert.Equal(t, `"Foo" <foo@bar.com>`, msg.Header.Get("To"))
+	assert.Equal(t, `"Pizza Friday" <pizza@example.com>`, msg.Header.Get("From"))
+	assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+	assert.Contains(t, ics, "UID:"+event.Id+"@rsvp.pizza\r\n")
+	assert.Contains(t, ics, "DTSTART:20250307T223000Z\r\n")
+	assert.Contains(t, ics, "DTEND:20250308T023000Z\r\n")
+	assert.Contains(t, ics, "SEQUENCE:0\r\n")
+	assert.Contains(t, ics, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, ics, "LOCATION:Matt's\\, 1 Main St\r\n")
+	assert.Contains(t, ics, "ORGANIZER;CN=Pizza Friday:mailto:pizza@example.com\r\n")
+	assert.Contains(t, ics, "mailto:foo@bar.com\r\n")
+	assert.Contains(t, ics, "PARTSTAT=NEEDS-ACTION")
+
+	// WHEN
+	err1 = calendar.InviteToEvent(event.Id, "bar@bar.com", "Bar", pizza.RSVPTentative)
+	moved := event
+	moved.Location = "Baz's"
+	err2 = calendar.UpdateEvent(moved)
+	err3 = calendar.DeclineEvent(event.Id, "foo@bar.com")
+
+	// THEN
+	// an update goes to everyone and bumps the sequence so that calendars replace the old invite
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	mails = capture.Mails()
+	require.Len(t, mails, 5)
+	_, _, ics = parseInvite(t, mails[1].Data)
+	assert.Equal(t, []string{"bar@bar.com"}, mails[1].To)
+	assert.Contains(t, ics, "PARTSTAT=TENTATIVE")
+	for _, sent := range mails[2:4] {
+		_, subject, ics = parseInvite(t, sent.Data)
+		assert.Equal(t, "Updated invitation: Pizza Friday @ Fri Mar 7, 2025 5:30pm EST", subject)
+		assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+		assert.Contains(t, ics, "SEQUENCE:1\r\n")
+		assert.Contains(t, ics, "LOCATION:Baz's\r\n")
+	}
+	assert.ElementsMatch(t, []string{"foo@bar.com", "bar@bar.com"}, append(mails[2].To, mails[3].To...))
+	_, subject, ics = parseInvite(t, mails[4].Data)
+	assert.Equal(t, []string{"foo@bar.com"}, mails[4].To)
+	assert.Equal(t, "Declined: Pizza Friday @ Fri Mar 7, 2025 5:30pm EST", subject)
+	assert.Contains(t, ics, "METHOD:CANCEL\r\n")
+	assert.Contains(t, ics, "PARTSTAT=DECLINED")
+
+	// WHEN
+	err1 = calendar.CancelEvent(event.Id)
+	err2 = calendar.ActivateEvent(event.Id)
+	notInvitedErr := calendar.DeclineEvent(event.Id, "baz@bar.com")
+
+	// THEN
+	// guests who declined do not hear about the party again
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.ErrorIs(t, notInvitedErr, pizza.ErrNotInvited)
+	mails = capture.Mails()
+	require.Len(t, mails, 7)
+	_, subject, ics = parseInvite(t, mails[5].Data)
+	assert.Equal(t, []string{"bar@bar.com"}, mails[5].To)
+	assert.Equal(t, "Cancelled: Pizza Friday @ Fri Mar 7, 2025 5:30pm EST", subject)
+	assert.Contains(t, ics, "METHOD:CANCEL\r\n")
+	assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+	assert.Contains(t, ics, "SEQUENCE:2\r\n")
+	_, _, ics = parseInvite(t, mails[6].Data)
+	assert.Equal(t, []string{"bar@bar.com"}, mails[6].To)
+	assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+	assert.Contains(t, ics, "STATUS:CONFIRMED\r\n")
+	assert.Contains(t, ics, "SEQUENCE:3\r\n")
+}
+
+func TestEmailCalendar_SendFails(t *testing.T) {
+	// GIVEN
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+	local := newTestLocalCalendar(t, filepath.Join(t.TempDir(), "calendar.db"))
+	calendar, err := pizza.NewEmailCalendar(local, pizza.SMTPConfig{
+		Host: addr.IP.String(),
+		Port: addr.Port,
+		From: "pizza@example.com",
+	}, time.UTC)
+	require.Nil(t, err)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+	require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{Id: "1", StartTime: start, EndTime: start.Add(time.Hour)}))
+
+	// WHEN
+	inviteErr := calendar.InviteToEvent("1", "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	saved, getErr := calendar.GetEvent("1")
+	_, badFromErr := pizza.NewEmailCalendar(local, pizza.SMTPConfig{Host: "localhost", From: "pizza"}, time.UTC)
+
+	// THEN
+	// the RSVP is kept even when the email could not be sent
+	assert.NotNil(t, inviteErr)
+	assert.Nil(t, getErr)
+	assert.Equal(t, []pizza.CalendarAttendee{{Email: "foo@bar.com", ResponseStatus: "needsAction"}}, saved.Attendees)
+	assert.NotNil(t, badFromErr)
+}
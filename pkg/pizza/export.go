@@ -0,0 +1,1032 @@
+package pizza
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/types"
+)
+
+// ExportVersion is the version of the export document, which must be bumped whenever its fields change meaning
+const ExportVersion = 1
+
+var (
+	ErrExportVersion  = errors.New("unsupported export version")
+	ErrImportConflict = errors.New("import conflicts with existing data")
+)
+
+// ExportDocument is every friend, friday, series and venue in the database in a form that does not depend on the
+// backend
+type ExportDocument struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Friends    []ExportFriend `json:"friends"`
+	Fridays    []ExportFriday `json:"fridays"`
+	Series     []ExportSeries `json:"series,omitempty"`
+	Venues     []ExportVenue  `json:"venues,omitempty"`
+}
+
+// ExportSeries is identified by its name, which fridays refer to
+type ExportSeries struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Group       *string `json:"group"`
+	MaxGuests   int     `json:"maxGuests"`
+	Recurrence  string  `json:"recurrence"`
+	CalendarID  string  `json:"calendarId"`
+}
+
+// ExportVenue is identified by its name, which fridays refer to
+type ExportVenue struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Capacity int    `json:"capacity"`
+	Notes    string `json:"notes"`
+	Ovens    int    `json:"ovens"`
+}
+
+type ExportFriend struct {
+	Email       string            `json:"email"`
+	Name        string            `json:"name"`
+	Preferences ExportPreferences `json:"preferences"`
+}
+
+// ExportPreferences holds the names of the preferences rather than their numbers, so that the export stays readable
+type ExportPreferences struct {
+	Toppings []string `json:"toppings"`
+	Cheese   []string `json:"cheese"`
+	Sauce    []string `json:"sauce"`
+	Doneness string   `json:"doneness"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+// ExportFriday only has a start and duration when the host changed them, only has a series when it is not part of
+// the default series, only has a venue when the host picked one and only has hosts when someone owns it
+type ExportFriday struct {
+	Date            time.Time         `json:"date"`
+	Start           *time.Time        `json:"start,omitempty"`
+	DurationMinutes int               `json:"durationMinutes,omitempty"`
+	Group           *string           `json:"group"`
+	Details         *string           `json:"details"`
+	MaxGuests       int               `json:"maxGuests"`
+	Enabled         bool              `json:"enabled"`
+	Guests          []string          `json:"guests"`
+	Waitlist        []string          `json:"waitlist"`
+	Series          string            `json:"series,omitempty"`
+	RSVPPolicy      *ExportRSVPPolicy `json:"rsvpPolicy,omitempty"`
+	Venue           string            `json:"venue,omitempty"`
+	Host            string            `json:"host,omitempty"`
+	CoHosts         []string          `json:"coHosts,omitempty"`
+	PlusOnes        []ExportPlusOne   `json:"plusOnes,omitempty"`
+	Tentative       []string          `json:"tentative,omitempty"`
+}
+
+// ExportPlusOne is a guest without an account, who is brought by the friend whose email is Sponsor
+type ExportPlusOne struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	Sponsor string `json:"sponsor"`
+}
+
+// ExportRSVPPolicy is the policy of a friday that does not follow the policy of the deployment, in minutes before the
+// friday starts
+type ExportRSVPPolicy struct {
+	OpensMinutes         int `json:"opensMinutes"`
+	ClosesMinutes        int `json:"closesMinutes"`
+	DeclineCutoffMinutes int `json:"declineCutoffMinutes"`
+}
+
+// ConflictPolicy decides what an import does with friends and fridays that already exist
+type ConflictPolicy string
+
+const (
+	// ConflictFail refuses the whole import before anything is written
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip keeps the existing friend or friday as it is
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing friend or friday, including its guest list
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+func ParseConflictPolicy(policy string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(policy); p {
+	case ConflictFail, ConflictSkip, ConflictOverwrite:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", policy)
+	}
+}
+
+// ImportResult counts what an import did
+type ImportResult struct {
+	FriendsCreated int
+	FriendsUpdated int
+	FriendsSkipped int
+	FridaysCreated int
+	FridaysUpdated int
+	FridaysSkipped int
+	SeriesCreated  int
+	SeriesUpdated  int
+	SeriesSkipped  int
+	VenuesCreated  int
+	VenuesUpdated  int
+	VenuesSkipped  int
+}
+
+func exportPreferences(prefs Preferences) ExportPreferences {
+	res := ExportPreferences{
+		Toppings: make([]string, 0, len(prefs.Toppings)),
+		Cheese:   make([]string, 0, len(prefs.Cheese)),
+		Sauce:    make([]string, 0, len(prefs.Sauce)),
+		Doneness: prefs.Doneness.String(),
+		Timezone: prefs.Timezone,
+	}
+	for _, t := range prefs.Toppings {
+		res.Toppings = append(res.Toppings, t.String())
+	}
+	for _, c := range prefs.Cheese {
+		res.Cheese = append(res.Cheese, c.String())
+	}
+	for _, s := range prefs.Sauce {
+		res.Sauce = append(res.Sauce, s.String())
+	}
+	return res
+}
+
+// preferences decodes the names of the preferences and rejects any that are not known
+func (p ExportPreferences) preferences() (Preferences, error) {
+	prefs := Preferences{
+		Toppings: types.ParseToppings(p.Toppings),
+		Cheese:   types.ParseCheeses(p.Cheese),
+		Sauce:    types.ParseSauces(p.Sauce),
+		Doneness: types.ParseDoneness(p.Doneness),
+		Timezone: p.Timezone,
+	}
+	for i, t := range prefs.Toppings {
+		if t == 0 {
+			return prefs, fmt.Errorf("unknown topping %q", p.Toppings[i])
+		}
+	}
+	for i, c := range prefs.Cheese {
+		if c == 0 {
+			return prefs, fmt.Errorf("unknown cheese %q", p.Cheese[i])
+		}
+	}
+	for i, s := range prefs.Sauce {
+		if s == 0 {
+			return prefs, fmt.Errorf("unknown sauce %q", p.Sauce[i])
+		}
+	}
+	if prefs.Doneness == 0 && len(p.Doneness) > 0 {
+		return prefs, fmt.Errorf("unknown doneness %q", p.Doneness)
+	}
+	if len(p.Timezone) > 0 {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			return prefs, fmt.Errorf("unknown timezone %q", p.Timezone)
+		}
+	}
+	return prefs, nil
+}
+
+// ExportData reads every friend, friday, series and venue from the accessor
+func ExportData(accessor Accessor, now time.Time) (ExportDocument, error) {
+	export := ExportDocument{
+		Version:    ExportVersion,
+		ExportedAt: now.UTC(),
+		Friends:    make([]ExportFriend, 0),
+		Fridays:    make([]ExportFriday, 0),
+	}
+	friends, err := accessor.ListFriends()
+	if err != nil {
+		return export, err
+	}
+	for _, friend := range friends {
+		prefs, err := accessor.GetPreferences(friend.Email)
+		if err != nil {
+			return export, fmt.Errorf("preferences of %s: %w", friend.Email, err)
+		}
+		export.Friends = append(export.Friends, ExportFriend{
+			Email:       friend.Email,
+			Name:        friend.Name,
+			Preferences: exportPreferences(prefs),
+		})
+	}
+	series, err := accessor.ListSeries()
+	if err != nil {
+		return export, err
+	}
+	seriesNames := make(map[int64]string)
+	for _, sr := range series {
+		seriesNames[sr.ID] = sr.Name
+		export.Series = append(export.Series, ExportSeries{
+			Name:        sr.Name,
+			Description: sr.Description,
+			Group:       sr.Group,
+			MaxGuests:   sr.MaxGuests,
+			Recurrence:  sr.Recurrence,
+			CalendarID:  sr.CalendarID,
+		})
+	}
+	venues, err := accessor.ListVenues()
+	if err != nil {
+		return export, err
+	}
+	venueNames := make(map[int64]string)
+	for _, venue := range venues {
+		venueNames[venue.ID] = venue.Name
+		export.Venues = append(export.Venues, ExportVenue{
+			Name:     venue.Name,
+			Address:  venue.Address,
+			Capacity: venue.Capacity,
+			Notes:    venue.Notes,
+			Ovens:    venue.Ovens,
+		})
+	}
+	fridays, err := accessor.ListFridays()
+	if err != nil {
+		return export, err
+	}
+	for _, f := range fridays {
+		friday, err := accessor.GetFriday(f.Date)
+		if err != nil {
+			return export, fmt.Errorf("friday %s: %w", f.Date, err)
+		}
+		exportFriday := ExportFriday{
+			Date:            friday.Date.UTC(),
+			DurationMinutes: int(friday.Duration / time.Minute),
+			Group:           friday.Group,
+			Details:         friday.Details,
+			MaxGuests:       friday.MaxGuests,
+			Enabled:         friday.Enabled,
+			Guests:          nonNil(friday.Guests),
+			Waitlist:        nonNil(friday.Waitlist),
+			Series:          seriesNames[friday.SeriesID],
+			Venue:           venueNames[friday.VenueID],
+			Host:            friday.Host,
+		}
+		if len(friday.CoHosts) > 0 {
+			exportFriday.CoHosts = friday.CoHosts
+		}
+		if len(friday.Tentative) > 0 {
+			exportFriday.Tentative = friday.Tentative
+		}
+		for _, plusOne := range friday.PlusOnes {
+			exportFriday.PlusOnes = append(exportFriday.PlusOnes, ExportPlusOne{
+				Name:    plusOne.Name,
+				Email:   plusOne.Email,
+				Sponsor: plusOne.Sponsor,
+			})
+		}
+		if !friday.Start.IsZero() {
+			start := friday.Start.UTC()
+			exportFriday.Start = &start
+		}
+		if friday.Policy != nil {
+			exportFriday.RSVPPolicy = &ExportRSVPPolicy{
+				OpensMinutes:         int(friday.Policy.OpensBefore / time.Minute),
+				ClosesMinutes:        int(friday.Policy.ClosesBefore / time.Minute),
+				DeclineCutoffMinutes: int(friday.Policy.DeclineCutoff / time.Minute),
+			}
+		}
+		export.Fridays = append(export.Fridays, exportFriday)
+	}
+	slices.SortFunc(export.Fridays, func(x, y ExportFriday) int {
+		return x.Date.Compare(y.Date)
+	})
+	return export, nil
+}
+
+func nonNil(list []string) []string {
+	if list == nil {
+		return make([]string, 0)
+	}
+	return list
+}
+
+// ImportData writes the friends, series, venues and fridays of the export through the accessor. Conflicts, unknown series and
+// venues, full guest lists and plus-ones without a sponsor are all found before anything is written, so that an invalid
+// import or ConflictFail leaves the database untouched. The writes are not rolled back if the accessor fails part way
+// through. Fridays are stored at their time in loc.
+func ImportData(accessor Accessor, export ExportDocument, policy ConflictPolicy, loc *time.Location) (ImportResult, error) {
+	result := ImportResult{}
+	if export.Version < 1 || export.Version > ExportVersion {
+		return result, fmt.Errorf("%w: %d", ErrExportVersion, export.Version)
+	}
+	prefs := make([]Preferences, len(export.Friends))
+	for i, friend := range export.Friends {
+		var err error
+		if prefs[i], err = friend.Preferences.preferences(); err != nil {
+			return result, fmt.Errorf("friend %s: %w", friend.Email, err)
+		}
+	}
+
+	// fridays are stored at their time in the timezone of the deployment, which the export does not keep
+	fridays := make([]ExportFriday, len(export.Fridays))
+	for i, friday := range export.Fridays {
+		friday.Date = friday.Date.In(loc)
+		fridays[i] = friday
+	}
+
+	existingSeries, err := accessor.ListSeries()
+	if err != nil {
+		return result, err
+	}
+	seriesIDs := make(map[string]int64)
+	for _, sr := range existingSeries {
+		seriesIDs[sr.Name] = sr.ID
+	}
+	for _, friday := range fridays {
+		known := len(friday.Series) == 0 || seriesIDs[friday.Series] != 0 ||
+			slices.ContainsFunc(export.Series, func(sr ExportSeries) bool { return sr.Name == friday.Series })
+		if !known {
+			return result, fmt.Errorf("friday %s: unknown series %q", friday.Date.Format(time.RFC3339), friday.Series)
+		}
+	}
+	existingVenues, err := accessor.ListVenues()
+	if err != nil {
+		return result, err
+	}
+	venueIDs := make(map[string]int64)
+	for _, venue := range existingVenues {
+		venueIDs[venue.Name] = venue.ID
+	}
+	for _, friday := range fridays {
+		known := len(friday.Venue) == 0 || venueIDs[friday.Venue] != 0 ||
+			slices.ContainsFunc(export.Venues, func(venue ExportVenue) bool { return venue.Name == friday.Venue })
+		if !known {
+			return result, fmt.Errorf("friday %s: unknown venue %q", friday.Date.Format(time.RFC3339), friday.Venue)
+		}
+	}
+	friendEmails := make(map[string]bool, len(export.Friends))
+	for _, friend := range export.Friends {
+		friendEmails[friend.Email] = true
+	}
+	for _, friday := range fridays {
+		if err := validateImportFriday(accessor, friday, friendEmails); err != nil {
+			return result, fmt.Errorf("friday %s: %w", friday.Date.Format(time.RFC3339), err)
+		}
+	}
+	for _, sr := range export.Series {
+		if len(sr.Recurrence) > 0 {
+			if _, err := ParseRecurrence(sr.Recurrence, loc); err != nil {
+				return result, fmt.Errorf("series %s: %w", sr.Name, err)
+			}
+		}
+	}
+
+	conflicts := make([]string, 0)
+	for _, sr := range export.Series {
+		if _, ok := seriesIDs[sr.Name]; ok {
+			conflicts = append(conflicts, sr.Name)
+		}
+	}
+	for _, venue := range export.Venues {
+		if _, ok := venueIDs[venue.Name]; ok {
+			conflicts = append(conflicts, venue.Name)
+		}
+	}
+	friendExists := make([]bool, len(export.Friends))
+	for i, friend := range export.Friends {
+		_, err := accessor.GetFriendByEmail(friend.Email)
+		if err == nil {
+			friendExists[i] = true
+			conflicts = append(conflicts, friend.Email)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+	}
+	fridayExists := make([]bool, len(fridays))
+	for i, friday := range fridays {
+		exists, err := accessor.DoesFridayExist(friday.Date)
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			fridayExists[i] = true
+			conflicts = append(conflicts, friday.Date.Format(time.RFC3339))
+		}
+	}
+	if policy == ConflictFail && len(conflicts) > 0 {
+		return result, fmt.Errorf("%w: %s", ErrImportConflict, strings.Join(conflicts, ", "))
+	}
+
+	for i, friend := range export.Friends {
+		if friendExists[i] && policy == ConflictSkip {
+			result.FriendsSkipped++
+			continue
+		}
+		if err := accessor.AddFriend(friend.Email, friend.Name); err != nil {
+			return result, fmt.Errorf("friend %s: %w", friend.Email, err)
+		}
+		if err := accessor.SetPreferences(friend.Email, prefs[i]); err != nil {
+			return result, fmt.Errorf("friend %s: %w", friend.Email, err)
+		}
+		if friendExists[i] {
+			result.FriendsUpdated++
+		} else {
+			result.FriendsCreated++
+		}
+	}
+
+	for _, sr := range export.Series {
+		ID, exists := seriesIDs[sr.Name]
+		if exists && policy == ConflictSkip {
+			result.SeriesSkipped++
+			continue
+		}
+		series := Series{
+			ID:          ID,
+			Name:        sr.Name,
+			Description: sr.Description,
+			Group:       sr.Group,
+			MaxGuests:   sr.MaxGuests,
+			Recurrence:  sr.Recurrence,
+			CalendarID:  sr.CalendarID,
+		}
+		if exists {
+			if err := accessor.UpdateSeries(series); err != nil {
+				return result, fmt.Errorf("series %s: %w", sr.Name, err)
+			}
+			result.SeriesUpdated++
+		} else {
+			if seriesIDs[sr.Name], err = accessor.AddSeries(series); err != nil {
+				return result, fmt.Errorf("series %s: %w", sr.Name, err)
+			}
+			result.SeriesCreated++
+		}
+	}
+
+	for _, ev := range export.Venues {
+		ID, exists := venueIDs[ev.Name]
+		if exists && policy == ConflictSkip {
+			result.VenuesSkipped++
+			continue
+		}
+		venue := Venue{
+			ID:       ID,
+			Name:     ev.Name,
+			Address:  ev.Address,
+			Capacity: ev.Capacity,
+			Notes:    ev.Notes,
+			Ovens:    ev.Ovens,
+		}
+		if exists {
+			if err := accessor.UpdateVenue(venue); err != nil {
+				return result, fmt.Errorf("venue %s: %w", ev.Name, err)
+			}
+			result.VenuesUpdated++
+		} else {
+			if venueIDs[ev.Name], err = accessor.AddVenue(venue); err != nil {
+				return result, fmt.Errorf("venue %s: %w", ev.Name, err)
+			}
+			result.VenuesCreated++
+		}
+	}
+
+	for i, friday := range fridays {
+		if fridayExists[i] && policy == ConflictSkip {
+			result.FridaysSkipped++
+			continue
+		}
+		err := importFriday(accessor, friday, seriesIDs[friday.Series], venueIDs[friday.Venue], fridayExists[i])
+		if err != nil {
+			return result, fmt.Errorf("friday %s: %w", friday.Date.Format(time.RFC3339), err)
+		}
+		if fridayExists[i] {
+			result.FridaysUpdated++
+		} else {
+			result.FridaysCreated++
+		}
+	}
+	return result, nil
+}
+
+// validateImportFriday checks what the accessor would otherwise only reject part way through writing the friday, once its
+// guest list has already been replaced
+func validateImportFriday(accessor Accessor, friday ExportFriday, friendEmails map[string]bool) error {
+	if len(friday.Guests)+len(friday.PlusOnes) > friday.MaxGuests {
+		return fmt.Errorf("%w: %d guests and %d plus-ones for %d places", ErrFridayIsFull, len(friday.Guests),
+			len(friday.PlusOnes), friday.MaxGuests)
+	}
+	for _, plusOne := range friday.PlusOnes {
+		// the guests of the friday are added before its plus-ones, so they can sponsor them
+		if friendEmails[plusOne.Sponsor] ||
+			slices.Contains(slices.Concat(friday.Guests, friday.Waitlist, friday.Tentative), plusOne.Sponsor) {
+			continue
+		}
+		if _, err := accessor.GetFriendByEmail(plusOne.Sponsor); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("plus-one %s: unknown sponsor %q", plusOne.Name, plusOne.Sponsor)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importFriday(accessor Accessor, friday ExportFriday, seriesID, venueID int64, exists bool) error {
+	if exists {
+		// start the guest list over so that it matches the import, order included
+		current, err := accessor.GetFriday(friday.Date)
+		if err != nil {
+			return err
+		}
+		for _, email := range slices.Concat(current.Guests, current.Waitlist, current.Tentative) {
+			if err = accessor.RemoveFriendFromFriday(email, friday.Date); err != nil {
+				return err
+			}
+		}
+		for _, plusOne := range current.PlusOnes {
+			if err = accessor.RemovePlusOne(friday.Date, plusOne.ID); err != nil {
+				return err
+			}
+		}
+	} else if err := accessor.AddFriday(friday.Date); err != nil {
+		return err
+	}
+	f := Friday{
+		Date:      friday.Date,
+		SeriesID:  seriesID,
+		Duration:  time.Duration(friday.DurationMinutes) * time.Minute,
+		Group:     friday.Group,
+		Details:   friday.Details,
+		MaxGuests: friday.MaxGuests,
+		Enabled:   friday.Enabled,
+		VenueID:   venueID,
+	}
+	if friday.Start != nil {
+		f.Start = friday.Start.In(friday.Date.Location())
+	}
+	if friday.RSVPPolicy != nil {
+		f.Policy = &RSVPPolicy{
+			OpensBefore:   time.Duration(friday.RSVPPolicy.OpensMinutes) * time.Minute,
+			ClosesBefore:  time.Duration(friday.RSVPPolicy.ClosesMinutes) * time.Minute,
+			DeclineCutoff: time.Duration(friday.RSVPPolicy.DeclineCutoffMinutes) * time.Minute,
+		}
+	}
+	if err := accessor.UpdateFriday(f); err != nil {
+		return err
+	}
+	if exists || len(friday.Host) > 0 || len(friday.CoHosts) > 0 {
+		if err := accessor.SetFridayHosts(f.Date, friday.Host, friday.CoHosts); err != nil {
+			return fmt.Errorf("hosts: %w", err)
+		}
+	}
+	for _, email := range friday.Guests {
+		if err := accessor.AddFriendToFriday(email, f, ""); err != nil {
+			return fmt.Errorf("guest %s: %w", email, err)
+		}
+	}
+	for _, email := range friday.Waitlist {
+		if err := accessor.AddFriendToWaitlist(email, f.Date); err != nil {
+			return fmt.Errorf("waitlist %s: %w", email, err)
+		}
+	}
+	for _, email := range friday.Tentative {
+		if err := accessor.AddFriendAsTentative(email, f.Date); err != nil {
+			return fmt.Errorf("tentative %s: %w", email, err)
+		}
+	}
+	for _, plusOne := range friday.PlusOnes {
+		_, err := accessor.AddPlusOne(f.Date, PlusOne{Name: plusOne.Name, Email: plusOne.Email, Sponsor: plusOne.Sponsor})
+		if err != nil {
+			return fmt.Errorf("plus-one %s: %w", plusOne.Name, err)
+		}
+	}
+	return nil
+}
+
+func WriteExportJSON(w io.Writer, export ExportDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+func ReadExportJSON(r io.Reader) (ExportDocument, error) {
+	export := ExportDocument{}
+	err := json.NewDecoder(r).Decode(&export)
+	return export, err
+}
+
+const (
+	exportFriendsCSV  = "friends.csv"
+	exportFridaysCSV  = "fridays.csv"
+	exportSeriesCSV   = "series.csv"
+	exportVenuesCSV   = "venues.csv"
+	exportPlusOnesCSV = "plus_ones.csv"
+	// csvListSeparator joins the lists within a single CSV cell
+	csvListSeparator = ";"
+)
+
+var (
+	exportFriendsHeader = []string{"email", "name", "toppings", "cheese", "sauce", "doneness", "timezone"}
+	exportFridaysHeader = []string{"date", "start", "duration_minutes", "group", "details", "max_guests", "enabled",
+		"guests", "waitlist", "series", "rsvp_opens_minutes", "rsvp_closes_minutes", "decline_cutoff_minutes", "venue",
+		"host", "co_hosts", "tentative"}
+	exportSeriesHeader   = []string{"name", "description", "group", "max_guests", "recurrence", "calendar_id"}
+	exportVenuesHeader   = []string{"name", "address", "capacity", "notes", "ovens"}
+	exportPlusOnesHeader = []string{"date", "name", "email", "sponsor"}
+)
+
+func joinCSVList(list []string) string {
+	return strings.Join(list, csvListSeparator)
+}
+
+func splitCSVList(cell string) []string {
+	if len(cell) == 0 {
+		return make([]string, 0)
+	}
+	return strings.Split(cell, csvListSeparator)
+}
+
+func csvOptional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func csvOptionalCell(cell string) *string {
+	if len(cell) == 0 {
+		return nil
+	}
+	return &cell
+}
+
+// WriteExportCSV writes the friends, fridays, series, venues and plus-ones of the export to their own CSV files in the directory. Empty
+// groups and details are written as empty cells, so they are read back as unset.
+func WriteExportCSV(dir string, export ExportDocument) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	friends := [][]string{exportFriendsHeader}
+	for _, friend := range export.Friends {
+		friends = append(friends, []string{
+			friend.Email,
+			friend.Name,
+			joinCSVList(friend.Preferences.Toppings),
+			joinCSVList(friend.Preferences.Cheese),
+			joinCSVList(friend.Preferences.Sauce),
+			friend.Preferences.Doneness,
+			friend.Preferences.Timezone,
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, exportFriendsCSV), friends); err != nil {
+		return err
+	}
+	fridays := [][]string{exportFridaysHeader}
+	for _, friday := range export.Fridays {
+		start, duration := "", ""
+		if friday.Start != nil {
+			start = friday.Start.Format(time.RFC3339)
+		}
+		if friday.DurationMinutes > 0 {
+			duration = strconv.Itoa(friday.DurationMinutes)
+		}
+		opens, closes, declineCutoff := "", "", ""
+		if friday.RSVPPolicy != nil {
+			opens = strconv.Itoa(friday.RSVPPolicy.OpensMinutes)
+			closes = strconv.Itoa(friday.RSVPPolicy.ClosesMinutes)
+			declineCutoff = strconv.Itoa(friday.RSVPPolicy.DeclineCutoffMinutes)
+		}
+		fridays = append(fridays, []string{
+			friday.Date.Format(time.RFC3339),
+			start,
+			duration,
+			csvOptional(friday.Group),
+			csvOptional(friday.Details),
+			strconv.Itoa(friday.MaxGuests),
+			strconv.FormatBool(friday.Enabled),
+			joinCSVList(friday.Guests),
+			joinCSVList(friday.Waitlist),
+			friday.Series,
+			opens,
+			closes,
+			declineCutoff,
+			friday.Venue,
+			friday.Host,
+			joinCSVList(friday.CoHosts),
+			joinCSVList(friday.Tentative),
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, exportFridaysCSV), fridays); err != nil {
+		return err
+	}
+	series := [][]string{exportSeriesHeader}
+	for _, sr := range export.Series {
+		series = append(series, []string{
+			sr.Name,
+			sr.Description,
+			csvOptional(sr.Group),
+			strconv.Itoa(sr.MaxGuests),
+			sr.Recurrence,
+			sr.CalendarID,
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, exportSeriesCSV), series); err != nil {
+		return err
+	}
+	venues := [][]string{exportVenuesHeader}
+	for _, venue := range export.Venues {
+		venues = append(venues, []string{
+			venue.Name,
+			venue.Address,
+			strconv.Itoa(venue.Capacity),
+			venue.Notes,
+			strconv.Itoa(venue.Ovens),
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, exportVenuesCSV), venues); err != nil {
+		return err
+	}
+	plusOnes := [][]string{exportPlusOnesHeader}
+	for _, friday := range export.Fridays {
+		for _, plusOne := range friday.PlusOnes {
+			plusOnes = append(plusOnes, []string{
+				friday.Date.Format(time.RFC3339),
+				plusOne.Name,
+				plusOne.Email,
+				plusOne.Sponsor,
+			})
+		}
+	}
+	return writeCSVFile(filepath.Join(dir, exportPlusOnesCSV), plusOnes)
+}
+
+func writeCSVFile(file string, records [][]string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err = w.WriteAll(records); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadExportCSV reads the CSV files written by WriteExportCSV from the directory. The series, venues and plus-ones
+// files are optional, since older exports do not have them.
+func ReadExportCSV(dir string) (ExportDocument, error) {
+	export := ExportDocument{
+		Version: ExportVersion,
+		Friends: make([]ExportFriend, 0),
+		Fridays: make([]ExportFriday, 0),
+	}
+	friends, err := readCSVFile(filepath.Join(dir, exportFriendsCSV), exportFriendsHeader)
+	if err != nil {
+		return export, err
+	}
+	for _, record := range friends {
+		export.Friends = append(export.Friends, ExportFriend{
+			Email: record[0],
+			Name:  record[1],
+			Preferences: ExportPreferences{
+				Toppings: splitCSVList(record[2]),
+				Cheese:   splitCSVList(record[3]),
+				Sauce:    splitCSVList(record[4]),
+				Doneness: record[5],
+				Timezone: record[6],
+			},
+		})
+	}
+	fridays, err := readCSVFile(filepath.Join(dir, exportFridaysCSV), exportFridaysHeader)
+	if err != nil {
+		return export, err
+	}
+	for i, record := range fridays {
+		date, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+		}
+		friday := ExportFriday{
+			Date:     date,
+			Group:    csvOptionalCell(record[3]),
+			Details:  csvOptionalCell(record[4]),
+			Guests:   splitCSVList(record[7]),
+			Waitlist: splitCSVList(record[8]),
+			Series:   record[9],
+			Venue:    record[13],
+			Host:     record[14],
+		}
+		if coHosts := splitCSVList(record[15]); len(coHosts) > 0 {
+			friday.CoHosts = coHosts
+		}
+		if tentative := splitCSVList(record[16]); len(tentative) > 0 {
+			friday.Tentative = tentative
+		}
+		if len(record[1]) > 0 {
+			start, err := time.Parse(time.RFC3339, record[1])
+			if err != nil {
+				return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+			}
+			friday.Start = &start
+		}
+		if len(record[2]) > 0 {
+			if friday.DurationMinutes, err = strconv.Atoi(record[2]); err != nil {
+				return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+			}
+		}
+		if len(record[10]) > 0 {
+			friday.RSVPPolicy = &ExportRSVPPolicy{}
+			for j, dest := range []*int{&friday.RSVPPolicy.OpensMinutes, &friday.RSVPPolicy.ClosesMinutes,
+				&friday.RSVPPolicy.DeclineCutoffMinutes} {
+				if *dest, err = strconv.Atoi(record[10+j]); err != nil {
+					return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+				}
+			}
+		}
+		if friday.MaxGuests, err = strconv.Atoi(record[5]); err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+		}
+		if friday.Enabled, err = strconv.ParseBool(record[6]); err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportFridaysCSV, i+2, err)
+		}
+		export.Fridays = append(export.Fridays, friday)
+	}
+	series, err := readCSVFile(filepath.Join(dir, exportSeriesCSV), exportSeriesHeader)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return export, err
+	}
+	for i, record := range series {
+		sr := ExportSeries{
+			Name:        record[0],
+			Description: record[1],
+			Group:       csvOptionalCell(record[2]),
+			Recurrence:  record[4],
+			CalendarID:  record[5],
+		}
+		if sr.MaxGuests, err = strconv.Atoi(record[3]); err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportSeriesCSV, i+2, err)
+		}
+		export.Series = append(export.Series, sr)
+	}
+	venues, err := readCSVFile(filepath.Join(dir, exportVenuesCSV), exportVenuesHeader)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return export, err
+	}
+	for i, record := range venues {
+		venue := ExportVenue{
+			Name:    record[0],
+			Address: record[1],
+			Notes:   record[3],
+		}
+		if venue.Capacity, err = strconv.Atoi(record[2]); err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportVenuesCSV, i+2, err)
+		}
+		if venue.Ovens, err = strconv.Atoi(record[4]); err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportVenuesCSV, i+2, err)
+		}
+		export.Venues = append(export.Venues, venue)
+	}
+	plusOnes, err := readCSVFile(filepath.Join(dir, exportPlusOnesCSV), exportPlusOnesHeader)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return export, err
+	}
+	for i, record := range plusOnes {
+		date, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			return export, fmt.Errorf("%s line %d: %w", exportPlusOnesCSV, i+2, err)
+		}
+		j := slices.IndexFunc(export.Fridays, func(friday ExportFriday) bool { return friday.Date.Equal(date) })
+		if j < 0 {
+			return export, fmt.Errorf("%s line %d: unknown friday %s", exportPlusOnesCSV, i+2, record[0])
+		}
+		export.Fridays[j].PlusOnes = append(export.Fridays[j].PlusOnes, ExportPlusOne{
+			Name:    record[1],
+			Email:   record[2],
+			Sponsor: record[3],
+		})
+	}
+	return export, nil
+}
+
+// readCSVFile returns the records of the file after checking that its header matches
+func readCSVFile(file string, header []string) ([][]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = len(header)
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(file), err)
+	}
+	if len(records) == 0 || !slices.Equal(records[0], header) {
+		return nil, fmt.Errorf("%s: header must be %s", filepath.Base(file), strings.Join(header, ","))
+	}
+	return records[1:], nil
+}
+
+func Export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "json or csv")
+	out := fs.String("out", "", "file to write the JSON to, or directory to write the CSV files to")
+	fs.Parse(args)
+	if *format != "json" && *format != "csv" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *format == "csv" && len(*out) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	accessor, err := NewAccessor(LoadConfigEnv(), true)
+	if err != nil {
+		slog.Error("accessor init failure", "error", err)
+		os.Exit(1)
+	}
+	export, err := ExportData(accessor, time.Now())
+	if err != nil {
+		slog.Error("export failed", "error", err)
+		os.Exit(1)
+	}
+
+	if *format == "csv" {
+		err = WriteExportCSV(*out, export)
+	} else if len(*out) == 0 {
+		err = WriteExportJSON(os.Stdout, export)
+	} else {
+		var f *os.File
+		if f, err = os.Create(*out); err == nil {
+			err = WriteExportJSON(f, export)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		slog.Error("export failed", "error", err, "out", *out)
+		os.Exit(1)
+	}
+	slog.Info("export complete", "friends", len(export.Friends), "fridays", len(export.Fridays),
+		"series", len(export.Series), "venues", len(export.Venues))
+}
+
+func Import(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "json", "json or csv")
+	in := fs.String("in", "", "JSON file, or directory of CSV files, to import")
+	onConflict := fs.String("on-conflict", string(ConflictFail),
+		"what to do with existing emails and dates: fail, skip or overwrite. An import that fails part way through is not rolled back")
+	fs.Parse(args)
+	policy, err := ParseConflictPolicy(*onConflict)
+	if err != nil || len(*in) == 0 || (*format != "json" && *format != "csv") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var export ExportDocument
+	if *format == "csv" {
+		export, err = ReadExportCSV(*in)
+	} else {
+		var f *os.File
+		if f, err = os.Open(*in); err == nil {
+			export, err = ReadExportJSON(f)
+			f.Close()
+		}
+	}
+	if err != nil {
+		slog.Error("could not read import", "error", err, "in", *in)
+		os.Exit(1)
+	}
+
+	config := LoadConfigEnv()
+	loc, err := config.Location()
+	if err != nil {
+		slog.Error("invalid timezone", "error", err)
+		os.Exit(1)
+	}
+	accessor, err := NewAccessor(config, true)
+	if err != nil {
+		slog.Error("accessor init failure", "error", err)
+		os.Exit(1)
+	}
+	result, err := ImportData(accessor, export, policy, loc)
+	if err != nil {
+		slog.Error("import failed", "error", err, "in", *in)
+		os.Exit(1)
+	}
+	slog.Info("import complete",
+		"friendsCreated", result.FriendsCreated, "friendsUpdated", result.FriendsUpdated,
+		"friendsSkipped", result.FriendsSkipped, "fridaysCreated", result.FridaysCreated,
+		"fridaysUpdated", result.FridaysUpdated, "fridaysSkipped", result.FridaysSkipped,
+		"seriesCreated", result.SeriesCreated, "seriesUpdated", result.SeriesUpdated,
+		"seriesSkipped", result.SeriesSkipped, "venuesCreated", result.VenuesCreated,
+		"venuesUpdated", result.VenuesUpdated, "venuesSkipped", result.VenuesSkipped)
+}
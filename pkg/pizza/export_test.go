@@ -0,0 +1,304 @@
+package pizza_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/mpoegel/rsvp.pizza/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportTestAccessor(t *testing.T, friday time.Time) *pizza.MemoryAccessor {
+	accessor := pizza.NewMemoryAccessor()
+	require.Nil(t, accessor.AddFriend("foo@bar.com", "foo"))
+	require.Nil(t, accessor.SetPreferences("foo@bar.com", pizza.Preferences{
+		Toppings: []types.Topping{types.Basil},
+		Cheese:   []types.Cheese{types.Parmesan},
+		Sauce:    []types.Sauce{types.Vodka},
+		Doneness: types.Medium,
+		Timezone: "Europe/Berlin",
+	}))
+	require.Nil(t, accessor.AddFriend("bar@bar.com", "bar"))
+	require.Nil(t, accessor.AddFriday(friday))
+	group := "pizza"
+	seriesID, err := accessor.AddSeries(pizza.Series{Name: "Late Pizza", Group: &group, MaxGuests: 1,
+		Recurrence: "DTSTART:20250103T220000 RRULE:FREQ=WEEKLY;BYDAY=FR"})
+	require.Nil(t, err)
+	venueID, err := accessor.AddVenue(pizza.Venue{Name: "Matt's", Address: "1 Main St", Capacity: 8, Notes: "ring",
+		Ovens: 1})
+	require.Nil(t, err)
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: friday, SeriesID: seriesID, Group: &group, MaxGuests: 2,
+		Enabled: true, Start: friday.Add(30 * time.Minute), Duration: 3 * time.Hour, VenueID: venueID,
+		Policy: &pizza.RSVPPolicy{OpensBefore: 72 * time.Hour, ClosesBefore: time.Hour}}))
+	f, err := accessor.GetFriday(friday)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", f, ""))
+	require.Nil(t, accessor.AddFriendToWaitlist("bar@bar.com", friday))
+	require.Nil(t, accessor.AddFriendAsTentative("baz@bar.com", friday))
+	require.Nil(t, accessor.SetFridayHosts(friday, "foo@bar.com", []string{"bar@bar.com"}))
+	_, err = accessor.AddPlusOne(friday, pizza.PlusOne{Name: "Alex", Sponsor: "foo@bar.com"})
+	require.Nil(t, err)
+	return accessor
+}
+
+func TestExportData(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	accessor := newExportTestAccessor(t, friday)
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	// WHEN
+	export, err := pizza.ExportData(accessor, now)
+
+	// THEN
+	assert.Nil(t, err)
+	group := "pizza"
+	start := friday.Add(30 * time.Minute)
+	assert.Equal(t, pizza.ExportDocument{
+		Version:    pizza.ExportVersion,
+		ExportedAt: now,
+		Friends: []pizza.ExportFriend{
+			{
+				Email: "foo@bar.com",
+				Name:  "foo",
+				Preferences: pizza.ExportPreferences{
+					Toppings: []string{"Basil"},
+					Cheese:   []string{"Parmesan"},
+					Sauce:    []string{"Vodka"},
+					Doneness: "Medium",
+					Timezone: "Europe/Berlin",
+				},
+			},
+			{
+				Email: "bar@bar.com",
+				Name:  "bar",
+				Preferences: pizza.ExportPreferences{
+					Toppings: []string{},
+					Cheese:   []string{},
+					Sauce:    []string{},
+				},
+			},
+			{
+				Email: "baz@bar.com",
+				Preferences: pizza.ExportPreferences{
+					Toppings: []string{},
+					Cheese:   []string{},
+					Sauce:    []string{},
+				},
+			},
+		},
+		Fridays: []pizza.ExportFriday{
+			{
+				Date:            friday,
+				Start:           &start,
+				DurationMinutes: 180,
+				Group:           &group,
+				MaxGuests:       2,
+				Enabled:         true,
+				Guests:          []string{"foo@bar.com"},
+				Waitlist:        []string{"bar@bar.com"},
+				Series:          "Late Pizza",
+				RSVPPolicy:      &pizza.ExportRSVPPolicy{OpensMinutes: 72 * 60, ClosesMinutes: 60},
+				Venue:           "Matt's",
+				Host:            "foo@bar.com",
+				CoHosts:         []string{"bar@bar.com"},
+				PlusOnes:        []pizza.ExportPlusOne{{Name: "Alex", Sponsor: "foo@bar.com"}},
+				Tentative:       []string{"baz@bar.com"},
+			},
+		},
+		Series: []pizza.ExportSeries{
+			{
+				Name:       "Late Pizza",
+				Group:      &group,
+				MaxGuests:  1,
+				Recurrence: "DTSTART:20250103T220000 RRULE:FREQ=WEEKLY;BYDAY=FR",
+			},
+		},
+		Venues: []pizza.ExportVenue{
+			{Name: "Matt's", Address: "1 Main St", Capacity: 8, Notes: "ring", Ovens: 1},
+		},
+	}, export)
+}
+
+func TestExportData_JSONAndCSVRoundTrip(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	export, err := pizza.ExportData(newExportTestAccessor(t, friday), time.Now())
+	require.Nil(t, err)
+	dir := t.TempDir()
+
+	// WHEN
+	buf := &bytes.Buffer{}
+	err = pizza.WriteExportJSON(buf, export)
+	fromJSON, err2 := pizza.ReadExportJSON(buf)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, export.Friends, fromJSON.Friends)
+	assert.Equal(t, export.Fridays, fromJSON.Fridays)
+	assert.Equal(t, export.Series, fromJSON.Series)
+	assert.Equal(t, export.Venues, fromJSON.Venues)
+
+	// WHEN
+	err = pizza.WriteExportCSV(dir, export)
+	fromCSV, err2 := pizza.ReadExportCSV(dir)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.FileExists(t, filepath.Join(dir, "friends.csv"))
+	assert.Equal(t, export.Friends, fromCSV.Friends)
+	assert.Equal(t, export.Fridays, fromCSV.Fridays)
+	assert.Equal(t, export.Series, fromCSV.Series)
+	assert.Equal(t, export.Venues, fromCSV.Venues)
+}
+
+func TestImportData(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	export, err := pizza.ExportData(newExportTestAccessor(t, friday), time.Now())
+	require.Nil(t, err)
+	accessor := pizza.NewMemoryAccessor()
+
+	// WHEN
+	result, err := pizza.ImportData(accessor, export, pizza.ConflictFail, mustLoadNY(t))
+	imported, err2 := pizza.ExportData(accessor, export.ExportedAt)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, pizza.ImportResult{FriendsCreated: 3, FridaysCreated: 1, SeriesCreated: 1,
+		VenuesCreated: 1}, result)
+	assert.Equal(t, export, imported)
+}
+
+func TestImportData_Conflicts(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	export, err := pizza.ExportData(newExportTestAccessor(t, friday), time.Now())
+	require.Nil(t, err)
+	export.Friends[0].Name = "new foo"
+	export.Fridays[0].MaxGuests = 2
+	export.Fridays[0].Guests = []string{"bar@bar.com", "foo@bar.com"}
+	export.Fridays[0].Waitlist = []string{}
+	export.Fridays[0].PlusOnes = nil
+	export.Fridays[0].Tentative = nil
+
+	// WHEN
+	accessor := newExportTestAccessor(t, friday)
+	_, err = pizza.ImportData(accessor, export, pizza.ConflictFail, mustLoadNY(t))
+	friend, _ := accessor.GetFriendByEmail("foo@bar.com")
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrImportConflict)
+	assert.Equal(t, "foo", friend.Name)
+
+	// WHEN
+	result, err := pizza.ImportData(accessor, export, pizza.ConflictSkip, mustLoadNY(t))
+	friend, _ = accessor.GetFriendByEmail("foo@bar.com")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, pizza.ImportResult{FriendsSkipped: 3, FridaysSkipped: 1, SeriesSkipped: 1,
+		VenuesSkipped: 1}, result)
+	assert.Equal(t, "foo", friend.Name)
+
+	// WHEN
+	result, err = pizza.ImportData(accessor, export, pizza.ConflictOverwrite, mustLoadNY(t))
+	friend, _ = accessor.GetFriendByEmail("foo@bar.com")
+	f, _ := accessor.GetFriday(friday)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, pizza.ImportResult{FriendsUpdated: 3, FridaysUpdated: 1, SeriesUpdated: 1,
+		VenuesUpdated: 1}, result)
+	assert.Equal(t, "new foo", friend.Name)
+	assert.Equal(t, 2, f.MaxGuests)
+	assert.Equal(t, []string{"bar@bar.com", "foo@bar.com"}, f.Guests)
+	assert.Empty(t, f.Waitlist)
+	assert.Empty(t, f.PlusOnes)
+	assert.Empty(t, f.Tentative)
+}
+
+func TestImportData_Invalid(t *testing.T) {
+	// GIVEN
+	accessor := pizza.NewMemoryAccessor()
+	export := pizza.ExportDocument{
+		Version: pizza.ExportVersion,
+		Friends: []pizza.ExportFriend{
+			{Email: "foo@bar.com", Preferences: pizza.ExportPreferences{Toppings: []string{"Pineapple Express"}}},
+		},
+	}
+
+	unknownSeries := pizza.ExportDocument{
+		Version: pizza.ExportVersion,
+		Friends: []pizza.ExportFriend{{Email: "foo@bar.com"}},
+		Fridays: []pizza.ExportFriday{{Date: time.Now(), Series: "Sunday Calzones"}},
+	}
+	unknownVenue := pizza.ExportDocument{
+		Version: pizza.ExportVersion,
+		Fridays: []pizza.ExportFriday{{Date: time.Now(), Venue: "The Moon"}},
+	}
+
+	// WHEN
+	_, err := pizza.ImportData(accessor, export, pizza.ConflictFail, mustLoadNY(t))
+	_, err2 := pizza.ImportData(accessor, pizza.ExportDocument{Version: pizza.ExportVersion + 1}, pizza.ConflictFail, mustLoadNY(t))
+	_, err3 := pizza.ImportData(accessor, unknownSeries, pizza.ConflictFail, mustLoadNY(t))
+	_, err4 := pizza.ImportData(accessor, unknownVenue, pizza.ConflictFail, mustLoadNY(t))
+	friends, _ := accessor.ListFriends()
+
+	// THEN
+	assert.ErrorContains(t, err, "unknown topping")
+	assert.ErrorIs(t, err2, pizza.ErrExportVersion)
+	assert.ErrorContains(t, err3, "unknown series")
+	assert.ErrorContains(t, err4, "unknown venue")
+	assert.Empty(t, friends)
+}
+
+func TestImportData_SQLAccessor(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	export, err := pizza.ExportData(newExportTestAccessor(t, friday), time.Now())
+	require.Nil(t, err)
+	accessor := newTestSQLAccessor(t, filepath.Join(t.TempDir(), "pizza.db"))
+
+	// WHEN
+	_, err = pizza.ImportData(accessor, export, pizza.ConflictFail, mustLoadNY(t))
+	imported, err2 := pizza.ExportData(accessor, export.ExportedAt)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, export, imported)
+}
+
+func TestImportData_InvalidFridayLeavesDatabaseUntouched(t *testing.T) {
+	// GIVEN
+	friday := time.Date(2025, time.March, 7, 22, 0, 0, 0, time.UTC)
+	export, err := pizza.ExportData(newExportTestAccessor(t, friday), time.Now())
+	require.Nil(t, err)
+	full := export
+	full.Fridays = []pizza.ExportFriday{export.Fridays[0]}
+	full.Fridays[0].Guests = []string{"foo@bar.com", "bar@bar.com"}
+	unknownSponsor := export
+	unknownSponsor.Fridays = []pizza.ExportFriday{export.Fridays[0]}
+	unknownSponsor.Fridays[0].PlusOnes = []pizza.ExportPlusOne{{Name: "Sam", Sponsor: "qux@bar.com"}}
+	accessor := newExportTestAccessor(t, friday)
+
+	// WHEN
+	_, err = pizza.ImportData(accessor, full, pizza.ConflictOverwrite, mustLoadNY(t))
+	_, err2 := pizza.ImportData(accessor, unknownSponsor, pizza.ConflictOverwrite, mustLoadNY(t))
+	f, _ := accessor.GetFriday(friday)
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrFridayIsFull)
+	assert.ErrorContains(t, err2, "unknown sponsor")
+	assert.Equal(t, []string{"foo@bar.com"}, f.Guests)
+	assert.Equal(t, []string{"bar@bar.com"}, f.Waitlist)
+	assert.Len(t, f.PlusOnes, 1)
+}
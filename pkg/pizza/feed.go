@@ -0,0 +1,305 @@
+package pizza
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CalendarFeedPastDays is how many days after a friday ends that it stays in the calendar feeds, so that calendars
+	// do not drop the parties that just happened
+	CalendarFeedPastDays = 30
+	// CalendarFeedDaysAhead is how far ahead the calendar feeds list fridays
+	CalendarFeedDaysAhead = 365
+)
+
+// CalendarFeedData is the calendar feed section of the profile page
+type CalendarFeedData struct {
+	URL string
+	// WebcalURL is trusted, since html/template only lets through http and https links
+	WebcalURL   template.URL
+	OpenFridays bool
+}
+
+// newCalendarFeedToken makes a token that cannot be guessed, which is safe to put in a URL
+func newCalendarFeedToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// calendarFeed returns the friend's calendar feed, making one when they do not have one yet. The groups of the feed
+// are kept up to date with the friend's, since the feed does not know who reads it.
+func (s *Server) calendarFeed(claims *TokenClaims) (CalendarFeed, error) {
+	feed, err := s.store.GetCalendarFeed(claims.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		feed = CalendarFeed{Email: claims.Email}
+		if feed.Token, err = newCalendarFeedToken(); err != nil {
+			return feed, err
+		}
+	} else if err != nil {
+		return feed, err
+	} else if slices.Equal(feed.Groups, claims.Groups) {
+		return feed, nil
+	}
+	feed.Groups = claims.Groups
+	return feed, s.store.SetCalendarFeed(feed)
+}
+
+// calendarFeedData is how the profile page shows the feed, with URLs on the host that the request was made to
+func calendarFeedData(r *http.Request, feed CalendarFeed) CalendarFeedData {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	path := "/ical/" + feed.Token + ".ics"
+	return CalendarFeedData{
+		URL:         scheme + "://" + r.Host + path,
+		WebcalURL:   template.URL("webcal://" + r.Host + path),
+		OpenFridays: feed.OpenFridays,
+	}
+}
+
+// HandleCalendarFeed serves the iCalendar feed of the token in the URL, which needs no login so that calendar apps
+// can subscribe to it
+func (s *Server) HandleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok || len(token) == 0 {
+		s.Handle4xx(w, r)
+		return
+	}
+	feed, err := s.store.GetCalendarFeedByToken(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusNotFound)
+		s.Handle4xx(w, r)
+		return
+	} else if err != nil {
+		slog.Error("failed to get calendar feed", "error", err)
+		s.Handle500(w, r)
+		return
+	}
+	// look back a day further than the feed keeps fridays, since they start before they end
+	now := time.Now()
+	from := now.AddDate(0, 0, -CalendarFeedPastDays-1)
+	fridays, err := s.store.GetUpcomingFridaysAfter(from, CalendarFeedPastDays+1+CalendarFeedDaysAhead)
+	if err != nil {
+		slog.Error("failed to list fridays for calendar feed", "error", err, "email", feed.Email)
+		s.Handle500(w, r)
+		return
+	}
+
+	timezone := icalTimezone(s.loc, from, now.AddDate(0, 0, CalendarFeedDaysAhead+1))
+	components := append([]icalComponent{timezone}, s.calendarFeedEvents(feed, fridays, now)...)
+	var body bytes.Buffer
+	err = writeICalendar(&body, []icalProperty{
+		{Name: "CALSCALE", Value: "GREGORIAN"},
+		{Name: "X-WR-CALNAME", Value: escapeICalText("rsvp.pizza")},
+		{Name: "X-WR-TIMEZONE", Value: s.loc.String()},
+		{Name: "REFRESH-INTERVAL", Params: map[string]string{"VALUE": "DURATION"}, Value: "PT1H"},
+	}, components...)
+	if err != nil {
+		slog.Error("failed to write calendar feed", "error", err, "email", feed.Email)
+		s.Handle500(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(body.Bytes())
+}
+
+// calendarFeedEvents are the VEVENTs of the fridays that the friend is going to, might go to or is waiting for, and
+// of the open fridays of their groups when they asked for them. Fridays that were disabled or blacked out after the
+// friend answered are cancelled, so that calendars take them off.
+func (s *Server) calendarFeedEvents(feed CalendarFeed, fridays []Friday, now time.Time) []icalComponent {
+	blackouts := s.blackouts()
+	events := make([]icalComponent, 0)
+	for _, friday := range fridays {
+		if friday.EndsAt().Before(now.AddDate(0, 0, -CalendarFeedPastDays)) {
+			continue
+		}
+		_, blackedOut := blackouts[s.blackoutDay(friday)]
+		cancelled := !friday.Enabled || blackedOut
+		var note, status, transparency string
+		switch {
+		case slices.Contains(friday.Guests, feed.Email):
+			status, transparency = "CONFIRMED", "OPAQUE"
+		case slices.Contains(friday.Tentative, feed.Email):
+			note, status, transparency = "maybe", "TENTATIVE", "TRANSPARENT"
+		case slices.Contains(friday.Waitlist, feed.Email):
+			note, status, transparency = "waitlist", "TENTATIVE", "TRANSPARENT"
+		case feed.OpenFridays && !cancelled && friday.EndsAt().After(now) &&
+			(friday.Group == nil || slices.Contains(feed.Groups, *friday.Group)):
+			note, status, transparency = "open", "TENTATIVE", "TRANSPARENT"
+		default:
+			continue
+		}
+		if cancelled {
+			status = "CANCELLED"
+		}
+		events = append(events, s.calendarFeedEvent(friday, note, status, transparency, now))
+	}
+	return events
+}
+
+// calendarFeedEvent is the VEVENT of the friday, with the note about the friend's RSVP after its name
+func (s *Server) calendarFeedEvent(friday Friday, note, status, transparency string, now time.Time) icalComponent {
+	series := s.getSeries(friday.SeriesID)
+	summary := series.Name
+	if len(note) > 0 {
+		summary = fmt.Sprintf("%s (%s)", summary, note)
+	}
+	tzid := map[string]string{"TZID": s.loc.String()}
+	event := icalComponent{Name: "VEVENT", Properties: []icalProperty{
+		{Name: "UID", Value: strconv.FormatInt(friday.Date.Unix(), 10) + "@rsvp.pizza"},
+		{Name: "DTSTAMP", Value: icalUTCTime(now)},
+		{Name: "DTSTART", Params: tzid, Value: friday.StartsAt().In(s.loc).Format(icalDateTimeFormat)},
+		{Name: "DTEND", Params: tzid, Value: friday.EndsAt().In(s.loc).Format(icalDateTimeFormat)},
+		{Name: "SEQUENCE", Value: strconv.Itoa(friday.Sequence)},
+		{Name: "SUMMARY", Value: escapeICalText(summary)},
+		{Name: "STATUS", Value: status},
+		{Name: "TRANSP", Value: transparency},
+	}}
+	description := make([]string, 0, 2)
+	if len(series.Description) > 0 {
+		description = append(description, series.Description)
+	}
+	if friday.Details != nil && len(*friday.Details) > 0 {
+		description = append(description, *friday.Details)
+	}
+	if len(description) > 0 {
+		event.Set(icalProperty{Name: "DESCRIPTION", Value: escapeICalText(strings.Join(description, "\n\n"))})
+	}
+	if location := s.eventLocation(friday); len(location) > 0 {
+		event.Set(icalProperty{Name: "LOCATION", Value: escapeICalText(location)})
+	}
+	return event
+}
+
+// icalTimezone is the VTIMEZONE of the location with its changes of offset from the year before from until to, so
+// that it covers every event in between. A location without changes gets a single STANDARD observance.
+func icalTimezone(loc *time.Location, from, to time.Time) icalComponent {
+	timezone := icalComponent{Name: "VTIMEZONE", Properties: []icalProperty{{Name: "TZID", Value: loc.String()}}}
+	t := time.Date(from.In(loc).Year()-1, time.January, 1, 0, 0, 0, 0, loc)
+	for t.Before(to) {
+		next := t.AddDate(0, 0, 1)
+		_, before := t.Zone()
+		if _, after := next.Zone(); after != before {
+			timezone.Components = append(timezone.Components, icalObservance(loc, icalZoneChange(t, next)))
+		}
+		t = next
+	}
+	if len(timezone.Components) == 0 {
+		name, offset := t.Zone()
+		timezone.Components = append(timezone.Components, icalComponent{Name: "STANDARD", Properties: []icalProperty{
+			{Name: "DTSTART", Value: "19700101T000000"},
+			{Name: "TZOFFSETFROM", Value: icalUTCOffset(offset)},
+			{Name: "TZOFFSETTO", Value: icalUTCOffset(offset)},
+			{Name: "TZNAME", Value: name},
+		}})
+	}
+	return timezone
+}
+
+// icalZoneChange finds the first second of the new offset between two times with different offsets
+func icalZoneChange(before, after time.Time) time.Time {
+	_, offset := before.Zone()
+	for after.Sub(before) > time.Second {
+		middle := before.Add(after.Sub(before) / 2).Truncate(time.Second)
+		if _, middleOffset := middle.Zone(); middleOffset == offset {
+			before = middle
+		} else {
+			after = middle
+		}
+	}
+	return after
+}
+
+// icalObservance is the STANDARD or DAYLIGHT block of the change of offset at the time, which starts at the local
+// time of the old offset
+func icalObservance(loc *time.Location, change time.Time) icalComponent {
+	_, from := change.Add(-time.Second).Zone()
+	name, to := change.In(loc).Zone()
+	kind := "STANDARD"
+	if change.In(loc).IsDST() {
+		kind = "DAYLIGHT"
+	}
+	return icalComponent{Name: kind, Properties: []icalProperty{
+		{Name: "DTSTART", Value: change.UTC().Add(time.Duration(from) * time.Second).Format(icalDateTimeFormat)},
+		{Name: "TZOFFSETFROM", Value: icalUTCOffset(from)},
+		{Name: "TZOFFSETTO", Value: icalUTCOffset(to)},
+		{Name: "TZNAME", Value: name},
+	}}
+}
+
+// icalUTCOffset writes the offset in seconds east of UTC like -0500, with seconds only when there are any
+func icalUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	result := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		result += fmt.Sprintf("%02d", offset%60)
+	}
+	return result
+}
+
+// HandleUpdateCalendarFeed saves whether the friend's feed lists the open fridays of their groups
+func (s *Server) HandleUpdateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	s.saveCalendarFeed(w, r, false)
+}
+
+// HandleRotateCalendarFeed gives the friend's feed a new token, so that the old URL stops working
+func (s *Server) HandleRotateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	s.saveCalendarFeed(w, r, true)
+}
+
+func (s *Server) saveCalendarFeed(w http.ResponseWriter, r *http.Request, rotate bool) {
+	claims, ok := s.authenticateRequest(r)
+	if !ok {
+		w.Write(getToast("not logged in"))
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		slog.Error("form parse failure on calendar feed", "error", err)
+		w.Write(getToast("bad request"))
+		return
+	}
+
+	feed, err := s.calendarFeed(claims)
+	if err != nil {
+		slog.Error("failed to get calendar feed", "error", err, "email", claims.Email)
+		w.Write(getToast("failed to update calendar feed"))
+		return
+	}
+	if rotate {
+		if feed.Token, err = newCalendarFeedToken(); err != nil {
+			slog.Error("failed to make calendar feed token", "error", err)
+			w.Write(getToast("failed to update calendar feed"))
+			return
+		}
+		slog.Info("rotated calendar feed", "email", claims.Email)
+	} else {
+		feed.OpenFridays = r.Form.Get("open_fridays") == "on"
+	}
+	if err = s.store.SetCalendarFeed(feed); err != nil {
+		slog.Error("failed to set calendar feed", "error", err, "email", claims.Email)
+		w.Write(getToast("failed to update calendar feed"))
+		return
+	}
+
+	s.executeTemplate(w, "CalendarFeed", calendarFeedData(r, feed))
+}
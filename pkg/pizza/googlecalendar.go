@@ -16,7 +16,7 @@ import (
 
 const (
 	DefaultGoogleCalendarTimeout  = 5 * time.Second
-	DefaultGoogleCalendarTimezone = "America/New_York"
+	DefaultGoogleCalendarTimezone = DefaultTimezone
 )
 
 var (
@@ -69,6 +69,7 @@ func (c *GoogleCalendar) CreateEvent(newEvent CalendarEvent) error {
 		GuestsCanInviteOthers: &newEvent.GuestsCanInviteOthers,
 		GuestsCanModify:       newEvent.GuestsCanModify,
 		Id:                    newEvent.Id,
+		Location:              newEvent.Location,
 		Locked:                newEvent.Locked,
 		Reminders:             nil,
 		Start: &calendar.EventDateTime{
@@ -125,6 +126,7 @@ func (c *GoogleCalendar) googleEventToEvent(event *calendar.Event) CalendarEvent
 		EndTime:          endTime,
 		GuestsCanModify:  event.GuestsCanModify,
 		Id:               event.Id,
+		Location:         event.Location,
 		Locked:           event.Locked,
 		StartTime:        startTime,
 		Status:           event.Status,
@@ -146,25 +148,64 @@ func (c *GoogleCalendar) GetEvent(eventID string) (CalendarEvent, error) {
 	}
 }
 
-func (c *GoogleCalendar) InviteToEvent(eventID, email, name string) error {
+func (c *GoogleCalendar) UpdateEvent(updated CalendarEvent) error {
+	event, err := c.getCalendarEvent(updated.Id)
+	if err != nil {
+		return err
+	}
+	event.Start = &calendar.EventDateTime{
+		DateTime: updated.StartTime.Format(time.RFC3339),
+		TimeZone: c.Timezone,
+	}
+	event.End = &calendar.EventDateTime{
+		DateTime: updated.EndTime.Format(time.RFC3339),
+		TimeZone: c.Timezone,
+	}
+	event.Location = updated.Location
+	if len(updated.Description) > 0 {
+		event.Description = updated.Description
+	}
+	// TODO add timeout
+	_, err = c.srv.Events.Update(c.id, updated.Id, event).Do()
+	return err
+}
+
+func (c *GoogleCalendar) InviteToEvent(eventID, email, name, status string) error {
 	// TODO add locks
 	event, err := c.getCalendarEvent(eventID)
 	if err != nil {
 		return err
 	}
 
+	// new guests still have to answer the invite, unless they only said maybe
+	responseStatus := "needsAction"
+	if status == RSVPTentative {
+		responseStatus = "tentative"
+	}
+
+	found := false
 	for _, attendee := range event.Attendees {
-		if attendee.Email == email && attendee.ResponseStatus != "declined" {
+		if attendee.Email != email || attendee.ResponseStatus == "declined" {
+			continue
+		}
+		// guests who were a maybe and are now coming have accepted, which needs no answer from them
+		if attendee.ResponseStatus == "tentative" && status == RSVPAccepted {
+			responseStatus = "accepted"
+		} else if (attendee.ResponseStatus == "tentative") == (status == RSVPTentative) {
 			slog.Info("already invited", "email", email, "eventID", eventID)
 			return nil
 		}
+		attendee.ResponseStatus = responseStatus
+		found = true
 	}
 
-	event.Attendees = append(event.Attendees, &calendar.EventAttendee{
-		Email:          email,
-		DisplayName:    name,
-		ResponseStatus: "needsAction",
-	})
+	if !found {
+		event.Attendees = append(event.Attendees, &calendar.EventAttendee{
+			Email:          email,
+			DisplayName:    name,
+			ResponseStatus: responseStatus,
+		})
+	}
 
 	// TODO add timeout
 	_, err = c.srv.Events.Update(c.id, eventID, event).Do()
@@ -248,3 +289,10 @@ func (c *GoogleCalendar) ActivateEvent(eventID string) error {
 	_, err = c.srv.Events.Update(c.id, eventID, event).Do()
 	return err
 }
+
+// WithCalendarID uses the same credentials on another calendar
+func (c *GoogleCalendar) WithCalendarID(ID string) Calendar {
+	other := *c
+	other.id = ID
+	return &other
+}
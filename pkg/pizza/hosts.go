@@ -0,0 +1,118 @@
+package pizza
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	api "github.com/mpoegel/rsvp.pizza/pkg/api"
+)
+
+// AdminRole is the role of the friends who run the deployment, who can change every friday, the schedule and the
+// series, and see the audit log
+const AdminRole = "pizza_host"
+
+// isAdmin reports whether the user has the AdminRole
+func isAdmin(claims *TokenClaims) bool {
+	return claims.HasRole(AdminRole)
+}
+
+// canHostFriday reports whether the user can edit, enable and disable the friday. Admins can for every friday, and its
+// owner and co-hosts for their own.
+func canHostFriday(claims *TokenClaims, friday Friday) bool {
+	return isAdmin(claims) || friday.IsHost(claims.Email)
+}
+
+// canChangeHost reports whether the user can hand the friday to another owner, which only its owner and admins can
+func canChangeHost(claims *TokenClaims, friday Friday) bool {
+	return isAdmin(claims) || (len(friday.Host) > 0 && friday.Host == claims.Email)
+}
+
+// apiFridayHosts is the hosts relation of the friday with its owner first, which is nil when nobody hosts it
+func (s *Server) apiFridayHosts(friday Friday) []*api.Guest {
+	if len(friday.Hosts()) == 0 {
+		return nil
+	}
+	return s.apiGuests(friday.Hosts(), "")
+}
+
+// parseHostEmails reads a list of emails separated by commas or spaces, without duplicates
+func parseHostEmails(value string) []string {
+	emails := make([]string, 0)
+	for _, email := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		email = normalizeEmail(email)
+		if !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// hostNames writes who hosts the friday for the friday card, like "Foo with Bar and Baz", and is empty when nobody
+// does
+func (s *Server) hostNames(friday Friday) string {
+	names := make([]string, 0, len(friday.CoHosts)+1)
+	for _, friend := range s.loadFriends(friday.Hosts()) {
+		if len(friend.Name) > 0 {
+			names = append(names, friend.Name)
+		} else {
+			names = append(names, friend.Email)
+		}
+	}
+	switch {
+	case len(names) == 0:
+		return ""
+	case len(friday.Host) == 0:
+		// co-hosts without an owner are listed as equals
+		return joinNames(names)
+	case len(names) == 1:
+		return names[0]
+	default:
+		return fmt.Sprintf("%s with %s", names[0], joinNames(names[1:]))
+	}
+}
+
+// joinNames joins the names like "Foo, Bar and Baz"
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// parseHostsForm applies the host and coHosts fields of the edit form to the friday and reports whether they changed
+// who hosts it. Forms without these fields leave the hosts as they are.
+func (s *Server) parseHostsForm(r *http.Request, claims *TokenClaims, friday *Friday) (bool, error) {
+	_, hasHost := r.Form["host"]
+	_, hasCoHosts := r.Form["coHosts"]
+	if !hasHost && !hasCoHosts {
+		return false, nil
+	}
+	host, coHosts := friday.Host, friday.CoHosts
+	if hasHost {
+		host = normalizeEmail(r.Form.Get("host"))
+		if host != friday.Host && !canChangeHost(claims, *friday) {
+			return false, errors.New("only the host can hand over the friday")
+		}
+	}
+	if hasCoHosts {
+		coHosts = parseHostEmails(r.Form.Get("coHosts"))
+	}
+	coHosts = slices.DeleteFunc(slices.Clone(coHosts), func(email string) bool { return email == host })
+	slices.Sort(coHosts)
+	if host == friday.Host && slices.Equal(coHosts, friday.CoHosts) {
+		return false, nil
+	}
+	for _, email := range append([]string{host}, coHosts...) {
+		if len(email) == 0 {
+			continue
+		}
+		if _, err := s.store.GetFriendByEmail(email); err != nil {
+			return false, fmt.Errorf("friend not found: %s", email)
+		}
+	}
+	friday.Host, friday.CoHosts = host, coHosts
+	return true, nil
+}
@@ -0,0 +1,245 @@
+package pizza
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// icalProductID names rsvp.pizza as the author of the iCalendar files that it writes
+const icalProductID = "-//rsvp.pizza//rsvp.pizza//EN"
+
+// icalProperty is a single content line of an iCalendar file, like DTSTART;VALUE=DATE:20251225
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icalComponent is a BEGIN and END block of an iCalendar file with its own properties. Components are the components
+// nested within it, like the STANDARD and DAYLIGHT blocks of a VTIMEZONE, which are written but never read.
+type icalComponent struct {
+	Name       string
+	Properties []icalProperty
+	Components []icalComponent
+}
+
+// Get returns the first property of the name
+func (c icalComponent) Get(name string) (icalProperty, bool) {
+	for _, property := range c.Properties {
+		if property.Name == name {
+			return property, true
+		}
+	}
+	return icalProperty{}, false
+}
+
+// GetAll returns every property of the name, like the ATTENDEEs of an event
+func (c icalComponent) GetAll(name string) []icalProperty {
+	result := make([]icalProperty, 0)
+	for _, property := range c.Properties {
+		if property.Name == name {
+			result = append(result, property)
+		}
+	}
+	return result
+}
+
+// Set replaces every property of the name with the property, or adds it when the component does not have it yet
+func (c *icalComponent) Set(property icalProperty) {
+	c.SetAll(property.Name, []icalProperty{property})
+}
+
+// SetAll replaces every property of the name, keeping them where the first one was
+func (c *icalComponent) SetAll(name string, properties []icalProperty) {
+	i := slices.IndexFunc(c.Properties, func(p icalProperty) bool { return p.Name == name })
+	c.Properties = slices.DeleteFunc(c.Properties, func(p icalProperty) bool { return p.Name == name })
+	if i < 0 || i > len(c.Properties) {
+		i = len(c.Properties)
+	}
+	c.Properties = slices.Insert(c.Properties, i, properties...)
+}
+
+// readICalComponents returns every component of the name, e.g. VEVENT, with its properties. The properties of
+// components nested within it, like the VALARM of an event, are left out.
+func readICalComponents(r io.Reader, name string) ([]icalComponent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]icalComponent, 0)
+	var current *icalComponent
+	depth := 0
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		property, err := parseICalLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case property.Name == "BEGIN" && current == nil && strings.EqualFold(property.Value, name):
+			current = &icalComponent{Name: name}
+		case property.Name == "BEGIN" && current != nil:
+			depth++
+		case property.Name == "END" && current != nil && depth > 0:
+			depth--
+		case property.Name == "END" && current != nil:
+			result = append(result, *current)
+			current = nil
+		case current != nil && depth == 0:
+			current.Properties = append(current.Properties, property)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("%s is missing its END", name)
+	}
+	return result, nil
+}
+
+// unfoldICalLines joins the lines that were folded by starting their continuation with a space or tab
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalLine splits a content line into its name, parameters and value. Parameter values may be quoted to
+// contain colons and semicolons.
+func parseICalLine(line string) (icalProperty, error) {
+	inQuotes := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ':' && !inQuotes:
+			name, rawParams, _ := strings.Cut(line[:i], ";")
+			params := make(map[string]string)
+			for _, param := range splitICalParams(rawParams) {
+				if key, value, ok := strings.Cut(param, "="); ok {
+					params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+				}
+			}
+			return icalProperty{Name: strings.ToUpper(name), Params: params, Value: line[i+1:]}, nil
+		}
+	}
+	return icalProperty{}, fmt.Errorf("property %q has no value", line)
+}
+
+func splitICalParams(raw string) []string {
+	params := make([]string, 0)
+	inQuotes := false
+	start := 0
+	for i, c := range raw {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			params = append(params, raw[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(raw) {
+		params = append(params, raw[start:])
+	}
+	return params
+}
+
+// unescapeICalText reads a TEXT value, in which commas, semicolons, backslashes and newlines are escaped
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// escapeICalText writes a TEXT value, escaping what unescapeICalText reads
+func escapeICalText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// icalUTCTime writes the time as a DATE-TIME in UTC
+func icalUTCTime(t time.Time) string {
+	return t.UTC().Format(icalDateTimeFormat + "Z")
+}
+
+// writeICalendar writes a VCALENDAR with the components, e.g. VEVENTs, which can carry extra properties of the
+// calendar like METHOD first
+func writeICalendar(w io.Writer, properties []icalProperty, components ...icalComponent) error {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:" + icalProductID}
+	for _, property := range properties {
+		lines = append(lines, formatICalLine(property))
+	}
+	for _, component := range components {
+		lines = appendICalComponent(lines, component)
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldICalLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendICalComponent appends the content lines of the component and the components nested within it
+func appendICalComponent(lines []string, component icalComponent) []string {
+	lines = append(lines, "BEGIN:"+component.Name)
+	for _, property := range component.Properties {
+		lines = append(lines, formatICalLine(property))
+	}
+	for _, nested := range component.Components {
+		lines = appendICalComponent(lines, nested)
+	}
+	return append(lines, "END:"+component.Name)
+}
+
+// formatICalLine writes the property as a content line, with its parameters in order of name. Parameter values are
+// quoted when they contain a colon, semicolon or comma.
+func formatICalLine(property icalProperty) string {
+	var b strings.Builder
+	b.WriteString(property.Name)
+	names := make([]string, 0, len(property.Params))
+	for name := range property.Params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := property.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + strings.ReplaceAll(value, `"`, "") + `"`
+		}
+		fmt.Fprintf(&b, ";%s=%s", name, value)
+	}
+	b.WriteString(":")
+	b.WriteString(property.Value)
+	return b.String()
+}
+
+// foldICalLine breaks lines longer than 75 octets, continuing them with a space on the next line. Lines are not
+// broken within a UTF-8 character.
+func foldICalLine(line string) string {
+	const maxOctets = 75
+	var b strings.Builder
+	width := 0
+	for _, c := range line {
+		size := len(string(c))
+		if width+size > maxOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(c)
+		width += size
+	}
+	return b.String()
+}
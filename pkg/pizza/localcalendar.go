@@ -0,0 +1,354 @@
+package pizza
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// localCalendarSchema is the name of the version of the local calendar in the app_versions table, which keeps it apart
+// from the schema of rsvp.pizza when both are in the same database
+const localCalendarSchema = "local_calendar"
+
+// LocalCalendarMigrations are the schema changes of the database of the local calendar, oldest first
+var LocalCalendarMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "events",
+		Up: `CREATE TABLE IF NOT EXISTS calendar_events
+				(calendar_id              text NOT NULL,
+				 id                       text NOT NULL,
+				 summary                  text NOT NULL default '',
+				 description              text NOT NULL default '',
+				 location                 text NOT NULL default '',
+				 start_time               datetime NOT NULL,
+				 end_time                 datetime NOT NULL,
+				 status                   text NOT NULL default 'confirmed',
+				 visibility               text NOT NULL default '',
+				 anyone_can_add_self      bool NOT NULL default false,
+				 guests_can_invite_others bool NOT NULL default false,
+				 guests_can_modify        bool NOT NULL default false,
+				 locked                   bool NOT NULL default false,
+				 PRIMARY KEY (calendar_id, id));
+			CREATE TABLE IF NOT EXISTS calendar_attendees
+				(calendar_id     text NOT NULL,
+				 event_id        text NOT NULL,
+				 email           text NOT NULL,
+				 name            text NOT NULL default '',
+				 response_status text NOT NULL default 'needsAction',
+				 PRIMARY KEY (calendar_id, event_id, email));`,
+		Down: `DROP TABLE calendar_attendees;
+			DROP TABLE calendar_events;`,
+	},
+	{
+		Version: 2,
+		Name:    "event sequence",
+		Up:      `ALTER TABLE calendar_events ADD COLUMN sequence int NOT NULL default 0;`,
+		Down:    `ALTER TABLE calendar_events DROP COLUMN sequence;`,
+	},
+}
+
+// localEventColumns are the columns of the calendar_events table read by scanLocalEvent
+const localEventColumns = `id, summary, description, location, start_time, end_time, status, visibility,
+	anyone_can_add_self, guests_can_invite_others, guests_can_modify, locked`
+
+// LocalCalendar keeps the events and the responses of their attendees in a SQLite database, so that invites, declines,
+// the calendar sync and wrapped work without an online calendar. Nobody is sent their invites, which makes it meant
+// for development and for deployments without a calendar. The events of each calendar ID are kept apart.
+type LocalCalendar struct {
+	db         *sql.DB
+	calendarID string
+	loc        *time.Location
+}
+
+// NewLocalCalendar opens the calendar in the SQLite database file, creating its tables when they do not exist yet.
+// Times of the events are returned in loc.
+func NewLocalCalendar(dbfile, calendarID string, loc *time.Location) (*LocalCalendar, error) {
+	db, err := sql.Open("sqlite3", sqliteDSN(dbfile))
+	if err != nil {
+		return nil, err
+	}
+	migrator := NewNamedMigrator(db, localCalendarSchema, LocalCalendarMigrations)
+	if err = adoptLocalCalendarSchema(db, migrator); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err = migrator.Up(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &LocalCalendar{db: db, calendarID: calendarID, loc: loc}, nil
+}
+
+// adoptLocalCalendarSchema records the version of a calendar made before the local calendar had a version of its own,
+// whose tables are there without it
+func adoptLocalCalendarSchema(db *sql.DB, migrator *Migrator) error {
+	var ignored any
+	if err := db.QueryRow("SELECT 1 FROM calendar_events LIMIT 1").Scan(&ignored); err != nil && err != sql.ErrNoRows {
+		// a new calendar
+		return nil
+	}
+	version, err := migrator.Version()
+	if err != nil || version > 0 {
+		return err
+	}
+	version = 1
+	if err = db.QueryRow("SELECT sequence FROM calendar_events LIMIT 1").Scan(&ignored); err == nil || err == sql.ErrNoRows {
+		version = 2
+	}
+	slog.Info("adopting the schema of the local calendar", "version", version)
+	return migrator.StampTo(version)
+}
+
+func (c *LocalCalendar) Close() {
+	c.db.Close()
+}
+
+// WithCalendarID is the calendar of the ID in the same database
+func (c *LocalCalendar) WithCalendarID(ID string) Calendar {
+	return &LocalCalendar{db: c.db, calendarID: ID, loc: c.loc}
+}
+
+func (c *LocalCalendar) CreateEvent(newEvent CalendarEvent) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	status := newEvent.Status
+	if len(status) == 0 {
+		status = "confirmed"
+	}
+	_, err = tx.Exec(`INSERT INTO calendar_events (calendar_id, `+localEventColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.calendarID, newEvent.Id, newEvent.Summary, newEvent.Description, newEvent.Location,
+		newEvent.StartTime.UTC(), newEvent.EndTime.UTC(), status, newEvent.Visibility, newEvent.AnyoneCanAddSelf,
+		newEvent.GuestsCanInviteOthers, newEvent.GuestsCanModify, newEvent.Locked)
+	if err != nil {
+		return err
+	}
+	for _, attendee := range newEvent.Attendees {
+		_, err = tx.Exec(`INSERT INTO calendar_attendees (calendar_id, event_id, email, response_status)
+			VALUES (?, ?, ?, ?)`, c.calendarID, newEvent.Id, attendee.Email, attendee.ResponseStatus)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (c *LocalCalendar) GetEvent(eventID string) (CalendarEvent, error) {
+	event, err := c.scanLocalEvent(c.db.QueryRow(`SELECT `+localEventColumns+` FROM calendar_events
+		WHERE calendar_id = ? AND id = ?`, c.calendarID, eventID))
+	if err == sql.ErrNoRows {
+		return event, ErrEventNotFound
+	} else if err != nil {
+		return event, err
+	}
+	event.Attendees, err = c.attendees(eventID)
+	return event, err
+}
+
+func (c *LocalCalendar) scanLocalEvent(row rowScanner) (CalendarEvent, error) {
+	var event CalendarEvent
+	err := row.Scan(&event.Id, &event.Summary, &event.Description, &event.Location, &event.StartTime, &event.EndTime,
+		&event.Status, &event.Visibility, &event.AnyoneCanAddSelf, &event.GuestsCanInviteOthers,
+		&event.GuestsCanModify, &event.Locked)
+	event.StartTime = event.StartTime.In(c.loc)
+	event.EndTime = event.EndTime.In(c.loc)
+	return event, err
+}
+
+// localAttendee is an attendee of an event along with the name they were invited with, which may be empty
+type localAttendee struct {
+	CalendarAttendee
+	Name string
+}
+
+// attendees lists the attendees of the event in the order they were invited
+func (c *LocalCalendar) attendees(eventID string) ([]CalendarAttendee, error) {
+	named, err := c.namedAttendees(eventID)
+	if err != nil {
+		return nil, err
+	}
+	attendees := make([]CalendarAttendee, len(named))
+	for i, attendee := range named {
+		attendees[i] = attendee.CalendarAttendee
+	}
+	return attendees, nil
+}
+
+func (c *LocalCalendar) namedAttendees(eventID string) ([]localAttendee, error) {
+	rows, err := c.db.Query(`SELECT email, name, response_status FROM calendar_attendees
+		WHERE calendar_id = ? AND event_id = ? ORDER BY rowid`, c.calendarID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attendees := make([]localAttendee, 0)
+	for rows.Next() {
+		var attendee localAttendee
+		if err = rows.Scan(&attendee.Email, &attendee.Name, &attendee.ResponseStatus); err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, attendee)
+	}
+	return attendees, rows.Err()
+}
+
+// sequence counts the changes of the time, place and status of the event, so that the invites sent after a change
+// replace the ones before it
+func (c *LocalCalendar) sequence(eventID string) (int, error) {
+	var sequence int
+	err := c.db.QueryRow("SELECT sequence FROM calendar_events WHERE calendar_id = ? AND id = ?",
+		c.calendarID, eventID).Scan(&sequence)
+	if err == sql.ErrNoRows {
+		return 0, ErrEventNotFound
+	}
+	return sequence, err
+}
+
+func (c *LocalCalendar) UpdateEvent(updated CalendarEvent) error {
+	return c.updateLocalEvent(`UPDATE calendar_events SET start_time = ?, end_time = ?, location = ?,
+		description = COALESCE(NULLIF(?, ''), description), sequence = sequence + 1 WHERE calendar_id = ? AND id = ?`,
+		updated.StartTime.UTC(), updated.EndTime.UTC(), updated.Location, updated.Description, c.calendarID, updated.Id)
+}
+
+// updateLocalEvent runs an update of a single row, and returns ErrEventNotFound when it did not change any
+func (c *LocalCalendar) updateLocalEvent(stmt string, args ...any) error {
+	res, err := c.db.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrEventNotFound
+	}
+	return nil
+}
+
+func (c *LocalCalendar) InviteToEvent(eventID, email, name, status string) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM calendar_events WHERE calendar_id = ? AND id = ?)",
+		c.calendarID, eventID).Scan(&exists)
+	if err != nil {
+		return err
+	} else if !exists {
+		return ErrEventNotFound
+	}
+
+	// new guests still have to answer the invite, unless they only said maybe
+	responseStatus := "needsAction"
+	if status == RSVPTentative {
+		responseStatus = "tentative"
+	}
+	var current string
+	err = tx.QueryRow(`SELECT response_status FROM calendar_attendees
+		WHERE calendar_id = ? AND event_id = ? AND email = ?`, c.calendarID, eventID, email).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && current != "declined" {
+		// guests who were a maybe and are now coming have accepted, which needs no answer from them
+		if current == "tentative" && status == RSVPAccepted {
+			responseStatus = "accepted"
+		} else if (current == "tentative") == (status == RSVPTentative) {
+			slog.Info("already invited", "email", email, "eventID", eventID)
+			return nil
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO calendar_attendees (calendar_id, event_id, email, name, response_status)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (calendar_id, event_id, email) DO UPDATE SET response_status = excluded.response_status,
+			name = CASE WHEN name = '' THEN excluded.name ELSE name END`,
+		c.calendarID, eventID, email, name, responseStatus)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *LocalCalendar) DeclineEvent(eventID, email string) error {
+	return c.RespondToEvent(eventID, email, "declined")
+}
+
+// RespondToEvent answers the invite of the guest with the response status, like accepted or declined, which stands in
+// for the guest answering in their own calendar. It returns ErrNotInvited when the guest is not invited.
+func (c *LocalCalendar) RespondToEvent(eventID, email, responseStatus string) error {
+	err := c.updateLocalEvent(`UPDATE calendar_attendees SET response_status = ?
+		WHERE calendar_id = ? AND event_id = ? AND email = ?`, responseStatus, c.calendarID, eventID, email)
+	if !errors.Is(err, ErrEventNotFound) {
+		return err
+	}
+	if _, err = c.GetEvent(eventID); err != nil {
+		return err
+	}
+	return ErrNotInvited
+}
+
+func (c *LocalCalendar) ListEvents(numEvents int) ([]CalendarEvent, error) {
+	return c.listLocalEvents(time.Now(), time.Time{}, numEvents)
+}
+
+func (c *LocalCalendar) ListEventsBetween(start, end time.Time, numEvents int) ([]CalendarEvent, error) {
+	return c.listLocalEvents(start, end, numEvents)
+}
+
+// listLocalEvents lists up to numEvents events that are not cancelled and overlap start and end in order of when they
+// start, where a zero end has no limit
+func (c *LocalCalendar) listLocalEvents(start, end time.Time, numEvents int) ([]CalendarEvent, error) {
+	stmt := `SELECT ` + localEventColumns + ` FROM calendar_events
+		WHERE calendar_id = ? AND status != 'cancelled' AND end_time > ?`
+	args := []any{c.calendarID, start.UTC()}
+	if !end.IsZero() {
+		stmt += " AND start_time < ?"
+		args = append(args, end.UTC())
+	}
+	stmt += " ORDER BY start_time, id"
+	if numEvents > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, numEvents)
+	}
+	rows, err := c.db.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]CalendarEvent, 0)
+	for rows.Next() {
+		event, err := c.scanLocalEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i].Attendees, err = c.attendees(events[i].Id); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// CancelEvent keeps the event as cancelled, so that it can be activated again
+func (c *LocalCalendar) CancelEvent(eventID string) error {
+	return c.updateLocalEvent(`UPDATE calendar_events SET status = 'cancelled', sequence = sequence + 1
+		WHERE calendar_id = ? AND id = ?`, c.calendarID, eventID)
+}
+
+func (c *LocalCalendar) ActivateEvent(eventID string) error {
+	return c.updateLocalEvent(`UPDATE calendar_events SET status = 'confirmed', sequence = sequence + 1
+		WHERE calendar_id = ? AND id = ?`, c.calendarID, eventID)
+}
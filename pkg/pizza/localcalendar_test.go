@@ -0,0 +1,290 @@
+package pizza_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalCalendar(t *testing.T, dbfile string) *pizza.LocalCalendar {
+	calendar, err := pizza.NewLocalCalendar(dbfile, "primary", mustLoadNY(t))
+	require.Nil(t, err)
+	t.Cleanup(calendar.Close)
+	return calendar
+}
+
+func TestLocalCalendar(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	calendar := newTestLocalCalendar(t, filepath.Join(t.TempDir(), "calendar.db"))
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	event := pizza.CalendarEvent{
+		Id:          "1741386600",
+		Summary:     "Pizza Friday",
+		Description: "Welcome to Pizza Friday",
+		Location:    "Matt's, 1 Main St",
+		StartTime:   start,
+		EndTime:     start.Add(4 * time.Hour),
+		Locked:      true,
+		Status:      "confirmed",
+		Visibility:  "private",
+	}
+
+	// WHEN
+	_, missingErr := calendar.GetEvent(event.Id)
+	err := calendar.CreateEvent(event)
+	createdAgainErr := calendar.CreateEvent(event)
+	err1 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	err2 := calendar.InviteToEvent(event.Id, "bar@bar.com", "Bar", pizza.RSVPTentative)
+	err3 := calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	missingInviteErr := calendar.InviteToEvent("missing", "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	saved, err4 := calendar.GetEvent(event.Id)
+
+	// THEN
+	assert.ErrorIs(t, missingErr, pizza.ErrEventNotFound)
+	assert.Nil(t, err)
+	assert.NotNil(t, createdAgainErr)
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.ErrorIs(t, missingInviteErr, pizza.ErrEventNotFound)
+	assert.Nil(t, err4)
+	event.Attendees = []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "needsAction"},
+		{Email: "bar@bar.com", ResponseStatus: "tentative"},
+	}
+	assert.Equal(t, event, saved)
+	assert.Equal(t, loc, saved.StartTime.Location())
+
+	// WHEN
+	err1 = calendar.InviteToEvent(event.Id, "bar@bar.com", "Bar", pizza.RSVPAccepted)
+	err2 = calendar.DeclineEvent(event.Id, "foo@bar.com")
+	notInvitedErr := calendar.DeclineEvent(event.Id, "baz@bar.com")
+	missingDeclineErr := calendar.DeclineEvent("missing", "foo@bar.com")
+	moved := event
+	moved.StartTime = start.Add(time.Hour)
+	moved.EndTime = start.Add(3 * time.Hour)
+	moved.Location = "Baz's"
+	err3 = calendar.UpdateEvent(moved)
+	saved, err4 = calendar.GetEvent(event.Id)
+
+	// THEN
+	// maybes who are coming now have accepted
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.ErrorIs(t, notInvitedErr, pizza.ErrNotInvited)
+	assert.ErrorIs(t, missingDeclineErr, pizza.ErrEventNotFound)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "declined"},
+		{Email: "bar@bar.com", ResponseStatus: "accepted"},
+	}, saved.Attendees)
+	assert.True(t, moved.StartTime.Equal(saved.StartTime))
+	assert.True(t, moved.EndTime.Equal(saved.EndTime))
+	assert.Equal(t, "Baz's", saved.Location)
+	assert.Equal(t, event.Description, saved.Description)
+
+	// WHEN
+	err1 = calendar.InviteToEvent(event.Id, "foo@bar.com", "Foo", pizza.RSVPAccepted)
+	err2 = calendar.RespondToEvent(event.Id, "bar@bar.com", "tentative")
+	err3 = calendar.CancelEvent(event.Id)
+	cancelled, err4 := calendar.GetEvent(event.Id)
+	err5 := calendar.ActivateEvent(event.Id)
+	activated, err6 := calendar.GetEvent(event.Id)
+
+	// THEN
+	// guests who declined and RSVP again have to answer the new invite
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, "cancelled", cancelled.Status)
+	assert.Equal(t, []pizza.CalendarAttendee{
+		{Email: "foo@bar.com", ResponseStatus: "needsAction"},
+		{Email: "bar@bar.com", ResponseStatus: "tentative"},
+	}, cancelled.Attendees)
+	assert.Nil(t, err5)
+	assert.Nil(t, err6)
+	assert.Equal(t, "confirmed", activated.Status)
+	assert.ErrorIs(t, calendar.CancelEvent("missing"), pizza.ErrEventNotFound)
+}
+
+func TestLocalCalendar_ListEvents(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	dbfile := filepath.Join(t.TempDir(), "calendar.db")
+	calendar := newTestLocalCalendar(t, dbfile)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, loc)
+	for i := range 4 {
+		eventStart := start.AddDate(0, 0, 7*(3-i))
+		require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{
+			Id:        fmt.Sprint(eventStart.Unix()),
+			Summary:   "Pizza Friday",
+			StartTime: eventStart,
+			EndTime:   eventStart.Add(4 * time.Hour),
+		}))
+	}
+	require.Nil(t, calendar.CancelEvent(fmt.Sprint(start.AddDate(0, 0, 7).Unix())))
+	upcoming := time.Now().Add(time.Hour)
+	require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{Id: "upcoming", StartTime: upcoming,
+		EndTime: upcoming.Add(time.Hour)}))
+	other := calendar.WithCalendarID("late-pizza")
+	require.Nil(t, other.CreateEvent(pizza.CalendarEvent{Id: "late", StartTime: start, EndTime: start.Add(time.Hour)}))
+
+	// WHEN
+	between, err1 := calendar.ListEventsBetween(start, start.AddDate(0, 0, 21), 10)
+	limited, err2 := calendar.ListEventsBetween(start, start.AddDate(0, 0, 21), 1)
+	events, err3 := calendar.ListEvents(10)
+	otherEvents, err4 := other.ListEventsBetween(start, start.AddDate(0, 0, 21), 10)
+	reopened := newTestLocalCalendar(t, dbfile)
+	reopenedEvents, err5 := reopened.ListEventsBetween(start, start.AddDate(0, 0, 21), 10)
+
+	// THEN
+	// cancelled events are left out and the rest are in order of when they start
+	assert.Nil(t, err1)
+	require.Len(t, between, 2)
+	assert.True(t, start.Equal(between[0].StartTime))
+	assert.True(t, start.AddDate(0, 0, 14).Equal(between[1].StartTime))
+	assert.Nil(t, err2)
+	require.Len(t, limited, 1)
+	assert.Equal(t, between[0].Id, limited[0].Id)
+	assert.Nil(t, err3)
+	require.Len(t, events, 1)
+	assert.Equal(t, "upcoming", events[0].Id)
+	// each calendar keeps its own events
+	assert.Nil(t, err4)
+	require.Len(t, otherEvents, 1)
+	assert.Equal(t, "late", otherEvents[0].Id)
+	// the events are still there after a restart
+	assert.Nil(t, err5)
+	assert.Equal(t, between, reopenedEvents)
+}
+
+func TestLocalCalendar_Server(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	accessor := pizza.NewMemoryAccessor()
+	calendar := newTestLocalCalendar(t, filepath.Join(t.TempDir(), "calendar.db"))
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone := mustLoadNY(t)
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	eventID := strconv.FormatInt(fridayTime.Unix(), 10)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 10, Enabled: true}))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+	wrappedStart := time.Date(2023, time.March, 3, 17, 30, 0, 0, estZone)
+	require.Nil(t, calendar.CreateEvent(pizza.CalendarEvent{
+		Id:        strconv.FormatInt(wrappedStart.Unix(), 10),
+		StartTime: wrappedStart,
+		EndTime:   wrappedStart.Add(4 * time.Hour),
+		Attendees: []pizza.CalendarAttendee{{Email: claims.Email, ResponseStatus: "accepted"}},
+	}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	// WHEN
+	do(http.MethodPost, fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix()))
+	invited, err1 := calendar.GetEvent(eventID)
+	do(http.MethodDelete, fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix()))
+	declined, err2 := calendar.GetEvent(eventID)
+	wrapped, err3 := server.GetWrapped(2023)
+
+	// THEN
+	// the event is made on the first RSVP like it is in an online calendar
+	assert.Nil(t, err1)
+	assert.Equal(t, "Pizza Friday", invited.Summary)
+	assert.True(t, fridayTime.Equal(invited.StartTime))
+	assert.Equal(t, []pizza.CalendarAttendee{{Email: claims.Email, ResponseStatus: "needsAction"}}, invited.Attendees)
+	assert.Nil(t, err2)
+	assert.Equal(t, []pizza.CalendarAttendee{{Email: claims.Email, ResponseStatus: "declined"}}, declined.Attendees)
+	assert.Nil(t, err3)
+	assert.Equal(t, 1, wrapped.TotalFridays)
+	assert.Len(t, wrapped.Friends[claims.Email], 1)
+}
+
+func TestLocalCalendar_SharedDatabase(t *testing.T) {
+	// GIVEN
+	file := filepath.Join(t.TempDir(), "pizza.db")
+	accessor := newTestSQLAccessor(t, file)
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, mustLoadNY(t))
+
+	// WHEN
+	calendar := newTestLocalCalendar(t, file)
+	err := calendar.CreateEvent(pizza.CalendarEvent{Id: "1741386600", StartTime: start, EndTime: start.Add(time.Hour)})
+	reopened := newTestLocalCalendar(t, file)
+	_, err1 := reopened.GetEvent("1741386600")
+	version, err2 := accessor.Migrator().Version()
+	status, err3 := accessor.Migrator().Status()
+
+	// THEN
+	// the calendar keeps its own version next to the schema of rsvp.pizza
+	assert.Nil(t, err)
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, len(pizza.SQLiteMigrations), version)
+	assert.Nil(t, err3)
+	for _, s := range status {
+		assert.True(t, s.Applied)
+		assert.False(t, s.Modified())
+	}
+}
+
+func TestLocalCalendar_AdoptsSchema(t *testing.T) {
+	for version := range len(pizza.LocalCalendarMigrations) {
+		// GIVEN
+		// calendars used to keep their version as the schema of the database
+		file := filepath.Join(t.TempDir(), "calendar.db")
+		require.Nil(t, pizza.NewMigrator(openTestDBFile(t, file), pizza.LocalCalendarMigrations).To(version+1))
+		start := time.Date(2025, time.March, 7, 17, 30, 0, 0, mustLoadNY(t))
+
+		// WHEN
+		calendar, err := pizza.NewLocalCalendar(file, "primary", mustLoadNY(t))
+		require.Nil(t, err, version+1)
+		err1 := calendar.CreateEvent(pizza.CalendarEvent{Id: "1741386600", StartTime: start, EndTime: start.Add(time.Hour)})
+		calendar.Close()
+
+		// THEN
+		assert.Nil(t, err1, version+1)
+	}
+}
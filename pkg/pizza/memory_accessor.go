@@ -0,0 +1,850 @@
+package pizza
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryFriend struct {
+	id          int64
+	email       string
+	name        string
+	preferences string
+}
+
+type memoryFriday struct {
+	date      time.Time
+	seriesID  int64
+	start     time.Time
+	duration  time.Duration
+	group     *string
+	details   *string
+	maxGuests int
+	enabled   bool
+	policy    *RSVPPolicy
+	venueID   int64
+	host      string
+	coHosts   []string
+	sequence  int
+}
+
+type memoryRSVP struct {
+	friday    time.Time
+	friendID  int64
+	status    string
+	createdAt time.Time
+	createdBy int64
+	// seq breaks ties between RSVPs made at the same time, like the rowid does in SQLite
+	seq int64
+}
+
+type memoryCalendarFeed struct {
+	friendID    int64
+	token       string
+	groups      []string
+	openFridays bool
+}
+
+type memoryPlusOne struct {
+	id        int64
+	friday    time.Time
+	name      string
+	email     string
+	sponsorID int64
+}
+
+// MemoryAccessor keeps everything in memory with the same semantics as the SQLAccessor. It is meant for local
+// development and tests, so nothing survives a restart. Lookups of missing rows return sql.ErrNoRows.
+type MemoryAccessor struct {
+	mu sync.RWMutex
+
+	friends   []*memoryFriend
+	fridays   map[int64]*memoryFriday
+	rsvps     []*memoryRSVP
+	plusOnes  []*memoryPlusOne
+	feeds     []*memoryCalendarFeed
+	audit     []AuditEntry
+	series    []Series
+	venues    []Venue
+	blackouts map[string]string
+	settings  map[string]string
+	nextSeq   int64
+	// nextPlusOneID is the ID of the newest plus-one, which is never reused like an autoincrement column
+	nextPlusOneID int64
+
+	// Location is the timezone that ListFridays returns dates in
+	Location *time.Location
+}
+
+func NewMemoryAccessor() *MemoryAccessor {
+	return &MemoryAccessor{
+		friends:   make([]*memoryFriend, 0),
+		fridays:   make(map[int64]*memoryFriday),
+		rsvps:     make([]*memoryRSVP, 0),
+		plusOnes:  make([]*memoryPlusOne, 0),
+		feeds:     make([]*memoryCalendarFeed, 0),
+		audit:     make([]AuditEntry, 0),
+		series:    make([]Series, 0),
+		venues:    make([]Venue, 0),
+		blackouts: make(map[string]string),
+		settings:  make(map[string]string),
+		Location:  defaultLocation(),
+	}
+}
+
+// fridayKey identifies a friday by its instant, regardless of the location of the time
+func fridayKey(date time.Time) int64 {
+	return date.UnixNano()
+}
+
+// CreateTables has nothing to create, the accessor is ready to use as soon as it is made
+func (a *MemoryAccessor) CreateTables() error {
+	return nil
+}
+
+func (a *MemoryAccessor) friendByEmail(email string) *memoryFriend {
+	for _, friend := range a.friends {
+		if friend.email == email {
+			return friend
+		}
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) friendByID(id int64) *memoryFriend {
+	if id < 1 || id > int64(len(a.friends)) {
+		return nil
+	}
+	return a.friends[id-1]
+}
+
+// addFriend inserts the friend if they do not exist yet and returns them either way
+func (a *MemoryAccessor) addFriend(email string) *memoryFriend {
+	if friend := a.friendByEmail(email); friend != nil {
+		return friend
+	}
+	friend := &memoryFriend{
+		id:          int64(len(a.friends) + 1),
+		email:       email,
+		preferences: "{}",
+	}
+	a.friends = append(a.friends, friend)
+	return friend
+}
+
+func (a *MemoryAccessor) GetFriendByID(ID string) (Friend, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	id, err := strconv.ParseInt(ID, 10, 64)
+	if err != nil {
+		return Friend{ID: ID}, sql.ErrNoRows
+	}
+	friend := a.friendByID(id)
+	if friend == nil {
+		return Friend{ID: ID}, sql.ErrNoRows
+	}
+	return Friend{ID: ID, Email: friend.email, Name: friend.name}, nil
+}
+
+func (a *MemoryAccessor) GetFriendByEmail(email string) (Friend, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	friend := a.friendByEmail(email)
+	if friend == nil {
+		return Friend{ID: "0", Email: email}, sql.ErrNoRows
+	}
+	return Friend{ID: strconv.FormatInt(friend.id, 10), Email: email, Name: friend.name}, nil
+}
+
+func (a *MemoryAccessor) AddFriend(email, name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.addFriend(email).name = name
+	return nil
+}
+
+func (a *MemoryAccessor) ListFriends() ([]Friend, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make([]Friend, 0, len(a.friends))
+	for _, friend := range a.friends {
+		res = append(res, Friend{ID: strconv.FormatInt(friend.id, 10), Email: friend.email, Name: friend.name})
+	}
+	return res, nil
+}
+
+func (a *MemoryAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
+	return a.GetUpcomingFridaysAfter(time.Now(), daysAhead)
+}
+
+func (a *MemoryAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	before := after.AddDate(0, 0, daysAhead)
+	result := make([]Friday, 0)
+	for _, f := range a.sortedFridays() {
+		if !f.date.After(before) && !f.date.Before(after) {
+			result = append(result, a.toFriday(f))
+		}
+	}
+	return result, nil
+}
+
+// sortedFridays lists the fridays in chronological order
+func (a *MemoryAccessor) sortedFridays() []*memoryFriday {
+	fridays := make([]*memoryFriday, 0, len(a.fridays))
+	for _, f := range a.fridays {
+		fridays = append(fridays, f)
+	}
+	slices.SortFunc(fridays, func(x, y *memoryFriday) int {
+		return x.date.Compare(y.date)
+	})
+	return fridays
+}
+
+// fridayRSVPs lists the RSVPs to the friday in the order they were made
+func (a *MemoryAccessor) fridayRSVPs(date time.Time) []*memoryRSVP {
+	rsvps := make([]*memoryRSVP, 0)
+	for _, rsvp := range a.rsvps {
+		if fridayKey(rsvp.friday) == fridayKey(date) {
+			rsvps = append(rsvps, rsvp)
+		}
+	}
+	slices.SortFunc(rsvps, func(x, y *memoryRSVP) int {
+		if c := x.createdAt.Compare(y.createdAt); c != 0 {
+			return c
+		}
+		return int(x.seq - y.seq)
+	})
+	return rsvps
+}
+
+func (a *MemoryAccessor) fridayEmails(date time.Time, status string) []string {
+	emails := make([]string, 0)
+	for _, rsvp := range a.fridayRSVPs(date) {
+		if rsvp.status == status {
+			emails = append(emails, a.friendByID(rsvp.friendID).email)
+		}
+	}
+	return emails
+}
+
+func (a *MemoryAccessor) fridayPlusOnes(date time.Time) []PlusOne {
+	plusOnes := make([]PlusOne, 0)
+	for _, plusOne := range a.plusOnes {
+		if fridayKey(plusOne.friday) == fridayKey(date) {
+			plusOnes = append(plusOnes, PlusOne{
+				ID:      plusOne.id,
+				Name:    plusOne.name,
+				Email:   plusOne.email,
+				Sponsor: a.friendByID(plusOne.sponsorID).email,
+			})
+		}
+	}
+	return plusOnes
+}
+
+// countGuests is how many guests are coming to the friday, plus-ones included
+func (a *MemoryAccessor) countGuests(date time.Time) int {
+	return a.countRSVPs(date, RSVPAccepted) + len(a.fridayPlusOnes(date))
+}
+
+func (a *MemoryAccessor) toFriday(f *memoryFriday) Friday {
+	friday := Friday{
+		Date:      f.date,
+		SeriesID:  f.seriesID,
+		Start:     f.start,
+		Duration:  f.duration,
+		Guests:    a.fridayEmails(f.date, RSVPAccepted),
+		Waitlist:  a.fridayEmails(f.date, RSVPWaitlisted),
+		Tentative: a.fridayEmails(f.date, RSVPTentative),
+		MaxGuests: f.maxGuests,
+		Enabled:   f.enabled,
+		VenueID:   f.venueID,
+		Host:      f.host,
+		CoHosts:   append(make([]string, 0, len(f.coHosts)), f.coHosts...),
+		PlusOnes:  a.fridayPlusOnes(f.date),
+		Sequence:  f.sequence,
+	}
+	// copy so that callers cannot change the stored friday through the pointers
+	if f.policy != nil {
+		policy := *f.policy
+		friday.Policy = &policy
+	}
+	if f.group != nil {
+		group := *f.group
+		friday.Group = &group
+	}
+	if f.details != nil {
+		details := *f.details
+		friday.Details = &details
+	}
+	return friday
+}
+
+func (a *MemoryAccessor) DoesFridayExist(date time.Time) (bool, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.fridays[fridayKey(date)]
+	return ok, nil
+}
+
+func (a *MemoryAccessor) ListFridays() ([]Friday, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make([]Friday, 0)
+	for _, f := range a.sortedFridays() {
+		res = append(res, Friday{Date: f.date.In(a.Location)})
+	}
+	return res, nil
+}
+
+func (a *MemoryAccessor) AddFriday(date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.fridays[fridayKey(date)]; ok {
+		return nil
+	}
+	a.fridays[fridayKey(date)] = &memoryFriday{
+		date:      date,
+		maxGuests: 10,
+		enabled:   true,
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) GetFriday(date time.Time) (Friday, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	f, ok := a.fridays[fridayKey(date)]
+	if !ok {
+		return Friday{}, sql.ErrNoRows
+	}
+	return a.toFriday(f), nil
+}
+
+func (a *MemoryAccessor) RemoveFriday(date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rsvps = slices.DeleteFunc(a.rsvps, func(rsvp *memoryRSVP) bool {
+		return fridayKey(rsvp.friday) == fridayKey(date)
+	})
+	a.plusOnes = slices.DeleteFunc(a.plusOnes, func(plusOne *memoryPlusOne) bool {
+		return fridayKey(plusOne.friday) == fridayKey(date)
+	})
+	delete(a.fridays, fridayKey(date))
+	return nil
+}
+
+func (a *MemoryAccessor) UpdateFriday(friday Friday) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fridays[fridayKey(friday.Date)]
+	if !ok {
+		return nil
+	}
+	f.group = nil
+	if friday.Group != nil {
+		group := *friday.Group
+		f.group = &group
+	}
+	f.details = nil
+	if friday.Details != nil {
+		details := *friday.Details
+		f.details = &details
+	}
+	f.sequence++
+	f.seriesID = friday.SeriesID
+	f.venueID = friday.VenueID
+	f.maxGuests = friday.MaxGuests
+	f.enabled = friday.Enabled
+	f.start = friday.Start
+	// the databases keep whole minutes
+	f.duration = max(friday.Duration.Truncate(time.Minute), 0)
+	f.policy = nil
+	if friday.Policy != nil {
+		policy := RSVPPolicy{
+			OpensBefore:   friday.Policy.OpensBefore.Truncate(time.Minute),
+			ClosesBefore:  friday.Policy.ClosesBefore.Truncate(time.Minute),
+			DeclineCutoff: friday.Policy.DeclineCutoff.Truncate(time.Minute),
+		}
+		f.policy = &policy
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) findRSVP(date time.Time, friendID int64) *memoryRSVP {
+	for _, rsvp := range a.rsvps {
+		if fridayKey(rsvp.friday) == fridayKey(date) && rsvp.friendID == friendID {
+			return rsvp
+		}
+	}
+	return nil
+}
+
+// setRSVP creates or replaces the friend's RSVP to the friday, which counts as a new RSVP
+func (a *MemoryAccessor) setRSVP(date time.Time, friendID int64, status string, createdBy int64) {
+	rsvp := a.findRSVP(date, friendID)
+	if rsvp == nil {
+		rsvp = &memoryRSVP{friday: date, friendID: friendID}
+		a.rsvps = append(a.rsvps, rsvp)
+	}
+	a.nextSeq++
+	rsvp.status = status
+	rsvp.createdAt = time.Now()
+	rsvp.createdBy = createdBy
+	rsvp.seq = a.nextSeq
+}
+
+func (a *MemoryAccessor) countRSVPs(date time.Time, status string) int {
+	count := 0
+	for _, rsvp := range a.rsvps {
+		if fridayKey(rsvp.friday) == fridayKey(date) && rsvp.status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *MemoryAccessor) AddFriendToFriday(email string, friday Friday, createdBy string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fridays[fridayKey(friday.Date)]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if friend := a.friendByEmail(email); friend != nil {
+		if rsvp := a.findRSVP(friday.Date, friend.id); rsvp != nil && rsvp.status == RSVPAccepted {
+			// already invited
+			return nil
+		}
+	}
+	if a.countGuests(friday.Date) >= f.maxGuests {
+		return ErrFridayIsFull
+	}
+
+	// guests do not need to have logged in before to be invited
+	friend := a.addFriend(email)
+	var creatorID int64
+	if len(createdBy) > 0 && createdBy != email {
+		if creator := a.friendByEmail(createdBy); creator != nil {
+			creatorID = creator.id
+		}
+	}
+	a.setRSVP(friday.Date, friend.id, RSVPAccepted, creatorID)
+	return nil
+}
+
+func (a *MemoryAccessor) RemoveFriendFromFriday(email string, date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	friend := a.friendByEmail(email)
+	if friend == nil {
+		return nil
+	}
+	if rsvp := a.findRSVP(date, friend.id); rsvp != nil {
+		rsvp.status = RSVPDeclined
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) SetFridayHosts(date time.Time, host string, coHosts []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fridays[fridayKey(date)]
+	if !ok {
+		return nil
+	}
+	f.host = ""
+	if len(host) > 0 && a.friendByEmail(host) != nil {
+		f.host = host
+	}
+	f.coHosts = make([]string, 0, len(coHosts))
+	for _, email := range coHosts {
+		if email != f.host && a.friendByEmail(email) != nil && !slices.Contains(f.coHosts, email) {
+			f.coHosts = append(f.coHosts, email)
+		}
+	}
+	slices.Sort(f.coHosts)
+	return nil
+}
+
+func (a *MemoryAccessor) AddFriendAsTentative(email string, date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.fridays[fridayKey(date)]; !ok {
+		return sql.ErrNoRows
+	}
+	friend := a.addFriend(email)
+	// maybes keep their place among the maybes
+	if rsvp := a.findRSVP(date, friend.id); rsvp == nil || rsvp.status != RSVPTentative {
+		a.setRSVP(date, friend.id, RSVPTentative, 0)
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) AddPlusOne(date time.Time, plusOne PlusOne) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fridays[fridayKey(date)]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	if a.countGuests(date) >= f.maxGuests {
+		return 0, ErrFridayIsFull
+	}
+	sponsor := a.friendByEmail(plusOne.Sponsor)
+	if sponsor == nil {
+		return 0, sql.ErrNoRows
+	}
+	a.nextPlusOneID++
+	a.plusOnes = append(a.plusOnes, &memoryPlusOne{
+		id:        a.nextPlusOneID,
+		friday:    date,
+		name:      plusOne.Name,
+		email:     plusOne.Email,
+		sponsorID: sponsor.id,
+	})
+	return a.nextPlusOneID, nil
+}
+
+func (a *MemoryAccessor) RemovePlusOne(date time.Time, ID int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.plusOnes = slices.DeleteFunc(a.plusOnes, func(plusOne *memoryPlusOne) bool {
+		return fridayKey(plusOne.friday) == fridayKey(date) && plusOne.id == ID
+	})
+	return nil
+}
+
+func (a *MemoryAccessor) AddFriendToWaitlist(email string, date time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.fridays[fridayKey(date)]; !ok {
+		return sql.ErrNoRows
+	}
+	friend := a.addFriend(email)
+	// accepted guests stay accepted and waitlisted guests keep their place in line
+	if rsvp := a.findRSVP(date, friend.id); rsvp == nil || rsvp.status == RSVPDeclined {
+		a.setRSVP(date, friend.id, RSVPWaitlisted, 0)
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) PromoteFromWaitlist(date time.Time) ([]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fridays[fridayKey(date)]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	promoted := make([]string, 0)
+	openSpots := f.maxGuests - a.countGuests(date)
+	for _, rsvp := range a.fridayRSVPs(date) {
+		if len(promoted) >= openSpots {
+			break
+		}
+		if rsvp.status == RSVPWaitlisted {
+			rsvp.status = RSVPAccepted
+			promoted = append(promoted, a.friendByID(rsvp.friendID).email)
+		}
+	}
+	return promoted, nil
+}
+
+func (a *MemoryAccessor) GetRSVPs(date time.Time) ([]RSVP, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	result := make([]RSVP, 0)
+	for _, rsvp := range a.fridayRSVPs(date) {
+		friend := a.friendByID(rsvp.friendID)
+		r := RSVP{
+			Friday:    rsvp.friday,
+			Email:     friend.email,
+			Name:      friend.name,
+			Status:    rsvp.status,
+			CreatedAt: rsvp.createdAt,
+		}
+		if creator := a.friendByID(rsvp.createdBy); creator != nil {
+			r.CreatedBy = creator.email
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (a *MemoryAccessor) GetPreferences(email string) (Preferences, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var prefs Preferences
+	friend := a.friendByEmail(email)
+	if friend == nil {
+		return prefs, sql.ErrNoRows
+	}
+	// preferences are kept as JSON so that they round trip exactly like they do through the database
+	err := json.Unmarshal([]byte(friend.preferences), &prefs)
+	return prefs, err
+}
+
+func (a *MemoryAccessor) SetPreferences(email string, prefs Preferences) error {
+	rawPrefs, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if friend := a.friendByEmail(email); friend != nil {
+		friend.preferences = string(rawPrefs)
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) toCalendarFeed(feed *memoryCalendarFeed) CalendarFeed {
+	return CalendarFeed{
+		Email:       a.friendByID(feed.friendID).email,
+		Token:       feed.token,
+		Groups:      append(make([]string, 0, len(feed.groups)), feed.groups...),
+		OpenFridays: feed.openFridays,
+	}
+}
+
+func (a *MemoryAccessor) GetCalendarFeed(email string) (CalendarFeed, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if friend := a.friendByEmail(email); friend != nil {
+		for _, feed := range a.feeds {
+			if feed.friendID == friend.id {
+				return a.toCalendarFeed(feed), nil
+			}
+		}
+	}
+	return CalendarFeed{}, sql.ErrNoRows
+}
+
+func (a *MemoryAccessor) GetCalendarFeedByToken(token string) (CalendarFeed, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, feed := range a.feeds {
+		if feed.token == token {
+			return a.toCalendarFeed(feed), nil
+		}
+	}
+	return CalendarFeed{}, sql.ErrNoRows
+}
+
+func (a *MemoryAccessor) SetCalendarFeed(feed CalendarFeed) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	friend := a.addFriend(feed.Email)
+	for _, other := range a.feeds {
+		if other.token == feed.Token && other.friendID != friend.id {
+			return errors.New("calendar feed token already exists")
+		}
+	}
+	stored := &memoryCalendarFeed{
+		friendID:    friend.id,
+		token:       feed.Token,
+		groups:      append(make([]string, 0, len(feed.Groups)), feed.Groups...),
+		openFridays: feed.OpenFridays,
+	}
+	for i, other := range a.feeds {
+		if other.friendID == friend.id {
+			a.feeds[i] = stored
+			return nil
+		}
+	}
+	a.feeds = append(a.feeds, stored)
+	return nil
+}
+
+func (a *MemoryAccessor) AddAuditEntry(entry AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry.ID = int64(len(a.audit) + 1)
+	a.audit = append(a.audit, entry)
+	return nil
+}
+
+func (a *MemoryAccessor) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	result := make([]AuditEntry, 0)
+	skipped := 0
+	for i := len(a.audit) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+		entry := a.audit[i]
+		if !filter.Friday.IsZero() && !filter.Friday.Equal(entry.Friday) {
+			continue
+		}
+		if len(filter.Target) > 0 && filter.Target != entry.Target {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (a *MemoryAccessor) GetSetting(name string) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	value, ok := a.settings[name]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return value, nil
+}
+
+func (a *MemoryAccessor) SetSetting(name, value string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.settings[name] = value
+	return nil
+}
+
+// copySeries copies the series so that callers cannot change the stored series through the group pointer
+func copySeries(series Series) Series {
+	if series.Group != nil {
+		group := *series.Group
+		series.Group = &group
+	}
+	return series
+}
+
+func (a *MemoryAccessor) ListSeries() ([]Series, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make([]Series, 0, len(a.series))
+	for _, series := range a.series {
+		res = append(res, copySeries(series))
+	}
+	return res, nil
+}
+
+func (a *MemoryAccessor) GetSeries(ID int64) (Series, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, series := range a.series {
+		if series.ID == ID {
+			return copySeries(series), nil
+		}
+	}
+	return Series{}, sql.ErrNoRows
+}
+
+func (a *MemoryAccessor) AddSeries(series Series) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.series {
+		if existing.Name == series.Name {
+			return 0, errors.New("series name already exists")
+		}
+	}
+	series.ID = int64(len(a.series) + 1)
+	a.series = append(a.series, copySeries(series))
+	return series.ID, nil
+}
+
+func (a *MemoryAccessor) UpdateSeries(series Series) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.series {
+		if existing.ID != series.ID && existing.Name == series.Name {
+			return errors.New("series name already exists")
+		}
+	}
+	for i, existing := range a.series {
+		if existing.ID == series.ID {
+			a.series[i] = copySeries(series)
+		}
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) ListVenues() ([]Venue, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := slices.Clone(a.venues)
+	slices.SortFunc(res, func(x, y Venue) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+	return res, nil
+}
+
+func (a *MemoryAccessor) GetVenue(ID int64) (Venue, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, venue := range a.venues {
+		if venue.ID == ID {
+			return venue, nil
+		}
+	}
+	return Venue{}, sql.ErrNoRows
+}
+
+func (a *MemoryAccessor) AddVenue(venue Venue) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.venues {
+		if existing.Name == venue.Name {
+			return 0, errors.New("venue name already exists")
+		}
+	}
+	venue.ID = int64(len(a.venues) + 1)
+	a.venues = append(a.venues, venue)
+	return venue.ID, nil
+}
+
+func (a *MemoryAccessor) UpdateVenue(venue Venue) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, existing := range a.venues {
+		if existing.ID != venue.ID && existing.Name == venue.Name {
+			return errors.New("venue name already exists")
+		}
+	}
+	for i, existing := range a.venues {
+		if existing.ID == venue.ID {
+			a.venues[i] = venue
+		}
+	}
+	return nil
+}
+
+func (a *MemoryAccessor) ListBlackouts() ([]Blackout, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	res := make([]Blackout, 0, len(a.blackouts))
+	for day, reason := range a.blackouts {
+		res = append(res, Blackout{Day: day, Reason: reason})
+	}
+	slices.SortFunc(res, func(x, y Blackout) int {
+		return strings.Compare(x.Day, y.Day)
+	})
+	return res, nil
+}
+
+func (a *MemoryAccessor) AddBlackout(blackout Blackout) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.blackouts[blackout.Day] = blackout.Reason
+	return nil
+}
+
+func (a *MemoryAccessor) RemoveBlackout(day string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.blackouts, day)
+	return nil
+}
@@ -0,0 +1,308 @@
+package pizza
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrMigrationModified     = errors.New("applied migration has been modified")
+	ErrMigrationIrreversible = errors.New("migration cannot be reverted")
+	ErrMigrationUnknown      = errors.New("no such migration")
+)
+
+// Migration is a single numbered schema change. Up and Down are SQL scripts that each run in one transaction. A
+// migration without a Down script cannot be reverted.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the Up script, so that a migration changed after it was applied can be detected
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationStatus is a migration along with whether it has been applied to the database. Checksum is what was
+// recorded when the migration was applied, which is empty for migrations applied before checksums were kept.
+type MigrationStatus struct {
+	Migration
+	Applied  bool
+	Checksum string
+}
+
+// Modified is true when the migration was applied with a different Up script than it has now
+func (s MigrationStatus) Modified() bool {
+	return s.Applied && len(s.Checksum) > 0 && s.Checksum != s.Migration.Checksum()
+}
+
+// Migrator applies and reverts migrations. The current version is the row of its name in the app_versions table, which
+// is 'schema' for the database of rsvp.pizza, and each applied migration has its own row holding its checksum.
+type Migrator struct {
+	db         *sql.DB
+	name       string
+	migrations []Migration
+	dryRun     io.Writer
+}
+
+// NewMigrator manages the migrations, which must be numbered from 1 without gaps
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return NewNamedMigrator(db, "schema", migrations)
+}
+
+// NewNamedMigrator manages migrations that keep their own version in the row of the name, and their checksums in
+// rows prefixed with it, so that another schema can share the database and its app_versions table
+func NewNamedMigrator(db *sql.DB, name string, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		name:       name,
+		migrations: migrations,
+	}
+}
+
+// DryRun makes the migrator print the SQL it would run to w instead of running it
+func (m *Migrator) DryRun(w io.Writer) {
+	m.dryRun = w
+}
+
+// Latest is the version of the newest migration
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// migrationName is the row of the checksum of the migration
+func (m *Migrator) migrationName(version int) string {
+	if m.name == "schema" {
+		return fmt.Sprintf("migration_%03d", version)
+	}
+	return fmt.Sprintf("%s_migration_%03d", m.name, version)
+}
+
+// Version is the version of the database schema, which is 0 when nothing has been applied
+func (m *Migrator) Version() (int, error) {
+	version, _, err := m.state()
+	return version, err
+}
+
+// state reads the schema version and the recorded checksums, which are missing for databases patched before
+// checksums were kept
+func (m *Migrator) state() (int, map[string]string, error) {
+	checksums := make(map[string]string)
+	var version int
+	err := m.db.QueryRow("SELECT version FROM app_versions WHERE name = $1", m.name).Scan(&version)
+	if err == sql.ErrNoRows || (err != nil && m.dryRun != nil) {
+		// a dry run does not create the app_versions table, so it may not exist yet
+		return 0, checksums, nil
+	} else if err != nil {
+		return 0, checksums, err
+	}
+	rows, err := m.db.Query("SELECT name, checksum FROM app_versions WHERE checksum IS NOT NULL")
+	if err != nil {
+		// the checksum column is added the first time migrations are applied
+		return version, checksums, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, checksum string
+		if err = rows.Scan(&name, &checksum); err != nil {
+			return 0, nil, err
+		}
+		checksums[name] = checksum
+	}
+	return version, checksums, rows.Err()
+}
+
+// Status lists every migration and whether it has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	version, checksums, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		status[i] = MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= version,
+			Checksum:  checksums[m.migrationName(migration.Version)],
+		}
+	}
+	return status, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down reverts the newest applied migration
+func (m *Migrator) Down() error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	return m.To(version - 1)
+}
+
+// To applies or reverts migrations, one transaction each, until the database is at the target version
+func (m *Migrator) To(target int) error {
+	if target < 0 || target > m.Latest() {
+		return fmt.Errorf("%w: %d", ErrMigrationUnknown, target)
+	}
+	if err := m.prepare(); err != nil {
+		return err
+	}
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range status {
+		if s.Modified() {
+			return fmt.Errorf("%w: %03d %s", ErrMigrationModified, s.Version, s.Name)
+		}
+	}
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	// check before reverting anything, so that a downgrade is not left half done
+	for v := version; v > target; v-- {
+		if len(m.migrations[v-1].Down) == 0 {
+			return fmt.Errorf("%w: %03d %s", ErrMigrationIrreversible, v, m.migrations[v-1].Name)
+		}
+	}
+	for v := version + 1; v <= target; v++ {
+		migration := m.migrations[v-1]
+		if err = m.apply(migration.Version, migration.Up, "up", migration); err != nil {
+			return fmt.Errorf("migration %03d %s: %w", v, migration.Name, err)
+		}
+	}
+	for v := version; v > target; v-- {
+		migration := m.migrations[v-1]
+		if err = m.apply(v-1, migration.Down, "down", migration); err != nil {
+			return fmt.Errorf("revert migration %03d %s: %w", v, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// Stamp records every migration as applied without running any of them, for a database that was just created with
+// the newest schema
+func (m *Migrator) Stamp() error {
+	return m.StampTo(m.Latest())
+}
+
+// StampTo records the migrations up to the version as applied without running any of them, for a database whose
+// schema is already at the version
+func (m *Migrator) StampTo(version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("%w: %d", ErrMigrationUnknown, version)
+	}
+	if err := m.prepare(); err != nil {
+		return err
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, migration := range m.migrations[:version] {
+		if err = recordMigration(tx, m.migrationName(migration.Version), migration); err != nil {
+			return err
+		}
+	}
+	if err = setSchemaVersion(tx, m.name, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// prepare adds the checksum column to the app_versions table and records the checksums of migrations that were
+// applied before checksums were kept
+func (m *Migrator) prepare() error {
+	if m.dryRun != nil {
+		return nil
+	}
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS app_versions
+		(name text NOT NULL PRIMARY KEY, version int NOT NULL, checksum text)`)
+	if err != nil {
+		return err
+	}
+	if rows, err := m.db.Query("SELECT checksum FROM app_versions LIMIT 1"); err != nil {
+		if _, err = m.db.Exec("ALTER TABLE app_versions ADD COLUMN checksum text"); err != nil {
+			return err
+		}
+	} else {
+		rows.Close()
+	}
+	version, checksums, err := m.state()
+	if err != nil {
+		return err
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := checksums[m.migrationName(migration.Version)]; ok {
+			continue
+		}
+		if err = recordMigration(m.db, m.migrationName(migration.Version), migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func recordMigration(db sqlExecer, name string, migration Migration) error {
+	_, err := db.Exec(`INSERT INTO app_versions (name, version, checksum) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET version = excluded.version, checksum = excluded.checksum`,
+		name, migration.Version, migration.Checksum())
+	return err
+}
+
+func setSchemaVersion(db sqlExecer, name string, version int) error {
+	_, err := db.Exec(`INSERT INTO app_versions (name, version) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET version = excluded.version`, name, version)
+	return err
+}
+
+// apply runs the script of the migration and moves the schema to the version in a single transaction
+func (m *Migrator) apply(version int, script, direction string, migration Migration) error {
+	if m.dryRun != nil {
+		_, err := fmt.Fprintf(m.dryRun, "-- %s %03d %s\n%s\n\n", direction, migration.Version, migration.Name, script)
+		return err
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec(script); err != nil {
+		return err
+	}
+	if direction == "up" {
+		err = recordMigration(tx, m.migrationName(migration.Version), migration)
+	} else {
+		_, err = tx.Exec("DELETE FROM app_versions WHERE name = $1", m.migrationName(migration.Version))
+	}
+	if err != nil {
+		return err
+	}
+	if err = setSchemaVersion(tx, m.name, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
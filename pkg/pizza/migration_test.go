@@ -0,0 +1,245 @@
+package pizza_test
+
+import (
+	"bytes"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []pizza.Migration{
+	{
+		Version: 1,
+		Name:    "pizzas",
+		Up:      `CREATE TABLE pizzas (name text NOT NULL PRIMARY KEY);`,
+		Down:    `DROP TABLE pizzas;`,
+	},
+	{
+		Version: 2,
+		Name:    "toppings",
+		Up: `ALTER TABLE pizzas ADD COLUMN toppings text;
+			INSERT INTO pizzas (name, toppings) VALUES ('margherita', 'basil');`,
+		Down: `ALTER TABLE pizzas DROP COLUMN toppings;
+			DELETE FROM pizzas;`,
+	},
+	{
+		Version: 3,
+		Name:    "cheese",
+		Up:      `ALTER TABLE pizzas ADD COLUMN cheese text;`,
+	},
+}
+
+func openTestDB(t *testing.T, name string) *sql.DB {
+	return openTestDBFile(t, filepath.Join(t.TempDir(), name))
+}
+
+func openTestDBFile(t *testing.T, file string) *sql.DB {
+	db, err := sql.Open("sqlite3", file)
+	require.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// tableColumns lists the column names of every table in the database, except the bookkeeping tables
+func tableColumns(t *testing.T, db *sql.DB) map[string][]string {
+	rows, err := db.Query(`SELECT m.name, p.name FROM sqlite_master AS m, pragma_table_info(m.name) AS p
+		WHERE m.type = 'table' AND m.name NOT IN ('sqlite_sequence', 'versions', 'app_versions')
+		ORDER BY m.name, p.name`)
+	require.Nil(t, err)
+	defer rows.Close()
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		require.Nil(t, rows.Scan(&table, &column))
+		columns[table] = append(columns[table], column)
+	}
+	return columns
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	// GIVEN
+	db := openTestDB(t, "test.db")
+	migrator := pizza.NewMigrator(db, testMigrations)
+
+	// WHEN
+	err := migrator.To(2)
+	version, err2 := migrator.Version()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, 2, version)
+	var toppings string
+	assert.Nil(t, db.QueryRow("SELECT toppings FROM pizzas WHERE name = 'margherita'").Scan(&toppings))
+	assert.Equal(t, "basil", toppings)
+
+	// WHEN
+	err = migrator.Up()
+	status, err2 := migrator.Status()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	require.Equal(t, 3, len(status))
+	for _, s := range status {
+		assert.True(t, s.Applied)
+		assert.False(t, s.Modified())
+		assert.Equal(t, s.Migration.Checksum(), s.Checksum)
+	}
+
+	// WHEN
+	err = migrator.Down()
+	version, _ = migrator.Version()
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrMigrationIrreversible)
+	assert.Equal(t, 3, version)
+
+	// WHEN
+	err = migrator.To(4)
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrMigrationUnknown)
+}
+
+func TestMigrator_Revert(t *testing.T) {
+	// GIVEN
+	db := openTestDB(t, "test.db")
+	migrator := pizza.NewMigrator(db, testMigrations)
+	require.Nil(t, migrator.To(2))
+
+	// WHEN
+	err := migrator.Down()
+	version, _ := migrator.Version()
+	status, _ := migrator.Status()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, map[string][]string{"pizzas": {"name"}}, tableColumns(t, db))
+	assert.True(t, status[0].Applied)
+	assert.False(t, status[1].Applied)
+	assert.Empty(t, status[1].Checksum)
+
+	// WHEN
+	err = migrator.To(0)
+	version, _ = migrator.Version()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, 0, version)
+	assert.Empty(t, tableColumns(t, db))
+}
+
+func TestMigrator_RollsBackFailedMigration(t *testing.T) {
+	// GIVEN
+	db := openTestDB(t, "test.db")
+	migrations := []pizza.Migration{
+		testMigrations[0],
+		{
+			Version: 2,
+			Name:    "broken",
+			Up: `ALTER TABLE pizzas ADD COLUMN toppings text;
+				INSERT INTO nowhere VALUES (1);`,
+		},
+	}
+	migrator := pizza.NewMigrator(db, migrations)
+
+	// WHEN
+	err := migrator.Up()
+	version, _ := migrator.Version()
+
+	// THEN
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, map[string][]string{"pizzas": {"name"}}, tableColumns(t, db))
+}
+
+func TestMigrator_Modified(t *testing.T) {
+	// GIVEN
+	db := openTestDB(t, "test.db")
+	require.Nil(t, pizza.NewMigrator(db, testMigrations[:1]).Up())
+	modified := []pizza.Migration{
+		{
+			Version: 1,
+			Name:    "pizzas",
+			Up:      `CREATE TABLE pizzas (name text NOT NULL PRIMARY KEY, size int);`,
+		},
+		testMigrations[1],
+	}
+	migrator := pizza.NewMigrator(db, modified)
+
+	// WHEN
+	err := migrator.Up()
+	status, err2 := migrator.Status()
+
+	// THEN
+	assert.ErrorIs(t, err, pizza.ErrMigrationModified)
+	assert.Nil(t, err2)
+	assert.True(t, status[0].Modified())
+	assert.False(t, status[1].Applied)
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	// GIVEN
+	db := openTestDB(t, "test.db")
+	migrator := pizza.NewMigrator(db, testMigrations)
+	out := &bytes.Buffer{}
+	migrator.DryRun(out)
+
+	// WHEN
+	err := migrator.To(2)
+	version, _ := migrator.Version()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, 0, version)
+	assert.Empty(t, tableColumns(t, db))
+	assert.Contains(t, out.String(), "-- up 001 pizzas\n"+testMigrations[0].Up)
+	assert.Contains(t, out.String(), "-- up 002 toppings\n"+testMigrations[1].Up)
+	assert.NotContains(t, out.String(), "003")
+}
+
+func TestSQLiteMigrations_MatchCreateTables(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	created, err := pizza.NewSQLAccessor(filepath.Join(dir, "created.db"), true)
+	require.Nil(t, err)
+	defer created.Close()
+	require.Nil(t, created.CreateTables())
+	// the tables as they were before the first patch
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "migrated.db"))
+	require.Nil(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE friends (email text, name text);
+		CREATE TABLE fridays (start_time datetime);`)
+	require.Nil(t, err)
+
+	// WHEN
+	migrated, err := pizza.NewSQLAccessor(filepath.Join(dir, "migrated.db"), false)
+	require.Nil(t, err)
+	defer migrated.Close()
+	createdVersion, err := created.Migrator().Version()
+	require.Nil(t, err)
+	migratedVersion, err := migrated.Migrator().Version()
+	require.Nil(t, err)
+
+	// THEN
+	assert.Equal(t, len(pizza.SQLiteMigrations), createdVersion)
+	assert.Equal(t, len(pizza.SQLiteMigrations), migratedVersion)
+	expected := tableColumns(t, openTestDBFile(t, filepath.Join(dir, "created.db")))
+	assert.Equal(t, expected, tableColumns(t, db))
+
+	// WHEN
+	err = migrated.Migrator().To(6)
+	err2 := migrated.Migrator().Up()
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Equal(t, expected, tableColumns(t, db))
+}
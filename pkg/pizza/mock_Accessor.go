@@ -21,6 +21,98 @@ func (_m *MockAccessor) EXPECT() *MockAccessor_Expecter {
 	return &MockAccessor_Expecter{mock: &_m.Mock}
 }
 
+// AddAuditEntry provides a mock function with given fields: entry
+func (_m *MockAccessor) AddAuditEntry(entry AuditEntry) error {
+	ret := _m.Called(entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAuditEntry")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(AuditEntry) error); ok {
+		r0 = rf(entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_AddAuditEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddAuditEntry'
+type MockAccessor_AddAuditEntry_Call struct {
+	*mock.Call
+}
+
+// AddAuditEntry is a helper method to define mock.On call
+//   - entry AuditEntry
+func (_e *MockAccessor_Expecter) AddAuditEntry(entry interface{}) *MockAccessor_AddAuditEntry_Call {
+	return &MockAccessor_AddAuditEntry_Call{Call: _e.mock.On("AddAuditEntry", entry)}
+}
+
+func (_c *MockAccessor_AddAuditEntry_Call) Run(run func(entry AuditEntry)) *MockAccessor_AddAuditEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(AuditEntry))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_AddAuditEntry_Call) Return(_a0 error) *MockAccessor_AddAuditEntry_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_AddAuditEntry_Call) RunAndReturn(run func(AuditEntry) error) *MockAccessor_AddAuditEntry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddBlackout provides a mock function with given fields: blackout
+func (_m *MockAccessor) AddBlackout(blackout Blackout) error {
+	ret := _m.Called(blackout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddBlackout")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Blackout) error); ok {
+		r0 = rf(blackout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_AddBlackout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddBlackout'
+type MockAccessor_AddBlackout_Call struct {
+	*mock.Call
+}
+
+// AddBlackout is a helper method to define mock.On call
+//   - blackout Blackout
+func (_e *MockAccessor_Expecter) AddBlackout(blackout interface{}) *MockAccessor_AddBlackout_Call {
+	return &MockAccessor_AddBlackout_Call{Call: _e.mock.On("AddBlackout", blackout)}
+}
+
+func (_c *MockAccessor_AddBlackout_Call) Run(run func(blackout Blackout)) *MockAccessor_AddBlackout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Blackout))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_AddBlackout_Call) Return(_a0 error) *MockAccessor_AddBlackout_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_AddBlackout_Call) RunAndReturn(run func(Blackout) error) *MockAccessor_AddBlackout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddFriday provides a mock function with given fields: date
 func (_m *MockAccessor) AddFriday(date time.Time) error {
 	ret := _m.Called(date)
@@ -114,17 +206,17 @@ func (_c *MockAccessor_AddFriend_Call) RunAndReturn(run func(string, string) err
 	return _c
 }
 
-// AddFriendToFriday provides a mock function with given fields: email, friday
-func (_m *MockAccessor) AddFriendToFriday(email string, friday Friday) error {
-	ret := _m.Called(email, friday)
+// AddFriendAsTentative provides a mock function with given fields: email, date
+func (_m *MockAccessor) AddFriendAsTentative(email string, date time.Time) error {
+	ret := _m.Called(email, date)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AddFriendToFriday")
+		panic("no return value specified for AddFriendAsTentative")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, Friday) error); ok {
-		r0 = rf(email, friday)
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(email, date)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -132,46 +224,46 @@ func (_m *MockAccessor) AddFriendToFriday(email string, friday Friday) error {
 	return r0
 }
 
-// MockAccessor_AddFriendToFriday_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddFriendToFriday'
-type MockAccessor_AddFriendToFriday_Call struct {
+// MockAccessor_AddFriendAsTentative_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddFriendAsTentative'
+type MockAccessor_AddFriendAsTentative_Call struct {
 	*mock.Call
 }
 
-// AddFriendToFriday is a helper method to define mock.On call
+// AddFriendAsTentative is a helper method to define mock.On call
 //   - email string
-//   - friday Friday
-func (_e *MockAccessor_Expecter) AddFriendToFriday(email interface{}, friday interface{}) *MockAccessor_AddFriendToFriday_Call {
-	return &MockAccessor_AddFriendToFriday_Call{Call: _e.mock.On("AddFriendToFriday", email, friday)}
+//   - date time.Time
+func (_e *MockAccessor_Expecter) AddFriendAsTentative(email interface{}, date interface{}) *MockAccessor_AddFriendAsTentative_Call {
+	return &MockAccessor_AddFriendAsTentative_Call{Call: _e.mock.On("AddFriendAsTentative", email, date)}
 }
 
-func (_c *MockAccessor_AddFriendToFriday_Call) Run(run func(email string, friday Friday)) *MockAccessor_AddFriendToFriday_Call {
+func (_c *MockAccessor_AddFriendAsTentative_Call) Run(run func(email string, date time.Time)) *MockAccessor_AddFriendAsTentative_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(Friday))
+		run(args[0].(string), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_AddFriendToFriday_Call) Return(_a0 error) *MockAccessor_AddFriendToFriday_Call {
+func (_c *MockAccessor_AddFriendAsTentative_Call) Return(_a0 error) *MockAccessor_AddFriendAsTentative_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockAccessor_AddFriendToFriday_Call) RunAndReturn(run func(string, Friday) error) *MockAccessor_AddFriendToFriday_Call {
+func (_c *MockAccessor_AddFriendAsTentative_Call) RunAndReturn(run func(string, time.Time) error) *MockAccessor_AddFriendAsTentative_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateTables provides a mock function with no fields
-func (_m *MockAccessor) CreateTables() error {
-	ret := _m.Called()
+// AddFriendToFriday provides a mock function with given fields: email, friday, createdBy
+func (_m *MockAccessor) AddFriendToFriday(email string, friday Friday, createdBy string) error {
+	ret := _m.Called(email, friday, createdBy)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateTables")
+		panic("no return value specified for AddFriendToFriday")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(string, Friday, string) error); ok {
+		r0 = rf(email, friday, createdBy)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -179,110 +271,104 @@ func (_m *MockAccessor) CreateTables() error {
 	return r0
 }
 
-// MockAccessor_CreateTables_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTables'
-type MockAccessor_CreateTables_Call struct {
+// MockAccessor_AddFriendToFriday_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddFriendToFriday'
+type MockAccessor_AddFriendToFriday_Call struct {
 	*mock.Call
 }
 
-// CreateTables is a helper method to define mock.On call
-func (_e *MockAccessor_Expecter) CreateTables() *MockAccessor_CreateTables_Call {
-	return &MockAccessor_CreateTables_Call{Call: _e.mock.On("CreateTables")}
+// AddFriendToFriday is a helper method to define mock.On call
+//   - email string
+//   - friday Friday
+//   - createdBy string
+func (_e *MockAccessor_Expecter) AddFriendToFriday(email interface{}, friday interface{}, createdBy interface{}) *MockAccessor_AddFriendToFriday_Call {
+	return &MockAccessor_AddFriendToFriday_Call{Call: _e.mock.On("AddFriendToFriday", email, friday, createdBy)}
 }
 
-func (_c *MockAccessor_CreateTables_Call) Run(run func()) *MockAccessor_CreateTables_Call {
+func (_c *MockAccessor_AddFriendToFriday_Call) Run(run func(email string, friday Friday, createdBy string)) *MockAccessor_AddFriendToFriday_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(string), args[1].(Friday), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_CreateTables_Call) Return(_a0 error) *MockAccessor_CreateTables_Call {
+func (_c *MockAccessor_AddFriendToFriday_Call) Return(_a0 error) *MockAccessor_AddFriendToFriday_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockAccessor_CreateTables_Call) RunAndReturn(run func() error) *MockAccessor_CreateTables_Call {
+func (_c *MockAccessor_AddFriendToFriday_Call) RunAndReturn(run func(string, Friday, string) error) *MockAccessor_AddFriendToFriday_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DoesFridayExist provides a mock function with given fields: date
-func (_m *MockAccessor) DoesFridayExist(date time.Time) (bool, error) {
-	ret := _m.Called(date)
+// AddFriendToWaitlist provides a mock function with given fields: email, date
+func (_m *MockAccessor) AddFriendToWaitlist(email string, date time.Time) error {
+	ret := _m.Called(email, date)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DoesFridayExist")
-	}
-
-	var r0 bool
-	var r1 error
-	if rf, ok := ret.Get(0).(func(time.Time) (bool, error)); ok {
-		return rf(date)
-	}
-	if rf, ok := ret.Get(0).(func(time.Time) bool); ok {
-		r0 = rf(date)
-	} else {
-		r0 = ret.Get(0).(bool)
+		panic("no return value specified for AddFriendToWaitlist")
 	}
 
-	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
-		r1 = rf(date)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(email, date)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockAccessor_DoesFridayExist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DoesFridayExist'
-type MockAccessor_DoesFridayExist_Call struct {
+// MockAccessor_AddFriendToWaitlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddFriendToWaitlist'
+type MockAccessor_AddFriendToWaitlist_Call struct {
 	*mock.Call
 }
 
-// DoesFridayExist is a helper method to define mock.On call
+// AddFriendToWaitlist is a helper method to define mock.On call
+//   - email string
 //   - date time.Time
-func (_e *MockAccessor_Expecter) DoesFridayExist(date interface{}) *MockAccessor_DoesFridayExist_Call {
-	return &MockAccessor_DoesFridayExist_Call{Call: _e.mock.On("DoesFridayExist", date)}
+func (_e *MockAccessor_Expecter) AddFriendToWaitlist(email interface{}, date interface{}) *MockAccessor_AddFriendToWaitlist_Call {
+	return &MockAccessor_AddFriendToWaitlist_Call{Call: _e.mock.On("AddFriendToWaitlist", email, date)}
 }
 
-func (_c *MockAccessor_DoesFridayExist_Call) Run(run func(date time.Time)) *MockAccessor_DoesFridayExist_Call {
+func (_c *MockAccessor_AddFriendToWaitlist_Call) Run(run func(email string, date time.Time)) *MockAccessor_AddFriendToWaitlist_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(time.Time))
+		run(args[0].(string), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_DoesFridayExist_Call) Return(_a0 bool, _a1 error) *MockAccessor_DoesFridayExist_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockAccessor_AddFriendToWaitlist_Call) Return(_a0 error) *MockAccessor_AddFriendToWaitlist_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockAccessor_DoesFridayExist_Call) RunAndReturn(run func(time.Time) (bool, error)) *MockAccessor_DoesFridayExist_Call {
+func (_c *MockAccessor_AddFriendToWaitlist_Call) RunAndReturn(run func(string, time.Time) error) *MockAccessor_AddFriendToWaitlist_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetFriday provides a mock function with given fields: date
-func (_m *MockAccessor) GetFriday(date time.Time) (Friday, error) {
-	ret := _m.Called(date)
+// AddPlusOne provides a mock function with given fields: date, plusOne
+func (_m *MockAccessor) AddPlusOne(date time.Time, plusOne PlusOne) (int64, error) {
+	ret := _m.Called(date, plusOne)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetFriday")
+		panic("no return value specified for AddPlusOne")
 	}
 
-	var r0 Friday
+	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(time.Time) (Friday, error)); ok {
-		return rf(date)
+	if rf, ok := ret.Get(0).(func(time.Time, PlusOne) (int64, error)); ok {
+		return rf(date, plusOne)
 	}
-	if rf, ok := ret.Get(0).(func(time.Time) Friday); ok {
-		r0 = rf(date)
+	if rf, ok := ret.Get(0).(func(time.Time, PlusOne) int64); ok {
+		r0 = rf(date, plusOne)
 	} else {
-		r0 = ret.Get(0).(Friday)
+		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
-		r1 = rf(date)
+	if rf, ok := ret.Get(1).(func(time.Time, PlusOne) error); ok {
+		r1 = rf(date, plusOne)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -290,55 +376,56 @@ func (_m *MockAccessor) GetFriday(date time.Time) (Friday, error) {
 	return r0, r1
 }
 
-// MockAccessor_GetFriday_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriday'
-type MockAccessor_GetFriday_Call struct {
+// MockAccessor_AddPlusOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddPlusOne'
+type MockAccessor_AddPlusOne_Call struct {
 	*mock.Call
 }
 
-// GetFriday is a helper method to define mock.On call
+// AddPlusOne is a helper method to define mock.On call
 //   - date time.Time
-func (_e *MockAccessor_Expecter) GetFriday(date interface{}) *MockAccessor_GetFriday_Call {
-	return &MockAccessor_GetFriday_Call{Call: _e.mock.On("GetFriday", date)}
+//   - plusOne PlusOne
+func (_e *MockAccessor_Expecter) AddPlusOne(date interface{}, plusOne interface{}) *MockAccessor_AddPlusOne_Call {
+	return &MockAccessor_AddPlusOne_Call{Call: _e.mock.On("AddPlusOne", date, plusOne)}
 }
 
-func (_c *MockAccessor_GetFriday_Call) Run(run func(date time.Time)) *MockAccessor_GetFriday_Call {
+func (_c *MockAccessor_AddPlusOne_Call) Run(run func(date time.Time, plusOne PlusOne)) *MockAccessor_AddPlusOne_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(time.Time))
+		run(args[0].(time.Time), args[1].(PlusOne))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetFriday_Call) Return(_a0 Friday, _a1 error) *MockAccessor_GetFriday_Call {
+func (_c *MockAccessor_AddPlusOne_Call) Return(_a0 int64, _a1 error) *MockAccessor_AddPlusOne_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAccessor_GetFriday_Call) RunAndReturn(run func(time.Time) (Friday, error)) *MockAccessor_GetFriday_Call {
+func (_c *MockAccessor_AddPlusOne_Call) RunAndReturn(run func(time.Time, PlusOne) (int64, error)) *MockAccessor_AddPlusOne_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetFriendByEmail provides a mock function with given fields: email
-func (_m *MockAccessor) GetFriendByEmail(email string) (Friend, error) {
-	ret := _m.Called(email)
+// AddSeries provides a mock function with given fields: series
+func (_m *MockAccessor) AddSeries(series Series) (int64, error) {
+	ret := _m.Called(series)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetFriendByEmail")
+		panic("no return value specified for AddSeries")
 	}
 
-	var r0 Friend
+	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (Friend, error)); ok {
-		return rf(email)
+	if rf, ok := ret.Get(0).(func(Series) (int64, error)); ok {
+		return rf(series)
 	}
-	if rf, ok := ret.Get(0).(func(string) Friend); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(Series) int64); ok {
+		r0 = rf(series)
 	} else {
-		r0 = ret.Get(0).(Friend)
+		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+	if rf, ok := ret.Get(1).(func(Series) error); ok {
+		r1 = rf(series)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -346,55 +433,55 @@ func (_m *MockAccessor) GetFriendByEmail(email string) (Friend, error) {
 	return r0, r1
 }
 
-// MockAccessor_GetFriendByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriendByEmail'
-type MockAccessor_GetFriendByEmail_Call struct {
+// MockAccessor_AddSeries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddSeries'
+type MockAccessor_AddSeries_Call struct {
 	*mock.Call
 }
 
-// GetFriendByEmail is a helper method to define mock.On call
-//   - email string
-func (_e *MockAccessor_Expecter) GetFriendByEmail(email interface{}) *MockAccessor_GetFriendByEmail_Call {
-	return &MockAccessor_GetFriendByEmail_Call{Call: _e.mock.On("GetFriendByEmail", email)}
+// AddSeries is a helper method to define mock.On call
+//   - series Series
+func (_e *MockAccessor_Expecter) AddSeries(series interface{}) *MockAccessor_AddSeries_Call {
+	return &MockAccessor_AddSeries_Call{Call: _e.mock.On("AddSeries", series)}
 }
 
-func (_c *MockAccessor_GetFriendByEmail_Call) Run(run func(email string)) *MockAccessor_GetFriendByEmail_Call {
+func (_c *MockAccessor_AddSeries_Call) Run(run func(series Series)) *MockAccessor_AddSeries_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(Series))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetFriendByEmail_Call) Return(_a0 Friend, _a1 error) *MockAccessor_GetFriendByEmail_Call {
+func (_c *MockAccessor_AddSeries_Call) Return(_a0 int64, _a1 error) *MockAccessor_AddSeries_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAccessor_GetFriendByEmail_Call) RunAndReturn(run func(string) (Friend, error)) *MockAccessor_GetFriendByEmail_Call {
+func (_c *MockAccessor_AddSeries_Call) RunAndReturn(run func(Series) (int64, error)) *MockAccessor_AddSeries_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetFriendByID provides a mock function with given fields: ID
-func (_m *MockAccessor) GetFriendByID(ID string) (Friend, error) {
-	ret := _m.Called(ID)
+// AddVenue provides a mock function with given fields: venue
+func (_m *MockAccessor) AddVenue(venue Venue) (int64, error) {
+	ret := _m.Called(venue)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetFriendByID")
+		panic("no return value specified for AddVenue")
 	}
 
-	var r0 Friend
+	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (Friend, error)); ok {
-		return rf(ID)
+	if rf, ok := ret.Get(0).(func(Venue) (int64, error)); ok {
+		return rf(venue)
 	}
-	if rf, ok := ret.Get(0).(func(string) Friend); ok {
-		r0 = rf(ID)
+	if rf, ok := ret.Get(0).(func(Venue) int64); ok {
+		r0 = rf(venue)
 	} else {
-		r0 = ret.Get(0).(Friend)
+		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(ID)
+	if rf, ok := ret.Get(1).(func(Venue) error); ok {
+		r1 = rf(venue)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -402,113 +489,952 @@ func (_m *MockAccessor) GetFriendByID(ID string) (Friend, error) {
 	return r0, r1
 }
 
-// MockAccessor_GetFriendByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriendByID'
-type MockAccessor_GetFriendByID_Call struct {
+// MockAccessor_AddVenue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddVenue'
+type MockAccessor_AddVenue_Call struct {
 	*mock.Call
 }
 
-// GetFriendByID is a helper method to define mock.On call
-//   - ID string
-func (_e *MockAccessor_Expecter) GetFriendByID(ID interface{}) *MockAccessor_GetFriendByID_Call {
-	return &MockAccessor_GetFriendByID_Call{Call: _e.mock.On("GetFriendByID", ID)}
+// AddVenue is a helper method to define mock.On call
+//   - venue Venue
+func (_e *MockAccessor_Expecter) AddVenue(venue interface{}) *MockAccessor_AddVenue_Call {
+	return &MockAccessor_AddVenue_Call{Call: _e.mock.On("AddVenue", venue)}
 }
 
-func (_c *MockAccessor_GetFriendByID_Call) Run(run func(ID string)) *MockAccessor_GetFriendByID_Call {
+func (_c *MockAccessor_AddVenue_Call) Run(run func(venue Venue)) *MockAccessor_AddVenue_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(Venue))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetFriendByID_Call) Return(_a0 Friend, _a1 error) *MockAccessor_GetFriendByID_Call {
+func (_c *MockAccessor_AddVenue_Call) Return(_a0 int64, _a1 error) *MockAccessor_AddVenue_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAccessor_GetFriendByID_Call) RunAndReturn(run func(string) (Friend, error)) *MockAccessor_GetFriendByID_Call {
+func (_c *MockAccessor_AddVenue_Call) RunAndReturn(run func(Venue) (int64, error)) *MockAccessor_AddVenue_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetPreferences provides a mock function with given fields: email
-func (_m *MockAccessor) GetPreferences(email string) (Preferences, error) {
-	ret := _m.Called(email)
+// CreateTables provides a mock function with no fields
+func (_m *MockAccessor) CreateTables() error {
+	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetPreferences")
-	}
-
-	var r0 Preferences
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (Preferences, error)); ok {
-		return rf(email)
-	}
-	if rf, ok := ret.Get(0).(func(string) Preferences); ok {
-		r0 = rf(email)
-	} else {
-		r0 = ret.Get(0).(Preferences)
+		panic("no return value specified for CreateTables")
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(email)
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockAccessor_GetPreferences_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPreferences'
-type MockAccessor_GetPreferences_Call struct {
+// MockAccessor_CreateTables_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTables'
+type MockAccessor_CreateTables_Call struct {
 	*mock.Call
 }
 
-// GetPreferences is a helper method to define mock.On call
-//   - email string
-func (_e *MockAccessor_Expecter) GetPreferences(email interface{}) *MockAccessor_GetPreferences_Call {
-	return &MockAccessor_GetPreferences_Call{Call: _e.mock.On("GetPreferences", email)}
+// CreateTables is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) CreateTables() *MockAccessor_CreateTables_Call {
+	return &MockAccessor_CreateTables_Call{Call: _e.mock.On("CreateTables")}
 }
 
-func (_c *MockAccessor_GetPreferences_Call) Run(run func(email string)) *MockAccessor_GetPreferences_Call {
+func (_c *MockAccessor_CreateTables_Call) Run(run func()) *MockAccessor_CreateTables_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run()
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetPreferences_Call) Return(_a0 Preferences, _a1 error) *MockAccessor_GetPreferences_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockAccessor_CreateTables_Call) Return(_a0 error) *MockAccessor_CreateTables_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockAccessor_GetPreferences_Call) RunAndReturn(run func(string) (Preferences, error)) *MockAccessor_GetPreferences_Call {
+func (_c *MockAccessor_CreateTables_Call) RunAndReturn(run func() error) *MockAccessor_CreateTables_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUpcomingFridays provides a mock function with given fields: daysAhead
-func (_m *MockAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
-	ret := _m.Called(daysAhead)
+// DoesFridayExist provides a mock function with given fields: date
+func (_m *MockAccessor) DoesFridayExist(date time.Time) (bool, error) {
+	ret := _m.Called(date)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUpcomingFridays")
+		panic("no return value specified for DoesFridayExist")
 	}
 
-	var r0 []Friday
+	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) ([]Friday, error)); ok {
+	if rf, ok := ret.Get(0).(func(time.Time) (bool, error)); ok {
+		return rf(date)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) bool); ok {
+		r0 = rf(date)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(date)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_DoesFridayExist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DoesFridayExist'
+type MockAccessor_DoesFridayExist_Call struct {
+	*mock.Call
+}
+
+// DoesFridayExist is a helper method to define mock.On call
+//   - date time.Time
+func (_e *MockAccessor_Expecter) DoesFridayExist(date interface{}) *MockAccessor_DoesFridayExist_Call {
+	return &MockAccessor_DoesFridayExist_Call{Call: _e.mock.On("DoesFridayExist", date)}
+}
+
+func (_c *MockAccessor_DoesFridayExist_Call) Run(run func(date time.Time)) *MockAccessor_DoesFridayExist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_DoesFridayExist_Call) Return(_a0 bool, _a1 error) *MockAccessor_DoesFridayExist_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_DoesFridayExist_Call) RunAndReturn(run func(time.Time) (bool, error)) *MockAccessor_DoesFridayExist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCalendarFeed provides a mock function with given fields: email
+func (_m *MockAccessor) GetCalendarFeed(email string) (CalendarFeed, error) {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCalendarFeed")
+	}
+
+	var r0 CalendarFeed
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (CalendarFeed, error)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) CalendarFeed); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(CalendarFeed)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetCalendarFeed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCalendarFeed'
+type MockAccessor_GetCalendarFeed_Call struct {
+	*mock.Call
+}
+
+// GetCalendarFeed is a helper method to define mock.On call
+//   - email string
+func (_e *MockAccessor_Expecter) GetCalendarFeed(email interface{}) *MockAccessor_GetCalendarFeed_Call {
+	return &MockAccessor_GetCalendarFeed_Call{Call: _e.mock.On("GetCalendarFeed", email)}
+}
+
+func (_c *MockAccessor_GetCalendarFeed_Call) Run(run func(email string)) *MockAccessor_GetCalendarFeed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetCalendarFeed_Call) Return(_a0 CalendarFeed, _a1 error) *MockAccessor_GetCalendarFeed_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetCalendarFeed_Call) RunAndReturn(run func(string) (CalendarFeed, error)) *MockAccessor_GetCalendarFeed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCalendarFeedByToken provides a mock function with given fields: token
+func (_m *MockAccessor) GetCalendarFeedByToken(token string) (CalendarFeed, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCalendarFeedByToken")
+	}
+
+	var r0 CalendarFeed
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (CalendarFeed, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) CalendarFeed); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(CalendarFeed)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetCalendarFeedByToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCalendarFeedByToken'
+type MockAccessor_GetCalendarFeedByToken_Call struct {
+	*mock.Call
+}
+
+// GetCalendarFeedByToken is a helper method to define mock.On call
+//   - token string
+func (_e *MockAccessor_Expecter) GetCalendarFeedByToken(token interface{}) *MockAccessor_GetCalendarFeedByToken_Call {
+	return &MockAccessor_GetCalendarFeedByToken_Call{Call: _e.mock.On("GetCalendarFeedByToken", token)}
+}
+
+func (_c *MockAccessor_GetCalendarFeedByToken_Call) Run(run func(token string)) *MockAccessor_GetCalendarFeedByToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetCalendarFeedByToken_Call) Return(_a0 CalendarFeed, _a1 error) *MockAccessor_GetCalendarFeedByToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetCalendarFeedByToken_Call) RunAndReturn(run func(string) (CalendarFeed, error)) *MockAccessor_GetCalendarFeedByToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFriday provides a mock function with given fields: date
+func (_m *MockAccessor) GetFriday(date time.Time) (Friday, error) {
+	ret := _m.Called(date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFriday")
+	}
+
+	var r0 Friday
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (Friday, error)); ok {
+		return rf(date)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) Friday); ok {
+		r0 = rf(date)
+	} else {
+		r0 = ret.Get(0).(Friday)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(date)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetFriday_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriday'
+type MockAccessor_GetFriday_Call struct {
+	*mock.Call
+}
+
+// GetFriday is a helper method to define mock.On call
+//   - date time.Time
+func (_e *MockAccessor_Expecter) GetFriday(date interface{}) *MockAccessor_GetFriday_Call {
+	return &MockAccessor_GetFriday_Call{Call: _e.mock.On("GetFriday", date)}
+}
+
+func (_c *MockAccessor_GetFriday_Call) Run(run func(date time.Time)) *MockAccessor_GetFriday_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetFriday_Call) Return(_a0 Friday, _a1 error) *MockAccessor_GetFriday_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetFriday_Call) RunAndReturn(run func(time.Time) (Friday, error)) *MockAccessor_GetFriday_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFriendByEmail provides a mock function with given fields: email
+func (_m *MockAccessor) GetFriendByEmail(email string) (Friend, error) {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFriendByEmail")
+	}
+
+	var r0 Friend
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (Friend, error)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) Friend); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(Friend)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetFriendByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriendByEmail'
+type MockAccessor_GetFriendByEmail_Call struct {
+	*mock.Call
+}
+
+// GetFriendByEmail is a helper method to define mock.On call
+//   - email string
+func (_e *MockAccessor_Expecter) GetFriendByEmail(email interface{}) *MockAccessor_GetFriendByEmail_Call {
+	return &MockAccessor_GetFriendByEmail_Call{Call: _e.mock.On("GetFriendByEmail", email)}
+}
+
+func (_c *MockAccessor_GetFriendByEmail_Call) Run(run func(email string)) *MockAccessor_GetFriendByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetFriendByEmail_Call) Return(_a0 Friend, _a1 error) *MockAccessor_GetFriendByEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetFriendByEmail_Call) RunAndReturn(run func(string) (Friend, error)) *MockAccessor_GetFriendByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFriendByID provides a mock function with given fields: ID
+func (_m *MockAccessor) GetFriendByID(ID string) (Friend, error) {
+	ret := _m.Called(ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFriendByID")
+	}
+
+	var r0 Friend
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (Friend, error)); ok {
+		return rf(ID)
+	}
+	if rf, ok := ret.Get(0).(func(string) Friend); ok {
+		r0 = rf(ID)
+	} else {
+		r0 = ret.Get(0).(Friend)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetFriendByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFriendByID'
+type MockAccessor_GetFriendByID_Call struct {
+	*mock.Call
+}
+
+// GetFriendByID is a helper method to define mock.On call
+//   - ID string
+func (_e *MockAccessor_Expecter) GetFriendByID(ID interface{}) *MockAccessor_GetFriendByID_Call {
+	return &MockAccessor_GetFriendByID_Call{Call: _e.mock.On("GetFriendByID", ID)}
+}
+
+func (_c *MockAccessor_GetFriendByID_Call) Run(run func(ID string)) *MockAccessor_GetFriendByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetFriendByID_Call) Return(_a0 Friend, _a1 error) *MockAccessor_GetFriendByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetFriendByID_Call) RunAndReturn(run func(string) (Friend, error)) *MockAccessor_GetFriendByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPreferences provides a mock function with given fields: email
+func (_m *MockAccessor) GetPreferences(email string) (Preferences, error) {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreferences")
+	}
+
+	var r0 Preferences
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (Preferences, error)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) Preferences); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(Preferences)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetPreferences_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPreferences'
+type MockAccessor_GetPreferences_Call struct {
+	*mock.Call
+}
+
+// GetPreferences is a helper method to define mock.On call
+//   - email string
+func (_e *MockAccessor_Expecter) GetPreferences(email interface{}) *MockAccessor_GetPreferences_Call {
+	return &MockAccessor_GetPreferences_Call{Call: _e.mock.On("GetPreferences", email)}
+}
+
+func (_c *MockAccessor_GetPreferences_Call) Run(run func(email string)) *MockAccessor_GetPreferences_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetPreferences_Call) Return(_a0 Preferences, _a1 error) *MockAccessor_GetPreferences_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetPreferences_Call) RunAndReturn(run func(string) (Preferences, error)) *MockAccessor_GetPreferences_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRSVPs provides a mock function with given fields: date
+func (_m *MockAccessor) GetRSVPs(date time.Time) ([]RSVP, error) {
+	ret := _m.Called(date)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRSVPs")
+	}
+
+	var r0 []RSVP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) ([]RSVP, error)); ok {
+		return rf(date)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) []RSVP); ok {
+		r0 = rf(date)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]RSVP)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(date)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetRSVPs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRSVPs'
+type MockAccessor_GetRSVPs_Call struct {
+	*mock.Call
+}
+
+// GetRSVPs is a helper method to define mock.On call
+//   - date time.Time
+func (_e *MockAccessor_Expecter) GetRSVPs(date interface{}) *MockAccessor_GetRSVPs_Call {
+	return &MockAccessor_GetRSVPs_Call{Call: _e.mock.On("GetRSVPs", date)}
+}
+
+func (_c *MockAccessor_GetRSVPs_Call) Run(run func(date time.Time)) *MockAccessor_GetRSVPs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetRSVPs_Call) Return(_a0 []RSVP, _a1 error) *MockAccessor_GetRSVPs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetRSVPs_Call) RunAndReturn(run func(time.Time) ([]RSVP, error)) *MockAccessor_GetRSVPs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSeries provides a mock function with given fields: ID
+func (_m *MockAccessor) GetSeries(ID int64) (Series, error) {
+	ret := _m.Called(ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSeries")
+	}
+
+	var r0 Series
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (Series, error)); ok {
+		return rf(ID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) Series); ok {
+		r0 = rf(ID)
+	} else {
+		r0 = ret.Get(0).(Series)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetSeries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSeries'
+type MockAccessor_GetSeries_Call struct {
+	*mock.Call
+}
+
+// GetSeries is a helper method to define mock.On call
+//   - ID int64
+func (_e *MockAccessor_Expecter) GetSeries(ID interface{}) *MockAccessor_GetSeries_Call {
+	return &MockAccessor_GetSeries_Call{Call: _e.mock.On("GetSeries", ID)}
+}
+
+func (_c *MockAccessor_GetSeries_Call) Run(run func(ID int64)) *MockAccessor_GetSeries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetSeries_Call) Return(_a0 Series, _a1 error) *MockAccessor_GetSeries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetSeries_Call) RunAndReturn(run func(int64) (Series, error)) *MockAccessor_GetSeries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSetting provides a mock function with given fields: name
+func (_m *MockAccessor) GetSetting(name string) (string, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSetting")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetSetting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSetting'
+type MockAccessor_GetSetting_Call struct {
+	*mock.Call
+}
+
+// GetSetting is a helper method to define mock.On call
+//   - name string
+func (_e *MockAccessor_Expecter) GetSetting(name interface{}) *MockAccessor_GetSetting_Call {
+	return &MockAccessor_GetSetting_Call{Call: _e.mock.On("GetSetting", name)}
+}
+
+func (_c *MockAccessor_GetSetting_Call) Run(run func(name string)) *MockAccessor_GetSetting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetSetting_Call) Return(_a0 string, _a1 error) *MockAccessor_GetSetting_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetSetting_Call) RunAndReturn(run func(string) (string, error)) *MockAccessor_GetSetting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUpcomingFridays provides a mock function with given fields: daysAhead
+func (_m *MockAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
+	ret := _m.Called(daysAhead)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUpcomingFridays")
+	}
+
+	var r0 []Friday
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]Friday, error)); ok {
 		return rf(daysAhead)
 	}
-	if rf, ok := ret.Get(0).(func(int) []Friday); ok {
-		r0 = rf(daysAhead)
+	if rf, ok := ret.Get(0).(func(int) []Friday); ok {
+		r0 = rf(daysAhead)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Friday)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(daysAhead)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetUpcomingFridays_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUpcomingFridays'
+type MockAccessor_GetUpcomingFridays_Call struct {
+	*mock.Call
+}
+
+// GetUpcomingFridays is a helper method to define mock.On call
+//   - daysAhead int
+func (_e *MockAccessor_Expecter) GetUpcomingFridays(daysAhead interface{}) *MockAccessor_GetUpcomingFridays_Call {
+	return &MockAccessor_GetUpcomingFridays_Call{Call: _e.mock.On("GetUpcomingFridays", daysAhead)}
+}
+
+func (_c *MockAccessor_GetUpcomingFridays_Call) Run(run func(daysAhead int)) *MockAccessor_GetUpcomingFridays_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetUpcomingFridays_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_GetUpcomingFridays_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetUpcomingFridays_Call) RunAndReturn(run func(int) ([]Friday, error)) *MockAccessor_GetUpcomingFridays_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUpcomingFridaysAfter provides a mock function with given fields: after, daysAhead
+func (_m *MockAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error) {
+	ret := _m.Called(after, daysAhead)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUpcomingFridaysAfter")
+	}
+
+	var r0 []Friday
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]Friday, error)); ok {
+		return rf(after, daysAhead)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []Friday); ok {
+		r0 = rf(after, daysAhead)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Friday)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(after, daysAhead)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetUpcomingFridaysAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUpcomingFridaysAfter'
+type MockAccessor_GetUpcomingFridaysAfter_Call struct {
+	*mock.Call
+}
+
+// GetUpcomingFridaysAfter is a helper method to define mock.On call
+//   - after time.Time
+//   - daysAhead int
+func (_e *MockAccessor_Expecter) GetUpcomingFridaysAfter(after interface{}, daysAhead interface{}) *MockAccessor_GetUpcomingFridaysAfter_Call {
+	return &MockAccessor_GetUpcomingFridaysAfter_Call{Call: _e.mock.On("GetUpcomingFridaysAfter", after, daysAhead)}
+}
+
+func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) Run(run func(after time.Time, daysAhead int)) *MockAccessor_GetUpcomingFridaysAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_GetUpcomingFridaysAfter_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) RunAndReturn(run func(time.Time, int) ([]Friday, error)) *MockAccessor_GetUpcomingFridaysAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVenue provides a mock function with given fields: ID
+func (_m *MockAccessor) GetVenue(ID int64) (Venue, error) {
+	ret := _m.Called(ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVenue")
+	}
+
+	var r0 Venue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (Venue, error)); ok {
+		return rf(ID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) Venue); ok {
+		r0 = rf(ID)
+	} else {
+		r0 = ret.Get(0).(Venue)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_GetVenue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVenue'
+type MockAccessor_GetVenue_Call struct {
+	*mock.Call
+}
+
+// GetVenue is a helper method to define mock.On call
+//   - ID int64
+func (_e *MockAccessor_Expecter) GetVenue(ID interface{}) *MockAccessor_GetVenue_Call {
+	return &MockAccessor_GetVenue_Call{Call: _e.mock.On("GetVenue", ID)}
+}
+
+func (_c *MockAccessor_GetVenue_Call) Run(run func(ID int64)) *MockAccessor_GetVenue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_GetVenue_Call) Return(_a0 Venue, _a1 error) *MockAccessor_GetVenue_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_GetVenue_Call) RunAndReturn(run func(int64) (Venue, error)) *MockAccessor_GetVenue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAuditEntries provides a mock function with given fields: filter
+func (_m *MockAccessor) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAuditEntries")
+	}
+
+	var r0 []AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(AuditFilter) ([]AuditEntry, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(AuditFilter) []AuditEntry); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(AuditFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_ListAuditEntries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAuditEntries'
+type MockAccessor_ListAuditEntries_Call struct {
+	*mock.Call
+}
+
+// ListAuditEntries is a helper method to define mock.On call
+//   - filter AuditFilter
+func (_e *MockAccessor_Expecter) ListAuditEntries(filter interface{}) *MockAccessor_ListAuditEntries_Call {
+	return &MockAccessor_ListAuditEntries_Call{Call: _e.mock.On("ListAuditEntries", filter)}
+}
+
+func (_c *MockAccessor_ListAuditEntries_Call) Run(run func(filter AuditFilter)) *MockAccessor_ListAuditEntries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(AuditFilter))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_ListAuditEntries_Call) Return(_a0 []AuditEntry, _a1 error) *MockAccessor_ListAuditEntries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_ListAuditEntries_Call) RunAndReturn(run func(AuditFilter) ([]AuditEntry, error)) *MockAccessor_ListAuditEntries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBlackouts provides a mock function with no fields
+func (_m *MockAccessor) ListBlackouts() ([]Blackout, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBlackouts")
+	}
+
+	var r0 []Blackout
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]Blackout, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []Blackout); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Blackout)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_ListBlackouts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBlackouts'
+type MockAccessor_ListBlackouts_Call struct {
+	*mock.Call
+}
+
+// ListBlackouts is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) ListBlackouts() *MockAccessor_ListBlackouts_Call {
+	return &MockAccessor_ListBlackouts_Call{Call: _e.mock.On("ListBlackouts")}
+}
+
+func (_c *MockAccessor_ListBlackouts_Call) Run(run func()) *MockAccessor_ListBlackouts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAccessor_ListBlackouts_Call) Return(_a0 []Blackout, _a1 error) *MockAccessor_ListBlackouts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_ListBlackouts_Call) RunAndReturn(run func() ([]Blackout, error)) *MockAccessor_ListBlackouts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFridays provides a mock function with no fields
+func (_m *MockAccessor) ListFridays() ([]Friday, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFridays")
+	}
+
+	var r0 []Friday
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]Friday, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []Friday); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]Friday)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(daysAhead)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -516,57 +1442,227 @@ func (_m *MockAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
 	return r0, r1
 }
 
-// MockAccessor_GetUpcomingFridays_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUpcomingFridays'
-type MockAccessor_GetUpcomingFridays_Call struct {
+// MockAccessor_ListFridays_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFridays'
+type MockAccessor_ListFridays_Call struct {
 	*mock.Call
 }
 
-// GetUpcomingFridays is a helper method to define mock.On call
-//   - daysAhead int
-func (_e *MockAccessor_Expecter) GetUpcomingFridays(daysAhead interface{}) *MockAccessor_GetUpcomingFridays_Call {
-	return &MockAccessor_GetUpcomingFridays_Call{Call: _e.mock.On("GetUpcomingFridays", daysAhead)}
+// ListFridays is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) ListFridays() *MockAccessor_ListFridays_Call {
+	return &MockAccessor_ListFridays_Call{Call: _e.mock.On("ListFridays")}
+}
+
+func (_c *MockAccessor_ListFridays_Call) Run(run func()) *MockAccessor_ListFridays_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAccessor_ListFridays_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_ListFridays_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_ListFridays_Call) RunAndReturn(run func() ([]Friday, error)) *MockAccessor_ListFridays_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFriends provides a mock function with no fields
+func (_m *MockAccessor) ListFriends() ([]Friend, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFriends")
+	}
+
+	var r0 []Friend
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]Friend, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []Friend); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Friend)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_ListFriends_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFriends'
+type MockAccessor_ListFriends_Call struct {
+	*mock.Call
+}
+
+// ListFriends is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) ListFriends() *MockAccessor_ListFriends_Call {
+	return &MockAccessor_ListFriends_Call{Call: _e.mock.On("ListFriends")}
+}
+
+func (_c *MockAccessor_ListFriends_Call) Run(run func()) *MockAccessor_ListFriends_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAccessor_ListFriends_Call) Return(_a0 []Friend, _a1 error) *MockAccessor_ListFriends_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_ListFriends_Call) RunAndReturn(run func() ([]Friend, error)) *MockAccessor_ListFriends_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSeries provides a mock function with no fields
+func (_m *MockAccessor) ListSeries() ([]Series, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSeries")
+	}
+
+	var r0 []Series
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]Series, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []Series); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Series)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_ListSeries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSeries'
+type MockAccessor_ListSeries_Call struct {
+	*mock.Call
+}
+
+// ListSeries is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) ListSeries() *MockAccessor_ListSeries_Call {
+	return &MockAccessor_ListSeries_Call{Call: _e.mock.On("ListSeries")}
+}
+
+func (_c *MockAccessor_ListSeries_Call) Run(run func()) *MockAccessor_ListSeries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockAccessor_ListSeries_Call) Return(_a0 []Series, _a1 error) *MockAccessor_ListSeries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAccessor_ListSeries_Call) RunAndReturn(run func() ([]Series, error)) *MockAccessor_ListSeries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListVenues provides a mock function with no fields
+func (_m *MockAccessor) ListVenues() ([]Venue, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVenues")
+	}
+
+	var r0 []Venue
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]Venue, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []Venue); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Venue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAccessor_ListVenues_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVenues'
+type MockAccessor_ListVenues_Call struct {
+	*mock.Call
+}
+
+// ListVenues is a helper method to define mock.On call
+func (_e *MockAccessor_Expecter) ListVenues() *MockAccessor_ListVenues_Call {
+	return &MockAccessor_ListVenues_Call{Call: _e.mock.On("ListVenues")}
 }
 
-func (_c *MockAccessor_GetUpcomingFridays_Call) Run(run func(daysAhead int)) *MockAccessor_GetUpcomingFridays_Call {
+func (_c *MockAccessor_ListVenues_Call) Run(run func()) *MockAccessor_ListVenues_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run()
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetUpcomingFridays_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_GetUpcomingFridays_Call {
+func (_c *MockAccessor_ListVenues_Call) Return(_a0 []Venue, _a1 error) *MockAccessor_ListVenues_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAccessor_GetUpcomingFridays_Call) RunAndReturn(run func(int) ([]Friday, error)) *MockAccessor_GetUpcomingFridays_Call {
+func (_c *MockAccessor_ListVenues_Call) RunAndReturn(run func() ([]Venue, error)) *MockAccessor_ListVenues_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUpcomingFridaysAfter provides a mock function with given fields: after, daysAhead
-func (_m *MockAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error) {
-	ret := _m.Called(after, daysAhead)
+// PromoteFromWaitlist provides a mock function with given fields: date
+func (_m *MockAccessor) PromoteFromWaitlist(date time.Time) ([]string, error) {
+	ret := _m.Called(date)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUpcomingFridaysAfter")
+		panic("no return value specified for PromoteFromWaitlist")
 	}
 
-	var r0 []Friday
+	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(time.Time, int) ([]Friday, error)); ok {
-		return rf(after, daysAhead)
+	if rf, ok := ret.Get(0).(func(time.Time) ([]string, error)); ok {
+		return rf(date)
 	}
-	if rf, ok := ret.Get(0).(func(time.Time, int) []Friday); ok {
-		r0 = rf(after, daysAhead)
+	if rf, ok := ret.Get(0).(func(time.Time) []string); ok {
+		r0 = rf(date)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]Friday)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
-		r1 = rf(after, daysAhead)
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(date)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -574,88 +1670,76 @@ func (_m *MockAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int)
 	return r0, r1
 }
 
-// MockAccessor_GetUpcomingFridaysAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUpcomingFridaysAfter'
-type MockAccessor_GetUpcomingFridaysAfter_Call struct {
+// MockAccessor_PromoteFromWaitlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PromoteFromWaitlist'
+type MockAccessor_PromoteFromWaitlist_Call struct {
 	*mock.Call
 }
 
-// GetUpcomingFridaysAfter is a helper method to define mock.On call
-//   - after time.Time
-//   - daysAhead int
-func (_e *MockAccessor_Expecter) GetUpcomingFridaysAfter(after interface{}, daysAhead interface{}) *MockAccessor_GetUpcomingFridaysAfter_Call {
-	return &MockAccessor_GetUpcomingFridaysAfter_Call{Call: _e.mock.On("GetUpcomingFridaysAfter", after, daysAhead)}
+// PromoteFromWaitlist is a helper method to define mock.On call
+//   - date time.Time
+func (_e *MockAccessor_Expecter) PromoteFromWaitlist(date interface{}) *MockAccessor_PromoteFromWaitlist_Call {
+	return &MockAccessor_PromoteFromWaitlist_Call{Call: _e.mock.On("PromoteFromWaitlist", date)}
 }
 
-func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) Run(run func(after time.Time, daysAhead int)) *MockAccessor_GetUpcomingFridaysAfter_Call {
+func (_c *MockAccessor_PromoteFromWaitlist_Call) Run(run func(date time.Time)) *MockAccessor_PromoteFromWaitlist_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(time.Time), args[1].(int))
+		run(args[0].(time.Time))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_GetUpcomingFridaysAfter_Call {
+func (_c *MockAccessor_PromoteFromWaitlist_Call) Return(_a0 []string, _a1 error) *MockAccessor_PromoteFromWaitlist_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockAccessor_GetUpcomingFridaysAfter_Call) RunAndReturn(run func(time.Time, int) ([]Friday, error)) *MockAccessor_GetUpcomingFridaysAfter_Call {
+func (_c *MockAccessor_PromoteFromWaitlist_Call) RunAndReturn(run func(time.Time) ([]string, error)) *MockAccessor_PromoteFromWaitlist_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListFridays provides a mock function with no fields
-func (_m *MockAccessor) ListFridays() ([]Friday, error) {
-	ret := _m.Called()
+// RemoveBlackout provides a mock function with given fields: day
+func (_m *MockAccessor) RemoveBlackout(day string) error {
+	ret := _m.Called(day)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListFridays")
-	}
-
-	var r0 []Friday
-	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]Friday, error)); ok {
-		return rf()
-	}
-	if rf, ok := ret.Get(0).(func() []Friday); ok {
-		r0 = rf()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]Friday)
-		}
+		panic("no return value specified for RemoveBlackout")
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(day)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockAccessor_ListFridays_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFridays'
-type MockAccessor_ListFridays_Call struct {
+// MockAccessor_RemoveBlackout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveBlackout'
+type MockAccessor_RemoveBlackout_Call struct {
 	*mock.Call
 }
 
-// ListFridays is a helper method to define mock.On call
-func (_e *MockAccessor_Expecter) ListFridays() *MockAccessor_ListFridays_Call {
-	return &MockAccessor_ListFridays_Call{Call: _e.mock.On("ListFridays")}
+// RemoveBlackout is a helper method to define mock.On call
+//   - day string
+func (_e *MockAccessor_Expecter) RemoveBlackout(day interface{}) *MockAccessor_RemoveBlackout_Call {
+	return &MockAccessor_RemoveBlackout_Call{Call: _e.mock.On("RemoveBlackout", day)}
 }
 
-func (_c *MockAccessor_ListFridays_Call) Run(run func()) *MockAccessor_ListFridays_Call {
+func (_c *MockAccessor_RemoveBlackout_Call) Run(run func(day string)) *MockAccessor_RemoveBlackout_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(string))
 	})
 	return _c
 }
 
-func (_c *MockAccessor_ListFridays_Call) Return(_a0 []Friday, _a1 error) *MockAccessor_ListFridays_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockAccessor_RemoveBlackout_Call) Return(_a0 error) *MockAccessor_RemoveBlackout_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockAccessor_ListFridays_Call) RunAndReturn(run func() ([]Friday, error)) *MockAccessor_ListFridays_Call {
+func (_c *MockAccessor_RemoveBlackout_Call) RunAndReturn(run func(string) error) *MockAccessor_RemoveBlackout_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -753,6 +1837,147 @@ func (_c *MockAccessor_RemoveFriendFromFriday_Call) RunAndReturn(run func(string
 	return _c
 }
 
+// RemovePlusOne provides a mock function with given fields: date, ID
+func (_m *MockAccessor) RemovePlusOne(date time.Time, ID int64) error {
+	ret := _m.Called(date, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemovePlusOne")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time, int64) error); ok {
+		r0 = rf(date, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_RemovePlusOne_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemovePlusOne'
+type MockAccessor_RemovePlusOne_Call struct {
+	*mock.Call
+}
+
+// RemovePlusOne is a helper method to define mock.On call
+//   - date time.Time
+//   - ID int64
+func (_e *MockAccessor_Expecter) RemovePlusOne(date interface{}, ID interface{}) *MockAccessor_RemovePlusOne_Call {
+	return &MockAccessor_RemovePlusOne_Call{Call: _e.mock.On("RemovePlusOne", date, ID)}
+}
+
+func (_c *MockAccessor_RemovePlusOne_Call) Run(run func(date time.Time, ID int64)) *MockAccessor_RemovePlusOne_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_RemovePlusOne_Call) Return(_a0 error) *MockAccessor_RemovePlusOne_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_RemovePlusOne_Call) RunAndReturn(run func(time.Time, int64) error) *MockAccessor_RemovePlusOne_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCalendarFeed provides a mock function with given fields: feed
+func (_m *MockAccessor) SetCalendarFeed(feed CalendarFeed) error {
+	ret := _m.Called(feed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCalendarFeed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(CalendarFeed) error); ok {
+		r0 = rf(feed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_SetCalendarFeed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCalendarFeed'
+type MockAccessor_SetCalendarFeed_Call struct {
+	*mock.Call
+}
+
+// SetCalendarFeed is a helper method to define mock.On call
+//   - feed CalendarFeed
+func (_e *MockAccessor_Expecter) SetCalendarFeed(feed interface{}) *MockAccessor_SetCalendarFeed_Call {
+	return &MockAccessor_SetCalendarFeed_Call{Call: _e.mock.On("SetCalendarFeed", feed)}
+}
+
+func (_c *MockAccessor_SetCalendarFeed_Call) Run(run func(feed CalendarFeed)) *MockAccessor_SetCalendarFeed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(CalendarFeed))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_SetCalendarFeed_Call) Return(_a0 error) *MockAccessor_SetCalendarFeed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_SetCalendarFeed_Call) RunAndReturn(run func(CalendarFeed) error) *MockAccessor_SetCalendarFeed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFridayHosts provides a mock function with given fields: date, host, coHosts
+func (_m *MockAccessor) SetFridayHosts(date time.Time, host string, coHosts []string) error {
+	ret := _m.Called(date, host, coHosts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFridayHosts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time, string, []string) error); ok {
+		r0 = rf(date, host, coHosts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_SetFridayHosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFridayHosts'
+type MockAccessor_SetFridayHosts_Call struct {
+	*mock.Call
+}
+
+// SetFridayHosts is a helper method to define mock.On call
+//   - date time.Time
+//   - host string
+//   - coHosts []string
+func (_e *MockAccessor_Expecter) SetFridayHosts(date interface{}, host interface{}, coHosts interface{}) *MockAccessor_SetFridayHosts_Call {
+	return &MockAccessor_SetFridayHosts_Call{Call: _e.mock.On("SetFridayHosts", date, host, coHosts)}
+}
+
+func (_c *MockAccessor_SetFridayHosts_Call) Run(run func(date time.Time, host string, coHosts []string)) *MockAccessor_SetFridayHosts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_SetFridayHosts_Call) Return(_a0 error) *MockAccessor_SetFridayHosts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_SetFridayHosts_Call) RunAndReturn(run func(time.Time, string, []string) error) *MockAccessor_SetFridayHosts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetPreferences provides a mock function with given fields: email, preferences
 func (_m *MockAccessor) SetPreferences(email string, preferences Preferences) error {
 	ret := _m.Called(email, preferences)
@@ -800,6 +2025,53 @@ func (_c *MockAccessor_SetPreferences_Call) RunAndReturn(run func(string, Prefer
 	return _c
 }
 
+// SetSetting provides a mock function with given fields: name, value
+func (_m *MockAccessor) SetSetting(name string, value string) error {
+	ret := _m.Called(name, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSetting")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_SetSetting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSetting'
+type MockAccessor_SetSetting_Call struct {
+	*mock.Call
+}
+
+// SetSetting is a helper method to define mock.On call
+//   - name string
+//   - value string
+func (_e *MockAccessor_Expecter) SetSetting(name interface{}, value interface{}) *MockAccessor_SetSetting_Call {
+	return &MockAccessor_SetSetting_Call{Call: _e.mock.On("SetSetting", name, value)}
+}
+
+func (_c *MockAccessor_SetSetting_Call) Run(run func(name string, value string)) *MockAccessor_SetSetting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_SetSetting_Call) Return(_a0 error) *MockAccessor_SetSetting_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_SetSetting_Call) RunAndReturn(run func(string, string) error) *MockAccessor_SetSetting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateFriday provides a mock function with given fields: friday
 func (_m *MockAccessor) UpdateFriday(friday Friday) error {
 	ret := _m.Called(friday)
@@ -846,6 +2118,98 @@ func (_c *MockAccessor_UpdateFriday_Call) RunAndReturn(run func(Friday) error) *
 	return _c
 }
 
+// UpdateSeries provides a mock function with given fields: series
+func (_m *MockAccessor) UpdateSeries(series Series) error {
+	ret := _m.Called(series)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSeries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Series) error); ok {
+		r0 = rf(series)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_UpdateSeries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSeries'
+type MockAccessor_UpdateSeries_Call struct {
+	*mock.Call
+}
+
+// UpdateSeries is a helper method to define mock.On call
+//   - series Series
+func (_e *MockAccessor_Expecter) UpdateSeries(series interface{}) *MockAccessor_UpdateSeries_Call {
+	return &MockAccessor_UpdateSeries_Call{Call: _e.mock.On("UpdateSeries", series)}
+}
+
+func (_c *MockAccessor_UpdateSeries_Call) Run(run func(series Series)) *MockAccessor_UpdateSeries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Series))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_UpdateSeries_Call) Return(_a0 error) *MockAccessor_UpdateSeries_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_UpdateSeries_Call) RunAndReturn(run func(Series) error) *MockAccessor_UpdateSeries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateVenue provides a mock function with given fields: venue
+func (_m *MockAccessor) UpdateVenue(venue Venue) error {
+	ret := _m.Called(venue)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVenue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Venue) error); ok {
+		r0 = rf(venue)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockAccessor_UpdateVenue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVenue'
+type MockAccessor_UpdateVenue_Call struct {
+	*mock.Call
+}
+
+// UpdateVenue is a helper method to define mock.On call
+//   - venue Venue
+func (_e *MockAccessor_Expecter) UpdateVenue(venue interface{}) *MockAccessor_UpdateVenue_Call {
+	return &MockAccessor_UpdateVenue_Call{Call: _e.mock.On("UpdateVenue", venue)}
+}
+
+func (_c *MockAccessor_UpdateVenue_Call) Run(run func(venue Venue)) *MockAccessor_UpdateVenue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Venue))
+	})
+	return _c
+}
+
+func (_c *MockAccessor_UpdateVenue_Call) Return(_a0 error) *MockAccessor_UpdateVenue_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockAccessor_UpdateVenue_Call) RunAndReturn(run func(Venue) error) *MockAccessor_UpdateVenue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockAccessor creates a new instance of MockAccessor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockAccessor(t interface {
@@ -262,17 +262,17 @@ func (_c *MockCalendar_GetEvent_Call) RunAndReturn(run func(string) (CalendarEve
 	return _c
 }
 
-// InviteToEvent provides a mock function with given fields: eventID, email, name
-func (_m *MockCalendar) InviteToEvent(eventID string, email string, name string) error {
-	ret := _m.Called(eventID, email, name)
+// InviteToEvent provides a mock function with given fields: eventID, email, name, status
+func (_m *MockCalendar) InviteToEvent(eventID string, email string, name string, status string) error {
+	ret := _m.Called(eventID, email, name, status)
 
 	if len(ret) == 0 {
 		panic("no return value specified for InviteToEvent")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(eventID, email, name)
+	if rf, ok := ret.Get(0).(func(string, string, string, string) error); ok {
+		r0 = rf(eventID, email, name, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -289,13 +289,14 @@ type MockCalendar_InviteToEvent_Call struct {
 //   - eventID string
 //   - email string
 //   - name string
-func (_e *MockCalendar_Expecter) InviteToEvent(eventID interface{}, email interface{}, name interface{}) *MockCalendar_InviteToEvent_Call {
-	return &MockCalendar_InviteToEvent_Call{Call: _e.mock.On("InviteToEvent", eventID, email, name)}
+//   - status string
+func (_e *MockCalendar_Expecter) InviteToEvent(eventID interface{}, email interface{}, name interface{}, status interface{}) *MockCalendar_InviteToEvent_Call {
+	return &MockCalendar_InviteToEvent_Call{Call: _e.mock.On("InviteToEvent", eventID, email, name, status)}
 }
 
-func (_c *MockCalendar_InviteToEvent_Call) Run(run func(eventID string, email string, name string)) *MockCalendar_InviteToEvent_Call {
+func (_c *MockCalendar_InviteToEvent_Call) Run(run func(eventID string, email string, name string, status string)) *MockCalendar_InviteToEvent_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(string))
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string))
 	})
 	return _c
 }
@@ -305,7 +306,7 @@ func (_c *MockCalendar_InviteToEvent_Call) Return(_a0 error) *MockCalendar_Invit
 	return _c
 }
 
-func (_c *MockCalendar_InviteToEvent_Call) RunAndReturn(run func(string, string, string) error) *MockCalendar_InviteToEvent_Call {
+func (_c *MockCalendar_InviteToEvent_Call) RunAndReturn(run func(string, string, string, string) error) *MockCalendar_InviteToEvent_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -428,6 +429,52 @@ func (_c *MockCalendar_ListEventsBetween_Call) RunAndReturn(run func(time.Time,
 	return _c
 }
 
+// UpdateEvent provides a mock function with given fields: _a0
+func (_m *MockCalendar) UpdateEvent(_a0 CalendarEvent) error {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(CalendarEvent) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCalendar_UpdateEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateEvent'
+type MockCalendar_UpdateEvent_Call struct {
+	*mock.Call
+}
+
+// UpdateEvent is a helper method to define mock.On call
+//   - _a0 CalendarEvent
+func (_e *MockCalendar_Expecter) UpdateEvent(_a0 interface{}) *MockCalendar_UpdateEvent_Call {
+	return &MockCalendar_UpdateEvent_Call{Call: _e.mock.On("UpdateEvent", _a0)}
+}
+
+func (_c *MockCalendar_UpdateEvent_Call) Run(run func(_a0 CalendarEvent)) *MockCalendar_UpdateEvent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(CalendarEvent))
+	})
+	return _c
+}
+
+func (_c *MockCalendar_UpdateEvent_Call) Return(_a0 error) *MockCalendar_UpdateEvent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCalendar_UpdateEvent_Call) RunAndReturn(run func(CalendarEvent) error) *MockCalendar_UpdateEvent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockCalendar creates a new instance of MockCalendar. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockCalendar(t interface {
@@ -2,104 +2,484 @@ package pizza
 
 import (
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
+	"text/tabwriter"
 )
 
-var AllPatches []func(*SQLAccessor) error
+// SQLiteMigrations are the schema changes of the SQLite database, oldest first. Never change a migration once it has
+// been released, add a new one instead.
+var SQLiteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "unique fridays",
+		Up: `CREATE TABLE IF NOT EXISTS fridays_new (start_time datetime NOT NULL PRIMARY KEY);
+			INSERT INTO fridays_new SELECT start_time FROM fridays;
+			DROP TABLE fridays;
+			ALTER TABLE fridays_new RENAME TO fridays;`,
+	},
+	{
+		Version: 2,
+		Name:    "versions",
+		Up:      `CREATE TABLE IF NOT EXISTS app_versions (name text NOT NULL PRIMARY KEY, version int NOT NULL);`,
+	},
+	{
+		Version: 3,
+		Name:    "friday details",
+		Up: `ALTER TABLE fridays ADD COLUMN invited_group text;
+			ALTER TABLE fridays ADD COLUMN details text;`,
+		Down: `ALTER TABLE fridays DROP COLUMN invited_group;
+			ALTER TABLE fridays DROP COLUMN details;`,
+	},
+	{
+		Version: 4,
+		Name:    "preferences",
+		Up:      `ALTER TABLE friends ADD COLUMN preferences text default "{}";`,
+		Down:    `ALTER TABLE friends DROP COLUMN preferences;`,
+	},
+	{
+		Version: 5,
+		Name:    "friday guests",
+		Up: `ALTER TABLE fridays ADD COLUMN invited text default "[]";
+			ALTER TABLE fridays ADD COLUMN max_guests int default 10;
+			ALTER TABLE fridays ADD COLUMN enabled bool default true;`,
+		Down: `ALTER TABLE fridays DROP COLUMN invited;
+			ALTER TABLE fridays DROP COLUMN max_guests;
+			ALTER TABLE fridays DROP COLUMN enabled;`,
+	},
+	{
+		Version: 6,
+		Name:    "friend ids",
+		Up: `CREATE TABLE IF NOT EXISTS friends_new
+				(id integer PRIMARY KEY AUTOINCREMENT,
+				 email text NOT NULL UNIQUE,
+				 name text,
+				 preferences text default "{}");
+			INSERT INTO friends_new (email, name, preferences) SELECT * FROM friends;
+			DROP TABLE friends;
+			ALTER TABLE friends_new RENAME TO friends;`,
+	},
+	{
+		Version: 7,
+		Name:    "rsvps",
+		// move the invited JSON list into its own table
+		Up: `CREATE TABLE IF NOT EXISTS rsvps
+				(friday     datetime NOT NULL REFERENCES fridays(start_time),
+				 friend_id  integer NOT NULL REFERENCES friends(id),
+				 status     text NOT NULL default 'accepted',
+				 created_at datetime NOT NULL default CURRENT_TIMESTAMP,
+				 created_by integer REFERENCES friends(id),
+				 PRIMARY KEY (friday, friend_id));
+			INSERT INTO friends (email)
+				SELECT DISTINCT invited.value FROM fridays, json_each(fridays.invited) AS invited WHERE true
+				ON CONFLICT (email) DO NOTHING;
+			INSERT INTO rsvps (friday, friend_id, status)
+				SELECT fridays.start_time, friends.id, 'accepted'
+				FROM fridays, json_each(fridays.invited) AS invited
+				JOIN friends ON friends.email = invited.value
+				ORDER BY fridays.start_time, invited.key;
+			ALTER TABLE fridays DROP COLUMN invited;`,
+		Down: `ALTER TABLE fridays ADD COLUMN invited text default "[]";
+			UPDATE fridays SET invited = (
+				SELECT json_group_array(friends.email ORDER BY rsvps.created_at, rsvps.rowid) FROM rsvps
+				JOIN friends ON friends.id = rsvps.friend_id
+				WHERE rsvps.friday = fridays.start_time AND rsvps.status = 'accepted');
+			DROP TABLE rsvps;`,
+	},
+	{
+		Version: 8,
+		Name:    "audit log",
+		Up: `CREATE TABLE IF NOT EXISTS audit_log
+				(id           integer PRIMARY KEY AUTOINCREMENT,
+				 created_at   datetime NOT NULL,
+				 actor        text NOT NULL,
+				 action       text NOT NULL,
+				 friday       datetime,
+				 target       text NOT NULL,
+				 before_value text NOT NULL,
+				 after_value  text NOT NULL,
+				 source       text NOT NULL);`,
+		Down: `DROP TABLE audit_log;`,
+	},
+	{
+		Version: 9,
+		Name:    "friday times",
+		Up: `ALTER TABLE fridays ADD COLUMN starts_at datetime;
+			ALTER TABLE fridays ADD COLUMN duration_minutes int;`,
+		Down: `ALTER TABLE fridays DROP COLUMN starts_at;
+			ALTER TABLE fridays DROP COLUMN duration_minutes;`,
+	},
+	{
+		Version: 10,
+		Name:    "settings",
+		Up: `CREATE TABLE IF NOT EXISTS settings
+				(name  text NOT NULL PRIMARY KEY,
+				 value text NOT NULL);`,
+		Down: `DROP TABLE settings;`,
+	},
+	{
+		Version: 11,
+		Name:    "series",
+		// series_id has no foreign key because sqlite cannot drop a column that has one
+		Up: `CREATE TABLE IF NOT EXISTS series
+				(id            integer PRIMARY KEY AUTOINCREMENT,
+				 name          text NOT NULL UNIQUE,
+				 description   text NOT NULL default '',
+				 invited_group text,
+				 max_guests    int NOT NULL default 10,
+				 recurrence    text NOT NULL default '',
+				 calendar_id   text NOT NULL default '');
+			ALTER TABLE fridays ADD COLUMN series_id integer;`,
+		Down: `ALTER TABLE fridays DROP COLUMN series_id;
+			DROP TABLE series;`,
+	},
+	{
+		Version: 12,
+		Name:    "blackouts",
+		Up: `CREATE TABLE IF NOT EXISTS blackouts
+				(day    text NOT NULL PRIMARY KEY,
+				 reason text NOT NULL default '');`,
+		Down: `DROP TABLE blackouts;`,
+	},
+	{
+		Version: 13,
+		Name:    "rsvp policy",
+		Up: `ALTER TABLE fridays ADD COLUMN rsvp_opens_minutes int;
+			ALTER TABLE fridays ADD COLUMN rsvp_closes_minutes int;
+			ALTER TABLE fridays ADD COLUMN decline_cutoff_minutes int;`,
+		Down: `ALTER TABLE fridays DROP COLUMN rsvp_opens_minutes;
+			ALTER TABLE fridays DROP COLUMN rsvp_closes_minutes;
+			ALTER TABLE fridays DROP COLUMN decline_cutoff_minutes;`,
+	},
+	{
+		Version: 14,
+		Name:    "venues",
+		// venue_id has no foreign key because sqlite cannot drop a column that has one
+		Up: `CREATE TABLE IF NOT EXISTS venues
+				(id       integer PRIMARY KEY AUTOINCREMENT,
+				 name     text NOT NULL UNIQUE,
+				 address  text NOT NULL default '',
+				 capacity int NOT NULL default 0,
+				 notes    text NOT NULL default '',
+				 ovens    int NOT NULL default 0);
+			ALTER TABLE fridays ADD COLUMN venue_id integer;`,
+		Down: `ALTER TABLE fridays DROP COLUMN venue_id;
+			DROP TABLE venues;`,
+	},
+	{
+		Version: 15,
+		Name:    "friday hosts",
+		Up: `CREATE TABLE IF NOT EXISTS friday_hosts
+				(friday    datetime NOT NULL REFERENCES fridays(start_time),
+				 friend_id integer NOT NULL REFERENCES friends(id),
+				 role      text NOT NULL default 'cohost',
+				 PRIMARY KEY (friday, friend_id));`,
+		Down: `DROP TABLE friday_hosts;`,
+	},
+	{
+		Version: 16,
+		Name:    "plus ones",
+		Up: `CREATE TABLE IF NOT EXISTS plus_ones
+				(id         integer PRIMARY KEY AUTOINCREMENT,
+				 friday     datetime NOT NULL REFERENCES fridays(start_time),
+				 name       text NOT NULL,
+				 email      text NOT NULL default '',
+				 sponsor_id integer NOT NULL REFERENCES friends(id),
+				 created_at datetime NOT NULL);`,
+		Down: `DROP TABLE plus_ones;`,
+	},
+	{
+		Version: 17,
+		Name:    "calendar feeds",
+		Up: `ALTER TABLE fridays ADD COLUMN sequence int NOT NULL default 0;
+			CREATE TABLE IF NOT EXISTS calendar_feeds
+				(friend_id     integer PRIMARY KEY REFERENCES friends(id),
+				 token         text NOT NULL UNIQUE,
+				 member_groups text NOT NULL default '[]',
+				 open_fridays  bool NOT NULL default false);`,
+		Down: `DROP TABLE calendar_feeds;
+			ALTER TABLE fridays DROP COLUMN sequence;`,
+	},
+}
+
+// PostgresMigrations are the schema changes of the PostgreSQL database, oldest first
+var PostgresMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "rsvps",
+		// move the invited JSON list into its own table
+		Up: `CREATE TABLE IF NOT EXISTS rsvps
+				(friday     timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+				 friend_id  int NOT NULL REFERENCES friends(id),
+				 status     text NOT NULL DEFAULT 'accepted',
+				 created_at timestamptz NOT NULL DEFAULT now(),
+				 created_by int REFERENCES friends(id),
+				 PRIMARY KEY (friday, friend_id));
+			INSERT INTO friends (email)
+				SELECT DISTINCT jsonb_array_elements_text(invited) FROM fridays
+				ON CONFLICT (email) DO NOTHING;
+			INSERT INTO rsvps (friday, friend_id, status, created_at)
+				SELECT fridays.start_time, friends.id, 'accepted', now() + invited.ord * interval '1 microsecond'
+				FROM fridays
+				CROSS JOIN LATERAL jsonb_array_elements_text(fridays.invited) WITH ORDINALITY AS invited(email, ord)
+				JOIN friends ON friends.email = invited.email
+				ON CONFLICT DO NOTHING;
+			ALTER TABLE fridays DROP COLUMN invited;`,
+		Down: `ALTER TABLE fridays ADD COLUMN invited jsonb DEFAULT '[]';
+			UPDATE fridays SET invited = COALESCE((
+				SELECT jsonb_agg(friends.email ORDER BY rsvps.created_at, rsvps.friend_id) FROM rsvps
+				JOIN friends ON friends.id = rsvps.friend_id
+				WHERE rsvps.friday = fridays.start_time AND rsvps.status = 'accepted'), '[]'::jsonb);
+			DROP TABLE rsvps;`,
+	},
+	{
+		Version: 2,
+		Name:    "audit log",
+		Up: `CREATE TABLE IF NOT EXISTS audit_log
+				(id           bigserial PRIMARY KEY,
+				 created_at   timestamptz NOT NULL,
+				 actor        text NOT NULL,
+				 action       text NOT NULL,
+				 friday       timestamptz,
+				 target       text NOT NULL,
+				 before_value text NOT NULL,
+				 after_value  text NOT NULL,
+				 source       text NOT NULL);`,
+		Down: `DROP TABLE audit_log;`,
+	},
+	{
+		Version: 3,
+		Name:    "friday times",
+		Up: `ALTER TABLE fridays ADD COLUMN starts_at timestamptz;
+			ALTER TABLE fridays ADD COLUMN duration_minutes int;`,
+		Down: `ALTER TABLE fridays DROP COLUMN starts_at;
+			ALTER TABLE fridays DROP COLUMN duration_minutes;`,
+	},
+	{
+		Version: 4,
+		Name:    "settings",
+		Up: `CREATE TABLE IF NOT EXISTS settings
+				(name  text NOT NULL PRIMARY KEY,
+				 value text NOT NULL);`,
+		Down: `DROP TABLE settings;`,
+	},
+	{
+		Version: 5,
+		Name:    "series",
+		Up: `CREATE TABLE IF NOT EXISTS series
+				(id            serial PRIMARY KEY,
+				 name          text NOT NULL UNIQUE,
+				 description   text NOT NULL DEFAULT '',
+				 invited_group text,
+				 max_guests    int NOT NULL DEFAULT 10,
+				 recurrence    text NOT NULL DEFAULT '',
+				 calendar_id   text NOT NULL DEFAULT '');
+			ALTER TABLE fridays ADD COLUMN series_id int REFERENCES series(id);`,
+		Down: `ALTER TABLE fridays DROP COLUMN series_id;
+			DROP TABLE series;`,
+	},
+	{
+		Version: 6,
+		Name:    "blackouts",
+		Up: `CREATE TABLE IF NOT EXISTS blackouts
+				(day    text NOT NULL PRIMARY KEY,
+				 reason text NOT NULL DEFAULT '');`,
+		Down: `DROP TABLE blackouts;`,
+	},
+	{
+		Version: 7,
+		Name:    "rsvp policy",
+		Up: `ALTER TABLE fridays ADD COLUMN rsvp_opens_minutes int;
+			ALTER TABLE fridays ADD COLUMN rsvp_closes_minutes int;
+			ALTER TABLE fridays ADD COLUMN decline_cutoff_minutes int;`,
+		Down: `ALTER TABLE fridays DROP COLUMN rsvp_opens_minutes;
+			ALTER TABLE fridays DROP COLUMN rsvp_closes_minutes;
+			ALTER TABLE fridays DROP COLUMN decline_cutoff_minutes;`,
+	},
+	{
+		Version: 8,
+		Name:    "venues",
+		Up: `CREATE TABLE IF NOT EXISTS venues
+				(id       serial PRIMARY KEY,
+				 name     text NOT NULL UNIQUE,
+				 address  text NOT NULL DEFAULT '',
+				 capacity int NOT NULL DEFAULT 0,
+				 notes    text NOT NULL DEFAULT '',
+				 ovens    int NOT NULL DEFAULT 0);
+			ALTER TABLE fridays ADD COLUMN venue_id int REFERENCES venues(id);`,
+		Down: `ALTER TABLE fridays DROP COLUMN venue_id;
+			DROP TABLE venues;`,
+	},
+	{
+		Version: 9,
+		Name:    "friday hosts",
+		Up: `CREATE TABLE IF NOT EXISTS friday_hosts
+				(friday    timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+				 friend_id int NOT NULL REFERENCES friends(id),
+				 role      text NOT NULL DEFAULT 'cohost',
+				 PRIMARY KEY (friday, friend_id));`,
+		Down: `DROP TABLE friday_hosts;`,
+	},
+	{
+		Version: 10,
+		Name:    "plus ones",
+		Up: `CREATE TABLE IF NOT EXISTS plus_ones
+				(id         serial PRIMARY KEY,
+				 friday     timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+				 name       text NOT NULL,
+				 email      text NOT NULL DEFAULT '',
+				 sponsor_id int NOT NULL REFERENCES friends(id),
+				 created_at timestamptz NOT NULL DEFAULT now());`,
+		Down: `DROP TABLE plus_ones;`,
+	},
+	{
+		Version: 11,
+		Name:    "calendar feeds",
+		Up: `ALTER TABLE fridays ADD COLUMN IF NOT EXISTS sequence int NOT NULL DEFAULT 0;
+			CREATE TABLE IF NOT EXISTS calendar_feeds
+				(friend_id     int PRIMARY KEY REFERENCES friends(id),
+				 token         text NOT NULL UNIQUE,
+				 member_groups jsonb NOT NULL DEFAULT '[]',
+				 open_fridays  bool NOT NULL DEFAULT false);`,
+		Down: `DROP TABLE calendar_feeds;
+			ALTER TABLE fridays DROP COLUMN sequence;`,
+	},
+}
+
+const patchUsage = `usage: rsvp.pizza patch [-init] [-drop -confirm-drop] [-dry-run] [status | up | down | to N]
+
+  status  list the migrations and whether they have been applied
+  up      apply all pending migrations
+  down    revert the newest applied migration
+  to N    apply or revert migrations until the schema is at version N
+`
 
 func Patch(args []string) {
 	fs := flag.NewFlagSet("patch", flag.ExitOnError)
 	isInit := fs.Bool("init", false, "initialize all tables")
-	_ = fs.Bool("drop", false, "drop all tables")
-	n := fs.Int("n", 0, "patch number")
+	isDrop := fs.Bool("drop", false, "drop all tables, which deletes every friend, friday and answer")
+	confirmDrop := fs.Bool("confirm-drop", false, "confirm -drop")
+	isDryRun := fs.Bool("dry-run", false, "print the SQL of the migrations instead of running it")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), patchUsage)
+		fs.PrintDefaults()
+	}
+	// allow the flags both before and after the command
 	fs.Parse(args)
+	command := make([]string, 0)
+	for fs.NArg() > 0 {
+		command = append(command, fs.Arg(0))
+		fs.Parse(fs.Args()[1:])
+	}
+
+	if *isDrop && (!*confirmDrop || *isDryRun) {
+		fmt.Fprintln(fs.Output(), "-drop deletes all data and needs -confirm-drop, and cannot be dry run")
+		os.Exit(2)
+	}
 
 	config := LoadConfigEnv()
-	var accessor *SQLAccessor
-	var err error
-	accessor, err = NewSQLAccessor(config.DBFile, true)
-	if err != nil {
-		slog.Error("sql accessor init failure", "error", err)
-		os.Exit(1)
+	var migrator *Migrator
+	var createTables, dropTables func() error
+	if len(config.PostgresDSN) > 0 {
+		accessor, err := NewPostgresAccessor(config.PostgresDSN, true)
+		if err != nil {
+			slog.Error("postgres accessor init failure", "error", err)
+			os.Exit(1)
+		}
+		defer accessor.Close()
+		migrator = accessor.Migrator()
+		createTables = accessor.CreateTables
+		dropTables = accessor.DropTables
+	} else {
+		accessor, err := NewSQLAccessor(config.DBFile, true)
+		if err != nil {
+			slog.Error("sql accessor init failure", "error", err)
+			os.Exit(1)
+		}
+		defer accessor.Close()
+		migrator = accessor.Migrator()
+		createTables = accessor.CreateTables
+		dropTables = accessor.DropTables
+	}
+	if *isDryRun {
+		migrator.DryRun(os.Stdout)
+	}
+
+	if *isDrop {
+		if err := dropTables(); err != nil {
+			slog.Error("drop tables failed", "error", err)
+			os.Exit(1)
+		}
 	}
 
 	if *isInit {
-		accessor.CreateTables()
+		if err := createTables(); err != nil {
+			slog.Error("create tables failed", "error", err)
+			os.Exit(1)
+		}
 	}
 
-	switch *n {
-	case 1:
-		err = Patch001(accessor)
-	case 2:
-		err = Patch002(accessor)
-	case 3:
-		err = Patch003(accessor)
-	case 4:
-		err = Patch004(accessor)
-	case 5:
-		err = Patch005(accessor)
-	case 6:
-		err = Patch006(accessor)
+	if len(command) == 0 {
+		if !*isInit && !*isDrop {
+			fs.Usage()
+			os.Exit(2)
+		}
+		return
 	}
 
-	if err != nil {
-		slog.Error("patch failed", "n", *n, "error", err)
+	var err error
+	switch command[0] {
+	case "status":
+		err = printMigrationStatus(migrator)
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		if len(command) < 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		var target int
+		if target, err = strconv.Atoi(command[1]); err == nil {
+			err = migrator.To(target)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
 	}
-}
 
-func Patch001(a *SQLAccessor) error {
-	// fridays should be unique
-	stmt := `CREATE TABLE IF NOT EXISTS fridays_new (start_time datetime NOT NULL PRIMARY KEY);
-			INSERT INTO fridays_new SELECT start_time FROM fridays;
-			DROP TABLE fridays;
-			ALTER TABLE fridays_new RENAME TO fridays`
-	_, err := a.db.Exec(stmt)
-	return err
+	if err != nil {
+		slog.Error("patch failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+	if command[0] != "status" && !*isDryRun {
+		version, _ := migrator.Version()
+		slog.Info("patch complete", "version", version)
+	}
 }
 
-func Patch002(a *SQLAccessor) error {
-	// create the versions table
-	stmt := `CREATE TABLE IF NOT EXISTS app_versions (name text NOT NULL PRIMARY KEY, version int NOT NULL)`
-	_, err := a.db.Exec(stmt)
+func printMigrationStatus(migrator *Migrator) error {
+	status, err := migrator.Status()
 	if err != nil {
 		return err
 	}
-	stmt = `INSERT INTO app_versions (name, version) VALUES ('schema', 2)`
-	_, err = a.db.Exec(stmt)
-	return err
-}
-
-func Patch003(a *SQLAccessor) error {
-	stmt := `ALTER TABLE fridays ADD COLUMN invited_group text;
-			ALTER TABLE fridays ADD COLUMN details text;`
-	_, err := a.db.Exec(stmt)
-	return err
-}
-
-func Patch004(a *SQLAccessor) error {
-	stmt := `ALTER TABLE friends ADD COLUMN preferences text default "{}";`
-	_, err := a.db.Exec(stmt)
-	return err
-}
-
-func Patch005(a *SQLAccessor) error {
-	stmt := `ALTER TABLE fridays ADD COLUMN invited text default "[]";
-			ALTER TABLE fridays ADD COLUMN max_guests int default 10;
-			ALTER TABLE fridays ADD COLUMN enabled bool default true;`
-	_, err := a.db.Exec(stmt)
-	return err
-}
-
-func Patch006(a *SQLAccessor) error {
-	stmt := `CREATE TABLE IF NOT EXISTS friends_new 
-				(id integer PRIMARY KEY AUTOINCREMENT,
-				 email text NOT NULL UNIQUE,
-				 name text,
-				 preferences text default "{}");
-			INSERT INTO friends_new (email, name, preferences) SELECT * FROM friends;
-			DROP TABLE friends;
-			ALTER TABLE friends_new RENAME TO friends;`
-	_, err := a.db.Exec(stmt)
-	return err
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tREVERSIBLE")
+	for _, s := range status {
+		state := "pending"
+		if s.Modified() {
+			state = "modified"
+		} else if s.Applied {
+			state = "applied"
+		}
+		reversible := "no"
+		if len(s.Down) > 0 {
+			reversible = "yes"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, reversible)
+	}
+	return w.Flush()
 }
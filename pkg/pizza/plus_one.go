@@ -0,0 +1,136 @@
+package pizza
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// IndexPlusOne is a guest without an account on the friday card, tagged with the name of their sponsor
+type IndexPlusOne struct {
+	ID      int64
+	Name    string
+	Sponsor string
+	// CanRemove is whether the viewer brought them
+	CanRemove bool
+}
+
+// indexPlusOnes shows the plus-ones of the friday to the viewer
+func (s *Server) indexPlusOnes(friday Friday, claims *TokenClaims) []IndexPlusOne {
+	plusOnes := make([]IndexPlusOne, 0, len(friday.PlusOnes))
+	sponsors := make(map[string]string)
+	for _, plusOne := range friday.PlusOnes {
+		if _, ok := sponsors[plusOne.Sponsor]; !ok {
+			sponsors[plusOne.Sponsor] = plusOne.Sponsor
+			if friend, err := s.store.GetFriendByEmail(plusOne.Sponsor); err == nil && len(friend.Name) > 0 {
+				sponsors[plusOne.Sponsor] = friend.Name
+			}
+		}
+		plusOnes = append(plusOnes, IndexPlusOne{
+			ID:        plusOne.ID,
+			Name:      plusOne.Name,
+			Sponsor:   sponsors[plusOne.Sponsor],
+			CanRemove: plusOne.Sponsor == claims.Email,
+		})
+	}
+	return plusOnes
+}
+
+// plusOneTarget is how the plus-one is named in the audit log
+func plusOneTarget(plusOne PlusOne) string {
+	if len(plusOne.Email) > 0 {
+		return plusOne.Email
+	}
+	return plusOne.Name
+}
+
+// rsvpPlusOne brings a guest without an account to the fridays as the plus-one of the user, who must be coming
+// themselves. The plus-one gets a calendar invite when they have an email.
+func (s *Server) rsvpPlusOne(w http.ResponseWriter, claims *TokenClaims, dates []string, plusOne PlusOne) {
+	slog.Info("plus-one request", "name", plusOne.Name, "dates", dates, "by", claims.Email)
+	for _, d := range dates {
+		fridayTime, err := s.parseFridayTime(d)
+		if err != nil {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+		friday, err := s.loadFriday(fridayTime, claims)
+		if err != nil {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+		if _, blackedOut := s.isBlackedOut(*friday); blackedOut {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+		if err = s.rsvpPolicy(*friday).CheckRSVP(*friday, time.Now()); err != nil {
+			w.Write(getToast(s.rsvpNotice(*friday, err, s.displayLocation(claims.Email))))
+			return
+		}
+		if !slices.Contains(friday.Guests, plusOne.Sponsor) {
+			w.Write(getToast("RSVP before bringing a guest"))
+			return
+		}
+
+		if plusOne.ID, err = s.store.AddPlusOne(friday.Date, plusOne); err == ErrFridayIsFull {
+			w.Write(getToast("friday is full"))
+			return
+		} else if err != nil {
+			slog.Error("failed to add plus-one", "error", err, "name", plusOne.Name, "friday", d)
+			s.executeTemplate(w, "RSVPError", nil)
+			return
+		}
+		if len(plusOne.Email) > 0 {
+			if err = s.inviteToEvent(d, *friday, plusOne.Email, plusOne.Name, RSVPAccepted); err != nil {
+				s.executeTemplate(w, "RSVPError", nil)
+				return
+			}
+		}
+		s.audit(AuditEntry{
+			Actor:  claims.Email,
+			Action: "plus-one",
+			Friday: friday.Date,
+			Target: plusOneTarget(plusOne),
+			After:  RSVPAccepted,
+			Source: AuditSourceWeb,
+		})
+	}
+
+	s.executeTemplate(w, "RSVPSuccess", nil)
+}
+
+// removePlusOne takes the plus-one off the friday and declines their calendar invite
+func (s *Server) removePlusOne(friday Friday, plusOne PlusOne, actor, source string) error {
+	if err := s.store.RemovePlusOne(friday.Date, plusOne.ID); err != nil {
+		slog.Error("failed to remove plus-one", "error", err, "ID", plusOne.ID, "friday", friday.Date)
+		return err
+	}
+	if len(plusOne.Email) > 0 && s.config.Calendar.Enabled {
+		ID := strconv.FormatInt(friday.Date.Unix(), 10)
+		if err := s.calendarFor(friday).DeclineEvent(ID, plusOne.Email); err != nil {
+			slog.Warn("failed to decline calendar invite of plus-one", "error", err, "email", plusOne.Email,
+				"friday", ID)
+		}
+	}
+	s.audit(AuditEntry{
+		Actor:  actor,
+		Action: "remove",
+		Friday: friday.Date,
+		Target: plusOneTarget(plusOne),
+		Before: RSVPAccepted,
+		After:  RSVPDeclined,
+		Source: source,
+	})
+	return nil
+}
+
+// removePlusOnesOf takes everyone that the sponsor brought off the friday, since they are not coming without them
+func (s *Server) removePlusOnesOf(friday Friday, sponsor, actor, source string) {
+	for _, plusOne := range friday.PlusOnes {
+		if plusOne.Sponsor == sponsor {
+			s.removePlusOne(friday, plusOne, actor, source)
+		}
+	}
+}
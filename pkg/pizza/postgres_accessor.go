@@ -0,0 +1,775 @@
+package pizza
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// pgFridayEmails selects the emails of a friday's RSVPs with the given status as a JSON array, in the order they
+// were made
+func pgFridayEmails(status string) string {
+	return `COALESCE((SELECT jsonb_agg(friends.email ORDER BY rsvps.created_at, rsvps.friend_id) FROM rsvps
+		JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = fridays.start_time AND rsvps.status = '` + status + `'), '[]'::jsonb)`
+}
+
+func pgFridayHosts(role string) string {
+	return `COALESCE((SELECT jsonb_agg(friends.email ORDER BY friends.email) FROM friday_hosts
+		JOIN friends ON friends.id = friday_hosts.friend_id
+		WHERE friday_hosts.friday = fridays.start_time AND friday_hosts.role = '` + role + `'), '[]'::jsonb)`
+}
+
+// pgFridayPlusOnes selects a friday's plus-ones as a JSON array of objects, in the order they were added
+var pgFridayPlusOnes = `COALESCE((SELECT jsonb_agg(jsonb_build_object('id', plus_ones.id, 'name', plus_ones.name,
+		'email', plus_ones.email, 'sponsor', friends.email) ORDER BY plus_ones.id) FROM plus_ones
+		JOIN friends ON friends.id = plus_ones.sponsor_id
+		WHERE plus_ones.friday = fridays.start_time), '[]'::jsonb)`
+
+// pgGuestCount counts the accepted RSVPs and plus-ones of the friday given as the first parameter
+const pgGuestCount = `(SELECT COUNT(*) FROM rsvps WHERE friday = $1 AND status = 'accepted') +
+	(SELECT COUNT(*) FROM plus_ones WHERE friday = $1)`
+
+// pgFridayColumns are the columns of the fridays table read by scanFriday
+var pgFridayColumns = "start_time, invited_group, details, " + pgFridayEmails(RSVPAccepted) + ", " +
+	pgFridayEmails(RSVPWaitlisted) + ", max_guests, enabled, starts_at, duration_minutes, series_id, " +
+	"rsvp_opens_minutes, rsvp_closes_minutes, decline_cutoff_minutes, venue_id, " + pgFridayHosts(FridayHostOwner) +
+	", " + pgFridayHosts(FridayCoHost) + ", " + pgFridayPlusOnes + ", " + pgFridayEmails(RSVPTentative) + ", sequence"
+
+type PostgresAccessor struct {
+	db *sql.DB
+	// Location is the timezone that ListFridays returns dates in
+	Location *time.Location
+}
+
+func NewPostgresAccessor(dsn string, skipPatch bool) (*PostgresAccessor, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	a := &PostgresAccessor{db: db, Location: defaultLocation()}
+	if skipPatch {
+		return a, nil
+	}
+	return a, a.PatchTables()
+}
+
+func (a *PostgresAccessor) Close() {
+	a.db.Close()
+}
+
+func (a *PostgresAccessor) CreateTables() error {
+	stmt := `CREATE TABLE friends (
+		id          serial PRIMARY KEY,
+		email       text NOT NULL UNIQUE,
+		name        text,
+		preferences text DEFAULT '{}'
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE series (
+		id            serial PRIMARY KEY,
+		name          text NOT NULL UNIQUE,
+		description   text NOT NULL DEFAULT '',
+		invited_group text,
+		max_guests    int NOT NULL DEFAULT 10,
+		recurrence    text NOT NULL DEFAULT '',
+		calendar_id   text NOT NULL DEFAULT ''
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE venues (
+		id       serial PRIMARY KEY,
+		name     text NOT NULL UNIQUE,
+		address  text NOT NULL DEFAULT '',
+		capacity int NOT NULL DEFAULT 0,
+		notes    text NOT NULL DEFAULT '',
+		ovens    int NOT NULL DEFAULT 0
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE fridays (
+		start_time             timestamptz NOT NULL PRIMARY KEY,
+		invited_group          text,
+		details                text,
+		max_guests             int DEFAULT 10,
+		enabled                bool DEFAULT true,
+		starts_at              timestamptz,
+		duration_minutes       int,
+		series_id              int REFERENCES series(id),
+		rsvp_opens_minutes     int,
+		rsvp_closes_minutes    int,
+		decline_cutoff_minutes int,
+		venue_id               int REFERENCES venues(id),
+		sequence               int NOT NULL DEFAULT 0
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE rsvps (
+		friday     timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+		friend_id  int NOT NULL REFERENCES friends(id),
+		status     text NOT NULL DEFAULT 'accepted',
+		created_at timestamptz NOT NULL DEFAULT now(),
+		created_by int REFERENCES friends(id),
+		PRIMARY KEY (friday, friend_id)
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE friday_hosts (
+		friday    timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+		friend_id int NOT NULL REFERENCES friends(id),
+		role      text NOT NULL DEFAULT 'cohost',
+		PRIMARY KEY (friday, friend_id)
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE calendar_feeds (
+		friend_id     int PRIMARY KEY REFERENCES friends(id),
+		token         text NOT NULL UNIQUE,
+		member_groups jsonb NOT NULL DEFAULT '[]',
+		open_fridays  bool NOT NULL DEFAULT false
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE plus_ones (
+		id         serial PRIMARY KEY,
+		friday     timestamptz NOT NULL REFERENCES fridays(start_time) ON DELETE CASCADE,
+		name       text NOT NULL,
+		email      text NOT NULL DEFAULT '',
+		sponsor_id int NOT NULL REFERENCES friends(id),
+		created_at timestamptz NOT NULL DEFAULT now()
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE audit_log (
+		id           bigserial PRIMARY KEY,
+		created_at   timestamptz NOT NULL,
+		actor        text NOT NULL,
+		action       text NOT NULL,
+		friday       timestamptz,
+		target       text NOT NULL,
+		before_value text NOT NULL,
+		after_value  text NOT NULL,
+		source       text NOT NULL
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE blackouts (
+		day    text NOT NULL PRIMARY KEY,
+		reason text NOT NULL DEFAULT ''
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE settings (
+		name  text NOT NULL PRIMARY KEY,
+		value text NOT NULL
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE app_versions (
+		name     text NOT NULL PRIMARY KEY,
+		version  int NOT NULL,
+		checksum text
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	// the tables are already at the newest version
+	return a.Migrator().Stamp()
+}
+
+func (a *PostgresAccessor) DropTables() error {
+	_, err := a.db.Exec(`DROP TABLE IF EXISTS audit_log, rsvps, friday_hosts, plus_ones, calendar_feeds, friends, fridays, series, venues, blackouts, settings, app_versions`)
+	return err
+}
+
+// Migrator manages the migrations of the PostgreSQL database
+func (a *PostgresAccessor) Migrator() *Migrator {
+	return NewMigrator(a.db, PostgresMigrations)
+}
+
+func (a *PostgresAccessor) PatchTables() error {
+	return a.Migrator().Up()
+}
+
+func (a *PostgresAccessor) GetFriendByID(ID string) (Friend, error) {
+	friend := Friend{}
+	id, err := strconv.ParseInt(ID, 10, 64)
+	if err != nil {
+		return friend, err
+	}
+	err = a.db.QueryRow("SELECT email, COALESCE(name, '') FROM friends WHERE id = $1", id).Scan(&friend.Email, &friend.Name)
+	friend.ID = ID
+	return friend, err
+}
+
+func (a *PostgresAccessor) GetFriendByEmail(email string) (Friend, error) {
+	friend := Friend{}
+	var id int64
+	err := a.db.QueryRow("SELECT id, COALESCE(name, '') FROM friends WHERE email = $1", email).Scan(&id, &friend.Name)
+	friend.ID = strconv.FormatInt(id, 10)
+	friend.Email = email
+	return friend, err
+}
+
+func (a *PostgresAccessor) AddFriend(email, name string) error {
+	_, err := a.db.Exec("INSERT INTO friends (email, name) VALUES ($1, $2) ON CONFLICT (email) DO UPDATE SET name=$2", email, name)
+	return err
+}
+
+func (a *PostgresAccessor) ListFriends() ([]Friend, error) {
+	rows, err := a.db.Query("SELECT id, email, COALESCE(name, '') FROM friends ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make([]Friend, 0)
+	for rows.Next() {
+		var id int64
+		friend := Friend{}
+		if err = rows.Scan(&id, &friend.Email, &friend.Name); err != nil {
+			return nil, err
+		}
+		friend.ID = strconv.FormatInt(id, 10)
+		res = append(res, friend)
+	}
+	return res, rows.Err()
+}
+
+func (a *PostgresAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
+	return a.GetUpcomingFridaysAfter(time.Now(), daysAhead)
+}
+
+func (a *PostgresAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error) {
+	before := after.AddDate(0, 0, daysAhead)
+	rows, err := a.db.Query(`SELECT `+pgFridayColumns+` FROM fridays
+		WHERE start_time <= $1 AND start_time >= $2 ORDER BY start_time`, before, after)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Friday, 0)
+	for rows.Next() {
+		friday, err := scanFriday(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, friday)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) DoesFridayExist(date time.Time) (bool, error) {
+	var count int
+	if err := a.db.QueryRow("SELECT COUNT(*) FROM fridays WHERE start_time = $1", date).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+func (a *PostgresAccessor) AddFriday(date time.Time) error {
+	_, err := a.db.Exec("INSERT INTO fridays (start_time) VALUES ($1) ON CONFLICT (start_time) DO NOTHING", date)
+	return err
+}
+
+func (a *PostgresAccessor) ListFridays() ([]Friday, error) {
+	rows, err := a.db.Query("SELECT start_time FROM fridays ORDER BY start_time")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make([]Friday, 0)
+	for rows.Next() {
+		f := Friday{}
+		if err = rows.Scan(&f.Date); err != nil {
+			return nil, err
+		}
+		f.Date = f.Date.In(a.Location)
+		res = append(res, f)
+	}
+	return res, rows.Err()
+}
+
+func (a *PostgresAccessor) GetFriday(date time.Time) (Friday, error) {
+	return scanFriday(a.db.QueryRow("SELECT "+pgFridayColumns+" FROM fridays WHERE start_time = $1", date))
+}
+
+func (a *PostgresAccessor) RemoveFriday(date time.Time) error {
+	_, err := a.db.Exec("DELETE FROM fridays WHERE start_time = $1", date)
+	return err
+}
+
+func (a *PostgresAccessor) UpdateFriday(friday Friday) error {
+	start, duration := fridayTimes(friday)
+	opens, closes, declineCutoff := fridayPolicy(friday)
+	_, err := a.db.Exec(`UPDATE fridays SET invited_group=$1, details=$2, max_guests=$3, enabled=$4, starts_at=$5,
+		duration_minutes=$6, series_id=$7, rsvp_opens_minutes=$8, rsvp_closes_minutes=$9, decline_cutoff_minutes=$10,
+		venue_id=$11, sequence=sequence+1 WHERE start_time=$12`,
+		friday.Group, friday.Details, friday.MaxGuests, friday.Enabled, start, duration, fridaySeries(friday),
+		opens, closes, declineCutoff, fridayVenue(friday), friday.Date)
+	return err
+}
+
+func (a *PostgresAccessor) AddFriendToFriday(email string, friday Friday, createdBy string) error {
+	// the capacity check and the insert must see the same guest list
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// locking the friday serializes concurrent RSVPs to it
+	var startTime time.Time
+	var maxGuests int
+	err = tx.QueryRow("SELECT start_time, max_guests FROM fridays WHERE start_time = $1 FOR UPDATE", friday.Date).
+		Scan(&startTime, &maxGuests)
+	if err != nil {
+		return err
+	}
+	var status string
+	err = tx.QueryRow(`SELECT rsvps.status FROM rsvps JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = $1 AND friends.email = $2`, startTime, email).Scan(&status)
+	if err == nil && status == RSVPAccepted {
+		// already invited
+		return nil
+	} else if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	var numGuests int
+	err = tx.QueryRow("SELECT "+pgGuestCount, startTime).Scan(&numGuests)
+	if err != nil {
+		return err
+	}
+	if numGuests >= maxGuests {
+		return ErrFridayIsFull
+	}
+
+	// guests do not need to have logged in before to be invited
+	if _, err = tx.Exec("INSERT INTO friends (email) VALUES ($1) ON CONFLICT (email) DO NOTHING", email); err != nil {
+		return err
+	}
+	var creator *string
+	if len(createdBy) > 0 && createdBy != email {
+		creator = &createdBy
+	}
+	// accepting after declining counts as a new RSVP
+	_, err = tx.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at, created_by)
+		SELECT $1, id, 'accepted', $2, (SELECT id FROM friends WHERE email = $3) FROM friends WHERE email = $4
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=excluded.created_by`,
+		startTime, time.Now(), creator, email)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (a *PostgresAccessor) RemoveFriendFromFriday(email string, date time.Time) error {
+	_, err := a.db.Exec(`UPDATE rsvps SET status = 'declined'
+		WHERE friday = $1 AND friend_id = (SELECT id FROM friends WHERE email = $2)`, date, email)
+	return err
+}
+
+func (a *PostgresAccessor) SetFridayHosts(date time.Time, host string, coHosts []string) error {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM friday_hosts WHERE friday = $1", date); err != nil {
+		return err
+	}
+	insert := `INSERT INTO friday_hosts (friday, friend_id, role) SELECT $1::timestamptz, id, $2::text FROM friends WHERE email = $3
+		ON CONFLICT (friday, friend_id) DO NOTHING`
+	if len(host) > 0 {
+		if _, err = tx.Exec(insert, date, FridayHostOwner, host); err != nil {
+			return err
+		}
+	}
+	for _, email := range coHosts {
+		if _, err = tx.Exec(insert, date, FridayCoHost, email); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (a *PostgresAccessor) AddFriendAsTentative(email string, date time.Time) error {
+	var startTime time.Time
+	if err := a.db.QueryRow("SELECT start_time FROM fridays WHERE start_time = $1", date).Scan(&startTime); err != nil {
+		return err
+	}
+	if _, err := a.db.Exec("INSERT INTO friends (email) VALUES ($1) ON CONFLICT (email) DO NOTHING", email); err != nil {
+		return err
+	}
+	_, err := a.db.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at)
+		SELECT $1::timestamptz, id, 'tentative', $2::timestamptz FROM friends WHERE email = $3
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=NULL
+		WHERE rsvps.status != 'tentative'`, startTime, time.Now(), email)
+	return err
+}
+
+func (a *PostgresAccessor) AddPlusOne(date time.Time, plusOne PlusOne) (int64, error) {
+	// the capacity check and the insert must see the same guest list
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// locking the friday serializes plus-ones with concurrent RSVPs
+	var startTime time.Time
+	var maxGuests int
+	err = tx.QueryRow("SELECT start_time, max_guests FROM fridays WHERE start_time = $1 FOR UPDATE", date).
+		Scan(&startTime, &maxGuests)
+	if err != nil {
+		return 0, err
+	}
+	var numGuests int
+	if err = tx.QueryRow("SELECT "+pgGuestCount, startTime).Scan(&numGuests); err != nil {
+		return 0, err
+	}
+	if numGuests >= maxGuests {
+		return 0, ErrFridayIsFull
+	}
+	// no row means that the sponsor is not a friend
+	var ID int64
+	err = tx.QueryRow(`INSERT INTO plus_ones (friday, name, email, sponsor_id, created_at)
+		SELECT $1::timestamptz, $2::text, $3::text, id, $4::timestamptz FROM friends WHERE email = $5 RETURNING id`,
+		startTime, plusOne.Name, plusOne.Email, time.Now(), plusOne.Sponsor).Scan(&ID)
+	if err != nil {
+		return 0, err
+	}
+	return ID, tx.Commit()
+}
+
+func (a *PostgresAccessor) RemovePlusOne(date time.Time, ID int64) error {
+	_, err := a.db.Exec("DELETE FROM plus_ones WHERE friday = $1 AND id = $2", date, ID)
+	return err
+}
+
+func (a *PostgresAccessor) AddFriendToWaitlist(email string, date time.Time) error {
+	var startTime time.Time
+	if err := a.db.QueryRow("SELECT start_time FROM fridays WHERE start_time = $1", date).Scan(&startTime); err != nil {
+		return err
+	}
+	if _, err := a.db.Exec("INSERT INTO friends (email) VALUES ($1) ON CONFLICT (email) DO NOTHING", email); err != nil {
+		return err
+	}
+	// accepted guests stay accepted and waitlisted guests keep their place in line
+	_, err := a.db.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at)
+		SELECT $1, id, 'waitlisted', $2 FROM friends WHERE email = $3
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=NULL
+		WHERE rsvps.status = 'declined'`, startTime, time.Now(), email)
+	return err
+}
+
+func (a *PostgresAccessor) PromoteFromWaitlist(date time.Time) ([]string, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// locking the friday serializes promotions with concurrent RSVPs
+	var startTime time.Time
+	var maxGuests int
+	err = tx.QueryRow("SELECT start_time, max_guests FROM fridays WHERE start_time = $1 FOR UPDATE", date).
+		Scan(&startTime, &maxGuests)
+	if err != nil {
+		return nil, err
+	}
+	var numGuests int
+	err = tx.QueryRow("SELECT "+pgGuestCount, startTime).Scan(&numGuests)
+	if err != nil {
+		return nil, err
+	}
+	promoted := make([]string, 0)
+	if numGuests >= maxGuests {
+		return promoted, nil
+	}
+	rows, err := tx.Query(`SELECT friends.id, friends.email FROM rsvps JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = $1 AND rsvps.status = 'waitlisted'
+		ORDER BY rsvps.created_at, rsvps.friend_id LIMIT $2`, startTime, maxGuests-numGuests)
+	if err != nil {
+		return nil, err
+	}
+	friendIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		var email string
+		if err = rows.Scan(&id, &email); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		friendIDs = append(friendIDs, id)
+		promoted = append(promoted, email)
+	}
+	rows.Close()
+	for _, id := range friendIDs {
+		if _, err = tx.Exec("UPDATE rsvps SET status = 'accepted' WHERE friday = $1 AND friend_id = $2", startTime, id); err != nil {
+			return nil, err
+		}
+	}
+	return promoted, tx.Commit()
+}
+
+func (a *PostgresAccessor) GetRSVPs(date time.Time) ([]RSVP, error) {
+	rows, err := a.db.Query(`SELECT rsvps.friday, friends.email, friends.name, rsvps.status, rsvps.created_at, creators.email
+		FROM rsvps
+		JOIN friends ON friends.id = rsvps.friend_id
+		LEFT JOIN friends AS creators ON creators.id = rsvps.created_by
+		WHERE rsvps.friday = $1 ORDER BY rsvps.created_at, rsvps.friend_id`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]RSVP, 0)
+	for rows.Next() {
+		var rsvp RSVP
+		var name, createdBy sql.NullString
+		if err = rows.Scan(&rsvp.Friday, &rsvp.Email, &name, &rsvp.Status, &rsvp.CreatedAt, &createdBy); err != nil {
+			return nil, err
+		}
+		rsvp.Name = name.String
+		rsvp.CreatedBy = createdBy.String
+		result = append(result, rsvp)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) GetPreferences(email string) (Preferences, error) {
+	var prefs Preferences
+	var rawPreferences string
+	err := a.db.QueryRow("SELECT preferences FROM friends WHERE email=$1", email).Scan(&rawPreferences)
+	if err != nil {
+		return prefs, err
+	}
+	err = json.Unmarshal([]byte(rawPreferences), &prefs)
+	return prefs, err
+}
+
+func (a *PostgresAccessor) SetPreferences(email string, prefs Preferences) error {
+	rawPrefs, err := json.Marshal(prefs)
+	if err != nil {
+		return err
+	}
+	_, err = a.db.Exec("UPDATE friends SET preferences=$1 WHERE email=$2", string(rawPrefs), email)
+	return err
+}
+
+func (a *PostgresAccessor) GetCalendarFeed(email string) (CalendarFeed, error) {
+	return scanCalendarFeed(a.db.QueryRow("SELECT "+sqlCalendarFeedColumns+" WHERE friends.email = $1", email))
+}
+
+func (a *PostgresAccessor) GetCalendarFeedByToken(token string) (CalendarFeed, error) {
+	return scanCalendarFeed(a.db.QueryRow("SELECT "+sqlCalendarFeedColumns+" WHERE calendar_feeds.token = $1", token))
+}
+
+func (a *PostgresAccessor) SetCalendarFeed(feed CalendarFeed) error {
+	rawGroups, err := calendarFeedGroups(feed)
+	if err != nil {
+		return err
+	}
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec("INSERT INTO friends (email) VALUES ($1) ON CONFLICT (email) DO NOTHING", feed.Email); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO calendar_feeds (friend_id, token, member_groups, open_fridays)
+		SELECT id, $1::text, $2::jsonb, $3::bool FROM friends WHERE email = $4
+		ON CONFLICT (friend_id) DO UPDATE SET
+			token=excluded.token, member_groups=excluded.member_groups, open_fridays=excluded.open_fridays`,
+		feed.Token, rawGroups, feed.OpenFridays, feed.Email)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (a *PostgresAccessor) AddAuditEntry(entry AuditEntry) error {
+	var friday *time.Time
+	if !entry.Friday.IsZero() {
+		friday = &entry.Friday
+	}
+	_, err := a.db.Exec(`INSERT INTO audit_log
+		(created_at, actor, action, friday, target, before_value, after_value, source) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		entry.Time, entry.Actor, entry.Action, friday, entry.Target, entry.Before, entry.After, entry.Source)
+	return err
+}
+
+func (a *PostgresAccessor) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	query := `SELECT id, created_at, actor, action, friday, target, before_value, after_value, source FROM audit_log
+		WHERE true`
+	args := make([]any, 0)
+	if !filter.Friday.IsZero() {
+		args = append(args, filter.Friday)
+		query += fmt.Sprintf(" AND friday = $%d", len(args))
+	}
+	if len(filter.Target) > 0 {
+		args = append(args, filter.Target)
+		query += fmt.Sprintf(" AND target = $%d", len(args))
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]AuditEntry, 0)
+	for rows.Next() {
+		var entry AuditEntry
+		var friday sql.NullTime
+		err = rows.Scan(&entry.ID, &entry.Time, &entry.Actor, &entry.Action, &friday, &entry.Target, &entry.Before,
+			&entry.After, &entry.Source)
+		if err != nil {
+			return nil, err
+		}
+		entry.Friday = friday.Time
+		result = append(result, entry)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) GetSetting(name string) (string, error) {
+	var value string
+	err := a.db.QueryRow("SELECT value FROM settings WHERE name = $1", name).Scan(&value)
+	return value, err
+}
+
+func (a *PostgresAccessor) SetSetting(name, value string) error {
+	_, err := a.db.Exec(`INSERT INTO settings (name, value) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET value = excluded.value`, name, value)
+	return err
+}
+
+func (a *PostgresAccessor) ListSeries() ([]Series, error) {
+	rows, err := a.db.Query("SELECT " + sqlSeriesColumns + " FROM series ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Series, 0)
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, series)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) GetSeries(ID int64) (Series, error) {
+	return scanSeries(a.db.QueryRow("SELECT "+sqlSeriesColumns+" FROM series WHERE id = $1", ID))
+}
+
+func (a *PostgresAccessor) AddSeries(series Series) (int64, error) {
+	var ID int64
+	err := a.db.QueryRow(`INSERT INTO series (name, description, invited_group, max_guests, recurrence, calendar_id)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, series.Name, series.Description, series.Group, series.MaxGuests,
+		series.Recurrence, series.CalendarID).Scan(&ID)
+	return ID, err
+}
+
+func (a *PostgresAccessor) UpdateSeries(series Series) error {
+	_, err := a.db.Exec(`UPDATE series SET name=$1, description=$2, invited_group=$3, max_guests=$4, recurrence=$5,
+		calendar_id=$6 WHERE id=$7`, series.Name, series.Description, series.Group, series.MaxGuests, series.Recurrence,
+		series.CalendarID, series.ID)
+	return err
+}
+
+func (a *PostgresAccessor) ListVenues() ([]Venue, error) {
+	rows, err := a.db.Query("SELECT " + sqlVenueColumns + " FROM venues ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Venue, 0)
+	for rows.Next() {
+		venue, err := scanVenue(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, venue)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) GetVenue(ID int64) (Venue, error) {
+	return scanVenue(a.db.QueryRow("SELECT "+sqlVenueColumns+" FROM venues WHERE id = $1", ID))
+}
+
+func (a *PostgresAccessor) AddVenue(venue Venue) (int64, error) {
+	var ID int64
+	err := a.db.QueryRow(`INSERT INTO venues (name, address, capacity, notes, ovens) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`, venue.Name, venue.Address, venue.Capacity, venue.Notes, venue.Ovens).Scan(&ID)
+	return ID, err
+}
+
+func (a *PostgresAccessor) UpdateVenue(venue Venue) error {
+	_, err := a.db.Exec(`UPDATE venues SET name=$1, address=$2, capacity=$3, notes=$4, ovens=$5 WHERE id=$6`,
+		venue.Name, venue.Address, venue.Capacity, venue.Notes, venue.Ovens, venue.ID)
+	return err
+}
+
+func (a *PostgresAccessor) ListBlackouts() ([]Blackout, error) {
+	rows, err := a.db.Query("SELECT day, reason FROM blackouts ORDER BY day")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Blackout, 0)
+	for rows.Next() {
+		var blackout Blackout
+		if err = rows.Scan(&blackout.Day, &blackout.Reason); err != nil {
+			return nil, err
+		}
+		result = append(result, blackout)
+	}
+	return result, rows.Err()
+}
+
+func (a *PostgresAccessor) AddBlackout(blackout Blackout) error {
+	_, err := a.db.Exec(`INSERT INTO blackouts (day, reason) VALUES ($1, $2)
+		ON CONFLICT (day) DO UPDATE SET reason = excluded.reason`, blackout.Day, blackout.Reason)
+	return err
+}
+
+func (a *PostgresAccessor) RemoveBlackout(day string) error {
+	_, err := a.db.Exec("DELETE FROM blackouts WHERE day = $1", day)
+	return err
+}
@@ -0,0 +1,53 @@
+package pizza_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPostgresAccessor connects to the database in PIZZA_TEST_POSTGRES_DSN and resets all tables. The test is
+// skipped when no database is configured, except in CI, which always has one.
+func newTestPostgresAccessor(t *testing.T) *pizza.PostgresAccessor {
+	dsn := os.Getenv("PIZZA_TEST_POSTGRES_DSN")
+	if len(dsn) == 0 && len(os.Getenv("CI")) > 0 {
+		t.Fatal("PIZZA_TEST_POSTGRES_DSN must be set in CI")
+	} else if len(dsn) == 0 {
+		t.Skip("PIZZA_TEST_POSTGRES_DSN is not set")
+	}
+	accessor, err := pizza.NewPostgresAccessor(dsn, true)
+	require.Nil(t, err)
+	t.Cleanup(accessor.Close)
+	require.Nil(t, accessor.DropTables())
+	require.Nil(t, accessor.CreateTables())
+	return accessor
+}
+
+func TestPostgresMigrations_RevertAndReapply(t *testing.T) {
+	// GIVEN
+	accessor := newTestPostgresAccessor(t)
+	loc, _ := time.LoadLocation("America/New_York")
+	f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+	require.Nil(t, accessor.AddFriday(f1))
+	require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", pizza.Friday{Date: f1}, ""))
+	require.Nil(t, accessor.AddFriendToFriday("baz@bar.com", pizza.Friday{Date: f1}, ""))
+	migrator := accessor.Migrator()
+
+	// WHEN
+	err := migrator.To(0)
+	err2 := migrator.Up()
+	version, err3 := migrator.Version()
+	friday, err4 := accessor.GetFriday(f1)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Nil(t, err2)
+	assert.Nil(t, err3)
+	assert.Nil(t, err4)
+	assert.Equal(t, len(pizza.PostgresMigrations), version)
+	assert.Equal(t, []string{"foo@bar.com", "baz@bar.com"}, friday.Guests)
+}
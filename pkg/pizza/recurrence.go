@@ -0,0 +1,332 @@
+package pizza
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRecurrence is every Friday at 17:30 in the timezone of the deployment, which is when pizza fridays have always
+// been
+const DefaultRecurrence = "DTSTART:20231222T173000 RRULE:FREQ=WEEKLY;BYDAY=FR"
+
+var ErrRecurrenceUnsupported = errors.New("unsupported recurrence")
+
+const (
+	recurrenceWeekly  = "WEEKLY"
+	recurrenceMonthly = "MONTHLY"
+
+	icalDateTimeFormat = "20060102T150405"
+	icalDateFormat     = "20060102"
+)
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceDay is a BYDAY value. Ordinal picks the nth weekday of the month for monthly rules, counting from the
+// end of the month when negative, and is zero for weekly rules.
+type RecurrenceDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+func (d RecurrenceDay) String() string {
+	day := strings.ToUpper(d.Weekday.String()[:2])
+	if d.Ordinal != 0 {
+		return strconv.Itoa(d.Ordinal) + day
+	}
+	return day
+}
+
+// Recurrence is the subset of an RFC 5545 recurrence that fridays are scheduled by: a DTSTART, which sets the time of
+// day, a weekly or monthly-by-weekday RRULE with INTERVAL, BYDAY, COUNT and UNTIL, and EXDATE exclusions. Exclusions
+// remove every occurrence on their day.
+type Recurrence struct {
+	Start    time.Time
+	Freq     string
+	Interval int
+	ByDay    []RecurrenceDay
+	Count    int
+	Until    time.Time
+	Exclude  []time.Time
+}
+
+// ParseRecurrence reads the DTSTART, RRULE and EXDATE properties of the text, which may be separated by newlines or
+// spaces. Times without a TZID are in loc.
+func ParseRecurrence(text string, loc *time.Location) (Recurrence, error) {
+	rec := Recurrence{Interval: 1}
+	hasRule := false
+	for _, property := range strings.Fields(text) {
+		name, value, ok := strings.Cut(property, ":")
+		if !ok {
+			return rec, fmt.Errorf("recurrence property %q has no value", property)
+		}
+		name, rawParams, _ := strings.Cut(name, ";")
+		params := parseICalParams(rawParams)
+		propLoc := loc
+		if tzid, ok := params["TZID"]; ok {
+			var err error
+			if propLoc, err = time.LoadLocation(tzid); err != nil {
+				return rec, err
+			}
+		}
+		switch strings.ToUpper(name) {
+		case "DTSTART":
+			start, err := parseICalTime(value, propLoc)
+			if err != nil {
+				return rec, fmt.Errorf("DTSTART: %w", err)
+			}
+			rec.Start = start
+		case "RRULE":
+			if err := rec.parseRule(value, loc); err != nil {
+				return rec, fmt.Errorf("RRULE: %w", err)
+			}
+			hasRule = true
+		case "EXDATE":
+			for _, raw := range strings.Split(value, ",") {
+				exclude, err := parseICalTime(raw, propLoc)
+				if err != nil {
+					return rec, fmt.Errorf("EXDATE: %w", err)
+				}
+				rec.Exclude = append(rec.Exclude, exclude)
+			}
+		default:
+			return rec, fmt.Errorf("%w: property %s", ErrRecurrenceUnsupported, name)
+		}
+	}
+	if rec.Start.IsZero() {
+		return rec, errors.New("recurrence must have a DTSTART")
+	}
+	if !hasRule {
+		return rec, errors.New("recurrence must have an RRULE")
+	}
+	// without BYDAY the rule repeats on the day of DTSTART
+	if len(rec.ByDay) == 0 {
+		day := RecurrenceDay{Weekday: rec.Start.Weekday()}
+		if rec.Freq == recurrenceMonthly {
+			day.Ordinal = (rec.Start.Day()-1)/7 + 1
+		}
+		rec.ByDay = []RecurrenceDay{day}
+	}
+	return rec, nil
+}
+
+func (r *Recurrence) parseRule(rule string, loc *time.Location) error {
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("%q is not KEY=VALUE", part)
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+			if r.Freq != recurrenceWeekly && r.Freq != recurrenceMonthly {
+				return fmt.Errorf("%w: FREQ=%s", ErrRecurrenceUnsupported, value)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return fmt.Errorf("INTERVAL must be a positive number, not %q", value)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return fmt.Errorf("COUNT must be a positive number, not %q", value)
+			}
+		case "UNTIL":
+			if r.Until, err = parseICalTime(value, loc); err != nil {
+				return fmt.Errorf("UNTIL: %w", err)
+			}
+		case "BYDAY":
+			for _, raw := range strings.Split(value, ",") {
+				day, err := parseRecurrenceDay(raw)
+				if err != nil {
+					return err
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return fmt.Errorf("%w: WKST=%s", ErrRecurrenceUnsupported, value)
+			}
+		default:
+			return fmt.Errorf("%w: %s", ErrRecurrenceUnsupported, key)
+		}
+	}
+	if len(r.Freq) == 0 {
+		return errors.New("FREQ is required")
+	}
+	for _, day := range r.ByDay {
+		if r.Freq == recurrenceWeekly && day.Ordinal != 0 {
+			return fmt.Errorf("%w: BYDAY=%s in a weekly rule", ErrRecurrenceUnsupported, day)
+		}
+		if r.Freq == recurrenceMonthly && day.Ordinal == 0 {
+			return fmt.Errorf("%w: BYDAY=%s in a monthly rule needs an ordinal like 1%s", ErrRecurrenceUnsupported,
+				day, day)
+		}
+	}
+	return nil
+}
+
+func parseRecurrenceDay(raw string) (RecurrenceDay, error) {
+	raw = strings.ToUpper(strings.TrimSpace(raw))
+	if len(raw) < 2 {
+		return RecurrenceDay{}, fmt.Errorf("BYDAY %q is not a weekday", raw)
+	}
+	weekday, ok := icalWeekdays[raw[len(raw)-2:]]
+	if !ok {
+		return RecurrenceDay{}, fmt.Errorf("BYDAY %q is not a weekday", raw)
+	}
+	day := RecurrenceDay{Weekday: weekday}
+	if ordinal := raw[:len(raw)-2]; len(ordinal) > 0 {
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RecurrenceDay{}, fmt.Errorf("BYDAY %q has an invalid ordinal", raw)
+		}
+		day.Ordinal = n
+	}
+	return day, nil
+}
+
+func parseICalParams(raw string) map[string]string {
+	params := make(map[string]string)
+	for _, param := range strings.Split(raw, ";") {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = value
+		}
+	}
+	return params
+}
+
+// parseICalTime reads a DATE or DATE-TIME value, which is in UTC when it ends with Z
+func parseICalTime(value string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(icalDateTimeFormat+"Z", value)
+	}
+	if len(value) == len(icalDateFormat) {
+		return time.ParseInLocation(icalDateFormat, value, loc)
+	}
+	return time.ParseInLocation(icalDateTimeFormat, value, loc)
+}
+
+// String formats the recurrence so that ParseRecurrence reads it back, one property per line
+func (r Recurrence) String() string {
+	lines := []string{fmt.Sprintf("DTSTART;TZID=%s:%s", r.Start.Location(), r.Start.Format(icalDateTimeFormat))}
+	rule := "RRULE:FREQ=" + r.Freq
+	if r.Interval > 1 {
+		rule += ";INTERVAL=" + strconv.Itoa(r.Interval)
+	}
+	days := make([]string, len(r.ByDay))
+	for i, day := range r.ByDay {
+		days[i] = day.String()
+	}
+	rule += ";BYDAY=" + strings.Join(days, ",")
+	if r.Count > 0 {
+		rule += ";COUNT=" + strconv.Itoa(r.Count)
+	}
+	if !r.Until.IsZero() {
+		rule += ";UNTIL=" + r.Until.UTC().Format(icalDateTimeFormat) + "Z"
+	}
+	lines = append(lines, rule)
+	if len(r.Exclude) > 0 {
+		excludes := make([]string, len(r.Exclude))
+		for i, exclude := range r.Exclude {
+			excludes[i] = exclude.In(r.Start.Location()).Format(icalDateFormat)
+		}
+		lines = append(lines, fmt.Sprintf("EXDATE;TZID=%s;VALUE=DATE:%s", r.Start.Location(), strings.Join(excludes, ",")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Between lists the occurrences from after until before, inclusive and in order
+func (r Recurrence) Between(after, before time.Time) []time.Time {
+	dates := make([]time.Time, 0)
+	count := 0
+	for period := 0; ; period += r.Interval {
+		occurrences := r.period(period)
+		if len(occurrences) == 0 {
+			return dates
+		}
+		for _, t := range occurrences {
+			if t.Before(r.Start) {
+				continue
+			}
+			count++
+			if t.After(before) || (!r.Until.IsZero() && t.After(r.Until)) || (r.Count > 0 && count > r.Count) {
+				return dates
+			}
+			if !t.Before(after) && !r.isExcluded(t) {
+				dates = append(dates, t)
+			}
+		}
+	}
+}
+
+// Occurs is true when t is one of the occurrences
+func (r Recurrence) Occurs(t time.Time) bool {
+	dates := r.Between(t, t)
+	return len(dates) == 1
+}
+
+// period lists the occurrences of the nth week or month after the one that DTSTART is in
+func (r Recurrence) period(n int) []time.Time {
+	loc := r.Start.Location()
+	hour, min, sec := r.Start.Clock()
+	dates := make([]time.Time, 0, len(r.ByDay))
+	switch r.Freq {
+	case recurrenceWeekly:
+		// weeks start on monday
+		offset := (int(r.Start.Weekday()) + 6) % 7
+		monday := time.Date(r.Start.Year(), r.Start.Month(), r.Start.Day()-offset+7*n, hour, min, sec, 0, loc)
+		for _, day := range r.ByDay {
+			dates = append(dates, monday.AddDate(0, 0, (int(day.Weekday)+6)%7))
+		}
+	case recurrenceMonthly:
+		first := time.Date(r.Start.Year(), r.Start.Month()+time.Month(n), 1, hour, min, sec, 0, loc)
+		for _, day := range r.ByDay {
+			if t, ok := nthWeekday(first, day); ok {
+				dates = append(dates, t)
+			}
+		}
+		if len(dates) == 0 {
+			// the month has none of the days, e.g. a fifth friday, but later months may
+			dates = append(dates, time.Time{})
+		}
+	}
+	slices.SortFunc(dates, func(x, y time.Time) int {
+		return x.Compare(y)
+	})
+	return dates
+}
+
+// nthWeekday finds the day in the month that starts on first
+func nthWeekday(first time.Time, day RecurrenceDay) (time.Time, bool) {
+	if day.Ordinal > 0 {
+		t := first.AddDate(0, 0, (int(day.Weekday)-int(first.Weekday())+7)%7+7*(day.Ordinal-1))
+		return t, t.Month() == first.Month()
+	}
+	last := first.AddDate(0, 1, -1)
+	t := last.AddDate(0, 0, -((int(last.Weekday())-int(day.Weekday)+7)%7)+7*(day.Ordinal+1))
+	return t, t.Month() == first.Month()
+}
+
+func (r Recurrence) isExcluded(t time.Time) bool {
+	loc := r.Start.Location()
+	y, m, d := t.In(loc).Date()
+	for _, exclude := range r.Exclude {
+		ey, em, ed := exclude.In(loc).Date()
+		if y == ey && m == em && d == ed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package pizza_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadNY(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+	return loc
+}
+
+func TestRecurrence_DefaultIsEveryFriday(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	rec, err := pizza.ParseRecurrence(pizza.DefaultRecurrence, loc)
+	require.Nil(t, err)
+
+	// WHEN
+	dates := rec.Between(time.Date(2025, time.March, 1, 0, 0, 0, 0, loc), time.Date(2025, time.March, 21, 23, 0, 0, 0, loc))
+
+	// THEN
+	assert.Equal(t, []time.Time{
+		time.Date(2025, time.March, 7, 17, 30, 0, 0, loc),
+		time.Date(2025, time.March, 14, 17, 30, 0, 0, loc),
+		time.Date(2025, time.March, 21, 17, 30, 0, 0, loc),
+	}, dates)
+	// the wall clock time holds across the daylight saving change on March 9th
+	assert.NotEqual(t, dates[0].UTC().Hour(), dates[1].UTC().Hour())
+	assert.True(t, rec.Occurs(time.Date(2025, time.March, 14, 17, 30, 0, 0, loc)))
+	assert.False(t, rec.Occurs(time.Date(2025, time.March, 14, 18, 30, 0, 0, loc)))
+	assert.False(t, rec.Occurs(time.Date(2025, time.March, 13, 17, 30, 0, 0, loc)))
+}
+
+func TestRecurrence_BiweeklyThursday(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	rec, err := pizza.ParseRecurrence("DTSTART:20250102T180000\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TH", loc)
+	require.Nil(t, err)
+
+	// WHEN
+	dates := rec.Between(time.Date(2025, time.January, 10, 0, 0, 0, 0, loc), time.Date(2025, time.February, 14, 0, 0, 0, 0, loc))
+
+	// THEN
+	assert.Equal(t, []time.Time{
+		time.Date(2025, time.January, 16, 18, 0, 0, 0, loc),
+		time.Date(2025, time.January, 30, 18, 0, 0, 0, loc),
+		time.Date(2025, time.February, 13, 18, 0, 0, 0, loc),
+	}, dates)
+}
+
+func TestRecurrence_MonthlyLastFriday(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	rec, err := pizza.ParseRecurrence("DTSTART:20250101T170000 RRULE:FREQ=MONTHLY;BYDAY=-1FR,1WE", loc)
+	require.Nil(t, err)
+
+	// WHEN
+	dates := rec.Between(rec.Start, time.Date(2025, time.March, 1, 0, 0, 0, 0, loc))
+
+	// THEN
+	assert.Equal(t, []time.Time{
+		time.Date(2025, time.January, 1, 17, 0, 0, 0, loc),
+		time.Date(2025, time.January, 31, 17, 0, 0, 0, loc),
+		time.Date(2025, time.February, 5, 17, 0, 0, 0, loc),
+		time.Date(2025, time.February, 28, 17, 0, 0, 0, loc),
+	}, dates)
+}
+
+func TestRecurrence_ExcludeCountUntil(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	counted, err := pizza.ParseRecurrence(
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;COUNT=3 EXDATE;VALUE=DATE:20250110", loc)
+	require.Nil(t, err)
+	until, err := pizza.ParseRecurrence("DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;UNTIL=20250118T000000Z", loc)
+	require.Nil(t, err)
+	end := time.Date(2026, time.January, 1, 0, 0, 0, 0, loc)
+
+	// WHEN
+	countedDates := counted.Between(counted.Start, end)
+	untilDates := until.Between(until.Start, end)
+
+	// THEN
+	assert.Equal(t, []time.Time{
+		time.Date(2025, time.January, 3, 17, 30, 0, 0, loc),
+		time.Date(2025, time.January, 17, 17, 30, 0, 0, loc),
+	}, countedDates)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, time.January, 3, 17, 30, 0, 0, loc),
+		time.Date(2025, time.January, 10, 17, 30, 0, 0, loc),
+		time.Date(2025, time.January, 17, 17, 30, 0, 0, loc),
+	}, untilDates)
+}
+
+func TestRecurrence_StringRoundTrip(t *testing.T) {
+	// GIVEN
+	loc := mustLoadNY(t)
+	rec, err := pizza.ParseRecurrence(
+		"DTSTART:20250103T173000 RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=2FR;COUNT=4 EXDATE:20250314T173000", loc)
+	require.Nil(t, err)
+
+	// WHEN
+	reparsed, err := pizza.ParseRecurrence(rec.String(), time.UTC)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, rec.String(), reparsed.String())
+	dates := rec.Between(rec.Start, rec.Start.AddDate(1, 0, 0))
+	reparsedDates := reparsed.Between(rec.Start, rec.Start.AddDate(1, 0, 0))
+	assert.Len(t, dates, 3)
+	require.Len(t, reparsedDates, len(dates))
+	for i := range dates {
+		assert.True(t, dates[i].Equal(reparsedDates[i]))
+	}
+}
+
+func TestParseRecurrence_Invalid(t *testing.T) {
+	loc := mustLoadNY(t)
+	for _, text := range []string{
+		"",
+		"RRULE:FREQ=WEEKLY",
+		"DTSTART:20250103T173000",
+		"DTSTART:20250103T173000 RRULE:FREQ=DAILY",
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;BYDAY=1FR",
+		"DTSTART:20250103T173000 RRULE:FREQ=MONTHLY;BYDAY=FR",
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;BYMONTH=1",
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;INTERVAL=0",
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY;BYDAY=XX",
+		"DTSTART:20250103T173000 RRULE:FREQ=WEEKLY RDATE:20250104T173000",
+		"DTSTART:tomorrow RRULE:FREQ=WEEKLY",
+	} {
+		_, err := pizza.ParseRecurrence(text, loc)
+		assert.NotNil(t, err, text)
+	}
+}
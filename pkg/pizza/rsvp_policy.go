@@ -0,0 +1,134 @@
+package pizza
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RSVPPolicy is when guests can RSVP to a friday and until when they can decline, relative to when it starts. RSVPs
+// are open from OpensBefore until ClosesBefore the start, and an OpensBefore of zero opens them as soon as the friday
+// is enabled. Guests cannot decline once it is less than DeclineCutoff until the start.
+type RSVPPolicy struct {
+	OpensBefore   time.Duration `yaml:"opensBefore"`
+	ClosesBefore  time.Duration `yaml:"closesBefore"`
+	DeclineCutoff time.Duration `yaml:"declineCutoff"`
+}
+
+var (
+	ErrRSVPNotOpen   = errors.New("rsvps are not open yet")
+	ErrRSVPClosed    = errors.New("rsvps are closed")
+	ErrDeclineClosed = errors.New("it is too late to decline")
+)
+
+// Validate checks that the policy has no negative durations and that RSVPs open before they close
+func (p RSVPPolicy) Validate() error {
+	if p.OpensBefore < 0 || p.ClosesBefore < 0 || p.DeclineCutoff < 0 {
+		return errors.New("rsvp policy durations cannot be negative")
+	}
+	if p.OpensBefore > 0 && p.OpensBefore <= p.ClosesBefore {
+		return errors.New("rsvps must open before they close")
+	}
+	return nil
+}
+
+func (p RSVPPolicy) String() string {
+	return fmt.Sprintf("opens %s, closes %s and declines close %s before the start", p.OpensBefore, p.ClosesBefore,
+		p.DeclineCutoff)
+}
+
+// OpensAt is when RSVPs open for the friday, which is the zero time when they are open as soon as it is enabled
+func (p RSVPPolicy) OpensAt(friday Friday) time.Time {
+	if p.OpensBefore <= 0 {
+		return time.Time{}
+	}
+	return friday.StartsAt().Add(-p.OpensBefore)
+}
+
+// ClosesAt is when RSVPs close for the friday
+func (p RSVPPolicy) ClosesAt(friday Friday) time.Time {
+	return friday.StartsAt().Add(-p.ClosesBefore)
+}
+
+// DeclineClosesAt is the last moment guests can decline the friday
+func (p RSVPPolicy) DeclineClosesAt(friday Friday) time.Time {
+	return friday.StartsAt().Add(-p.DeclineCutoff)
+}
+
+// CheckRSVP returns ErrRSVPNotOpen or ErrRSVPClosed when guests cannot RSVP or join the waitlist at now
+func (p RSVPPolicy) CheckRSVP(friday Friday, now time.Time) error {
+	if opensAt := p.OpensAt(friday); !opensAt.IsZero() && now.Before(opensAt) {
+		return ErrRSVPNotOpen
+	}
+	if !now.Before(p.ClosesAt(friday)) {
+		return ErrRSVPClosed
+	}
+	return nil
+}
+
+// CheckDecline returns ErrDeclineClosed when guests that RSVP'ed cannot decline at now
+func (p RSVPPolicy) CheckDecline(friday Friday, now time.Time) error {
+	if !now.Before(p.DeclineClosesAt(friday)) {
+		return ErrDeclineClosed
+	}
+	return nil
+}
+
+// rsvpPolicy is the policy of the friday, or the policy of the deployment when the friday does not have its own
+func (s *Server) rsvpPolicy(friday Friday) RSVPPolicy {
+	if friday.Policy != nil {
+		return *friday.Policy
+	}
+	return s.config.RSVP
+}
+
+// parseRSVPPolicyForm reads the hours before the start at which RSVPs open and close and declines close from the edit
+// form. The friday follows the policy of the deployment when they are all empty, and otherwise the empty ones are
+// taken from it.
+func parseRSVPPolicyForm(opens, closes, declineCutoff string, deployment RSVPPolicy) (*RSVPPolicy, error) {
+	if len(opens) == 0 && len(closes) == 0 && len(declineCutoff) == 0 {
+		return nil, nil
+	}
+	policy := deployment
+	for _, field := range []struct {
+		value string
+		dest  *time.Duration
+	}{{opens, &policy.OpensBefore}, {closes, &policy.ClosesBefore}, {declineCutoff, &policy.DeclineCutoff}} {
+		if len(field.value) == 0 {
+			continue
+		}
+		hours, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return nil, errors.New("rsvp policy must be a number of hours")
+		}
+		*field.dest = time.Duration(hours * float64(time.Hour)).Truncate(time.Minute)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// policyHours writes a duration of the policy as hours for the edit form
+func policyHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64)
+}
+
+// rsvpNoticeFormat is how the notices about the policy write times
+const rsvpNoticeFormat = "Mon Jan 2 at 3:04pm MST"
+
+// rsvpNotice explains to guests why err stopped them, with the times in viewLoc
+func (s *Server) rsvpNotice(friday Friday, err error, viewLoc *time.Location) string {
+	policy := s.rsvpPolicy(friday)
+	switch err {
+	case ErrRSVPNotOpen:
+		return fmt.Sprintf("RSVPs open %s.", policy.OpensAt(friday).In(viewLoc).Format(rsvpNoticeFormat))
+	case ErrRSVPClosed:
+		return fmt.Sprintf("RSVPs closed %s.", policy.ClosesAt(friday).In(viewLoc).Format(rsvpNoticeFormat))
+	case ErrDeclineClosed:
+		return fmt.Sprintf("Declining closed %s, ask a host to take you off the list.",
+			policy.DeclineClosesAt(friday).In(viewLoc).Format(rsvpNoticeFormat))
+	}
+	return ""
+}
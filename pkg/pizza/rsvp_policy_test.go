@@ -0,0 +1,54 @@
+package pizza_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSVPPolicy_Check(t *testing.T) {
+	// GIVEN
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+	friday := pizza.Friday{Date: start}
+	policy := pizza.RSVPPolicy{
+		OpensBefore:   7 * 24 * time.Hour,
+		ClosesBefore:  2 * time.Hour,
+		DeclineCutoff: 24 * time.Hour,
+	}
+
+	// THEN
+	assert.ErrorIs(t, policy.CheckRSVP(friday, start.Add(-8*24*time.Hour)), pizza.ErrRSVPNotOpen)
+	assert.Nil(t, policy.CheckRSVP(friday, start.Add(-7*24*time.Hour)))
+	assert.Nil(t, policy.CheckRSVP(friday, start.Add(-3*time.Hour)))
+	assert.ErrorIs(t, policy.CheckRSVP(friday, start.Add(-2*time.Hour)), pizza.ErrRSVPClosed)
+	assert.ErrorIs(t, policy.CheckRSVP(friday, start.Add(time.Hour)), pizza.ErrRSVPClosed)
+	assert.Nil(t, policy.CheckDecline(friday, start.Add(-25*time.Hour)))
+	assert.ErrorIs(t, policy.CheckDecline(friday, start.Add(-24*time.Hour)), pizza.ErrDeclineClosed)
+	// the windows move with the friday
+	friday.Start = start.Add(24 * time.Hour)
+	assert.Nil(t, policy.CheckRSVP(friday, start))
+	assert.Nil(t, policy.CheckDecline(friday, start.Add(-time.Hour)))
+}
+
+func TestRSVPPolicy_AlwaysOpen(t *testing.T) {
+	// GIVEN
+	start := time.Date(2025, time.March, 7, 17, 30, 0, 0, time.UTC)
+	friday := pizza.Friday{Date: start}
+	policy := pizza.RSVPPolicy{}
+
+	// THEN
+	assert.True(t, policy.OpensAt(friday).IsZero())
+	assert.Nil(t, policy.CheckRSVP(friday, start.AddDate(-1, 0, 0)))
+	assert.Nil(t, policy.CheckDecline(friday, start.Add(-time.Minute)))
+	assert.ErrorIs(t, policy.CheckRSVP(friday, start), pizza.ErrRSVPClosed)
+}
+
+func TestRSVPPolicy_Validate(t *testing.T) {
+	assert.Nil(t, pizza.RSVPPolicy{}.Validate())
+	assert.Nil(t, pizza.RSVPPolicy{OpensBefore: time.Hour, ClosesBefore: time.Minute}.Validate())
+	assert.NotNil(t, pizza.RSVPPolicy{OpensBefore: time.Hour, ClosesBefore: time.Hour}.Validate())
+	assert.NotNil(t, pizza.RSVPPolicy{ClosesBefore: -time.Hour}.Validate())
+	assert.NotNil(t, pizza.RSVPPolicy{DeclineCutoff: -time.Hour}.Validate())
+}
@@ -8,6 +8,36 @@ import (
 	"os/signal"
 )
 
+// NewAccessor opens the store selected by the config, which is the SQLite database unless postgres or the memory
+// store are configured
+func NewAccessor(config Config, skipPatch bool) (Accessor, error) {
+	loc, err := config.Location()
+	if err != nil {
+		return nil, err
+	}
+	if config.UseMemoryStore {
+		slog.Warn("using the in-memory accessor, nothing will be saved")
+		accessor := NewMemoryAccessor()
+		accessor.Location = loc
+		return accessor, nil
+	} else if len(config.PostgresDSN) > 0 {
+		slog.Info("using the postgres accessor")
+		accessor, err := NewPostgresAccessor(config.PostgresDSN, skipPatch)
+		if err != nil {
+			return nil, err
+		}
+		accessor.Location = loc
+		return accessor, nil
+	}
+	slog.Info("using the sqlite accessor")
+	accessor, err := NewSQLAccessor(config.DBFile, skipPatch)
+	if err != nil {
+		return nil, err
+	}
+	accessor.Location = loc
+	return accessor, nil
+}
+
 func Run(args []string) error {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 	fs.Parse(args)
@@ -15,13 +45,22 @@ func Run(args []string) error {
 	ctx := context.Background()
 
 	config := LoadConfigEnv()
-	slog.Info("using the sqlite accessor")
-	accessor, err := NewSQLAccessor(config.DBFile, false)
+	loc, err := config.Location()
+	if err != nil {
+		return err
+	}
+	accessor, err := NewAccessor(config, false)
 	if err != nil {
 		return err
 	}
 
-	googleCal, err := NewGoogleCalendar(config.Calendar.CredentialFile, config.Calendar.TokenFile, config.Calendar.ID, ctx)
+	calConfig := config.Calendar
+	if !calConfig.Enabled {
+		// nothing is written to the calendar, but wrapped still reads from one, which needs no credentials
+		slog.Info("the calendar is disabled, reading from the local calendar", "dbFile", calConfig.DBFile)
+		calConfig.Provider = CalendarProviderLocal
+	}
+	cal, err := NewCalendar(ctx, calConfig, loc)
 	if err != nil {
 		return err
 	}
@@ -38,7 +77,7 @@ func Run(args []string) error {
 	}
 
 	metricsReg := NewPrometheusRegistry()
-	server, err := NewServer(config, accessor, googleCal, authenticator, metricsReg)
+	server, err := NewServer(config, accessor, cal, authenticator, metricsReg)
 	if err != nil {
 		slog.Error("could not create server", "error", err)
 		os.Exit(1)
@@ -0,0 +1,121 @@
+package pizza
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// defaultSeries is the series of the configured recurrence, which every friday belongs to unless it was scheduled by
+// another series. Its rule is not stored with it, see seriesRecurrence.
+func (s *Server) defaultSeries() Series {
+	return Series{
+		ID:          DefaultSeriesID,
+		Name:        "Pizza Friday",
+		Description: "Welcome to Pizza Friday!",
+		MaxGuests:   10,
+	}
+}
+
+// allSeries lists the default series followed by the series that hosts have added
+func (s *Server) allSeries() []Series {
+	result := []Series{s.defaultSeries()}
+	series, err := s.store.ListSeries()
+	if err != nil {
+		slog.Error("failed to list series", "error", err)
+		return result
+	}
+	return append(result, series...)
+}
+
+// getSeries is the series of the given ID, or the default series when there is no such series
+func (s *Server) getSeries(ID int64) Series {
+	if ID == DefaultSeriesID {
+		return s.defaultSeries()
+	}
+	series, err := s.store.GetSeries(ID)
+	if err != nil {
+		slog.Warn("failed to get series, using the default", "seriesID", ID, "error", err)
+		return s.defaultSeries()
+	}
+	return series
+}
+
+// seriesRecurrence is the rule that schedules the series. A series without a rule has no candidate dates and only
+// lists the fridays that were scheduled for it before.
+func (s *Server) seriesRecurrence(series Series) (Recurrence, bool) {
+	if series.ID == DefaultSeriesID {
+		return s.recurrence(), true
+	}
+	if len(series.Recurrence) == 0 {
+		return Recurrence{}, false
+	}
+	rec, err := ParseRecurrence(series.Recurrence, s.loc)
+	if err != nil {
+		slog.Error("invalid series recurrence", "series", series.Name, "rule", series.Recurrence, "error", err)
+		return Recurrence{}, false
+	}
+	return rec, true
+}
+
+// seriesAt finds the first series that schedules an event at t
+func (s *Server) seriesAt(t time.Time) (Series, bool) {
+	for _, series := range s.allSeries() {
+		if rec, ok := s.seriesRecurrence(series); ok && rec.Occurs(t) {
+			return series, true
+		}
+	}
+	return Series{}, false
+}
+
+// newSeriesFriday is a friday of the series that has not been saved yet, with the defaults of the series
+func newSeriesFriday(series Series, date time.Time) Friday {
+	friday := Friday{
+		Date:      date,
+		SeriesID:  series.ID,
+		Guests:    []string{},
+		MaxGuests: series.MaxGuests,
+	}
+	if series.Group != nil {
+		group := *series.Group
+		friday.Group = &group
+	}
+	return friday
+}
+
+// calendarFor is the calendar that the events of the friday's series are kept in
+func (s *Server) calendarFor(friday Friday) Calendar {
+	if friday.SeriesID == DefaultSeriesID {
+		return s.calendar
+	}
+	series := s.getSeries(friday.SeriesID)
+	if len(series.CalendarID) == 0 {
+		return s.calendar
+	}
+	if switcher, ok := s.calendar.(CalendarSwitcher); ok {
+		return switcher.WithCalendarID(series.CalendarID)
+	}
+	slog.Warn("calendar cannot switch calendars, using the default", "series", series.Name)
+	return s.calendar
+}
+
+// groupBySeries splits the fridays by the series they belong to, in the order of the series. Fridays of a series
+// that is not listed are shown with the default series.
+func groupBySeries(series []Series, fridays []IndexFridayData) []IndexSeriesData {
+	groups := make([]IndexSeriesData, len(series))
+	position := make(map[int64]int)
+	for i, sr := range series {
+		groups[i] = IndexSeriesData{ID: sr.ID, Name: sr.Name, Description: sr.Description}
+		position[sr.ID] = i
+	}
+	for _, friday := range fridays {
+		i, ok := position[friday.SeriesID]
+		if !ok {
+			i = position[DefaultSeriesID]
+		}
+		groups[i].FridayTimes = append(groups[i].FridayTimes, friday)
+	}
+	return slices.DeleteFunc(groups, func(group IndexSeriesData) bool {
+		return len(group.FridayTimes) == 0
+	})
+}
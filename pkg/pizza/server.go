@@ -2,16 +2,20 @@ package pizza
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"html/template"
 	"log/slog"
 	"net/http"
 	"path"
+	"slices"
 	"strconv"
-	"text/template"
+	"strings"
 	"time"
 )
 
+// EventDuration is how long a friday lasts unless the host changes it
 var EventDuration = time.Hour * 4
 
 type WrappedData struct {
@@ -22,6 +26,7 @@ type WrappedData struct {
 type Server struct {
 	s             http.Server
 	config        Config
+	loc           *time.Location
 	store         Accessor
 	calendar      Calendar
 	authenticator Authenticator
@@ -32,10 +37,33 @@ type Server struct {
 	requestErrorMetric  CounterMetric
 	internalErrorMetric CounterMetric
 
+	autoEnabledMetric      CounterMetric
+	autoEnableErrorMetric  CounterMetric
+	autoEnablePausedMetric CounterMetric
+
+	calendarSyncMetric      CounterMetric
+	calendarSyncErrorMetric CounterMetric
+	calendarDriftMetrics    map[string]CounterMetric
+
 	wrapped map[int]WrappedData
+
+	// ctx is cancelled when the server stops, which stops the background work
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewServer(config Config, accessor Accessor, calendar Calendar, auth Authenticator, metricsReg MetricsRegistry) (*Server, error) {
+	loc, err := config.Location()
+	if err != nil {
+		return nil, err
+	}
+	if err = config.RSVP.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rsvp policy: %w", err)
+	}
+	if err = config.Calendar.Sync.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	mux := http.NewServeMux()
 
 	s := Server{
@@ -46,6 +74,7 @@ func NewServer(config Config, accessor Accessor, calendar Calendar, auth Authent
 			Handler:      mux,
 		},
 		config:        config,
+		loc:           loc,
 		store:         accessor,
 		calendar:      calendar,
 		authenticator: auth,
@@ -60,8 +89,22 @@ func NewServer(config Config, accessor Accessor, calendar Calendar, auth Authent
 			map[string]string{"statusCode": "4xx"}),
 		internalErrorMetric: metricsReg.NewCounterMetric("pizza_errors",
 			map[string]string{"statusCode": "500"}),
+		autoEnabledMetric: metricsReg.NewCounterMetric("pizza_auto_enable",
+			map[string]string{"result": "enabled"}),
+		autoEnableErrorMetric: metricsReg.NewCounterMetric("pizza_auto_enable",
+			map[string]string{"result": "failed"}),
+		autoEnablePausedMetric: metricsReg.NewCounterMetric("pizza_auto_enable",
+			map[string]string{"result": "paused"}),
+		calendarSyncMetric: metricsReg.NewCounterMetric("pizza_calendar_sync",
+			map[string]string{"result": "complete"}),
+		calendarSyncErrorMetric: metricsReg.NewCounterMetric("pizza_calendar_sync",
+			map[string]string{"result": "failed"}),
+		calendarDriftMetrics: newCalendarDriftMetrics(metricsReg),
 
 		wrapped: map[int]WrappedData{},
+
+		ctx:    ctx,
+		cancel: cancel,
 	}
 
 	s.LoadRoutes(mux)
@@ -82,6 +125,8 @@ func (s *Server) LoadRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /x/friday/{ID}", s.HandleFriday)
 	mux.HandleFunc("POST /x/rsvp", s.HandleRSVP)
 	mux.HandleFunc("DELETE /x/rsvp", s.HandleDeleteRSVP)
+	mux.HandleFunc("POST /x/waitlist", s.HandleJoinWaitlist)
+	mux.HandleFunc("POST /x/maybe", s.HandleTentative)
 	mux.HandleFunc("GET /x/friday/{ID}/edit", s.HandleFridayGetEdit)
 	mux.HandleFunc("POST /x/friday/{ID}/edit", s.HandleFridaySaveEdit)
 	mux.HandleFunc("POST /x/friday/{ID}/enable", s.HandleFridayEnable)
@@ -89,6 +134,12 @@ func (s *Server) LoadRoutes(mux *http.ServeMux) {
 
 	mux.HandleFunc("GET /profile", s.HandleGetProfile)
 	mux.HandleFunc("POST /profile/edit", s.HandleUpdateProfile)
+	mux.HandleFunc("POST /profile/feed", s.HandleUpdateCalendarFeed)
+	mux.HandleFunc("POST /profile/feed/rotate", s.HandleRotateCalendarFeed)
+
+	mux.HandleFunc("GET /ical/{file}", s.HandleCalendarFeed)
+
+	mux.HandleFunc("GET /audit", s.HandleAudit)
 
 	mux.HandleFunc("POST /api/token", s.HandleAPIAuth)
 	mux.HandleFunc("GET /api/friday", s.HandleAPIFriday)
@@ -96,13 +147,44 @@ func (s *Server) LoadRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PATCH /api/friday/{ID}", s.HandleAPIFriday)
 	mux.HandleFunc("GET /api/guest/{ID}", s.HandleAPIGuest)
 	mux.HandleFunc("GET /api/guest/{ID}/profile", s.HandleAPIGuestProfile)
+	mux.HandleFunc("GET /api/audit", s.HandleAPIAudit)
+	mux.HandleFunc("GET /api/recurrence", s.HandleAPIRecurrence)
+	mux.HandleFunc("PATCH /api/recurrence", s.HandleAPIRecurrence)
+	mux.HandleFunc("GET /api/series", s.HandleAPISeries)
+	mux.HandleFunc("POST /api/series", s.HandleAPISeries)
+	mux.HandleFunc("GET /api/series/{ID}", s.HandleAPISeries)
+	mux.HandleFunc("PATCH /api/series/{ID}", s.HandleAPISeries)
+	mux.HandleFunc("GET /api/venue", s.HandleAPIVenue)
+	mux.HandleFunc("POST /api/venue", s.HandleAPIVenue)
+	mux.HandleFunc("GET /api/venue/{ID}", s.HandleAPIVenue)
+	mux.HandleFunc("PATCH /api/venue/{ID}", s.HandleAPIVenue)
+	mux.HandleFunc("GET /api/auto-enable", s.HandleAPIAutoEnable)
+	mux.HandleFunc("PATCH /api/auto-enable", s.HandleAPIAutoEnable)
 
 	mux.HandleFunc("GET /p/{ID}", s.HandlePizza)
 }
 
 func (s *Server) Start() error {
-	// watch the calendar to keep credentials renewed and learn when they have expired
-	go s.WatchCalendar(1 * time.Hour)
+	// watch the calendar to keep credentials renewed, learn when they have expired and reconcile the guest lists
+	go s.WatchCalendar(s.ctx, s.config.Calendar.Sync.Interval)
+	// snapshot the database when backups are configured
+	if len(s.config.Backup.Dir) > 0 {
+		if backuper, ok := s.store.(Backuper); !ok {
+			slog.Warn("scheduled backups are not supported by the accessor")
+		} else if s.config.Backup.Interval > 0 {
+			go s.WatchBackups(s.ctx, backuper, s.config.Backup.Interval)
+		} else {
+			slog.Warn("scheduled backups need a positive interval")
+		}
+	}
+	// create and enable upcoming fridays ahead of time
+	if s.config.AutoEnable.Enabled {
+		if s.config.AutoEnable.Interval > 0 {
+			go s.WatchAutoEnable(s.ctx, s.config.AutoEnable.Interval)
+		} else {
+			slog.Warn("auto-enable needs a positive interval")
+		}
+	}
 	// start the HTTP server
 	if err := s.s.ListenAndServe(); err != http.ErrServerClosed {
 		slog.Error("http listen error", "error", err)
@@ -112,59 +194,73 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Stop() {
+	s.cancel()
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 	defer cancel()
 	s.s.Shutdown(ctx)
 }
 
-func (s *Server) WatchCalendar(period time.Duration) {
-	timer := time.NewTimer(0)
-	estZone, _ := time.LoadLocation("America/New_York")
-	for {
-		<-timer.C
-		fridays, err := s.store.GetUpcomingFridays(30)
-		if err != nil {
-			slog.Error("[sync] failed to get upcoming fridays", "err", err)
-			timer.Reset(1 * time.Minute)
-		}
-		for _, friday := range fridays {
-			t := friday.Date.In(estZone)
-			eventID := strconv.FormatInt(t.Unix(), 10)
-			event, err := s.calendar.GetEvent(eventID)
-			if err != nil {
-				slog.Warn("[sync] failed to get calendar event", "err", err, "eventID", eventID)
-			} else {
-				for _, attendee := range event.Attendees {
-					if attendee.ResponseStatus == "declined" {
-						if err = s.store.RemoveFriendFromFriday(attendee.Email, t); err != nil {
-							slog.Error("[sync] failed to remove friend from friday after calendar decline", "err", err, "email", attendee.Email, "eventID", eventID)
-						}
-					}
-				}
-			}
-		}
-
-		slog.Info("[sync] calendar sync complete")
-		timer.Reset(period)
-	}
+type IndexFridayData struct {
+	Date             string
+	ShortDate        string
+	StartTime        string
+	Timezone         string
+	EndTime          string
+	DurationMinutes  int
+	ID               int64
+	Guests           []Friend
+	Waitlist         []Friend
+	WaitlistPosition int
+	Active           bool
+	Group            string
+	Details          string
+	IsInvited        bool
+	MaxGuests        int
+	CanEdit          bool
+	CanPlusOne       bool
+	SeriesID         int64
+	// Blackout is why there is no pizza on the day, or empty when there is no blackout
+	Blackout string
+	// RSVPNotice and DeclineNotice explain why guests cannot RSVP or decline right now, and are empty when they can
+	RSVPNotice    string
+	DeclineNotice string
+	// RSVPOpensHours, RSVPClosesHours and DeclineCutoffHours are the policy of the friday for hosts to edit, which are
+	// empty when it follows the policy of the deployment
+	RSVPOpensHours     string
+	RSVPClosesHours    string
+	DeclineCutoffHours string
+	// VenueID, Venue and VenueAddress are where the party is, and are empty when the host has not picked a venue
+	VenueID      int64
+	Venue        string
+	VenueAddress string
+	// Venues are the venues that hosts can pick in the edit form
+	Venues []Venue
+	// Hosts is who hosts the friday for the friday card. Host and CoHosts are their emails for the edit form, where
+	// only the owner and admins can change the Host.
+	Hosts         string
+	Host          string
+	CoHosts       string
+	CanChangeHost bool
+	// PlusOnes are the guests without an account, and GuestCount is how many guests are coming with them included
+	PlusOnes   []IndexPlusOne
+	GuestCount int
+	// Tentative are the maybes, who do not take up a spot, and IsTentative is whether the user is one of them
+	Tentative      []Friend
+	TentativeCount int
+	IsTentative    bool
 }
 
-type IndexFridayData struct {
-	Date       string
-	ShortDate  string
-	ID         int64
-	Guests     []Friend
-	Active     bool
-	Group      string
-	Details    string
-	IsInvited  bool
-	MaxGuests  int
-	CanEdit    bool
-	CanPlusOne bool
+// IndexSeriesData lists the upcoming fridays of one series
+type IndexSeriesData struct {
+	ID          int64
+	Name        string
+	Description string
+	FridayTimes []IndexFridayData
 }
 
 type PageData struct {
 	FridayTimes []IndexFridayData
+	Series      []IndexSeriesData
 	Name        string
 	LoggedIn    bool
 	LogoutURL   string
@@ -182,7 +278,7 @@ func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
 	claims, ok := s.authenticateRequest(r)
 	if ok {
 		data.LoggedIn = true
-		data.IsAdmin = claims.HasRole("pizza_host")
+		data.IsAdmin = isAdmin(claims)
 
 		slog.Info("welcome", "name", claims.Name)
 
@@ -193,70 +289,85 @@ func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
 		data.Name = claims.GivenName
 		data.LogoutURL = fmt.Sprintf("%s/%s?post_logout_redirect_uri=%s/logout&client_id=%s", s.authenticator.GetAuthURL(), "../logout", s.config.OAuth2.RedirectURL, "pizza")
 
+		viewLoc := s.loc
 		if prefs, err := s.store.GetPreferences(claims.Email); err != nil {
 			slog.Error("failed to get user preferences", "email", claims.Email, "err", err)
 		} else {
-			data.PixelPizza.Pizza = NewPixelPizzaFromPreferences(prefs).Render("darkblue")
-			data.PixelPizza.Size = "12px"
+			data.PixelPizza = newPixelPizzaPageData(NewPixelPizzaFromPreferences(prefs), "12px")
+			viewLoc = s.preferredLocation(prefs)
 		}
 
-		fridays, err := s.loadAllFridays()
+		series := s.allSeries()
+		fridays, err := s.loadAllFridays(series)
 		if err != nil {
 			slog.Error("failed to get fridays", "error", err)
 			s.Handle500(w, r)
 			return
 		}
+		blackouts := s.blackouts()
 		data.FridayTimes = make([]IndexFridayData, 0)
 		for _, friday := range fridays {
-			if claims.HasRole("pizza_host") ||
+			if canHostFriday(claims, friday) ||
 				(friday.Enabled && friday.Group == nil) ||
 				(friday.Enabled && friday.Group != nil && claims.InGroup(*friday.Group)) {
 				// skip friday when the user is not in the invited group unless they are the host
-				fData := s.newIndexFridayData(&friday, claims)
+				fData := s.newIndexFridayData(&friday, claims, viewLoc)
+				fData.Blackout = blackouts[s.blackoutDay(friday)]
 				data.FridayTimes = append(data.FridayTimes, *fData)
 			}
 		}
+		data.Series = groupBySeries(series, data.FridayTimes)
 	}
 
 	s.executeTemplate(w, "Index", data)
 }
 
-func (s *Server) CreateAndInvite(ID string, friday Friday, email, name string) error {
-	newEvent := CalendarEvent{
+func (s *Server) CreateAndInvite(ID string, friday Friday, email, name, createdBy string) error {
+	// update local table with new guest list, which fails if there is no room left
+	if err := s.store.AddFriendToFriday(email, friday, createdBy); err != nil {
+		if err != ErrFridayIsFull {
+			slog.Error("update to local invite list failed", "error", err)
+		}
+		return err
+	}
+
+	return s.inviteToEvent(ID, friday, email, name, RSVPAccepted)
+}
+
+// newCalendarEvent is the event of the friday that guests are invited to, named after its series
+func (s *Server) newCalendarEvent(ID string, friday Friday) CalendarEvent {
+	series := s.getSeries(friday.SeriesID)
+	return CalendarEvent{
 		AnyoneCanAddSelf:      false,
-		Description:           "Welcome to Pizza Friday!",
-		StartTime:             friday.Date,
+		Description:           series.Description,
+		StartTime:             friday.StartsAt(),
 		GuestsCanInviteOthers: false,
 		GuestsCanModify:       false,
 		Id:                    ID,
+		Location:              s.eventLocation(friday),
 		Locked:                true,
-		EndTime:               friday.Date.Add(time.Hour + 5),
+		EndTime:               friday.EndsAt(),
 		Status:                "confirmed",
-		Summary:               "Pizza Friday",
+		Summary:               series.Name,
 		Visibility:            "private",
 	}
+}
 
-	if len(friday.Guests) >= friday.MaxGuests {
-		return ErrFridayIsFull
-	}
-
-	// update local table with new guest list
-	if err := s.store.AddFriendToFriday(email, friday); err != nil {
-		slog.Error("update to local invite list failed", "error", err)
-		return err
-	}
-
+// inviteToEvent invites the guest to the calendar event of the friday with the status of their RSVP, creating the event
+// when it does not exist yet
+func (s *Server) inviteToEvent(ID string, friday Friday, email, name, status string) error {
 	if !s.config.Calendar.Enabled {
 		return nil
 	}
 
-	err := s.calendar.InviteToEvent(ID, email, name)
+	cal := s.calendarFor(friday)
+	err := cal.InviteToEvent(ID, email, name, status)
 	if err != nil && err == ErrEventNotFound {
-		if err = s.calendar.CreateEvent(newEvent); err != nil {
+		if err = cal.CreateEvent(s.newCalendarEvent(ID, friday)); err != nil {
 			slog.Error("could not create event", "eventID", ID, "email", email, "error", err)
 			return err
 		}
-		err = s.calendar.InviteToEvent(ID, email, name)
+		err = cal.InviteToEvent(ID, email, name, status)
 	}
 	if err != nil {
 		slog.Error("invite failed", "eventID", ID, "email", email, "error", err)
@@ -267,9 +378,71 @@ func (s *Server) CreateAndInvite(ID string, friday Friday, email, name string) e
 	return nil
 }
 
+// updateEvent moves the calendar event after the host changed when the friday starts, how long it lasts or where it is
+func (s *Server) updateEvent(friday Friday) {
+	if !s.config.Calendar.Enabled {
+		return
+	}
+	ID := strconv.FormatInt(friday.Date.Unix(), 10)
+	err := s.calendarFor(friday).UpdateEvent(CalendarEvent{
+		Id:        ID,
+		StartTime: friday.StartsAt(),
+		EndTime:   friday.EndsAt(),
+		Location:  s.eventLocation(friday),
+	})
+	if err == ErrEventNotFound {
+		// nobody has RSVP'ed yet, so the event will be created at the new time
+		return
+	} else if err != nil {
+		slog.Error("failed to update event", "err", err, "eventID", ID)
+	}
+}
+
+// promoteWaitlist fills any open spots on the friday from its waitlist and sends the promoted guests their invites
+func (s *Server) promoteWaitlist(friday Friday, source string) {
+	promoted, err := s.store.PromoteFromWaitlist(friday.Date)
+	if err != nil {
+		slog.Error("failed to promote from waitlist", "err", err, "friday", friday.Date)
+		return
+	}
+	ID := strconv.FormatInt(friday.Date.Unix(), 10)
+	for _, email := range promoted {
+		slog.Info("promoted from waitlist", "email", email, "friday", ID)
+		s.audit(AuditEntry{
+			Action: "promote",
+			Friday: friday.Date,
+			Target: email,
+			Before: RSVPWaitlisted,
+			After:  RSVPAccepted,
+			Source: source,
+		})
+		name := ""
+		if friend, err := s.store.GetFriendByEmail(email); err == nil {
+			name = friend.Name
+		}
+		if err = s.inviteToEvent(ID, friday, email, name, RSVPAccepted); err != nil {
+			slog.Error("failed to invite guest promoted from waitlist", "err", err, "email", email, "friday", ID)
+		}
+	}
+}
+
 type PixelPizzaPageData struct {
 	Size  string
-	Pizza [][]string
+	Pizza [][]template.CSS
+}
+
+// newPixelPizzaPageData renders the pizza on a dark blue background. The colors come from the pizza types rather than
+// from users, so they are trusted as CSS.
+func newPixelPizzaPageData(p *PixelPizza, size string) PixelPizzaPageData {
+	data := PixelPizzaPageData{Size: size}
+	for _, row := range p.Render("darkblue") {
+		colors := make([]template.CSS, len(row))
+		for i, color := range row {
+			colors[i] = template.CSS(color)
+		}
+		data.Pizza = append(data.Pizza, colors)
+	}
+	return data
 }
 
 func (s *Server) HandlePizza(w http.ResponseWriter, r *http.Request) {
@@ -316,20 +489,17 @@ func (s *Server) Handle500(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func parseFridayTime(fridayID string) (time.Time, error) {
+func (s *Server) parseFridayTime(fridayID string) (time.Time, error) {
 	num, err := strconv.ParseInt(fridayID, 10, 64)
 	if err != nil {
 		slog.Error("failed parsing date int from rsvp form", "date", fridayID)
 		return time.Time{}, err
 	}
-	// TODO load timezone once somewhere
-	estZone, _ := time.LoadLocation("America/New_York")
-	return time.Unix(num, 0).In(estZone), nil
+	return time.Unix(num, 0).In(s.loc), nil
 }
 
 var (
 	ErrFridayNotFound = errors.New("friday not found")
-	ErrFridayIsFull   = errors.New("friday is full")
 )
 
 func (s *Server) loadFriday(fridayTime time.Time, claims *TokenClaims) (*Friday, error) {
@@ -339,7 +509,8 @@ func (s *Server) loadFriday(fridayTime time.Time, claims *TokenClaims) (*Friday,
 		return nil, ErrFridayNotFound
 	}
 
-	if !claims.HasRole("pizza_host") && (friday.Group != nil && !claims.InGroup(*friday.Group)) {
+	if !canHostFriday(claims, friday) &&
+		(friday.Group != nil && !claims.InGroup(*friday.Group)) {
 		// not part of invited group
 		return nil, ErrFridayNotFound
 	}
@@ -347,49 +518,71 @@ func (s *Server) loadFriday(fridayTime time.Time, claims *TokenClaims) (*Friday,
 	return &friday, nil
 }
 
-func (s *Server) loadAllFridays() ([]Friday, error) {
-	allFridayTimes := getFutureFridays()
+// loadAllFridays merges the upcoming dates of each series with the fridays that have been saved, which may have been
+// scheduled by an older recurrence. A date that more than one series schedules belongs to the first of them.
+func (s *Server) loadAllFridays(series []Series) ([]Friday, error) {
 	setFridays, err := s.store.GetUpcomingFridays(futureFridayLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	fridayIndex := 0
-	result := make([]Friday, 0)
-	for _, fridayTime := range allFridayTimes {
-		if fridayIndex < len(setFridays) && fridayTime.Equal(setFridays[fridayIndex].Date) {
-			result = append(result, setFridays[fridayIndex])
-			fridayIndex++
-		} else {
-			result = append(result, Friday{
-				Date:   fridayTime,
-				Guests: make([]string, 0),
-			})
+	result := make([]Friday, 0, len(setFridays))
+	saved := make(map[int64]bool)
+	for _, friday := range setFridays {
+		result = append(result, friday)
+		saved[friday.Date.Unix()] = true
+	}
+	for _, sr := range series {
+		rec, ok := s.seriesRecurrence(sr)
+		if !ok {
+			continue
+		}
+		for _, fridayTime := range getFutureFridays(rec) {
+			if !saved[fridayTime.Unix()] {
+				result = append(result, newSeriesFriday(sr, fridayTime))
+				saved[fridayTime.Unix()] = true
+			}
 		}
 	}
+	slices.SortFunc(result, func(a, b Friday) int {
+		return a.Date.Compare(b.Date)
+	})
 	return result, nil
 }
 
-func (s *Server) newIndexFridayData(friday *Friday, claims *TokenClaims) *IndexFridayData {
+// newIndexFridayData shows the friday in the viewer's timezone, except for the start time on the edit form, which is
+// in the timezone of the deployment
+func (s *Server) newIndexFridayData(friday *Friday, claims *TokenClaims, viewLoc *time.Location) *IndexFridayData {
+	start := friday.StartsAt().In(viewLoc)
 	fData := IndexFridayData{
 		MaxGuests:  friday.MaxGuests,
-		ShortDate:  fmt.Sprintf("%s %d", friday.Date.Month().String(), friday.Date.Day()),
-		CanEdit:    claims.HasRole("pizza_host"),
-		CanPlusOne: claims.HasRole("pizza_host") || claims.HasRole("plusOne"),
+		ShortDate:  fmt.Sprintf("%s %d", start.Month().String(), start.Day()),
+		CanEdit:    canHostFriday(claims, *friday),
+		CanPlusOne: isAdmin(claims) || claims.HasRole("plusOne"),
 		Active:     friday.Enabled,
-	}
-	// TODO load timezone once somewhere
-	estZone, _ := time.LoadLocation("America/New_York")
-	t := friday.Date
-	t = t.In(estZone)
-	fData.Date = t.Format(time.RFC822)
-	fData.ID = t.Unix()
+		SeriesID:   friday.SeriesID,
+		VenueID:    friday.VenueID,
+	}
+	fData.ID = friday.Date.Unix()
+	fData.Date = start.Format(time.RFC822)
+	fData.StartTime = friday.StartsAt().In(s.loc).Format(fridayClockFormat)
+	fData.Timezone = s.loc.String()
+	fData.EndTime = friday.EndsAt().In(viewLoc).Format(fridayClockFormat)
+	fData.DurationMinutes = int(friday.EndsAt().Sub(start) / time.Minute)
 	if friday.Details != nil {
 		fData.Details = *friday.Details
 	}
 	if friday.Group != nil {
 		fData.Group = *friday.Group
 	}
+	if venue, ok := s.fridayVenue(*friday); ok {
+		fData.Venue = venue.Name
+		fData.VenueAddress = venue.Address
+	}
+	fData.Hosts = s.hostNames(*friday)
+	fData.Host = friday.Host
+	fData.CoHosts = strings.Join(friday.CoHosts, ", ")
+	fData.CanChangeHost = canChangeHost(claims, *friday)
 	// add indicator if guest has already RSVP'ed for this friday
 	fData.IsInvited = false
 	for _, guest := range friday.Guests {
@@ -398,18 +591,44 @@ func (s *Server) newIndexFridayData(friday *Friday, claims *TokenClaims) *IndexF
 		}
 	}
 
-	fData.Guests = make([]Friend, len(friday.Guests))
-	for k, attendee := range friday.Guests {
-		fData.Guests[k].Email = attendee
-		if friend, err := s.store.GetFriendByEmail(attendee); err == nil {
-			fData.Guests[k].Name = friend.Name
-			fData.Guests[k].ID = friend.ID
-		}
+	fData.Guests = s.loadFriends(friday.Guests)
+	fData.Waitlist = s.loadFriends(friday.Waitlist)
+	fData.PlusOnes = s.indexPlusOnes(*friday, claims)
+	fData.GuestCount = friday.GuestCount()
+	fData.Tentative = s.loadFriends(friday.Tentative)
+	fData.TentativeCount = len(friday.Tentative)
+	fData.IsTentative = slices.Contains(friday.Tentative, claims.Email)
+	fData.WaitlistPosition = slices.Index(friday.Waitlist, claims.Email) + 1
+
+	if friday.Policy != nil {
+		fData.RSVPOpensHours = policyHours(friday.Policy.OpensBefore)
+		fData.RSVPClosesHours = policyHours(friday.Policy.ClosesBefore)
+		fData.DeclineCutoffHours = policyHours(friday.Policy.DeclineCutoff)
+	}
+	policy := s.rsvpPolicy(*friday)
+	now := time.Now()
+	if err := policy.CheckRSVP(*friday, now); err != nil {
+		fData.RSVPNotice = s.rsvpNotice(*friday, err, viewLoc)
+	}
+	if err := policy.CheckDecline(*friday, now); err != nil {
+		fData.DeclineNotice = s.rsvpNotice(*friday, err, viewLoc)
 	}
 
 	return &fData
 }
 
+func (s *Server) loadFriends(emails []string) []Friend {
+	friends := make([]Friend, len(emails))
+	for k, email := range emails {
+		friends[k].Email = email
+		if friend, err := s.store.GetFriendByEmail(email); err == nil {
+			friends[k].Name = friend.Name
+			friends[k].ID = friend.ID
+		}
+	}
+	return friends
+}
+
 func (s *Server) loadTemplates(w http.ResponseWriter) *template.Template {
 	plate, err := template.ParseGlob(path.Join(s.config.StaticDir, "html/*.html"))
 	if err != nil {
@@ -440,20 +659,69 @@ func (s *Server) executeTemplate(w http.ResponseWriter, name string, data any) {
 
 const futureFridayLimit = 30
 
-func getFutureFridays() []time.Time {
-	dates := make([]time.Time, 0)
-	loc, _ := time.LoadLocation("America/New_York")
-	start := time.Now()
-	friday := time.Date(start.Year(), start.Month(), start.Day(), 17, 30, 0, 0, loc)
-	for friday.Weekday() != time.Friday {
-		friday = friday.AddDate(0, 0, 1)
+// fridayClockFormat is how start and end times are shown and entered on the edit form
+const fridayClockFormat = "15:04"
+
+// parseFridayStart reads a start time from the edit form, which is on the same day as the friday in loc
+func parseFridayStart(friday Friday, clock string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(fridayClockFormat, clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d := friday.Date.In(loc)
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+}
+
+// displayLocation is the timezone the friend chose in their profile, or the timezone of the deployment
+func (s *Server) displayLocation(email string) *time.Location {
+	prefs, err := s.store.GetPreferences(email)
+	if err != nil {
+		return s.loc
 	}
-	endDate := time.Now().AddDate(0, 0, futureFridayLimit)
-	for friday.Before(endDate) {
-		dates = append(dates, friday)
-		friday = friday.AddDate(0, 0, 7)
+	return s.preferredLocation(prefs)
+}
+
+func (s *Server) preferredLocation(prefs Preferences) *time.Location {
+	if len(prefs.Timezone) == 0 {
+		return s.loc
 	}
-	return dates
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		slog.Warn("invalid timezone in preferences", "timezone", prefs.Timezone)
+		return s.loc
+	}
+	return loc
+}
+
+// recurrenceSetting is the name of the setting that holds the recurrence saved by a host
+const recurrenceSetting = "recurrence"
+
+// recurrence is the rule that fridays are scheduled by. A rule saved by a host takes precedence over the config, and
+// the default rule of every friday is used when neither can be parsed.
+func (s *Server) recurrence() Recurrence {
+	text := s.config.Recurrence
+	if saved, err := s.store.GetSetting(recurrenceSetting); err == nil {
+		text = saved
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("failed to get saved recurrence", "error", err)
+	}
+	if len(text) == 0 {
+		text = DefaultRecurrence
+	}
+	rec, err := ParseRecurrence(text, s.loc)
+	if err != nil {
+		slog.Error("invalid recurrence, falling back to the default", "rule", text, "error", err)
+		rec, _ = ParseRecurrence(DefaultRecurrence, s.loc)
+	}
+	return rec
+}
+
+// getFutureFridays lists the dates of the recurrence from the start of today until futureFridayLimit days from now
+func getFutureFridays(rec Recurrence) []time.Time {
+	now := time.Now().In(rec.Start.Location())
+	// fridays are identified by their default start time, which hosts can move on each friday
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return rec.Between(today, now.AddDate(0, 0, futureFridayLimit))
 }
 
 func getToast(msg string) []byte {
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	jsonapi "github.com/hashicorp/jsonapi"
@@ -25,6 +27,14 @@ func WriteAPIError(err error, status int, w http.ResponseWriter) {
 	jsonapi.MarshalErrors(w, allErrs)
 }
 
+// WriteAPIErrorStatus is WriteAPIError that also sets the status of the response, which the newer endpoints do while
+// the older ones keep answering errors with 200 for the clients that read the status from the body
+func WriteAPIErrorStatus(err error, status int, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(status)
+	WriteAPIError(err, status, w)
+}
+
 func (s *Server) HandleAPIAuth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusNotFound)
@@ -88,7 +98,6 @@ func (s *Server) HandleAPIFriday(w http.ResponseWriter, r *http.Request) {
 func (s *Server) HandleAPIGetFriday(accessToken *AccessToken, w http.ResponseWriter, r *http.Request) {
 	fridays := make([]Friday, 0)
 	var err error
-	estZone, _ := time.LoadLocation("America/New_York")
 
 	fridayID := r.PathValue("ID")
 	isDirectReq := len(fridayID) > 0
@@ -99,7 +108,7 @@ func (s *Server) HandleAPIGetFriday(accessToken *AccessToken, w http.ResponseWri
 			return
 		}
 
-		f, err := s.store.GetFriday(time.Unix(rawTime, 0).In(estZone))
+		f, err := s.store.GetFriday(time.Unix(rawTime, 0).In(s.loc))
 		if err != nil {
 			WriteAPIError(fmt.Errorf("no matching friday found with ID '%s'", fridayID), http.StatusNotFound, w)
 			return
@@ -116,21 +125,38 @@ func (s *Server) HandleAPIGetFriday(accessToken *AccessToken, w http.ResponseWri
 	}
 
 	res := make([]*api.Friday, 0)
+	series := make(map[int64]*api.Series)
+	venues := make(map[int64]*api.Venue)
+	blackouts := s.blackouts()
 	for _, f := range fridays {
 		id := strconv.FormatInt(f.Date.Unix(), 10)
 
 		friday := &api.Friday{
-			ID:        id,
-			StartTime: f.Date,
-			Guests:    nil,
+			ID:              id,
+			StartTime:       f.StartsAt(),
+			DurationMinutes: apiDurationMinutes(f),
+			Guests:          nil,
+		}
+		s.setAPIRSVPPolicy(friday, f)
+		if _, ok := series[f.SeriesID]; !ok {
+			series[f.SeriesID] = s.apiSeries(s.getSeries(f.SeriesID))
+		}
+		friday.Series = series[f.SeriesID]
+		if f.VenueID != 0 {
+			if _, ok := venues[f.VenueID]; !ok {
+				venues[f.VenueID] = s.apiFridayVenue(f)
+			}
+			friday.Venue = venues[f.VenueID]
 		}
+		friday.Hosts = s.apiFridayHosts(f)
 
 		if f.Details != nil {
 			friday.Details = *f.Details
 		}
 
-		// not part of invited group OR friday is disabled
-		if (f.Group != nil && !accessToken.Claims.InGroup(*f.Group)) || !f.Enabled {
+		// not part of invited group OR friday is disabled OR there is no pizza that day
+		_, blackedOut := blackouts[s.blackoutDay(f)]
+		if (f.Group != nil && !accessToken.Claims.InGroup(*f.Group)) || !f.Enabled || blackedOut {
 			// if this friday was specifically requested, the response needs to be 404
 			if isDirectReq {
 				WriteAPIError(fmt.Errorf("no matching friday found with ID '%s'", fridayID), http.StatusNotFound, w)
@@ -139,16 +165,9 @@ func (s *Server) HandleAPIGetFriday(accessToken *AccessToken, w http.ResponseWri
 			continue
 		}
 
-		friday.Guests = make([]*api.Guest, len(f.Guests))
-		for i, attendee := range f.Guests {
-			if friend, err := s.store.GetFriendByEmail(attendee); err == nil {
-				g := &api.Guest{
-					ID:   friend.ID,
-					Name: friend.Name,
-				}
-				friday.Guests[i] = g
-			}
-		}
+		friday.Guests = s.apiFridayGuests(f)
+		friday.Waitlist = s.apiGuests(f.Waitlist, RSVPWaitlisted)
+		friday.WaitlistPosition = slices.Index(f.Waitlist, accessToken.Claims.Email) + 1
 
 		res = append(res, friday)
 	}
@@ -181,14 +200,13 @@ func (s *Server) HandleAPIPatchFriday(accessToken *AccessToken, w http.ResponseW
 		return
 	}
 
-	estZone, _ := time.LoadLocation("America/New_York")
 	rawTime, err := strconv.ParseInt(friday.ID, 10, 64)
 	if err != nil {
 		WriteAPIError(err, http.StatusBadRequest, w)
 		return
 	}
 
-	f, err := s.store.GetFriday(time.Unix(rawTime, 0).In(estZone))
+	f, err := s.store.GetFriday(time.Unix(rawTime, 0).In(s.loc))
 	if err != nil {
 		WriteAPIError(fmt.Errorf("no matching friday found with ID '%s'", friday.ID), http.StatusNotFound, w)
 		return
@@ -196,16 +214,67 @@ func (s *Server) HandleAPIPatchFriday(accessToken *AccessToken, w http.ResponseW
 	if f.Details != nil {
 		friday.Details = *f.Details
 	}
-	friday.StartTime = f.Date
 
-	// not part of invited group OR friday not enabled
-	if (f.Group != nil && !accessToken.Claims.InGroup(*f.Group)) || !f.Enabled {
+	// not part of invited group OR friday not enabled OR there is no pizza that day
+	_, blackedOut := s.isBlackedOut(f)
+	if (f.Group != nil && !accessToken.Claims.InGroup(*f.Group)) || !f.Enabled || blackedOut {
 		WriteAPIError(fmt.Errorf("no matching friday found with ID '%s'", friday.ID), http.StatusNotFound, w)
 		return
 	}
 
-	// check the requested guests
-	for _, g := range friday.Guests {
+	// hosts can move the friday, which is all the request does when it has no guests
+	movesFriday := (!friday.StartTime.IsZero() && !friday.StartTime.Equal(f.StartsAt())) ||
+		(friday.DurationMinutes != 0 && friday.DurationMinutes != apiDurationMinutes(f))
+	if movesFriday {
+		if !canHostFriday(&accessToken.Claims, f) {
+			WriteAPIErrorStatus(errors.New("only hosts can change the start time or duration"), http.StatusForbidden, w)
+			return
+		}
+		if friday.DurationMinutes < 0 {
+			WriteAPIErrorStatus(errors.New("duration must be a positive number of minutes"), http.StatusBadRequest, w)
+			return
+		}
+		before := fridaySettings(f)
+		if !friday.StartTime.IsZero() {
+			f.Start = friday.StartTime.In(s.loc)
+		}
+		if friday.DurationMinutes > 0 {
+			f.Duration = time.Duration(friday.DurationMinutes) * time.Minute
+		}
+		if err = s.store.UpdateFriday(f); err != nil {
+			slog.Error("failed to update friday", "error", err, "friday", friday.ID)
+			WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+			return
+		}
+		s.audit(AuditEntry{
+			Actor:  accessToken.Claims.Email,
+			Action: "edit",
+			Friday: f.Date,
+			Before: before,
+			After:  fridaySettings(f),
+			Source: AuditSourceAPI,
+		})
+		s.updateEvent(f)
+	}
+	friday.StartTime = f.StartsAt()
+	friday.DurationMinutes = apiDurationMinutes(f)
+	s.setAPIRSVPPolicy(friday, f)
+	if movesFriday && len(friday.Guests) == 0 && len(friday.Waitlist) == 0 {
+		friday.Guests = s.apiFridayGuests(f)
+		friday.Series = s.apiSeries(s.getSeries(f.SeriesID))
+		friday.Venue = s.apiFridayVenue(f)
+		friday.Hosts = s.apiFridayHosts(f)
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		w.WriteHeader(http.StatusOK)
+		if err = jsonapi.MarshalPayload(w, friday); err != nil {
+			slog.Warn("api marshal payload", "error", err)
+			WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+		}
+		return
+	}
+
+	// check the requested guests, who may RSVP or join the waitlist for themselves
+	for _, g := range slices.Concat(friday.Guests, friday.Waitlist) {
 		// backwards compatibility for RSVPing using email instead of ID
 		if g.ID != accessToken.Claims.Email {
 			self, err := s.store.GetFriendByID(g.ID)
@@ -216,47 +285,92 @@ func (s *Server) HandleAPIPatchFriday(accessToken *AccessToken, w http.ResponseW
 		}
 	}
 
-	if friday.StartTime.Before(time.Now()) {
-		// TODO inappropriate usage of StatusNotModified
-		WriteAPIError(errors.New("friday is in the past"), http.StatusNotModified, w)
-		return
-	}
-
-	if friday.StartTime.After(time.Now().AddDate(0, 1, 0)) {
-		WriteAPIError(errors.New("friday is more than one month away"), http.StatusTooEarly, w)
-		return
+	before := rsvpStatus(f, accessToken.Claims.Email)
+	tentative := slices.ContainsFunc(friday.Guests, func(g *api.Guest) bool { return g.Status == RSVPTentative })
+	if tentative && before == RSVPAccepted {
+		// giving up a spot is declining it, which stays open after RSVPs close until the decline cutoff
+		err = s.rsvpPolicy(f).CheckDecline(f, time.Now())
+	} else {
+		err = s.rsvpPolicy(f).CheckRSVP(f, time.Now())
 	}
-
-	if friday.StartTime.Before(time.Now().Add(time.Hour * 24)) {
-		// TODO inappropriate usage of StatusNotModified
-		WriteAPIError(errors.New("modifications cannot be made less than 24 hours before the friday"), http.StatusNotModified, w)
+	if err != nil {
+		notice := s.rsvpNotice(f, err, s.displayLocation(accessToken.Claims.Email))
+		WriteAPIErrorStatus(errors.New(notice), http.StatusForbidden, w)
 		return
 	}
 
 	// all good to update invite
 	slog.Info("rsvp request", "email", accessToken.Claims.Email)
-
-	if err = s.CreateAndInvite(friday.ID, f, accessToken.Claims.Email, accessToken.Claims.Name); err != nil {
+	if len(friday.Waitlist) > 0 {
+		if err = s.store.AddFriendToWaitlist(accessToken.Claims.Email, f.Date); err != nil {
+			slog.Error("failed to add friend to waitlist", "error", err, "email", accessToken.Claims.Email)
+			WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+			return
+		}
+		if len(before) == 0 {
+			s.audit(AuditEntry{
+				Actor:  accessToken.Claims.Email,
+				Action: "waitlist",
+				Friday: f.Date,
+				Target: accessToken.Claims.Email,
+				After:  RSVPWaitlisted,
+				Source: AuditSourceAPI,
+			})
+		}
+		s.promoteWaitlist(f, AuditSourceAPI)
+	} else if tentative {
+		if err = s.markTentative(f, accessToken.Claims.Email, accessToken.Claims.Name, AuditSourceAPI); err != nil {
+			WriteAPIErrorStatus(errors.New("calendar failure"), http.StatusInternalServerError, w)
+			return
+		}
+	} else if err = s.CreateAndInvite(friday.ID, f, accessToken.Claims.Email, accessToken.Claims.Name, ""); err == ErrFridayIsFull {
+		WriteAPIErrorStatus(errors.New("friday is full, join the waitlist instead"), http.StatusConflict, w)
+		return
+	} else if err != nil {
 		WriteAPIError(errors.New("calendar failure"), http.StatusInternalServerError, w)
 		return
+	} else if before != RSVPAccepted {
+		s.audit(AuditEntry{
+			Actor:  accessToken.Claims.Email,
+			Action: "rsvp",
+			Friday: f.Date,
+			Target: accessToken.Claims.Email,
+			Before: before,
+			After:  RSVPAccepted,
+			Source: AuditSourceAPI,
+		})
+	}
+
+	friday.Waitlist = nil
+	updated, err := s.store.GetFriday(f.Date)
+	if err == nil {
+		friday.Waitlist = s.apiGuests(updated.Waitlist, RSVPWaitlisted)
+		friday.WaitlistPosition = slices.Index(updated.Waitlist, accessToken.Claims.Email) + 1
 	}
 
 	// TODO replace GetEvent with local guest list
-	if event, err := s.calendar.GetEvent(friday.ID); err != nil && err != ErrEventNotFound {
+	if !s.config.Calendar.Enabled {
+		// there is no event to read the guests from
+		friday.Guests = s.apiFridayGuests(updated)
+	} else if event, err := s.calendarFor(f).GetEvent(friday.ID); err != nil && err != ErrEventNotFound {
 		slog.Warn("failed to get calendar event", "error", err, "eventID", friday.ID)
 	} else {
 		friday.Guests = make([]*api.Guest, 0)
 		for _, attendee := range event.Attendees {
 			if friend, err := s.store.GetFriendByEmail(attendee.Email); err == nil {
 				g := &api.Guest{
-					ID:   friend.ID,
-					Name: friend.Name,
+					ID:     friend.ID,
+					Name:   friend.Name,
+					Status: rsvpStatus(updated, attendee.Email),
 				}
 				friday.Guests = append(friday.Guests, g)
 			}
 		}
 	}
 
+	friday.Series = s.apiSeries(s.getSeries(f.SeriesID))
+	friday.Venue = s.apiFridayVenue(f)
+	friday.Hosts = s.apiFridayHosts(f)
 	w.Header().Set("Content-Type", jsonapi.MediaType)
 	w.WriteHeader(http.StatusOK)
 
@@ -266,6 +380,103 @@ func (s *Server) HandleAPIPatchFriday(accessToken *AccessToken, w http.ResponseW
 	}
 }
 
+// apiDurationMinutes is how long the friday lasts
+func apiDurationMinutes(friday Friday) int {
+	return int(friday.EndsAt().Sub(friday.StartsAt()) / time.Minute)
+}
+
+// setAPIRSVPPolicy fills in when guests can RSVP to and decline the friday
+func (s *Server) setAPIRSVPPolicy(friday *api.Friday, f Friday) {
+	policy := s.rsvpPolicy(f)
+	friday.RSVPOpensAt = policy.OpensAt(f)
+	friday.RSVPClosesAt = policy.ClosesAt(f)
+	friday.DeclineClosesAt = policy.DeclineClosesAt(f)
+}
+
+func (s *Server) HandleAPIRecurrence(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := s.CheckAuthorization(r)
+	if !ok {
+		WriteAPIErrorStatus(errors.New("not authorized"), http.StatusUnauthorized, w)
+		return
+	}
+
+	if r.Header.Get("Accept") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("must accept %s", jsonapi.MediaType), http.StatusNotAcceptable, w)
+		return
+	}
+
+	rec := s.recurrence()
+	if r.Method == http.MethodPatch {
+		if r.Header.Get("Content-Type") != jsonapi.MediaType {
+			WriteAPIErrorStatus(fmt.Errorf("unsupported media type '%s'", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType, w)
+			return
+		}
+		if !isAdmin(&accessToken.Claims) {
+			WriteAPIErrorStatus(errors.New("only hosts can change the recurrence"), http.StatusForbidden, w)
+			return
+		}
+		payload, err := api.UnmarshalRecurrence(r.Body)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		newRec, err := ParseRecurrence(payload.Rule, s.loc)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		if err = s.store.SetSetting(recurrenceSetting, newRec.String()); err != nil {
+			slog.Error("failed to save recurrence", "error", err)
+			WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+			return
+		}
+		s.audit(AuditEntry{
+			Actor:  accessToken.Claims.Email,
+			Action: "recurrence",
+			Before: rec.String(),
+			After:  newRec.String(),
+			Source: AuditSourceAPI,
+		})
+		rec = newRec
+	}
+
+	res := &api.Recurrence{
+		ID:       "default",
+		Rule:     rec.String(),
+		Upcoming: make([]string, 0),
+	}
+	for _, fridayTime := range getFutureFridays(rec) {
+		res.Upcoming = append(res.Upcoming, strconv.FormatInt(fridayTime.Unix(), 10))
+	}
+
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(http.StatusOK)
+	if err := jsonapi.MarshalPayload(w, res); err != nil {
+		slog.Warn("api marshal payload", "error", err)
+		WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+	}
+}
+
+// apiFridayGuests are the guests of the friday followed by its maybes, who are told apart by their status
+func (s *Server) apiFridayGuests(friday Friday) []*api.Guest {
+	return append(s.apiGuests(friday.Guests, RSVPAccepted), s.apiGuests(friday.Tentative, RSVPTentative)...)
+}
+
+// apiGuests converts emails to guest resources with the status of their RSVP, skipping anyone who is not a known friend
+func (s *Server) apiGuests(emails []string, status string) []*api.Guest {
+	guests := make([]*api.Guest, 0, len(emails))
+	for _, email := range emails {
+		if friend, err := s.store.GetFriendByEmail(email); err == nil {
+			guests = append(guests, &api.Guest{
+				ID:     friend.ID,
+				Name:   friend.Name,
+				Status: status,
+			})
+		}
+	}
+	return guests
+}
+
 func (s *Server) HandleAPIGuest(w http.ResponseWriter, r *http.Request) {
 	_, ok := s.CheckAuthorization(r)
 	if !ok {
@@ -331,7 +542,7 @@ func (s *Server) HandleAPIGuestProfile(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// only the pizza hosts can view other guest profiles
-		if self.ID != guestID && !token.Claims.HasRole("pizza_host") {
+		if self.ID != guestID && !isAdmin(&token.Claims) {
 			WriteAPIError(errors.New("not authorized to view guest profile"), http.StatusForbidden, w)
 			return
 		}
@@ -378,3 +589,160 @@ func (s *Server) HandleAPIGuestProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 }
+
+// apiSeries converts the series to a resource, with the current recurrence for the default series
+func (s *Server) apiSeries(series Series) *api.Series {
+	res := &api.Series{
+		ID:          strconv.FormatInt(series.ID, 10),
+		Name:        series.Name,
+		Description: series.Description,
+		MaxGuests:   series.MaxGuests,
+		Rule:        series.Recurrence,
+		CalendarID:  series.CalendarID,
+	}
+	if series.Group != nil {
+		res.Group = *series.Group
+	}
+	if series.ID == DefaultSeriesID {
+		res.Rule = s.recurrence().String()
+	}
+	return res
+}
+
+func (s *Server) HandleAPISeries(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := s.CheckAuthorization(r)
+	if !ok {
+		WriteAPIErrorStatus(errors.New("not authorized"), http.StatusUnauthorized, w)
+		return
+	}
+
+	if r.Header.Get("Accept") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("must accept %s", jsonapi.MediaType), http.StatusNotAcceptable, w)
+		return
+	}
+
+	seriesID := r.PathValue("ID")
+	var series Series
+	if len(seriesID) > 0 {
+		ID, err := strconv.ParseInt(seriesID, 10, 64)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		if ID == DefaultSeriesID {
+			series = s.defaultSeries()
+		} else if series, err = s.store.GetSeries(ID); err != nil {
+			WriteAPIErrorStatus(fmt.Errorf("no matching series found with ID '%s'", seriesID), http.StatusNotFound, w)
+			return
+		}
+	}
+
+	if r.Method == http.MethodGet {
+		var err error
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		w.WriteHeader(http.StatusOK)
+		if len(seriesID) > 0 {
+			err = jsonapi.MarshalPayload(w, s.apiSeries(series))
+		} else {
+			res := make([]*api.Series, 0)
+			for _, series := range s.allSeries() {
+				res = append(res, s.apiSeries(series))
+			}
+			err = jsonapi.MarshalPayload(w, res)
+		}
+		if err != nil {
+			slog.Warn("api marshal payload", "error", err)
+			WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+		}
+		return
+	}
+
+	if r.Header.Get("Content-Type") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("unsupported media type '%s'", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType, w)
+		return
+	}
+	if !isAdmin(&accessToken.Claims) {
+		WriteAPIErrorStatus(errors.New("only hosts can change series"), http.StatusForbidden, w)
+		return
+	}
+	if len(seriesID) > 0 && series.ID == DefaultSeriesID {
+		WriteAPIErrorStatus(errors.New("the default series is changed with /api/recurrence"), http.StatusBadRequest, w)
+		return
+	}
+	payload, err := api.UnmarshalSeries(r.Body)
+	if err != nil {
+		WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+		return
+	}
+	if len(strings.TrimSpace(payload.Name)) == 0 {
+		WriteAPIErrorStatus(errors.New("series must have a name"), http.StatusBadRequest, w)
+		return
+	}
+	if payload.MaxGuests < 0 {
+		WriteAPIErrorStatus(errors.New("max_guests must not be negative"), http.StatusBadRequest, w)
+		return
+	}
+	for _, other := range s.allSeries() {
+		if other.Name == strings.TrimSpace(payload.Name) && (len(seriesID) == 0 || other.ID != series.ID) {
+			WriteAPIErrorStatus(fmt.Errorf("a series named '%s' already exists", other.Name), http.StatusConflict, w)
+			return
+		}
+	}
+	rule := ""
+	if len(payload.Rule) > 0 {
+		rec, err := ParseRecurrence(payload.Rule, s.loc)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		rule = rec.String()
+	}
+
+	before := ""
+	if len(seriesID) > 0 {
+		before = seriesSettings(series)
+	} else {
+		// new series take the default number of guests unless they say otherwise
+		series.MaxGuests = s.defaultSeries().MaxGuests
+	}
+	series.Name = strings.TrimSpace(payload.Name)
+	series.Description = payload.Description
+	series.Group = nil
+	if len(payload.Group) > 0 {
+		group := payload.Group
+		series.Group = &group
+	}
+	if payload.MaxGuests > 0 {
+		series.MaxGuests = payload.MaxGuests
+	}
+	series.Recurrence = rule
+	series.CalendarID = payload.CalendarID
+
+	status := http.StatusOK
+	if len(seriesID) > 0 {
+		err = s.store.UpdateSeries(series)
+	} else {
+		status = http.StatusCreated
+		series.ID, err = s.store.AddSeries(series)
+	}
+	if err != nil {
+		slog.Error("failed to save series", "error", err, "name", series.Name)
+		WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+		return
+	}
+	s.audit(AuditEntry{
+		Actor:  accessToken.Claims.Email,
+		Action: "series",
+		Target: series.Name,
+		Before: before,
+		After:  seriesSettings(series),
+		Source: AuditSourceAPI,
+	})
+
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(status)
+	if err = jsonapi.MarshalPayload(w, s.apiSeries(series)); err != nil {
+		slog.Warn("api marshal payload", "error", err)
+		WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+	}
+}
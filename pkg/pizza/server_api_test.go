@@ -2,11 +2,16 @@ package pizza_test
 
 import (
 	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,6 +24,52 @@ import (
 	require "github.com/stretchr/testify/require"
 )
 
+// assertJSONAPIEq compares two JSON:API documents regardless of the order of their included resources, which the
+// encoder does not keep
+func assertJSONAPIEq(t *testing.T, expected, actual string) {
+	normalize := func(raw string) string {
+		var doc map[string]any
+		require.Nil(t, json.Unmarshal([]byte(raw), &doc))
+		if included, ok := doc["included"].([]any); ok {
+			slices.SortFunc(included, func(a, b any) int {
+				x, y := a.(map[string]any), b.(map[string]any)
+				return strings.Compare(fmt.Sprint(x["type"], x["id"]), fmt.Sprint(y["type"], y["id"]))
+			})
+		}
+		normalized, err := json.Marshal(doc)
+		require.Nil(t, err)
+		return string(normalized)
+	}
+	assert.JSONEq(t, normalize(expected), normalize(actual))
+}
+
+// defaultSeriesRule is the rule of the default series as it appears in a response
+func defaultSeriesRule(t *testing.T) string {
+	rec, err := pizza.ParseRecurrence(pizza.DefaultRecurrence, mustLoadNY(t))
+	require.Nil(t, err)
+	raw, err := json.Marshal(rec.String())
+	require.Nil(t, err)
+	return string(raw)
+}
+
+func TestWriteAPIError(t *testing.T) {
+	// GIVEN
+	w := httptest.NewRecorder()
+	withStatus := httptest.NewRecorder()
+
+	// WHEN
+	pizza.WriteAPIError(errors.New("friday is full"), http.StatusConflict, w)
+	pizza.WriteAPIErrorStatus(errors.New("friday is full"), http.StatusConflict, withStatus)
+
+	// THEN
+	// the older endpoints only report the status in the body
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"409"`)
+	assert.Equal(t, http.StatusConflict, withStatus.Code)
+	assert.Equal(t, jsonapi.MediaType, withStatus.Header().Get("Content-Type"))
+	assert.Equal(t, w.Body.String(), withStatus.Body.String())
+}
+
 func TestHandleApiToken(t *testing.T) {
 	// GIVEN
 	config := pizza.LoadConfigEnv()
@@ -102,6 +153,8 @@ func TestHandleApiGetFriday(t *testing.T) {
 	friday1ID := strconv.FormatInt(friday1.Date.Unix(), 10)
 	accessor.On("GetUpcomingFridays", 30).Return([]pizza.Friday{friday1}, nil)
 	accessor.On("GetFriendByEmail", "kirk").Return(pizza.Friend{ID: "1", Name: "Captain Kirk"}, nil)
+	accessor.On("ListBlackouts").Return([]pizza.Blackout{}, nil)
+	accessor.On("GetSetting", "recurrence").Return("", sql.ErrNoRows)
 
 	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
 	require.Nil(t, err)
@@ -122,19 +175,27 @@ func TestHandleApiGetFriday(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 	b, err := io.ReadAll(res.Body)
 	assert.Nil(t, err)
+	defaultRule := defaultSeriesRule(t)
 	expected := fmt.Sprintf(`{
 	"data":[{
 		"type":"friday",
 		"id":"%s",
 		"attributes":{
 			"details":"details",
-			"start_time":%s
+			"duration_minutes":240,
+			"start_time":%s,
+			"rsvp_opens_at":%d,
+			"rsvp_closes_at":%d,
+			"decline_closes_at":%d
 		},
 		"relationships":{
 			"guests":{
 				"data":[
 					{"id":"1","type":"guest"}
 				]
+			},
+			"series":{
+				"data":{"id":"0","type":"series"}
 			}
 		},
 		"links":{
@@ -146,17 +207,32 @@ func TestHandleApiGetFriday(t *testing.T) {
 			"id":"1",
 			"type":"guest",
 			"attributes": {
-				"name":"Captain Kirk"
+				"name":"Captain Kirk",
+				"status":"accepted"
 			},
 			"links": {
 				"self": "/api/guest/1",
 				"profile": "/api/guest/1/profile"
 			}
+		},
+		{
+			"id":"0",
+			"type":"series",
+			"attributes": {
+				"name":"Pizza Friday",
+				"description":"Welcome to Pizza Friday!",
+				"max_guests":10,
+				"rule":%s
+			},
+			"links": {
+				"self": "/api/series/0"
+			}
 		}
 	]
 }`,
-		friday1ID, friday1ID, friday1ID)
-	assert.JSONEq(t, expected, string(b))
+		friday1ID, friday1ID, friday1.Date.Add(-31*24*time.Hour).Unix(), friday1.Date.Add(-24*time.Hour).Unix(),
+		friday1.Date.Add(-24*time.Hour).Unix(), friday1ID, defaultRule)
+	assertJSONAPIEq(t, expected, string(b))
 
 	authenticator.AssertExpectations(t)
 	accessor.AssertExpectations(t)
@@ -198,9 +274,14 @@ func TestHandleApiPatchFriday(t *testing.T) {
 		MaxGuests: 5,
 	}
 	accessor.On("GetFriday", friday.Date).Return(friday, nil)
-	accessor.On("AddFriendToFriday", token.Claims.Email, friday).Return(nil)
+	accessor.On("AddFriendToFriday", token.Claims.Email, friday, "").Return(nil)
+	accessor.On("ListBlackouts").Return([]pizza.Blackout{}, nil)
+	accessor.On("AddAuditEntry", mock.MatchedBy(func(entry pizza.AuditEntry) bool {
+		return entry.Action == "rsvp" && entry.Target == token.Claims.Email && entry.Source == pizza.AuditSourceAPI
+	})).Return(nil).Once()
 	accessor.On("GetFriendByEmail", mock.Anything).Return(pizza.Friend{ID: "2", Name: "Spock"}, nil)
-	calendar.On("InviteToEvent", reqFriday.ID, token.Claims.Email, token.Claims.GivenName).Return(nil)
+	accessor.On("GetSetting", "recurrence").Return("", sql.ErrNoRows)
+	calendar.On("InviteToEvent", reqFriday.ID, token.Claims.Email, token.Claims.GivenName, pizza.RSVPAccepted).Return(nil)
 	event := pizza.CalendarEvent{
 		Attendees: []pizza.CalendarAttendee{{Email: "spock"}},
 	}
@@ -229,19 +310,27 @@ func TestHandleApiPatchFriday(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 	b, err := io.ReadAll(res.Body)
 	assert.Nil(t, err)
+	defaultRule := defaultSeriesRule(t)
 	expected := fmt.Sprintf(`{
 	"data":{
 		"type":"friday",
 		"id":"%s",
 		"attributes":{
 			"details":"details",
-			"start_time":%s
+			"duration_minutes":240,
+			"start_time":%s,
+			"rsvp_opens_at":%d,
+			"rsvp_closes_at":%d,
+			"decline_closes_at":%d
 		},
 		"relationships":{
 			"guests":{
 				"data":[
 					{"id":"2","type":"guest"}
 				]
+			},
+			"series":{
+				"data":{"id":"0","type":"series"}
 			}
 		},
 		"links":{
@@ -259,11 +348,25 @@ func TestHandleApiPatchFriday(t *testing.T) {
 				"self": "/api/guest/2",
 				"profile": "/api/guest/2/profile"
 			}
+		},
+		{
+			"id":"0",
+			"type":"series",
+			"attributes": {
+				"name":"Pizza Friday",
+				"description":"Welcome to Pizza Friday!",
+				"max_guests":10,
+				"rule":%s
+			},
+			"links": {
+				"self": "/api/series/0"
+			}
 		}
 	]
 }`,
-		reqFriday.ID, reqFriday.ID, reqFriday.ID)
-	assert.JSONEq(t, expected, string(b))
+		reqFriday.ID, reqFriday.ID, fTime.Add(-31*24*time.Hour).Unix(), fTime.Add(-24*time.Hour).Unix(),
+		fTime.Add(-24*time.Hour).Unix(), reqFriday.ID, defaultRule)
+	assertJSONAPIEq(t, expected, string(b))
 
 	authenticator.AssertExpectations(t)
 	accessor.AssertExpectations(t)
@@ -422,3 +525,1008 @@ func TestHandleApiGetGuestProfile(t *testing.T) {
 	authenticator.AssertExpectations(t)
 	accessor.AssertExpectations(t)
 }
+
+func TestHandleApiPatchFriday_Full(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := &pizza.MockAccessor{}
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	fTime := time.Unix(time.Now().Add(time.Hour*36).Unix(), 0)
+	reqFriday := &api.Friday{
+		ID: strconv.FormatInt(fTime.Unix(), 10),
+	}
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	token := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "token").Return(token, nil)
+	friday := pizza.Friday{
+		Date:      fTime.In(estZone),
+		Enabled:   true,
+		MaxGuests: 1,
+	}
+	accessor.On("GetFriday", friday.Date).Return(friday, nil)
+	accessor.On("AddFriendToFriday", token.Claims.Email, friday, "").Return(pizza.ErrFridayIsFull)
+	accessor.On("ListBlackouts").Return([]pizza.Blackout{}, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	reqBody := &bytes.Buffer{}
+	require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	req.Header.Add("Content-Type", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	authenticator.AssertExpectations(t)
+	accessor.AssertExpectations(t)
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleApiGetAudit(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := &pizza.MockAccessor{}
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+	entry := pizza.AuditEntry{
+		ID:     7,
+		Time:   time.Unix(1672000000, 0).UTC(),
+		Actor:  "host@bar.com",
+		Action: "remove",
+		Friday: time.Unix(1672060005, 0).In(estZone),
+		Target: "foo@bar.com",
+		Before: pizza.RSVPAccepted,
+		After:  pizza.RSVPDeclined,
+		Source: pizza.AuditSourceWeb,
+	}
+	filter := pizza.AuditFilter{
+		Friday: entry.Friday,
+		Target: "foo@bar.com",
+		Limit:  50,
+	}
+	accessor.On("ListAuditEntries", filter).Return([]pizza.AuditEntry{entry}, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/audit?friday=1672060005&guest=foo@bar.com", nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer host")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	b, err := io.ReadAll(res.Body)
+	assert.Nil(t, err)
+	expected := `{
+	"data":[
+		{
+			"type":"audit",
+			"id":"7",
+			"attributes":{
+				"time":1672000000,
+				"actor":"host@bar.com",
+				"action":"remove",
+				"friday":"1672060005",
+				"target":"foo@bar.com",
+				"before":"accepted",
+				"after":"declined",
+				"source":"web"
+			}
+		}
+	]
+}`
+	assert.JSONEq(t, expected, string(b))
+
+	// WHEN
+	req, err = http.NewRequest(http.MethodGet, ts.URL+"/api/audit", nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer guest")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	authenticator.AssertExpectations(t)
+	accessor.AssertExpectations(t)
+}
+
+func TestHandleApiPatchFriday_Move(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+	fTime := time.Unix(time.Now().Add(time.Hour*72).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true}))
+	reqFriday := &api.Friday{
+		ID:              strconv.FormatInt(fTime.Unix(), 10),
+		StartTime:       fTime.Add(time.Hour),
+		DurationMinutes: 90,
+	}
+	calendar.On("UpdateEvent", mock.MatchedBy(func(event pizza.CalendarEvent) bool {
+		return event.Id == reqFriday.ID && event.StartTime.Equal(fTime.Add(time.Hour)) &&
+			event.EndTime.Equal(fTime.Add(150*time.Minute))
+	})).Return(pizza.ErrEventNotFound).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		token  string
+		status int
+	}{
+		{"guest", http.StatusForbidden},
+		{"host", http.StatusOK},
+	} {
+		// WHEN
+		reqBody := &bytes.Buffer{}
+		require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+tc.token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, tc.status, res.StatusCode, tc.token)
+	}
+	friday, err := accessor.GetFriday(fTime)
+	assert.Nil(t, err)
+	assert.True(t, fTime.Add(time.Hour).Equal(friday.StartsAt()))
+	assert.Equal(t, 90*time.Minute, friday.Duration)
+	assert.Empty(t, friday.Guests)
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{Friday: fTime})
+	assert.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "edit", entries[0].Action)
+	assert.Equal(t, pizza.AuditSourceAPI, entries[0].Source)
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleApiRecurrence(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	biweekly := "DTSTART:20250102T180000 RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TH"
+	for _, tc := range []struct {
+		token  string
+		rule   string
+		status int
+	}{
+		{"guest", biweekly, http.StatusForbidden},
+		{"host", "DTSTART:20250102T180000 RRULE:FREQ=DAILY", http.StatusBadRequest},
+		{"host", biweekly, http.StatusOK},
+	} {
+		// WHEN
+		reqBody := &bytes.Buffer{}
+		require.Nil(t, jsonapi.MarshalPayload(reqBody, &api.Recurrence{ID: "default", Rule: tc.rule}))
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/recurrence", reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+tc.token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, tc.status, res.StatusCode, tc.token, tc.rule)
+	}
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/recurrence", nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer guest")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	recurrence, err := api.UnmarshalRecurrence(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	saved, err := accessor.GetSetting("recurrence")
+	assert.Nil(t, err)
+	assert.Equal(t, saved, recurrence.Rule)
+	assert.Contains(t, recurrence.Rule, "INTERVAL=2;BYDAY=TH")
+	assert.NotEmpty(t, recurrence.Upcoming)
+	for _, id := range recurrence.Upcoming {
+		rawTime, err := strconv.ParseInt(id, 10, 64)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Thursday, time.Unix(rawTime, 0).In(estZone).Weekday())
+	}
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{})
+	assert.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "recurrence", entries[0].Action)
+	assert.Equal(t, recurrence.Rule, entries[0].After)
+}
+
+func TestHandleApiSeries(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path, token string, series *api.Series) *http.Response {
+		reqBody := &bytes.Buffer{}
+		if series != nil {
+			require.Nil(t, jsonapi.MarshalPayload(reqBody, series))
+		}
+		req, err := http.NewRequest(method, ts.URL+path, reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		return res
+	}
+	tuesdays := &api.Series{
+		Name:      "Pizza Tuesday",
+		Group:     "book club",
+		MaxGuests: 4,
+		Rule:      "DTSTART:20250107T190000 RRULE:FREQ=WEEKLY;BYDAY=TU",
+	}
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		token  string
+		series *api.Series
+		status int
+	}{
+		{http.MethodPost, "/api/series", "guest", tuesdays, http.StatusForbidden},
+		{http.MethodPost, "/api/series", "host", &api.Series{Name: " "}, http.StatusBadRequest},
+		{http.MethodPost, "/api/series", "host", &api.Series{Name: "Daily", Rule: "DTSTART:20250107T190000 RRULE:FREQ=DAILY"}, http.StatusBadRequest},
+		{http.MethodPost, "/api/series", "host", tuesdays, http.StatusCreated},
+		{http.MethodPost, "/api/series", "host", &api.Series{Name: "Pizza Friday"}, http.StatusConflict},
+		{http.MethodPatch, "/api/series/0", "host", &api.Series{ID: "0", Name: "Pizza Night"}, http.StatusBadRequest},
+		{http.MethodPatch, "/api/series/7", "host", &api.Series{ID: "7", Name: "Pizza Night"}, http.StatusNotFound},
+		{http.MethodPatch, "/api/series/1", "host", &api.Series{ID: "1", Name: "Pizza Tuesday", Description: "Pizza and books", Group: "book club", Rule: tuesdays.Rule}, http.StatusOK},
+	} {
+		// WHEN
+		res := do(tc.method, tc.path, tc.token, tc.series)
+
+		// THEN
+		assert.Equal(t, tc.status, res.StatusCode, tc.method, tc.path, tc.token)
+	}
+
+	// WHEN
+	res := do(http.MethodGet, "/api/series", "guest", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	all, err := api.UnmarshalManySeries(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "0", all[0].ID)
+	assert.Equal(t, "Pizza Friday", all[0].Name)
+	assert.Contains(t, all[0].Rule, "BYDAY=FR")
+	assert.Equal(t, "1", all[1].ID)
+	assert.Equal(t, "Pizza and books", all[1].Description)
+	assert.Equal(t, "book club", all[1].Group)
+	// max guests are kept when the update leaves them out
+	assert.Equal(t, 4, all[1].MaxGuests)
+	assert.Contains(t, all[1].Rule, "BYDAY=TU")
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{})
+	assert.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "series", entries[0].Action)
+	assert.Equal(t, "Pizza Tuesday", entries[0].Target)
+	assert.NotEmpty(t, entries[0].Before)
+	assert.Empty(t, entries[1].Before)
+}
+
+func TestHandleApiVenue(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path, token string, venue *api.Venue) *http.Response {
+		reqBody := &bytes.Buffer{}
+		if venue != nil {
+			require.Nil(t, jsonapi.MarshalPayload(reqBody, venue))
+		}
+		req, err := http.NewRequest(method, ts.URL+path, reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		return res
+	}
+	matts := &api.Venue{Name: "Matt's", Address: "1 Main St", Capacity: 8, Ovens: 1}
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		token  string
+		venue  *api.Venue
+		status int
+	}{
+		{http.MethodPost, "/api/venue", "guest", matts, http.StatusForbidden},
+		{http.MethodPost, "/api/venue", "host", &api.Venue{Name: " "}, http.StatusBadRequest},
+		{http.MethodPost, "/api/venue", "host", &api.Venue{Name: "Tiny", Capacity: -1}, http.StatusBadRequest},
+		{http.MethodPost, "/api/venue", "host", matts, http.StatusCreated},
+		{http.MethodPost, "/api/venue", "host", &api.Venue{Name: "Allie's", Capacity: 12, Ovens: 2}, http.StatusCreated},
+		{http.MethodPatch, "/api/venue/2", "host", &api.Venue{ID: "2", Name: "Matt's"}, http.StatusConflict},
+		{http.MethodPatch, "/api/venue/7", "host", &api.Venue{ID: "7", Name: "Nowhere"}, http.StatusNotFound},
+		{http.MethodPatch, "/api/venue/1", "host", &api.Venue{ID: "1", Name: "Matt's", Address: "1 Main St", Capacity: 10, Notes: "ring the bell", Ovens: 1}, http.StatusOK},
+	} {
+		// WHEN
+		res := do(tc.method, tc.path, tc.token, tc.venue)
+
+		// THEN
+		assert.Equal(t, tc.status, res.StatusCode, tc.method, tc.path, tc.token)
+	}
+
+	// WHEN
+	res := do(http.MethodGet, "/api/venue", "guest", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	all, err := api.UnmarshalVenues(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Allie's", all[0].Name)
+	assert.Equal(t, &api.Venue{ID: "1", Name: "Matt's", Address: "1 Main St", Capacity: 10, Notes: "ring the bell",
+		Ovens: 1}, all[1])
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{})
+	assert.Nil(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "venue", entries[0].Action)
+	assert.Equal(t, "Matt's", entries[0].Target)
+	assert.NotEmpty(t, entries[0].Before)
+
+	// GIVEN
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 10, Enabled: true, VenueID: 1}))
+
+	// WHEN
+	res = do(http.MethodGet, fmt.Sprintf("/api/friday/%d", fridayTime.Unix()), "guest", nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err := api.UnmarshalFriday(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	require.NotNil(t, friday.Venue)
+	assert.Equal(t, "Matt's", friday.Venue.Name)
+	assert.Equal(t, 10, friday.Venue.Capacity)
+}
+
+func TestHandleApiPatchFriday_RSVPClosed(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	fTime := time.Unix(time.Now().Add(3*time.Hour).Unix(), 0)
+	reqFriday := &api.Friday{
+		ID: strconv.FormatInt(fTime.Unix(), 10),
+	}
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	token := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "token").Return(token, nil)
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	reqBody := &bytes.Buffer{}
+	require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	req.Header.Add("Content-Type", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	b, err := io.ReadAll(res.Body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), "RSVPs closed")
+	friday, err := accessor.GetFriday(fTime)
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleApiAutoEnable(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.AutoEnable.Enabled = true
+	config.AutoEnable.LeadTime = 14 * 24 * time.Hour
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	host := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "host@bar.com",
+			Roles: []string{"pizza_host"},
+		},
+	}
+	guest := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "host").Return(host, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "guest").Return(guest, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		token  string
+		paused bool
+		status int
+	}{
+		{"guest", true, http.StatusForbidden},
+		{"host", true, http.StatusOK},
+	} {
+		// WHEN
+		reqBody := &bytes.Buffer{}
+		require.Nil(t, jsonapi.MarshalPayload(reqBody, &api.AutoEnable{ID: "default", Paused: tc.paused}))
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/auto-enable", reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+tc.token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, tc.status, res.StatusCode, tc.token)
+	}
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/auto-enable", nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer host")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	autoEnable, err := api.UnmarshalAutoEnable(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, autoEnable.Enabled)
+	assert.True(t, autoEnable.Paused)
+	assert.Equal(t, 14*24, autoEnable.LeadTimeHours)
+	// nothing is enabled while it is paused
+	assert.Equal(t, 0, server.AutoEnable(time.Now()))
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{})
+	assert.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "auto-enable", entries[0].Action)
+	assert.Equal(t, "paused", entries[0].After)
+}
+
+func TestHandleApiFriday_Hosts(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	for _, tc := range []struct {
+		token string
+		email string
+		roles []string
+	}{
+		{"host", "host@bar.com", []string{"pizza_host"}},
+		{"friend", "foo@bar.com", []string{}},
+		{"owner", "bar@bar.com", []string{}},
+		{"cohost", "baz@bar.com", []string{}},
+	} {
+		authenticator.On("DecodeAccessToken", mock.Anything, tc.token).Return(&pizza.AccessToken{
+			ExpiresAt: time.Now().Add(1 * time.Hour),
+			Claims: pizza.TokenClaims{
+				Email: tc.email,
+				Roles: tc.roles,
+			},
+		}, nil)
+	}
+	fTime := time.Unix(time.Now().Add(time.Hour*72).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.AddFriend("bar@bar.com", "Bar"))
+	require.Nil(t, accessor.AddFriend("baz@bar.com", "Baz"))
+	require.Nil(t, accessor.SetFridayHosts(fTime, "bar@bar.com", []string{"baz@bar.com"}))
+	reqFriday := &api.Friday{
+		ID:        strconv.FormatInt(fTime.Unix(), 10),
+		StartTime: fTime.Add(time.Hour),
+	}
+	calendar.On("UpdateEvent", mock.Anything).Return(pizza.ErrEventNotFound)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		token  string
+		status int
+	}{
+		// friends can only move the fridays they host, and admins every friday
+		{"friend", http.StatusForbidden},
+		{"host", http.StatusOK},
+		{"cohost", http.StatusOK},
+		{"owner", http.StatusOK},
+	} {
+		// WHEN
+		reqBody := &bytes.Buffer{}
+		require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+tc.token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+
+		// THEN
+		assert.Nil(t, err)
+		assert.Equal(t, tc.status, res.StatusCode, tc.token)
+		reqFriday.StartTime = reqFriday.StartTime.Add(time.Hour)
+	}
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/friday/"+reqFriday.ID, nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer host")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err := api.UnmarshalFriday(res.Body)
+
+	// THEN
+	// the owner comes first
+	assert.Nil(t, err)
+	require.Len(t, friday.Hosts, 2)
+	assert.Equal(t, "Bar", friday.Hosts[0].Name)
+	assert.Equal(t, "Baz", friday.Hosts[1].Name)
+}
+
+func TestHandleApiPatchFriday_Tentative(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	token := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims: pizza.TokenClaims{
+			Email: "foo@bar.com",
+			Name:  "Foo",
+		},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "token").Return(token, nil)
+	fTime := time.Unix(time.Now().Add(time.Hour*72).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.AddFriend("foo@bar.com", "Foo"))
+	require.Nil(t, accessor.AddFriend("bar@bar.com", "Bar"))
+	f, err := accessor.GetFriday(fTime)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday("bar@bar.com", f, ""))
+	foo, err := accessor.GetFriendByEmail("foo@bar.com")
+	require.Nil(t, err)
+	reqFriday := &api.Friday{
+		ID:     strconv.FormatInt(fTime.Unix(), 10),
+		Guests: []*api.Guest{{ID: foo.ID, Status: pizza.RSVPTentative}},
+	}
+	calendar.On("InviteToEvent", reqFriday.ID, "foo@bar.com", "Foo", pizza.RSVPTentative).Return(nil).Once()
+	calendar.On("GetEvent", reqFriday.ID).Return(pizza.CalendarEvent{
+		Attendees: []pizza.CalendarAttendee{
+			{Email: "bar@bar.com", ResponseStatus: "accepted"},
+			{Email: "foo@bar.com", ResponseStatus: "tentative"},
+		},
+	}, nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	reqBody := &bytes.Buffer{}
+	require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	req.Header.Add("Content-Type", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	patched, err := api.UnmarshalFriday(res.Body)
+
+	// THEN
+	assert.Nil(t, err)
+	require.Len(t, patched.Guests, 2)
+	assert.Equal(t, pizza.RSVPAccepted, patched.Guests[0].Status)
+	assert.Equal(t, pizza.RSVPTentative, patched.Guests[1].Status)
+
+	// WHEN
+	req, err = http.NewRequest(http.MethodGet, ts.URL+"/api/friday/"+reqFriday.ID, nil)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	res, err = http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err := api.UnmarshalFriday(res.Body)
+	saved, err2 := accessor.GetFriday(fTime)
+
+	// THEN
+	// maybes come after the guests who are coming
+	assert.Nil(t, err)
+	require.Len(t, friday.Guests, 2)
+	assert.Equal(t, &api.Guest{ID: friday.Guests[0].ID, Name: "Bar", Status: pizza.RSVPAccepted}, friday.Guests[0])
+	assert.Equal(t, &api.Guest{ID: foo.ID, Name: "Foo", Status: pizza.RSVPTentative}, friday.Guests[1])
+	assert.Nil(t, err2)
+	assert.Equal(t, []string{"bar@bar.com"}, saved.Guests)
+	assert.Equal(t, []string{"foo@bar.com"}, saved.Tentative)
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleApiPatchFriday_TentativeAfterRSVPsClose(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	authenticator.On("DecodeAccessToken", mock.Anything, "foo").Return(&pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims:    pizza.TokenClaims{Email: "foo@bar.com", Name: "Foo"},
+	}, nil)
+	authenticator.On("DecodeAccessToken", mock.Anything, "bar").Return(&pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims:    pizza.TokenClaims{Email: "bar@bar.com", Name: "Bar"},
+	}, nil)
+	// RSVPs closed a day ago, but guests can decline until a day before the start
+	fTime := time.Unix(time.Now().Add(72*time.Hour).Unix(), 0).In(mustLoadNY(t))
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true,
+		Policy: &pizza.RSVPPolicy{ClosesBefore: 96 * time.Hour, DeclineCutoff: 24 * time.Hour}}))
+	f, err := accessor.GetFriday(fTime)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday("foo@bar.com", f, ""))
+	require.Nil(t, accessor.AddFriend("bar@bar.com", "Bar"))
+	foo, err := accessor.GetFriendByEmail("foo@bar.com")
+	require.Nil(t, err)
+	bar, err := accessor.GetFriendByEmail("bar@bar.com")
+	require.Nil(t, err)
+
+	server, err := pizza.NewServer(config, accessor, &pizza.MockCalendar{}, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	maybe := func(token, ID string) int {
+		reqFriday := &api.Friday{
+			ID:     strconv.FormatInt(fTime.Unix(), 10),
+			Guests: []*api.Guest{{ID: ID, Status: pizza.RSVPTentative}},
+		}
+		reqBody := &bytes.Buffer{}
+		require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+		req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+		require.Nil(t, err)
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.Header.Add("Accept", "application/vnd.api+json")
+		req.Header.Add("Content-Type", "application/vnd.api+json")
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		return res.StatusCode
+	}
+
+	// WHEN
+	fooStatus := maybe("foo", foo.ID)
+	barStatus := maybe("bar", bar.ID)
+	saved, err := accessor.GetFriday(fTime)
+
+	// THEN
+	// a guest who is coming can still give up their spot, but nobody new can RSVP
+	assert.Equal(t, http.StatusOK, fooStatus)
+	assert.Equal(t, http.StatusForbidden, barStatus)
+	assert.Nil(t, err)
+	assert.Empty(t, saved.Guests)
+	assert.Equal(t, []string{"foo@bar.com"}, saved.Tentative)
+}
+
+func TestHandleApiPatchFriday_CalendarDisabled(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	token := &pizza.AccessToken{
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+		Claims:    pizza.TokenClaims{Email: "foo@bar.com", Name: "Foo"},
+	}
+	authenticator.On("DecodeAccessToken", mock.Anything, "token").Return(token, nil)
+	fTime := time.Unix(time.Now().Add(time.Hour*72).Unix(), 0).In(mustLoadNY(t))
+	require.Nil(t, accessor.AddFriday(fTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fTime, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.AddFriend("foo@bar.com", "Foo"))
+	foo, err := accessor.GetFriendByEmail("foo@bar.com")
+	require.Nil(t, err)
+	reqFriday := &api.Friday{
+		ID:     strconv.FormatInt(fTime.Unix(), 10),
+		Guests: []*api.Guest{{ID: foo.ID}},
+	}
+
+	// there is no calendar to call
+	server, err := pizza.NewServer(config, accessor, nil, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	reqBody := &bytes.Buffer{}
+	require.Nil(t, jsonapi.MarshalPayload(reqBody, reqFriday))
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodPatch, ts.URL+"/api/friday/"+reqFriday.ID, reqBody)
+	require.Nil(t, err)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Accept", "application/vnd.api+json")
+	req.Header.Add("Content-Type", "application/vnd.api+json")
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	patched, err := api.UnmarshalFriday(res.Body)
+
+	// THEN
+	// the guests come from the guest list
+	assert.Nil(t, err)
+	require.Len(t, patched.Guests, 1)
+	assert.Equal(t, foo.ID, patched.Guests[0].ID)
+	assert.Equal(t, pizza.RSVPAccepted, patched.Guests[0].Status)
+}
@@ -0,0 +1,280 @@
+package pizza
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	jsonapi "github.com/hashicorp/jsonapi"
+	api "github.com/mpoegel/rsvp.pizza/pkg/api"
+)
+
+const auditPageSize = 50
+
+// audit records a change in the audit log. A failure to record is logged but never fails the change itself.
+func (s *Server) audit(entry AuditEntry) {
+	entry.Time = time.Now()
+	if err := s.store.AddAuditEntry(entry); err != nil {
+		slog.Error("failed to record audit entry", "err", err, "action", entry.Action, "target", entry.Target)
+	}
+}
+
+// rsvpStatus is the status of the guest's RSVP to the friday, or empty if they have none
+func rsvpStatus(friday Friday, email string) string {
+	for _, guest := range friday.Guests {
+		if guest == email {
+			return RSVPAccepted
+		}
+	}
+	for _, guest := range friday.Waitlist {
+		if guest == email {
+			return RSVPWaitlisted
+		}
+	}
+	for _, guest := range friday.Tentative {
+		if guest == email {
+			return RSVPTentative
+		}
+	}
+	return ""
+}
+
+type auditFridaySettings struct {
+	Group           string   `json:"group"`
+	Details         string   `json:"details"`
+	MaxGuests       int      `json:"maxGuests"`
+	Enabled         bool     `json:"enabled"`
+	Start           string   `json:"start"`
+	DurationMinutes int      `json:"durationMinutes"`
+	RSVPPolicy      string   `json:"rsvpPolicy,omitempty"`
+	VenueID         int64    `json:"venueId,omitempty"`
+	Host            string   `json:"host,omitempty"`
+	CoHosts         []string `json:"coHosts,omitempty"`
+}
+
+type auditSeriesSettings struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Group       string `json:"group"`
+	MaxGuests   int    `json:"maxGuests"`
+	Recurrence  string `json:"recurrence"`
+	CalendarID  string `json:"calendarId"`
+}
+
+// seriesSettings is the state of the series as recorded in the audit log
+func seriesSettings(series Series) string {
+	settings := auditSeriesSettings{
+		Name:        series.Name,
+		Description: series.Description,
+		MaxGuests:   series.MaxGuests,
+		Recurrence:  series.Recurrence,
+		CalendarID:  series.CalendarID,
+	}
+	if series.Group != nil {
+		settings.Group = *series.Group
+	}
+	raw, _ := json.Marshal(settings)
+	return string(raw)
+}
+
+type auditVenueSettings struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Capacity int    `json:"capacity"`
+	Notes    string `json:"notes"`
+	Ovens    int    `json:"ovens"`
+}
+
+// venueSettings is the state of the venue as recorded in the audit log
+func venueSettings(venue Venue) string {
+	raw, _ := json.Marshal(auditVenueSettings{
+		Name:     venue.Name,
+		Address:  venue.Address,
+		Capacity: venue.Capacity,
+		Notes:    venue.Notes,
+		Ovens:    venue.Ovens,
+	})
+	return string(raw)
+}
+
+// fridaySettings is the host-editable state of the friday as recorded in the audit log
+func fridaySettings(friday Friday) string {
+	settings := auditFridaySettings{
+		MaxGuests:       friday.MaxGuests,
+		Enabled:         friday.Enabled,
+		Start:           friday.StartsAt().Format(time.RFC3339),
+		DurationMinutes: int(friday.EndsAt().Sub(friday.StartsAt()) / time.Minute),
+		VenueID:         friday.VenueID,
+		Host:            friday.Host,
+		CoHosts:         friday.CoHosts,
+	}
+	if friday.Group != nil {
+		settings.Group = *friday.Group
+	}
+	if friday.Details != nil {
+		settings.Details = *friday.Details
+	}
+	if friday.Policy != nil {
+		settings.RSVPPolicy = friday.Policy.String()
+	}
+	raw, _ := json.Marshal(settings)
+	return string(raw)
+}
+
+// parseAuditFilter reads the friday, guest and page query parameters
+func (s *Server) parseAuditFilter(r *http.Request) (AuditFilter, int, error) {
+	filter := AuditFilter{
+		Target: r.URL.Query().Get("guest"),
+		Limit:  auditPageSize,
+	}
+	if fridayID := r.URL.Query().Get("friday"); len(fridayID) > 0 {
+		fridayTime, err := s.parseFridayTime(fridayID)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid friday '%s'", fridayID)
+		}
+		filter.Friday = fridayTime
+	}
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); len(pageStr) > 0 {
+		p, err := strconv.Atoi(pageStr)
+		if err != nil || p < 1 {
+			return filter, 0, fmt.Errorf("invalid page '%s'", pageStr)
+		}
+		page = p
+	}
+	filter.Offset = (page - 1) * auditPageSize
+	return filter, page, nil
+}
+
+type AuditPageEntry struct {
+	Time     string
+	Actor    string
+	Action   string
+	FridayID string
+	Friday   string
+	Target   string
+	Before   string
+	After    string
+	Source   string
+}
+
+type AuditPageData struct {
+	LoggedIn bool
+	Name     string
+	Entries  []AuditPageEntry
+	FridayID string
+	Guest    string
+	Page     int
+	PrevPage int
+	NextPage int
+}
+
+func (s *Server) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.authenticateRequest(r)
+	if !ok || !isAdmin(claims) {
+		s.Handle4xx(w, r)
+		return
+	}
+
+	filter, page, err := s.parseAuditFilter(r)
+	if err != nil {
+		s.Handle4xx(w, r)
+		return
+	}
+	entries, err := s.store.ListAuditEntries(filter)
+	if err != nil {
+		slog.Error("failed to list audit entries", "err", err)
+		s.Handle500(w, r)
+		return
+	}
+
+	data := AuditPageData{
+		LoggedIn: true,
+		Name:     claims.GivenName,
+		Entries:  make([]AuditPageEntry, len(entries)),
+		FridayID: r.URL.Query().Get("friday"),
+		Guest:    filter.Target,
+		Page:     page,
+		PrevPage: page - 1,
+	}
+	if len(entries) == auditPageSize {
+		data.NextPage = page + 1
+	}
+	viewLoc := s.displayLocation(claims.Email)
+	for i, entry := range entries {
+		data.Entries[i] = AuditPageEntry{
+			Time:   entry.Time.In(viewLoc).Format(time.DateTime),
+			Actor:  entry.Actor,
+			Action: entry.Action,
+			Target: entry.Target,
+			Before: entry.Before,
+			After:  entry.After,
+			Source: entry.Source,
+		}
+		if !entry.Friday.IsZero() {
+			data.Entries[i].FridayID = strconv.FormatInt(entry.Friday.Unix(), 10)
+			data.Entries[i].Friday = entry.Friday.In(viewLoc).Format(time.RFC822)
+		}
+	}
+
+	s.executeTemplate(w, "Audit", data)
+}
+
+func (s *Server) HandleAPIAudit(w http.ResponseWriter, r *http.Request) {
+	token, ok := s.CheckAuthorization(r)
+	if !ok {
+		WriteAPIErrorStatus(errors.New("not authorized"), http.StatusUnauthorized, w)
+		return
+	}
+
+	if r.Header.Get("Accept") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("must accept %s", jsonapi.MediaType), http.StatusNotAcceptable, w)
+		return
+	}
+
+	if !isAdmin(&token.Claims) {
+		WriteAPIErrorStatus(errors.New("not authorized to view the audit log"), http.StatusForbidden, w)
+		return
+	}
+
+	filter, _, err := s.parseAuditFilter(r)
+	if err != nil {
+		WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+		return
+	}
+	entries, err := s.store.ListAuditEntries(filter)
+	if err != nil {
+		slog.Error("failed to list audit entries", "err", err)
+		WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+		return
+	}
+
+	res := make([]*api.AuditEntry, len(entries))
+	for i, entry := range entries {
+		res[i] = &api.AuditEntry{
+			ID:     strconv.FormatInt(entry.ID, 10),
+			Time:   entry.Time,
+			Actor:  entry.Actor,
+			Action: entry.Action,
+			Target: entry.Target,
+			Before: entry.Before,
+			After:  entry.After,
+			Source: entry.Source,
+		}
+		if !entry.Friday.IsZero() {
+			res[i].Friday = strconv.FormatInt(entry.Friday.Unix(), 10)
+		}
+	}
+
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(http.StatusOK)
+
+	if err = jsonapi.MarshalPayload(w, res); err != nil {
+		slog.Warn("api marshal payload", "error", err)
+		WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+	}
+}
@@ -1,21 +1,32 @@
 package pizza
 
 import (
+	"html/template"
 	"log/slog"
 	"net/http"
 	"path"
 	"strings"
-	"text/template"
 	"time"
 
 	uuid "github.com/google/uuid"
 )
 
+// normalizeEmail is how emails are stored and compared, whatever case the identity provider or a form used
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func (s *Server) authenticateRequest(r *http.Request) (*TokenClaims, bool) {
 	if cookie, err := r.Cookie("session"); err != nil {
 		return nil, false
 	} else {
-		return s.authenticator.IsValidSession(cookie.Value)
+		claims, ok := s.authenticator.IsValidSession(cookie.Value)
+		if !ok || claims == nil {
+			return claims, ok
+		}
+		normalized := *claims
+		normalized.Email = normalizeEmail(claims.Email)
+		return &normalized, true
 	}
 }
 
@@ -42,7 +53,9 @@ func (s *Server) CheckAuthorization(r *http.Request) (*AccessToken, bool) {
 		return nil, false
 	}
 
-	return accessToken, true
+	normalized := *accessToken
+	normalized.Claims.Email = normalizeEmail(accessToken.Claims.Email)
+	return &normalized, true
 }
 
 func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
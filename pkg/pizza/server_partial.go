@@ -1,11 +1,13 @@
 package pizza
 
 import (
+	"database/sql"
 	"log/slog"
 	"net/http"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (s *Server) HandleFriday(w http.ResponseWriter, r *http.Request) {
@@ -14,7 +16,7 @@ func (s *Server) HandleFriday(w http.ResponseWriter, r *http.Request) {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
-	fridayTime, err := parseFridayTime(r.PathValue("ID"))
+	fridayTime, err := s.parseFridayTime(r.PathValue("ID"))
 	if err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
@@ -22,19 +24,19 @@ func (s *Server) HandleFriday(w http.ResponseWriter, r *http.Request) {
 
 	friday, err := s.loadFriday(fridayTime, claims)
 	if err != nil {
-		if claims.HasRole("pizza_host") {
-			friday = &Friday{
-				Date:      fridayTime,
-				Guests:    []string{},
-				MaxGuests: 10,
-			}
+		// hosts can open any date of the recurrence to set it up
+		series, ok := s.seriesAt(fridayTime)
+		placeholder := newSeriesFriday(series, fridayTime)
+		if ok && canHostFriday(claims, placeholder) {
+			friday = &placeholder
 		} else {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
 		}
 	}
 
-	fData := s.newIndexFridayData(friday, claims)
+	fData := s.newIndexFridayData(friday, claims, s.displayLocation(claims.Email))
+	fData.Blackout, _ = s.isBlackedOut(*friday)
 	s.executeTemplate(w, "SelectedFriday", fData)
 }
 
@@ -53,31 +55,44 @@ func (s *Server) HandleRSVP(w http.ResponseWriter, r *http.Request) {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
-	email := strings.ToLower(claims.Email)
+	email := claims.Email
+	name := claims.GivenName
+	createdBy := ""
 
 	if err := r.ParseForm(); err != nil {
 		slog.Error("form parse failure on rsvp", "error", err)
 		w.Write(getToast("bad request"))
 		return
 	}
-	if len(r.Form["plus-one"]) > 0 {
-		if !claims.HasRole("plusOne") && !claims.HasRole("pizza_host") {
+	if len(r.Form["plus-one"]) > 0 || len(r.Form["plus-one-name"]) > 0 {
+		if !claims.HasRole("plusOne") && !isAdmin(claims) {
 			s.executeTemplate(w, "RSVPError", nil)
 			return
 		}
-		email = r.Form["plus-one"][0]
-		friend, err := s.store.GetFriendByEmail(email)
-		if err != nil {
+		sponsor := email
+		email = normalizeEmail(r.Form.Get("plus-one"))
+		plusOneName := strings.TrimSpace(r.Form.Get("plus-one-name"))
+		friend, err := Friend{}, sql.ErrNoRows
+		if len(email) > 0 {
+			friend, err = s.store.GetFriendByEmail(email)
+		}
+		if err != nil && len(plusOneName) > 0 {
+			// people without an account come along as the guest of whoever brings them
+			s.rsvpPlusOne(w, claims, dates, PlusOne{Name: plusOneName, Email: email, Sponsor: sponsor})
+			return
+		} else if err != nil {
 			w.Write(getToast("friend not found"))
 			return
 		}
+		name = friend.Name
+		createdBy = claims.Email
 		slog.Info("rsvp request", "name", friend.Name, "dates", dates, "by", claims.Email)
 	} else {
 		slog.Debug("rsvp request", "email", email, "dates", dates)
 	}
 
 	for _, d := range dates {
-		fridayTime, err := parseFridayTime(d)
+		fridayTime, err := s.parseFridayTime(d)
 		if err != nil {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
@@ -87,11 +102,43 @@ func (s *Server) HandleRSVP(w http.ResponseWriter, r *http.Request) {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
 		}
+		if _, blackedOut := s.isBlackedOut(*friday); blackedOut {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+		if err = s.rsvpPolicy(*friday).CheckRSVP(*friday, time.Now()); err != nil {
+			notice := s.rsvpNotice(*friday, err, s.displayLocation(claims.Email))
+			if len(createdBy) > 0 {
+				w.Write(getToast(notice))
+			} else {
+				s.executeTemplate(w, "RSVPClosed", notice)
+			}
+			return
+		}
 
-		if err = s.CreateAndInvite(d, *friday, email, claims.GivenName); err != nil {
+		before := rsvpStatus(*friday, email)
+		if err = s.CreateAndInvite(d, *friday, email, name, createdBy); err == ErrFridayIsFull && len(createdBy) > 0 {
+			w.Write(getToast("friday is full"))
+			return
+		} else if err == ErrFridayIsFull {
+			s.executeTemplate(w, "RSVPFull", d)
+			return
+		} else if err != nil {
 			s.executeTemplate(w, "RSVPError", nil)
 			return
 		}
+		if before == RSVPAccepted {
+			continue
+		}
+		s.audit(AuditEntry{
+			Actor:  claims.Email,
+			Action: "rsvp",
+			Friday: friday.Date,
+			Target: email,
+			Before: before,
+			After:  RSVPAccepted,
+			Source: AuditSourceWeb,
+		})
 	}
 
 	s.executeTemplate(w, "RSVPSuccess", nil)
@@ -112,18 +159,25 @@ func (s *Server) HandleDeleteRSVP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	guestEmail := claims.Email
+	action := "decline"
 	if guestEmails, ok := form["guest"]; ok {
-		if !claims.HasRole("pizza_host") {
+		guestEmail = guestEmails[0]
+		action = "remove"
+	}
+	var plusOneID int64
+	if plusOneIDs, ok := form["plusOne"]; ok {
+		var err error
+		if plusOneID, err = strconv.ParseInt(plusOneIDs[0], 10, 64); err != nil {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
 		}
-		guestEmail = guestEmails[0]
+		action = "remove"
 	}
 
 	slog.Debug("incoming decline request", "url", r.URL, "email", guestEmail, "dates", dates)
 
 	for _, d := range dates {
-		fridayTime, err := parseFridayTime(d)
+		fridayTime, err := s.parseFridayTime(d)
 		if err != nil {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
@@ -133,62 +187,272 @@ func (s *Server) HandleDeleteRSVP(w http.ResponseWriter, r *http.Request) {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
 		}
+		if plusOneID != 0 {
+			// plus-ones can be taken off the list by whoever brought them and by the hosts
+			i := slices.IndexFunc(friday.PlusOnes, func(plusOne PlusOne) bool { return plusOne.ID == plusOneID })
+			if i < 0 || (friday.PlusOnes[i].Sponsor != claims.Email && !canHostFriday(claims, *friday)) {
+				s.executeTemplate(w, "RSVPFail", nil)
+				return
+			}
+			if err = s.removePlusOne(*friday, friday.PlusOnes[i], claims.Email, AuditSourceWeb); err != nil {
+				s.executeTemplate(w, "RSVPFail", nil)
+				return
+			}
+			s.promoteWaitlist(*friday, AuditSourceWeb)
+			continue
+		}
+		// only the hosts of the friday can take other guests off the list
+		if action == "remove" && !canHostFriday(claims, *friday) {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
 
 		if slices.Contains(friday.Guests, guestEmail) {
+			// hosts can still take guests off the list after the cutoff
+			if err = s.rsvpPolicy(*friday).CheckDecline(*friday, time.Now()); err != nil && action != "remove" {
+				s.executeTemplate(w, "RSVPClosed", s.rsvpNotice(*friday, err, s.displayLocation(claims.Email)))
+				return
+			}
 			if err = s.store.RemoveFriendFromFriday(guestEmail, friday.Date); err != nil {
 				slog.Error("failed to remove friend from friday", "err", err, "email", guestEmail, "friday", d)
 				s.executeTemplate(w, "RSVPFail", nil)
 				return
 			}
 			if s.config.Calendar.Enabled {
-				if err = s.calendar.DeclineEvent(d, guestEmail); err != nil {
+				if err = s.calendarFor(*friday).DeclineEvent(d, guestEmail); err != nil {
+					slog.Error("failed to decline calendar invite", "err", err, "email", guestEmail, "friday", d)
+					s.executeTemplate(w, "RSVPFail", nil)
+					return
+				}
+			}
+			s.audit(AuditEntry{
+				Actor:  claims.Email,
+				Action: action,
+				Friday: friday.Date,
+				Target: guestEmail,
+				Before: RSVPAccepted,
+				After:  RSVPDeclined,
+				Source: AuditSourceWeb,
+			})
+			s.removePlusOnesOf(*friday, guestEmail, claims.Email, AuditSourceWeb)
+			s.promoteWaitlist(*friday, AuditSourceWeb)
+		} else if slices.Contains(friday.Tentative, guestEmail) {
+			// maybes are on the calendar event, but they do not take up a spot
+			if err = s.store.RemoveFriendFromFriday(guestEmail, friday.Date); err != nil {
+				slog.Error("failed to remove tentative friend from friday", "err", err, "email", guestEmail, "friday", d)
+				s.executeTemplate(w, "RSVPFail", nil)
+				return
+			}
+			if s.config.Calendar.Enabled {
+				if err = s.calendarFor(*friday).DeclineEvent(d, guestEmail); err != nil && err != ErrNotInvited {
 					slog.Error("failed to decline calendar invite", "err", err, "email", guestEmail, "friday", d)
 					s.executeTemplate(w, "RSVPFail", nil)
 					return
 				}
 			}
+			s.audit(AuditEntry{
+				Actor:  claims.Email,
+				Action: action,
+				Friday: friday.Date,
+				Target: guestEmail,
+				Before: RSVPTentative,
+				After:  RSVPDeclined,
+				Source: AuditSourceWeb,
+			})
+		} else if slices.Contains(friday.Waitlist, guestEmail) {
+			// leaving the waitlist does not involve the calendar
+			if err = s.store.RemoveFriendFromFriday(guestEmail, friday.Date); err != nil {
+				slog.Error("failed to remove friend from waitlist", "err", err, "email", guestEmail, "friday", d)
+				s.executeTemplate(w, "RSVPFail", nil)
+				return
+			}
+			s.audit(AuditEntry{
+				Actor:  claims.Email,
+				Action: action,
+				Friday: friday.Date,
+				Target: guestEmail,
+				Before: RSVPWaitlisted,
+				After:  RSVPDeclined,
+				Source: AuditSourceWeb,
+			})
 		}
 	}
 
 	s.executeTemplate(w, "DeclineSuccess", nil)
 }
 
-func (s *Server) HandleFridayGetEdit(w http.ResponseWriter, r *http.Request) {
+func (s *Server) HandleJoinWaitlist(w http.ResponseWriter, r *http.Request) {
 	claims, ok := s.authenticateRequest(r)
-	if !ok || !claims.HasRole("pizza_host") {
+	if !ok {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
-	fridayTime, err := parseFridayTime(r.PathValue("ID"))
+	fridayTime, err := s.parseFridayTime(r.URL.Query().Get("date"))
 	if err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 	friday, err := s.loadFriday(fridayTime, claims)
+	if err != nil || !friday.Enabled {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	if _, blackedOut := s.isBlackedOut(*friday); blackedOut {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	if err = s.rsvpPolicy(*friday).CheckRSVP(*friday, time.Now()); err != nil {
+		s.executeTemplate(w, "RSVPClosed", s.rsvpNotice(*friday, err, s.displayLocation(claims.Email)))
+		return
+	}
+
+	email := claims.Email
+	slog.Info("waitlist request", "email", email, "friday", fridayTime)
+	before := rsvpStatus(*friday, email)
+	if err = s.store.AddFriendToWaitlist(email, friday.Date); err != nil {
+		slog.Error("failed to add friend to waitlist", "err", err, "email", email, "friday", fridayTime)
+		s.executeTemplate(w, "RSVPError", nil)
+		return
+	}
+	if len(before) == 0 {
+		s.audit(AuditEntry{
+			Actor:  claims.Email,
+			Action: "waitlist",
+			Friday: friday.Date,
+			Target: email,
+			After:  RSVPWaitlisted,
+			Source: AuditSourceWeb,
+		})
+	}
+	// a spot may have opened up since the guest saw that the friday was full
+	s.promoteWaitlist(*friday, AuditSourceWeb)
+
+	friday, err = s.loadFriday(fridayTime, claims)
 	if err != nil {
+		s.executeTemplate(w, "RSVPError", nil)
+		return
+	}
+	if slices.Contains(friday.Guests, email) {
+		s.executeTemplate(w, "RSVPSuccess", nil)
+		return
+	}
+	s.executeTemplate(w, "WaitlistSuccess", slices.Index(friday.Waitlist, email)+1)
+}
+
+// HandleTentative marks the user as a maybe, which gives up their spot if they were coming so that the waitlist can
+// have it
+func (s *Server) HandleTentative(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.authenticateRequest(r)
+	if !ok {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
-	fData := s.newIndexFridayData(friday, claims)
+	fridayTime, err := s.parseFridayTime(r.URL.Query().Get("date"))
+	if err != nil {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	friday, err := s.loadFriday(fridayTime, claims)
+	if err != nil || !friday.Enabled {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	if _, blackedOut := s.isBlackedOut(*friday); blackedOut {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	email := claims.Email
+	before := rsvpStatus(*friday, email)
+	policy := s.rsvpPolicy(*friday)
+	if before == RSVPAccepted {
+		// giving up a spot is declining it, which stays open after RSVPs close until the decline cutoff
+		err = policy.CheckDecline(*friday, time.Now())
+	} else {
+		err = policy.CheckRSVP(*friday, time.Now())
+	}
+	if err != nil {
+		s.executeTemplate(w, "RSVPClosed", s.rsvpNotice(*friday, err, s.displayLocation(claims.Email)))
+		return
+	}
+
+	slog.Info("tentative request", "email", email, "friday", fridayTime)
+	if err = s.markTentative(*friday, email, claims.GivenName, AuditSourceWeb); err != nil {
+		s.executeTemplate(w, "RSVPError", nil)
+		return
+	}
+
+	s.executeTemplate(w, "TentativeSuccess", nil)
+}
+
+// markTentative makes the guest a maybe on the friday and in its calendar event. Their spot and anyone they brought go
+// to the waitlist if they were coming.
+func (s *Server) markTentative(friday Friday, email, name, source string) error {
+	before := rsvpStatus(friday, email)
+	if err := s.store.AddFriendAsTentative(email, friday.Date); err != nil {
+		slog.Error("failed to add friend as tentative", "err", err, "email", email, "friday", friday.Date)
+		return err
+	}
+	ID := strconv.FormatInt(friday.Date.Unix(), 10)
+	if err := s.inviteToEvent(ID, friday, email, name, RSVPTentative); err != nil {
+		return err
+	}
+	if before != RSVPTentative {
+		s.audit(AuditEntry{
+			Actor:  email,
+			Action: "maybe",
+			Friday: friday.Date,
+			Target: email,
+			Before: before,
+			After:  RSVPTentative,
+			Source: source,
+		})
+	}
+	if before == RSVPAccepted {
+		s.removePlusOnesOf(friday, email, email, source)
+		s.promoteWaitlist(friday, source)
+	}
+	return nil
+}
+
+func (s *Server) HandleFridayGetEdit(w http.ResponseWriter, r *http.Request) {
+	claims, ok := s.authenticateRequest(r)
+	if !ok {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+
+	fridayTime, err := s.parseFridayTime(r.PathValue("ID"))
+	if err != nil {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	friday, err := s.loadFriday(fridayTime, claims)
+	if err != nil || !canHostFriday(claims, *friday) {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+
+	fData := s.newIndexFridayData(friday, claims, s.displayLocation(claims.Email))
+	fData.Venues = s.listVenues()
 	s.executeTemplate(w, "SelectedFridayEdit", fData)
 }
 
 func (s *Server) HandleFridaySaveEdit(w http.ResponseWriter, r *http.Request) {
 	claims, ok := s.authenticateRequest(r)
-	if !ok || !claims.HasRole("pizza_host") {
+	if !ok {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
-	fridayTime, err := parseFridayTime(r.PathValue("ID"))
+	fridayTime, err := s.parseFridayTime(r.PathValue("ID"))
 	if err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 	friday, err := s.loadFriday(fridayTime, claims)
-	if err != nil {
+	if err != nil || !canHostFriday(claims, *friday) {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
@@ -201,8 +465,14 @@ func (s *Server) HandleFridaySaveEdit(w http.ResponseWriter, r *http.Request) {
 	group := r.Form["group"]
 	details := r.Form["details"]
 	maxGuestsStr := r.Form["maxGuests"]
+	startTime := r.Form.Get("startTime")
+	durationStr := r.Form.Get("duration")
+	venueStr := r.Form.Get("venue")
 
-	slog.Info("admin edit", "group", group, "details", details, "maxGuests", maxGuestsStr)
+	slog.Info("admin edit", "group", group, "details", details, "maxGuests", maxGuestsStr, "startTime", startTime,
+		"duration", durationStr, "venue", venueStr)
+	before := fridaySettings(*friday)
+	startsAt, endsAt, venueID, maxGuestsBefore := friday.StartsAt(), friday.EndsAt(), friday.VenueID, friday.MaxGuests
 
 	if len(group) > 0 {
 		friday.Group = &group[0]
@@ -216,24 +486,90 @@ func (s *Server) HandleFridaySaveEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	friday.MaxGuests = int(maxGuests)
+	if len(venueStr) > 0 {
+		if friday.VenueID, err = strconv.ParseInt(venueStr, 10, 64); err != nil {
+			w.Write(getToast("unknown venue"))
+			return
+		}
+	}
+	if friday.VenueID != venueID && friday.VenueID != 0 {
+		venue, err := s.store.GetVenue(friday.VenueID)
+		if err != nil {
+			w.Write(getToast("unknown venue"))
+			return
+		}
+		// the party moved, so it takes as many guests as the venue fits unless the host changed that too
+		if venue.Capacity > 0 && friday.MaxGuests == maxGuestsBefore {
+			friday.MaxGuests = venue.Capacity
+		}
+	}
+	if len(startTime) > 0 {
+		if friday.Start, err = parseFridayStart(*friday, startTime, s.loc); err != nil {
+			w.Write(getToast("start time must be HH:MM"))
+			return
+		}
+	}
+	if len(durationStr) > 0 {
+		duration, err := strconv.Atoi(durationStr)
+		if err != nil || duration <= 0 {
+			w.Write(getToast("duration must be a positive number of minutes"))
+			return
+		}
+		friday.Duration = time.Duration(duration) * time.Minute
+	}
+	policy, err := parseRSVPPolicyForm(r.Form.Get("rsvpOpens"), r.Form.Get("rsvpCloses"), r.Form.Get("declineCutoff"),
+		s.config.RSVP)
+	if err != nil {
+		w.Write(getToast(err.Error()))
+		return
+	}
+	friday.Policy = policy
+	hostsChanged, err := s.parseHostsForm(r, claims, friday)
+	if err != nil {
+		w.Write(getToast(err.Error()))
+		return
+	}
 	if err = s.store.UpdateFriday(*friday); err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
+	if hostsChanged {
+		if err = s.store.SetFridayHosts(friday.Date, friday.Host, friday.CoHosts); err != nil {
+			slog.Error("failed to set friday hosts", "error", err, "friday", friday.Date)
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+	}
+	if !friday.StartsAt().Equal(startsAt) || !friday.EndsAt().Equal(endsAt) || friday.VenueID != venueID {
+		s.updateEvent(*friday)
+	}
+	s.audit(AuditEntry{
+		Actor:  claims.Email,
+		Action: "edit",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(*friday),
+		Source: AuditSourceWeb,
+	})
+	// there may be more room now
+	s.promoteWaitlist(*friday, AuditSourceWeb)
+	if updated, err := s.loadFriday(fridayTime, claims); err == nil {
+		friday = updated
+	}
 
-	fData := s.newIndexFridayData(friday, claims)
+	fData := s.newIndexFridayData(friday, claims, s.displayLocation(claims.Email))
 	s.executeTemplate(w, "SelectedFriday", fData)
 }
 
 func (s *Server) HandleFridayEnable(w http.ResponseWriter, r *http.Request) {
 	claims, ok := s.authenticateRequest(r)
-	if !ok || !claims.HasRole("pizza_host") {
+	if !ok {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
 	fridayID := r.PathValue("ID")
-	fridayTime, err := parseFridayTime(fridayID)
+	fridayTime, err := s.parseFridayTime(fridayID)
 	if err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
@@ -241,6 +577,16 @@ func (s *Server) HandleFridayEnable(w http.ResponseWriter, r *http.Request) {
 	slog.Info("enable friday", "time", fridayTime)
 	friday, err := s.loadFriday(fridayTime, claims)
 	if err != nil {
+		series, ok := s.seriesAt(fridayTime)
+		if !ok {
+			slog.Warn("friday is not a date of any series", "time", fridayTime)
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
+		if !canHostFriday(claims, newSeriesFriday(series, fridayTime)) {
+			s.executeTemplate(w, "RSVPFail", nil)
+			return
+		}
 		err = s.store.AddFriday(fridayTime)
 		if err != nil {
 			s.executeTemplate(w, "RSVPFail", nil)
@@ -251,11 +597,27 @@ func (s *Server) HandleFridayEnable(w http.ResponseWriter, r *http.Request) {
 			s.executeTemplate(w, "RSVPFail", nil)
 			return
 		}
+		// new fridays start out with the defaults of their series
+		defaults := newSeriesFriday(series, fridayTime)
+		friday.SeriesID = defaults.SeriesID
+		friday.Group = defaults.Group
+		if defaults.MaxGuests > 0 {
+			friday.MaxGuests = defaults.MaxGuests
+		}
+	} else if !canHostFriday(claims, *friday) {
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
 	}
+	if reason, blackedOut := s.isBlackedOut(*friday); blackedOut {
+		slog.Warn("friday is blacked out", "time", fridayTime, "reason", reason)
+		s.executeTemplate(w, "RSVPFail", nil)
+		return
+	}
+	before := fridaySettings(*friday)
 	friday.Enabled = true
 
 	if s.config.Calendar.Enabled {
-		if err = s.calendar.ActivateEvent(fridayID); err != nil {
+		if err = s.calendarFor(*friday).ActivateEvent(fridayID); err != nil {
 			slog.Warn("failed to activate event", "friday", fridayID, "err", err)
 		}
 	}
@@ -265,35 +627,61 @@ func (s *Server) HandleFridayEnable(w http.ResponseWriter, r *http.Request) {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
-
-	fData := s.newIndexFridayData(friday, claims)
+	// whoever enables a friday that nobody owns becomes its host
+	if len(friday.Host) == 0 {
+		friday.Host = claims.Email
+		friday.CoHosts = slices.DeleteFunc(friday.CoHosts, func(email string) bool { return email == claims.Email })
+		if err = s.store.SetFridayHosts(friday.Date, friday.Host, friday.CoHosts); err != nil {
+			slog.Warn("failed to set friday host", "error", err, "friday", friday.Date)
+		}
+	}
+	s.audit(AuditEntry{
+		Actor:  claims.Email,
+		Action: "enable",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(*friday),
+		Source: AuditSourceWeb,
+	})
+
+	fData := s.newIndexFridayData(friday, claims, s.displayLocation(claims.Email))
+	fData.Venues = s.listVenues()
 	s.executeTemplate(w, "SelectedFridayEdit", fData)
 }
 
 func (s *Server) HandleFridayDisable(w http.ResponseWriter, r *http.Request) {
 	claims, ok := s.authenticateRequest(r)
-	if !ok || !claims.HasRole("pizza_host") {
+	if !ok {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
-	fridayTime, err := parseFridayTime(r.PathValue("ID"))
+	fridayTime, err := s.parseFridayTime(r.PathValue("ID"))
 	if err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 	friday, err := s.loadFriday(fridayTime, claims)
-	if err != nil {
+	if err != nil || !canHostFriday(claims, *friday) {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
 
+	before := fridaySettings(*friday)
 	friday.Enabled = false
 	if err = s.store.UpdateFriday(*friday); err != nil {
 		s.executeTemplate(w, "RSVPFail", nil)
 		return
 	}
+	s.audit(AuditEntry{
+		Actor:  claims.Email,
+		Action: "disable",
+		Friday: friday.Date,
+		Before: before,
+		After:  fridaySettings(*friday),
+		Source: AuditSourceWeb,
+	})
 
-	fData := s.newIndexFridayData(friday, claims)
+	fData := s.newIndexFridayData(friday, claims, s.displayLocation(claims.Email))
 	s.executeTemplate(w, "SelectedFriday", fData)
 }
@@ -1,10 +1,12 @@
 package pizza
 
 import (
+	"html/template"
 	"log/slog"
 	"net/http"
 	"path"
-	"text/template"
+	"strings"
+	"time"
 
 	types "github.com/mpoegel/rsvp.pizza/pkg/types"
 )
@@ -15,13 +17,16 @@ type Preference struct {
 }
 
 type ProfilePageData struct {
-	LoggedIn   bool
-	Name       string
-	Toppings   []Preference
-	Cheese     []Preference
-	Sauce      []Preference
-	Doneness   []Preference
-	PixelPizza PixelPizzaPageData
+	LoggedIn        bool
+	Name            string
+	Toppings        []Preference
+	Cheese          []Preference
+	Sauce           []Preference
+	Doneness        []Preference
+	Timezone        string
+	DefaultTimezone string
+	PixelPizza      PixelPizzaPageData
+	CalendarFeed    CalendarFeedData
 }
 
 func (s *Server) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
@@ -43,7 +48,8 @@ func (s *Server) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
 	var doneness types.Doneness
 
 	data := ProfilePageData{
-		LoggedIn: false,
+		LoggedIn:        false,
+		DefaultTimezone: s.loc.String(),
 	}
 
 	claims, ok := s.authenticateRequest(r)
@@ -65,9 +71,16 @@ func (s *Server) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
 			sauces[s] = true
 		}
 		doneness = prefs.Doneness
+		data.Timezone = prefs.Timezone
+
+		data.PixelPizza = newPixelPizzaPageData(NewPixelPizzaFromPreferences(prefs), "33px")
 
-		data.PixelPizza.Pizza = NewPixelPizzaFromPreferences(prefs).Render("darkblue")
-		data.PixelPizza.Size = "33px"
+		feed, err := s.calendarFeed(claims)
+		if err != nil {
+			slog.Error("failed to get calendar feed", "error", err, "email", claims.Email)
+		} else {
+			data.CalendarFeed = calendarFeedData(r, feed)
+		}
 	}
 
 	data.Toppings = []Preference{
@@ -132,6 +145,13 @@ func (s *Server) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
 		Cheese:   types.ParseCheeses(r.Form["cheese"]),
 		Sauce:    types.ParseSauces(r.Form["sauce"]),
 		Doneness: types.ParseDoneness(r.Form["doneness"][0]),
+		Timezone: strings.TrimSpace(r.Form.Get("timezone")),
+	}
+	if len(prefs.Timezone) > 0 {
+		if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+			w.Write(getToast("unknown timezone"))
+			return
+		}
 	}
 
 	slog.Info("got profile update", "preferences", prefs)
@@ -142,10 +162,7 @@ func (s *Server) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := PixelPizzaPageData{
-		Pizza: NewPixelPizzaFromPreferences(prefs).Render("darkblue"),
-		Size:  "33px",
-	}
+	data := newPixelPizzaPageData(NewPixelPizzaFromPreferences(prefs), "33px")
 
 	plate, err := template.ParseFiles(path.Join(s.config.StaticDir, "html/snippets/pizza.html"))
 	if err != nil {
@@ -1,9 +1,14 @@
 package pizza_test
 
 import (
+	"database/sql"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +41,9 @@ func TestHandleIndex(t *testing.T) {
 	accessor.On("AddFriend", claims.Email, claims.Name).Return(nil)
 	accessor.On("GetPreferences", claims.Email).Return(pizza.Preferences{}, nil)
 	accessor.On("GetUpcomingFridays", 30).Return([]pizza.Friday{}, nil)
+	accessor.On("GetSetting", "recurrence").Return("", sql.ErrNoRows)
+	accessor.On("ListSeries").Return([]pizza.Series{}, nil)
+	accessor.On("ListBlackouts").Return([]pizza.Blackout{}, nil)
 
 	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
 	require.Nil(t, err)
@@ -83,24 +91,31 @@ func TestHandleSubmit(t *testing.T) {
 		Groups:    []string{groupName},
 	}
 	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	// both fridays are within the rsvp window
+	date1 := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	date2 := date1.AddDate(0, 0, 7)
 	friday1 := pizza.Friday{
-		Date:      time.Unix(1672060005, 0).In(estZone),
+		Date:      date1,
 		Group:     &groupName,
 		Enabled:   true,
 		MaxGuests: 5,
 	}
 	friday2 := pizza.Friday{
-		Date:      time.Unix(1672040005, 0).In(estZone),
+		Date:      date2,
 		Group:     &groupName,
 		Enabled:   true,
 		MaxGuests: 5,
 	}
 	accessor.On("GetFriday", friday1.Date).Return(friday1, nil)
 	accessor.On("GetFriday", friday2.Date).Return(friday2, nil)
-	accessor.On("AddFriendToFriday", claims.Email, friday1).Return(nil)
-	accessor.On("AddFriendToFriday", claims.Email, friday2).Return(nil)
-	calendar.On("InviteToEvent", "1672060005", claims.Email, claims.GivenName).Return(nil)
-	calendar.On("InviteToEvent", "1672040005", claims.Email, claims.GivenName).Return(nil)
+	accessor.On("AddFriendToFriday", claims.Email, friday1, "").Return(nil)
+	accessor.On("AddFriendToFriday", claims.Email, friday2, "").Return(nil)
+	accessor.On("ListBlackouts").Return([]pizza.Blackout{}, nil)
+	accessor.On("AddAuditEntry", mock.MatchedBy(func(entry pizza.AuditEntry) bool {
+		return entry.Action == "rsvp" && entry.Target == claims.Email && entry.Source == pizza.AuditSourceWeb
+	})).Return(nil).Twice()
+	calendar.On("InviteToEvent", fmt.Sprint(date1.Unix()), claims.Email, claims.GivenName, pizza.RSVPAccepted).Return(nil)
+	calendar.On("InviteToEvent", fmt.Sprint(date2.Unix()), claims.Email, claims.GivenName, pizza.RSVPAccepted).Return(nil)
 
 	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
 	require.Nil(t, err)
@@ -108,7 +123,7 @@ func TestHandleSubmit(t *testing.T) {
 	server.LoadRoutes(mux)
 	ts := httptest.NewServer(mux)
 	defer ts.Close()
-	url := fmt.Sprintf("%s/x/rsvp?date=1672060005&date=1672040005", ts.URL)
+	url := fmt.Sprintf("%s/x/rsvp?date=%d&date=%d", ts.URL, date1.Unix(), date2.Unix())
 
 	// WHEN
 	req, err := http.NewRequest(http.MethodPost, url, nil)
@@ -126,3 +141,1492 @@ func TestHandleSubmit(t *testing.T) {
 	accessor.AssertExpectations(t)
 	calendar.AssertExpectations(t)
 }
+
+func TestHandleDeleteRSVP_PromotesWaitlist(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := &pizza.MockAccessor{}
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	// the friday is far enough away to decline
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	friday := pizza.Friday{
+		Date:      fridayTime,
+		Guests:    []string{claims.Email},
+		Waitlist:  []string{"spock@bar.com"},
+		Enabled:   true,
+		MaxGuests: 1,
+	}
+	accessor.On("GetFriday", friday.Date).Return(friday, nil)
+	accessor.On("RemoveFriendFromFriday", claims.Email, friday.Date).Return(nil)
+	accessor.On("PromoteFromWaitlist", friday.Date).Return([]string{"spock@bar.com"}, nil)
+	accessor.On("GetFriendByEmail", "spock@bar.com").Return(pizza.Friend{ID: "2", Name: "Spock"}, nil)
+	accessor.On("AddAuditEntry", mock.MatchedBy(func(entry pizza.AuditEntry) bool {
+		return entry.Action == "decline" && entry.Actor == claims.Email && entry.Target == claims.Email &&
+			entry.Before == pizza.RSVPAccepted && entry.After == pizza.RSVPDeclined
+	})).Return(nil).Once()
+	accessor.On("AddAuditEntry", mock.MatchedBy(func(entry pizza.AuditEntry) bool {
+		return entry.Action == "promote" && entry.Actor == "" && entry.Target == "spock@bar.com"
+	})).Return(nil).Once()
+	calendar.On("DeclineEvent", fmt.Sprint(fridayTime.Unix()), claims.Email).Return(nil)
+	calendar.On("InviteToEvent", fmt.Sprint(fridayTime.Unix()), "spock@bar.com", "Spock", pizza.RSVPAccepted).Return(nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	url := fmt.Sprintf("%s/x/rsvp?date=%d", ts.URL, fridayTime.Unix())
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.Nil(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	accessor.AssertExpectations(t)
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleRSVP_MemoryAccessor(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 1, Enabled: true}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/x/rsvp?date=%d", ts.URL, fridayTime.Unix()), nil)
+	require.Nil(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err := accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{claims.Email}, friday.Guests)
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{Target: claims.Email})
+	assert.Nil(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "rsvp", entries[0].Action)
+
+	// WHEN
+	req, err = http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/x/rsvp?date=%d", ts.URL, fridayTime.Unix()), nil)
+	require.Nil(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err = accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleFridaySaveEdit_MovesEvent(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Date(2023, 12, 22, 17, 30, 0, 0, estZone)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	start := time.Date(2023, 12, 22, 18, 15, 0, 0, estZone)
+	calendar.On("UpdateEvent", pizza.CalendarEvent{
+		Id:        "1703284200",
+		StartTime: start,
+		EndTime:   start.Add(150 * time.Minute),
+	}).Return(nil).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// WHEN
+	form := "details=pizza&group=&maxGuests=8&startTime=18:15&duration=150"
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, _ := io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "22 Dec 23 18:15 EST until 20:45")
+	friday, err := accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, friday.MaxGuests)
+	assert.True(t, start.Equal(friday.StartsAt()))
+	assert.Equal(t, 150*time.Minute, friday.Duration)
+
+	// WHEN
+	form = "details=pizza&group=&maxGuests=8&startTime=6pm&duration=150"
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "start time must be HH:MM")
+
+	// WHEN
+	form = "details=pizza&group=&maxGuests=8&startTime=18:15&duration=150&rsvpCloses=1.5&declineCutoff=48"
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err = accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	require.NotNil(t, friday.Policy)
+	// the empty field is the policy of the deployment
+	assert.Equal(t, pizza.RSVPPolicy{
+		OpensBefore:   config.RSVP.OpensBefore,
+		ClosesBefore:  90 * time.Minute,
+		DeclineCutoff: 48 * time.Hour,
+	}, *friday.Policy)
+
+	// WHEN
+	form = "details=pizza&group=&maxGuests=8&startTime=18:15&duration=150&rsvpOpens=1&rsvpCloses=2"
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "rsvps must open before they close")
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleFridaySaveEdit_Venue(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Date(2023, 12, 22, 17, 30, 0, 0, estZone)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	venueID, err := accessor.AddVenue(pizza.Venue{Name: "Allie House", Address: "1 Main St", Capacity: 12})
+	require.Nil(t, err)
+	calendar.On("UpdateEvent", pizza.CalendarEvent{
+		Id:        "1703284200",
+		StartTime: fridayTime,
+		EndTime:   fridayTime.Add(pizza.EventDuration),
+		Location:  "Allie House, 1 Main St",
+	}).Return(nil).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// WHEN
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/x/friday/1703284200/edit", nil)
+	require.Nil(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "Allie House (fits")
+
+	// WHEN
+	// the host did not touch the number of guests, so it comes from the venue
+	form := fmt.Sprintf("details=pizza&group=&maxGuests=10&venue=%d", venueID)
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, _ = io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "Allie House, 1 Main St")
+	friday, err := accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	assert.Equal(t, venueID, friday.VenueID)
+	assert.Equal(t, 12, friday.MaxGuests)
+
+	// WHEN
+	// the venue stays, so the event does not move
+	form = fmt.Sprintf("details=pizza&group=&maxGuests=6&venue=%d", venueID)
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	friday, err = accessor.GetFriday(fridayTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, friday.MaxGuests)
+
+	// WHEN
+	form = "details=pizza&group=&maxGuests=6&venue=42"
+	req, err = http.NewRequest(http.MethodPost, ts.URL+"/x/friday/1703284200/edit", strings.NewReader(form))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+	res, err = http.DefaultClient.Do(req)
+
+	// THEN
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(res.Body)
+	assert.Contains(t, string(body), "unknown venue")
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleFriday_VenueIsEscaped(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone := mustLoadNY(t)
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	venueID, err := accessor.AddVenue(pizza.Venue{Name: "<i>Matt's</i>", Address: "<img src=x onerror=alert(1)>"})
+	require.Nil(t, err)
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 10, Enabled: true,
+		VenueID: venueID, Host: claims.Email}))
+
+	server, err := pizza.NewServer(config, accessor, nil, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(path string) string {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	selected := do(fmt.Sprintf("/x/friday/%d", fridayTime.Unix()))
+	edit := do(fmt.Sprintf("/x/friday/%d/edit", fridayTime.Unix()))
+
+	// THEN
+	assert.Contains(t, selected, "&lt;i&gt;Matt&#39;s&lt;/i&gt;, &lt;img src=x onerror=alert(1)&gt;")
+	assert.NotContains(t, selected, "<img src=x")
+	assert.Contains(t, edit, "&lt;i&gt;Matt&#39;s&lt;/i&gt;")
+	assert.NotContains(t, edit, "<i>Matt's</i>")
+}
+
+func TestHandleIndex_Recurrence(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Recurrence = "DTSTART:20250102T180000 RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TH"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	authenticator.On("GetAuthURL").Return("/auth")
+	rec, err := pizza.ParseRecurrence(config.Recurrence, estZone)
+	require.Nil(t, err)
+	thursdays := rec.Between(time.Now(), time.Now().AddDate(0, 0, 29))
+	require.NotEmpty(t, thursdays)
+	friday := thursdays[0].AddDate(0, 0, 1)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	get := func(path string) string {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	index := get("/")
+	candidate := get(fmt.Sprintf("/x/friday/%d", thursdays[0].Unix()))
+	notCandidate := get(fmt.Sprintf("/x/friday/%d", friday.Unix()))
+
+	// THEN
+	for _, thursday := range thursdays {
+		assert.Contains(t, index, fmt.Sprintf("/x/friday/%d", thursday.Unix()))
+	}
+	assert.NotContains(t, index, fmt.Sprintf("/x/friday/%d", friday.Unix()))
+	assert.NotContains(t, candidate, "no pizza for you")
+	assert.Contains(t, notCandidate, "no pizza for you")
+}
+
+func TestNewServer_InvalidTimezone(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.Timezone = "Europe/Atlantis"
+	metrics := &pizza.MockMetricsRegistry{}
+
+	// WHEN
+	_, err := pizza.NewServer(config, pizza.NewMemoryAccessor(), &pizza.MockCalendar{}, &pizza.MockAuthenticator{}, metrics)
+
+	// THEN
+	assert.ErrorContains(t, err, "Europe/Atlantis")
+}
+
+func TestHandleFriday_Timezones(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Timezone = "Europe/Berlin"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Date(2025, 1, 10, 17, 30, 0, 0, berlin)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	get := func(path string) string {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	path := fmt.Sprintf("/x/friday/%d", fridayTime.Unix())
+
+	// WHEN
+	deployment := get(path)
+	require.Nil(t, accessor.SetPreferences(claims.Email, pizza.Preferences{Timezone: "America/New_York"}))
+	viewer := get(path)
+	edit := get(path + "/edit")
+
+	// THEN
+	assert.Contains(t, deployment, "10 Jan 25 17:30 CET until 21:30")
+	assert.Contains(t, viewer, "10 Jan 25 11:30 EST until 15:30")
+	assert.Contains(t, edit, `value="17:30"> Europe/Berlin`)
+}
+
+func TestHandleIndex_Series(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	authenticator.On("GetAuthURL").Return("/auth")
+	group := "book club"
+	series := pizza.Series{
+		Name:        "Pizza Tuesday",
+		Description: "Pizza and books",
+		Group:       &group,
+		MaxGuests:   4,
+		Recurrence:  "DTSTART:20250107T190000 RRULE:FREQ=WEEKLY;BYDAY=TU",
+	}
+	seriesID, err := accessor.AddSeries(series)
+	require.Nil(t, err)
+	rec, err := pizza.ParseRecurrence(series.Recurrence, estZone)
+	require.Nil(t, err)
+	tuesdays := rec.Between(time.Now(), time.Now().AddDate(0, 0, 29))
+	require.NotEmpty(t, tuesdays)
+	calendar.On("ActivateEvent", fmt.Sprintf("%d", tuesdays[0].Unix())).Return(nil)
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string) string {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	index := do(http.MethodGet, "/")
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/enable", tuesdays[0].Unix()))
+	enabled, enableErr := accessor.GetFriday(tuesdays[0])
+
+	// THEN
+	assert.Contains(t, index, "Pizza Friday")
+	assert.Contains(t, index, "Pizza Tuesday")
+	// the tuesdays are listed after the name of their series
+	assert.Greater(t, strings.Index(index, fmt.Sprintf("/x/friday/%d", tuesdays[0].Unix())),
+		strings.Index(index, "Pizza Tuesday"))
+	assert.Nil(t, enableErr)
+	assert.True(t, enabled.Enabled)
+	assert.Equal(t, seriesID, enabled.SeriesID)
+	assert.Equal(t, 4, enabled.MaxGuests)
+	require.NotNil(t, enabled.Group)
+	assert.Equal(t, group, *enabled.Group)
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleIndex_SeriesIsEscaped(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Roles:     []string{"pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	authenticator.On("GetAuthURL").Return("/auth")
+	_, err := accessor.AddSeries(pizza.Series{
+		Name:        "<b>Pizza Tuesday</b>",
+		Description: `"><script>alert(1)</script>`,
+		Recurrence:  "DTSTART:20250107T190000 RRULE:FREQ=WEEKLY;BYDAY=TU",
+	})
+	require.Nil(t, err)
+
+	server, err := pizza.NewServer(config, accessor, nil, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/", nil)
+	require.Nil(t, err)
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: "foobar",
+	})
+
+	// WHEN
+	res, err := http.DefaultClient.Do(req)
+
+	// THEN
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, _ := io.ReadAll(res.Body)
+	index := string(body)
+	assert.NotContains(t, index, "<b>Pizza Tuesday</b>")
+	assert.Contains(t, index, "&lt;b&gt;Pizza Tuesday&lt;/b&gt;")
+	assert.NotContains(t, index, "<script>alert(1)")
+	assert.Contains(t, index, `title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`)
+}
+
+func TestHandleIndex_Blackout(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	authenticator.On("GetAuthURL").Return("/auth")
+	rec, err := pizza.ParseRecurrence(pizza.DefaultRecurrence, estZone)
+	require.Nil(t, err)
+	fridays := rec.Between(time.Now(), time.Now().AddDate(0, 0, 29))
+	require.NotEmpty(t, fridays)
+	fridayTime := fridays[0]
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.AddBlackout(pizza.Blackout{
+		Day:    fridayTime.Format(pizza.BlackoutDayFormat),
+		Reason: "Pizzeria closed for renovations",
+	}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string) string {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	index := do(http.MethodGet, "/")
+	do(http.MethodPost, fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix()))
+	friday, err := accessor.GetFriday(fridayTime)
+
+	// THEN
+	assert.Contains(t, index, "Pizzeria closed for renovations")
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+}
+
+func TestHandleRSVP_Policy(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "test",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	now := time.Unix(time.Now().Unix(), 0).In(estZone)
+	// rsvps close a day before by default
+	soon := now.Add(3 * time.Hour)
+	// and open a month before
+	later := now.AddDate(0, 2, 0)
+	// this friday only lets guests decline up to ten days before
+	nextWeek := now.AddDate(0, 0, 7)
+	for _, fridayTime := range []time.Time{soon, later, nextWeek} {
+		require.Nil(t, accessor.AddFriday(fridayTime))
+	}
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: soon, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: later, MaxGuests: 5, Enabled: true}))
+	policy := config.RSVP
+	policy.DeclineCutoff = 10 * 24 * time.Hour
+	nextWeekFriday := pizza.Friday{Date: nextWeek, MaxGuests: 5, Enabled: true, Policy: &policy}
+	require.Nil(t, accessor.UpdateFriday(nextWeekFriday))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+	require.Nil(t, accessor.AddFriendToFriday(claims.Email, nextWeekFriday, ""))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string) string {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	closed := do(http.MethodPost, fmt.Sprintf("/x/rsvp?date=%d", soon.Unix()))
+	notOpen := do(http.MethodPost, fmt.Sprintf("/x/waitlist?date=%d", later.Unix()))
+	tooLate := do(http.MethodDelete, fmt.Sprintf("/x/rsvp?date=%d", nextWeek.Unix()))
+	selected := do(http.MethodGet, fmt.Sprintf("/x/friday/%d", nextWeek.Unix()))
+
+	// THEN
+	assert.Contains(t, closed, "RSVPs closed")
+	assert.Contains(t, notOpen, "RSVPs open")
+	assert.Contains(t, tooLate, "Declining closed")
+	assert.Contains(t, selected, "Declining closed")
+	assert.NotContains(t, selected, "Decline</button>")
+	for _, fridayTime := range []time.Time{soon, later} {
+		friday, err := accessor.GetFriday(fridayTime)
+		assert.Nil(t, err)
+		assert.Empty(t, friday.Guests)
+		assert.Empty(t, friday.Waitlist)
+	}
+	friday, err := accessor.GetFriday(nextWeek)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{claims.Email}, friday.Guests)
+
+	// WHEN
+	claims.Roles = []string{"pizza_host"}
+	do(http.MethodDelete, fmt.Sprintf("/x/rsvp?date=%d&guest=%s", nextWeek.Unix(), claims.Email))
+	friday, err = accessor.GetFriday(nextWeek)
+
+	// THEN
+	// hosts can still take guests off the list
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+}
+
+func TestHandleFriday_Hosts(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Roles:     []string{},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	now := time.Unix(time.Now().Unix(), 0).In(estZone)
+	theirs := now.AddDate(0, 0, 7)
+	unowned := now.AddDate(0, 0, 14)
+	for _, fridayTime := range []time.Time{theirs, unowned} {
+		require.Nil(t, accessor.AddFriday(fridayTime))
+	}
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: theirs, MaxGuests: 5, Enabled: true}))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: unowned, MaxGuests: 5}))
+	for _, friend := range []string{"foo", "bar", "baz"} {
+		require.Nil(t, accessor.AddFriend(friend+"@bar.com", strings.ToUpper(friend[:1])+friend[1:]))
+	}
+	require.Nil(t, accessor.SetFridayHosts(theirs, "bar@bar.com", []string{"baz@bar.com"}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path, form string) string {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(form))
+		require.Nil(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	selected := do(http.MethodGet, fmt.Sprintf("/x/friday/%d", theirs.Unix()), "")
+	edit := do(http.MethodGet, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()), "")
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/disable", theirs.Unix()), "")
+	friday, err := accessor.GetFriday(theirs)
+
+	// THEN
+	// friends who do not host the friday cannot change it
+	assert.Contains(t, selected, "Hosted by Bar with Baz")
+	assert.NotContains(t, selected, "/edit\"")
+	assert.NotContains(t, edit, "Save</button>")
+	assert.Nil(t, err)
+	assert.True(t, friday.Enabled)
+
+	// WHEN
+	claims.Email = "baz@bar.com"
+	claims.Roles = []string{}
+	edit = do(http.MethodGet, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()), "")
+	handOver := do(http.MethodPost, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()),
+		"details=pizza&group=&maxGuests=5&host=baz@bar.com&coHosts=")
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()),
+		"details=pizza&group=&maxGuests=5&coHosts=foo@bar.com")
+	friday, err = accessor.GetFriday(theirs)
+
+	// THEN
+	// co-hosts can edit the friday but only its owner can hand it over
+	assert.Contains(t, edit, "Save</button>")
+	assert.Contains(t, handOver, "only the host can hand over the friday")
+	assert.Nil(t, err)
+	assert.Equal(t, "bar@bar.com", friday.Host)
+	assert.Equal(t, []string{"foo@bar.com"}, friday.CoHosts)
+
+	// WHEN
+	claims.Email = "admin@bar.com"
+	claims.Roles = []string{"pizza_host"}
+	unknown := do(http.MethodPost, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()),
+		"details=pizza&group=&maxGuests=5&host=nobody@bar.com")
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/edit", theirs.Unix()),
+		"details=pizza&group=&maxGuests=5&host=foo@bar.com&coHosts=")
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/disable", theirs.Unix()), "")
+	friday, err = accessor.GetFriday(theirs)
+
+	// THEN
+	// admins can change every friday
+	assert.Contains(t, unknown, "friend not found")
+	assert.Nil(t, err)
+	assert.Equal(t, "foo@bar.com", friday.Host)
+	assert.Empty(t, friday.CoHosts)
+	assert.False(t, friday.Enabled)
+
+	// WHEN
+	claims.Email = "bar@bar.com"
+	claims.Roles = []string{"pizza_host"}
+	do(http.MethodPost, fmt.Sprintf("/x/friday/%d/enable", unowned.Unix()), "")
+	friday, err = accessor.GetFriday(unowned)
+
+	// THEN
+	// whoever enables a friday that nobody owns becomes its host
+	assert.Nil(t, err)
+	assert.True(t, friday.Enabled)
+	assert.Equal(t, "bar@bar.com", friday.Host)
+}
+
+func TestHandleRSVP_PlusOneWithoutAccount(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Roles:     []string{"plusOne"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	eventID := strconv.FormatInt(fridayTime.Unix(), 10)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 3, Enabled: true}))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+	calendar.On("InviteToEvent", eventID, claims.Email, "Foo", pizza.RSVPAccepted).Return(nil).Once()
+	calendar.On("InviteToEvent", eventID, "alex@work.com", "Alex", pizza.RSVPAccepted).Return(nil).Once()
+	calendar.On("DeclineEvent", eventID, claims.Email).Return(nil).Once()
+	calendar.On("DeclineEvent", eventID, "alex@work.com").Return(nil).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path, form string) string {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(form))
+		require.Nil(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	rsvpPath := fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix())
+
+	// WHEN
+	notComing := do(http.MethodPost, rsvpPath, "plus-one-name=Alex&plus-one=")
+	unknown := do(http.MethodPost, rsvpPath, "plus-one=nobody@bar.com")
+	do(http.MethodPost, rsvpPath, "")
+	do(http.MethodPost, rsvpPath, "plus-one-name=Alex&plus-one=Alex@Work.com")
+	do(http.MethodPost, rsvpPath, "plus-one-name=Sam&plus-one=")
+	full := do(http.MethodPost, rsvpPath, "plus-one-name=Robin&plus-one=")
+	selected := do(http.MethodGet, fmt.Sprintf("/x/friday/%d", fridayTime.Unix()), "")
+	friday, err := accessor.GetFriday(fridayTime)
+
+	// THEN
+	assert.Contains(t, notComing, "RSVP before bringing a guest")
+	assert.Contains(t, unknown, "friend not found")
+	assert.Contains(t, full, "friday is full")
+	assert.Contains(t, selected, "Alex <span class=\"plus-one-sponsor\">guest of Foo</span>")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{claims.Email}, friday.Guests)
+	require.Len(t, friday.PlusOnes, 2)
+	assert.Equal(t, pizza.PlusOne{ID: friday.PlusOnes[0].ID, Name: "Alex", Email: "alex@work.com",
+		Sponsor: claims.Email}, friday.PlusOnes[0])
+	assert.Equal(t, "Sam", friday.PlusOnes[1].Name)
+
+	// WHEN
+	do(http.MethodDelete, fmt.Sprintf("%s&plusOne=%d", rsvpPath, friday.PlusOnes[1].ID), "")
+	friday, err = accessor.GetFriday(fridayTime)
+
+	// THEN
+	assert.Nil(t, err)
+	require.Len(t, friday.PlusOnes, 1)
+	assert.Equal(t, "Alex", friday.PlusOnes[0].Name)
+
+	// WHEN
+	do(http.MethodDelete, rsvpPath, "")
+	friday, err = accessor.GetFriday(fridayTime)
+
+	// THEN
+	// the plus-ones of a guest who declines are not coming either
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+	assert.Empty(t, friday.PlusOnes)
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{Target: "alex@work.com"})
+	assert.Nil(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "remove", entries[0].Action)
+	assert.Equal(t, "plus-one", entries[1].Action)
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleRSVP_PlusOneNameIsEscaped(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone := mustLoadNY(t)
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Roles:     []string{"plusOne", "pizza_host"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	authenticator.On("GetAuthURL").Return("http://localhost:8080")
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 3, Enabled: true,
+		Host: claims.Email}))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+
+	server, err := pizza.NewServer(config, accessor, nil, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path, form string) string {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(form))
+		require.Nil(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	rsvpPath := fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix())
+	do(http.MethodPost, rsvpPath, "")
+	do(http.MethodPost, rsvpPath, "plus-one-name="+url.QueryEscape(`<script>alert("pizza")</script>`)+"&plus-one=")
+
+	// WHEN
+	pages := map[string]string{
+		"index":    do(http.MethodGet, "/", ""),
+		"selected": do(http.MethodGet, fmt.Sprintf("/x/friday/%d", fridayTime.Unix()), ""),
+		"edit":     do(http.MethodGet, fmt.Sprintf("/x/friday/%d/edit", fridayTime.Unix()), ""),
+	}
+
+	// THEN
+	for name, page := range pages {
+		assert.NotContains(t, page, "<script>alert", name)
+		assert.Contains(t, page, "&lt;script&gt;alert(&#34;pizza&#34;)&lt;/script&gt;", name)
+		assert.NotContains(t, page, "ZgotmplZ", name)
+	}
+}
+
+func TestHandleTentative(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = true
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone, _ := time.LoadLocation("America/New_York")
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	eventID := strconv.FormatInt(fridayTime.Unix(), 10)
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 1, Enabled: true}))
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+	require.Nil(t, accessor.AddFriend("spock@bar.com", "Spock"))
+	calendar.On("InviteToEvent", eventID, claims.Email, "Foo", pizza.RSVPAccepted).Return(nil).Twice()
+	calendar.On("InviteToEvent", eventID, claims.Email, "Foo", pizza.RSVPTentative).Return(nil).Once()
+	calendar.On("InviteToEvent", eventID, "spock@bar.com", "Spock", pizza.RSVPAccepted).Return(nil).Once()
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string) string {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{
+			Name:  "session",
+			Value: "foobar",
+		})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	rsvpPath := fmt.Sprintf("/x/rsvp?date=%d", fridayTime.Unix())
+	maybePath := fmt.Sprintf("/x/maybe?date=%d", fridayTime.Unix())
+	selectedPath := fmt.Sprintf("/x/friday/%d", fridayTime.Unix())
+
+	// WHEN
+	do(http.MethodPost, rsvpPath)
+	require.Nil(t, accessor.AddFriendToWaitlist("spock@bar.com", fridayTime))
+	maybe := do(http.MethodPost, maybePath)
+	selected := do(http.MethodGet, selectedPath)
+	friday, err := accessor.GetFriday(fridayTime)
+
+	// THEN
+	// the spot of a guest who turns into a maybe goes to the waitlist
+	assert.Contains(t, maybe, "You're a maybe")
+	assert.Contains(t, selected, "You're a maybe")
+	assert.NotContains(t, selected, "I'm")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"spock@bar.com"}, friday.Guests)
+	assert.Empty(t, friday.Waitlist)
+	assert.Equal(t, []string{claims.Email}, friday.Tentative)
+
+	// WHEN
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 2, Enabled: true}))
+	selected = do(http.MethodGet, selectedPath)
+	do(http.MethodPost, rsvpPath)
+	friday, err = accessor.GetFriday(fridayTime)
+
+	// THEN
+	assert.Contains(t, selected, "in</button>")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"spock@bar.com", claims.Email}, friday.Guests)
+	assert.Empty(t, friday.Tentative)
+	entries, err := accessor.ListAuditEntries(pizza.AuditFilter{Target: claims.Email})
+	assert.Nil(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, pizza.AuditEntry{ID: entries[0].ID, Time: entries[0].Time, Actor: claims.Email, Action: "rsvp",
+		Friday: entries[0].Friday, Target: claims.Email, Before: pizza.RSVPTentative, After: pizza.RSVPAccepted,
+		Source: pizza.AuditSourceWeb}, entries[0])
+	assert.Equal(t, "maybe", entries[1].Action)
+	assert.Equal(t, pizza.RSVPAccepted, entries[1].Before)
+
+	calendar.AssertExpectations(t)
+}
+
+func TestHandleFriday_HostWithMixedCaseEmail(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	// the identity provider keeps the case that the friend signed up with
+	fooClaims := &pizza.TokenClaims{GivenName: "Foo", Email: "Foo@Bar.com", Name: "Foo",
+		Exp: time.Now().Add(1 * time.Hour).Unix()}
+	adminClaims := &pizza.TokenClaims{GivenName: "Admin", Email: "admin@bar.com", Name: "Admin",
+		Roles: []string{"pizza_host"}, Exp: time.Now().Add(1 * time.Hour).Unix()}
+	authenticator.On("IsValidSession", "foo").Return(fooClaims, true)
+	authenticator.On("IsValidSession", "admin").Return(adminClaims, true)
+	authenticator.On("GetAuthURL").Return("/auth")
+	fridayTime := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(mustLoadNY(t))
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 5, Enabled: true}))
+
+	server, err := pizza.NewServer(config, accessor, &pizza.MockCalendar{}, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(session, method, path, form string) string {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(form))
+		require.Nil(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	editPath := fmt.Sprintf("/x/friday/%d/edit", fridayTime.Unix())
+
+	// WHEN
+	do("foo", http.MethodGet, "/", "")
+	do("admin", http.MethodPost, editPath, "details=pizza&group=&maxGuests=5&host=FOO@bar.com&coHosts=")
+	edit := do("foo", http.MethodGet, editPath, "")
+	friend, err := accessor.GetFriendByEmail("foo@bar.com")
+	friday, err2 := accessor.GetFriday(fridayTime)
+
+	// THEN
+	// emails are lowercased wherever they come from, so the friend is found and recognized as the host
+	assert.Nil(t, err)
+	assert.Equal(t, "Foo", friend.Name)
+	assert.Nil(t, err2)
+	assert.Equal(t, "foo@bar.com", friday.Host)
+	assert.Contains(t, edit, "Save</button>")
+}
+
+func TestHandleTentative_AfterRSVPsClose(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	fooClaims := &pizza.TokenClaims{GivenName: "Foo", Email: "foo@bar.com", Name: "Foo",
+		Exp: time.Now().Add(1 * time.Hour).Unix()}
+	barClaims := &pizza.TokenClaims{GivenName: "Bar", Email: "bar@bar.com", Name: "Bar",
+		Exp: time.Now().Add(1 * time.Hour).Unix()}
+	authenticator.On("IsValidSession", "foo").Return(fooClaims, true)
+	authenticator.On("IsValidSession", "bar").Return(barClaims, true)
+	// RSVPs closed a day ago, but guests can decline until a day before the start
+	fridayTime := time.Unix(time.Now().Add(72*time.Hour).Unix(), 0).In(mustLoadNY(t))
+	require.Nil(t, accessor.AddFriday(fridayTime))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: fridayTime, MaxGuests: 5, Enabled: true,
+		Policy: &pizza.RSVPPolicy{ClosesBefore: 96 * time.Hour, DeclineCutoff: 24 * time.Hour}}))
+	f, err := accessor.GetFriday(fridayTime)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday(fooClaims.Email, f, ""))
+	require.Nil(t, accessor.AddFriend(barClaims.Email, barClaims.Name))
+
+	server, err := pizza.NewServer(config, accessor, &pizza.MockCalendar{}, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	maybe := func(session string) string {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/x/maybe?date=%d", ts.URL, fridayTime.Unix()), nil)
+		require.Nil(t, err)
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	// WHEN
+	fooBody := maybe("foo")
+	barBody := maybe("bar")
+	friday, err := accessor.GetFriday(fridayTime)
+
+	// THEN
+	// a guest who is coming can still give up their spot, but nobody new can RSVP
+	assert.Contains(t, fooBody, "You're a maybe")
+	assert.Contains(t, barBody, "RSVPs closed")
+	assert.Nil(t, err)
+	assert.Empty(t, friday.Guests)
+	assert.Equal(t, []string{fooClaims.Email}, friday.Tentative)
+}
+
+func TestHandleCalendarFeed(t *testing.T) {
+	// GIVEN
+	config := pizza.LoadConfigEnv()
+	config.StaticDir = "../../static"
+	config.Calendar.Enabled = false
+	accessor := pizza.NewMemoryAccessor()
+	calendar := &pizza.MockCalendar{}
+	authenticator := &pizza.MockAuthenticator{}
+	metrics := &pizza.MockMetricsRegistry{}
+	counter := &pizza.MockCounterMetric{}
+	estZone := mustLoadNY(t)
+
+	metrics.On("NewCounterMetric", mock.Anything, mock.Anything).Return(counter)
+	counter.On("Increment").Return()
+
+	claims := &pizza.TokenClaims{
+		GivenName: "Foo",
+		Email:     "foo@bar.com",
+		Name:      "Foo",
+		Groups:    []string{"pizza"},
+		Exp:       time.Now().Add(1 * time.Hour).Unix(),
+	}
+	authenticator.On("IsValidSession", mock.Anything).Return(claims, true)
+	require.Nil(t, accessor.AddFriend(claims.Email, claims.Name))
+	pizzaGroup, otherGroup := "pizza", "other"
+	going := time.Unix(time.Now().AddDate(0, 0, 7).Unix(), 0).In(estZone)
+	cancelled := going.AddDate(0, 0, 7)
+	open := going.AddDate(0, 0, 14)
+	closed := going.AddDate(0, 0, 21)
+	for _, friday := range []pizza.Friday{
+		{Date: going, MaxGuests: 10, Enabled: true},
+		{Date: cancelled, MaxGuests: 10, Enabled: true},
+		{Date: open, MaxGuests: 10, Enabled: true, Group: &pizzaGroup},
+		{Date: closed, MaxGuests: 10, Enabled: true, Group: &otherGroup},
+	} {
+		require.Nil(t, accessor.AddFriday(friday.Date))
+		require.Nil(t, accessor.UpdateFriday(friday))
+	}
+	f, err := accessor.GetFriday(going)
+	require.Nil(t, err)
+	require.Nil(t, accessor.AddFriendToFriday(claims.Email, f, ""))
+	require.Nil(t, accessor.AddFriendAsTentative(claims.Email, cancelled))
+	require.Nil(t, accessor.UpdateFriday(pizza.Friday{Date: cancelled, MaxGuests: 10, Enabled: false}))
+
+	server, err := pizza.NewServer(config, accessor, calendar, authenticator, metrics)
+	require.Nil(t, err)
+	mux := http.NewServeMux()
+	server.LoadRoutes(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	do := func(method, path string, session bool) (int, string) {
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader("open_fridays=on"))
+		require.Nil(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if session {
+			req.AddCookie(&http.Cookie{
+				Name:  "session",
+				Value: "foobar",
+			})
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, strings.ReplaceAll(string(body), "\r\n ", "")
+	}
+	uid := func(date time.Time) string {
+		return fmt.Sprintf("UID:%d@rsvp.pizza", date.Unix())
+	}
+
+	// WHEN
+	_, profile := do(http.MethodGet, "/profile", true)
+	feed, err := accessor.GetCalendarFeed(claims.Email)
+	require.Nil(t, err)
+	code1, ics1 := do(http.MethodGet, "/ical/"+feed.Token+".ics", false)
+
+	// THEN
+	// the feed is made on the first visit to the profile and needs no login
+	assert.Contains(t, profile, "/ical/"+feed.Token+".ics")
+	assert.Equal(t, []string{"pizza"}, feed.Groups)
+	assert.Equal(t, http.StatusOK, code1)
+	assert.Contains(t, ics1, "BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\n")
+	assert.Contains(t, ics1, "BEGIN:DAYLIGHT\r\nDTSTART:")
+	assert.Contains(t, ics1, "TZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n")
+	assert.Contains(t, ics1, uid(going))
+	assert.Contains(t, ics1, "DTSTART;TZID=America/New_York:"+going.Format("20060102T150405")+"\r\n")
+	assert.Contains(t, ics1, "SEQUENCE:1\r\nSUMMARY:Pizza Friday\r\nSTATUS:CONFIRMED\r\nTRANSP:OPAQUE\r\n")
+	// disabling the friday after the maybe is another version of the event
+	assert.Contains(t, ics1, uid(cancelled))
+	assert.Contains(t, ics1, "SEQUENCE:2\r\nSUMMARY:Pizza Friday (maybe)\r\nSTATUS:CANCELLED\r\n")
+	assert.NotContains(t, ics1, uid(open))
+	assert.NotContains(t, ics1, uid(closed))
+
+	// WHEN
+	code2, _ := do(http.MethodPost, "/profile/feed", true)
+	_, ics2 := do(http.MethodGet, "/ical/"+feed.Token+".ics", false)
+	code3, rotated := do(http.MethodPost, "/profile/feed/rotate", true)
+	code4, _ := do(http.MethodGet, "/ical/"+feed.Token+".ics", false)
+	newFeed, err := accessor.GetCalendarFeed(claims.Email)
+	require.Nil(t, err)
+	code5, ics5 := do(http.MethodGet, "/ical/"+newFeed.Token+".ics", false)
+
+	// THEN
+	// only the open fridays of the friend's groups are added
+	assert.Equal(t, http.StatusOK, code2)
+	assert.Contains(t, ics2, uid(open))
+	assert.Contains(t, ics2, "SUMMARY:Pizza Friday (open)\r\nSTATUS:TENTATIVE\r\nTRANSP:TRANSPARENT\r\n")
+	assert.NotContains(t, ics2, uid(closed))
+	// the old link stops working once the feed is rotated
+	assert.Equal(t, http.StatusOK, code3)
+	assert.NotEqual(t, feed.Token, newFeed.Token)
+	assert.Contains(t, rotated, "/ical/"+newFeed.Token+".ics")
+	assert.Equal(t, http.StatusNotFound, code4)
+	assert.Equal(t, http.StatusOK, code5)
+	assert.Contains(t, ics5, uid(open))
+}
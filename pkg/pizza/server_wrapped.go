@@ -2,12 +2,12 @@ package pizza
 
 import (
 	"errors"
+	"html/template"
 	"log/slog"
 	"net/http"
 	"path"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 )
 
@@ -95,8 +95,8 @@ func (s *Server) GetWrapped(year int) (WrappedData, error) {
 	}
 
 	// fetch from source
-	start := time.Time{}.AddDate(year, 1, 1)
-	end := time.Time{}.AddDate(year, 12, 31)
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, s.loc)
+	end := start.AddDate(1, 0, 0)
 	events, err := s.calendar.ListEventsBetween(start, end, 100)
 	if err != nil {
 		return WrappedData{}, err
@@ -4,27 +4,173 @@ import (
 	"database/sql"
 	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// sqlFridayEmails selects the emails of a friday's RSVPs with the given status as a JSON array, in the order they
+// were made
+func sqlFridayEmails(status string) string {
+	return `(SELECT json_group_array(friends.email ORDER BY rsvps.created_at, rsvps.rowid) FROM rsvps
+		JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = fridays.start_time AND rsvps.status = '` + status + `')`
+}
+
+// sqlFridayHosts selects the emails of a friday's hosts with the given role as a JSON array, ordered by email
+func sqlFridayHosts(role string) string {
+	return `(SELECT json_group_array(friends.email ORDER BY friends.email) FROM friday_hosts
+		JOIN friends ON friends.id = friday_hosts.friend_id
+		WHERE friday_hosts.friday = fridays.start_time AND friday_hosts.role = '` + role + `')`
+}
+
+// sqlFridayPlusOnes selects a friday's plus-ones as a JSON array of objects, in the order they were added
+var sqlFridayPlusOnes = `(SELECT json_group_array(json_object('id', plus_ones.id, 'name', plus_ones.name,
+		'email', plus_ones.email, 'sponsor', friends.email) ORDER BY plus_ones.id) FROM plus_ones
+		JOIN friends ON friends.id = plus_ones.sponsor_id
+		WHERE plus_ones.friday = fridays.start_time)`
+
+// sqlGuestCount counts the accepted RSVPs and plus-ones of the friday given twice as a parameter
+const sqlGuestCount = `(SELECT COUNT(*) FROM rsvps WHERE friday = ? AND status = 'accepted') +
+	(SELECT COUNT(*) FROM plus_ones WHERE friday = ?)`
+
+// sqlFridayColumns are the columns of the fridays table read by scanFriday
+var sqlFridayColumns = "start_time, invited_group, details, " + sqlFridayEmails(RSVPAccepted) + ", " +
+	sqlFridayEmails(RSVPWaitlisted) + ", max_guests, enabled, starts_at, duration_minutes, series_id, " +
+	"rsvp_opens_minutes, rsvp_closes_minutes, decline_cutoff_minutes, venue_id, " + sqlFridayHosts(FridayHostOwner) +
+	", " + sqlFridayHosts(FridayCoHost) + ", " + sqlFridayPlusOnes + ", " + sqlFridayEmails(RSVPTentative) + ", sequence"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFriday(row rowScanner) (Friday, error) {
+	var friday Friday
+	var rawGuests, rawWaitlist, rawHost, rawCoHosts, rawPlusOnes, rawTentative string
+	var start sql.NullTime
+	var duration, seriesID, opens, closes, declineCutoff, venueID sql.NullInt64
+	err := row.Scan(&friday.Date, &friday.Group, &friday.Details, &rawGuests, &rawWaitlist, &friday.MaxGuests,
+		&friday.Enabled, &start, &duration, &seriesID, &opens, &closes, &declineCutoff, &venueID, &rawHost,
+		&rawCoHosts, &rawPlusOnes, &rawTentative, &friday.Sequence)
+	if err != nil {
+		return friday, err
+	}
+	if closes.Valid {
+		friday.Policy = &RSVPPolicy{
+			OpensBefore:   time.Duration(opens.Int64) * time.Minute,
+			ClosesBefore:  time.Duration(closes.Int64) * time.Minute,
+			DeclineCutoff: time.Duration(declineCutoff.Int64) * time.Minute,
+		}
+	}
+	if start.Valid {
+		friday.Start = start.Time
+	}
+	friday.Duration = time.Duration(duration.Int64) * time.Minute
+	friday.SeriesID = seriesID.Int64
+	friday.VenueID = venueID.Int64
+	if err = json.Unmarshal([]byte(rawGuests), &friday.Guests); err != nil {
+		return friday, err
+	}
+	if err = json.Unmarshal([]byte(rawWaitlist), &friday.Waitlist); err != nil {
+		return friday, err
+	}
+	// a friday has at most one owner
+	var owners []string
+	if err = json.Unmarshal([]byte(rawHost), &owners); err != nil {
+		return friday, err
+	}
+	if len(owners) > 0 {
+		friday.Host = owners[0]
+	}
+	if err = json.Unmarshal([]byte(rawCoHosts), &friday.CoHosts); err != nil {
+		return friday, err
+	}
+	if err = json.Unmarshal([]byte(rawPlusOnes), &friday.PlusOnes); err != nil {
+		return friday, err
+	}
+	err = json.Unmarshal([]byte(rawTentative), &friday.Tentative)
+	return friday, err
+}
+
+// fridayTimes are the values of the starts_at and duration_minutes columns, which are NULL unless the host changed
+// when the friday starts or how long it lasts
+func fridayTimes(friday Friday) (sql.NullTime, sql.NullInt64) {
+	start := sql.NullTime{Time: friday.Start, Valid: !friday.Start.IsZero()}
+	duration := sql.NullInt64{Int64: int64(friday.Duration / time.Minute), Valid: friday.Duration > 0}
+	return start, duration
+}
+
+// fridaySeries is the value of the series_id column, which is NULL for the default series
+func fridaySeries(friday Friday) sql.NullInt64 {
+	return sql.NullInt64{Int64: friday.SeriesID, Valid: friday.SeriesID != DefaultSeriesID}
+}
+
+// fridayVenue is the value of the venue_id column, which is NULL when the friday has no venue
+func fridayVenue(friday Friday) sql.NullInt64 {
+	return sql.NullInt64{Int64: friday.VenueID, Valid: friday.VenueID != 0}
+}
+
+// fridayPolicy are the values of the rsvp_opens_minutes, rsvp_closes_minutes and decline_cutoff_minutes columns,
+// which are NULL when the friday follows the policy of the deployment
+func fridayPolicy(friday Friday) (sql.NullInt64, sql.NullInt64, sql.NullInt64) {
+	if friday.Policy == nil {
+		return sql.NullInt64{}, sql.NullInt64{}, sql.NullInt64{}
+	}
+	minutes := func(d time.Duration) sql.NullInt64 {
+		return sql.NullInt64{Int64: int64(d / time.Minute), Valid: true}
+	}
+	return minutes(friday.Policy.OpensBefore), minutes(friday.Policy.ClosesBefore),
+		minutes(friday.Policy.DeclineCutoff)
+}
+
+// sqlSeriesColumns are the columns of the series table read by scanSeries
+const sqlSeriesColumns = "id, name, description, invited_group, max_guests, recurrence, calendar_id"
+
+func scanSeries(row rowScanner) (Series, error) {
+	var series Series
+	err := row.Scan(&series.ID, &series.Name, &series.Description, &series.Group, &series.MaxGuests,
+		&series.Recurrence, &series.CalendarID)
+	return series, err
+}
+
+// sqlVenueColumns are the columns of the venues table read by scanVenue
+const sqlVenueColumns = "id, name, address, capacity, notes, ovens"
+
+func scanVenue(row rowScanner) (Venue, error) {
+	var venue Venue
+	err := row.Scan(&venue.ID, &venue.Name, &venue.Address, &venue.Capacity, &venue.Notes, &venue.Ovens)
+	return venue, err
+}
+
 type SQLAccessor struct {
 	db *sql.DB
+	// Location is the timezone that ListFridays returns dates in
+	Location *time.Location
 }
 
 func NewSQLAccessor(dbfile string, skipPatch bool) (*SQLAccessor, error) {
-	db, err := sql.Open("sqlite3", dbfile)
+	db, err := sql.Open("sqlite3", sqliteDSN(dbfile))
 	if err != nil {
 		return nil, err
 	}
-	a := &SQLAccessor{db}
+	a := &SQLAccessor{db: db, Location: defaultLocation()}
 	if skipPatch {
 		return a, nil
 	}
 	return a, a.PatchTables()
 }
 
+// sqliteDSN makes transactions take the write lock up front so that concurrent read-then-write transactions wait for
+// each other instead of failing to upgrade their locks
+func sqliteDSN(dbfile string) string {
+	sep := "?"
+	if strings.Contains(dbfile, "?") {
+		sep = "&"
+	}
+	return dbfile + sep + "_txlock=immediate&_busy_timeout=5000"
+}
+
 func (a *SQLAccessor) Close() {
 	a.db.Close()
 }
@@ -40,12 +186,73 @@ func (a *SQLAccessor) CreateTables() error {
 		return err
 	}
 	stmt = `CREATE TABLE fridays (
-		start_time    datetime NOT NULL PRIMARY KEY,
-		invited_group text,
-		details       text,
-		invited       text default "[]",
-		max_guests    int default 10,
-		enabled       bool default true
+		start_time             datetime NOT NULL PRIMARY KEY,
+		invited_group          text,
+		details                text,
+		max_guests             int default 10,
+		enabled                bool default true,
+		starts_at              datetime,
+		duration_minutes       int,
+		series_id              integer,
+		rsvp_opens_minutes     int,
+		rsvp_closes_minutes    int,
+		decline_cutoff_minutes int,
+		venue_id               integer,
+		sequence               int NOT NULL default 0
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE friday_hosts (
+		friday    datetime NOT NULL REFERENCES fridays(start_time),
+		friend_id integer NOT NULL REFERENCES friends(id),
+		role      text NOT NULL default 'cohost',
+		PRIMARY KEY (friday, friend_id)
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE plus_ones (
+		id         integer PRIMARY KEY AUTOINCREMENT,
+		friday     datetime NOT NULL REFERENCES fridays(start_time),
+		name       text NOT NULL,
+		email      text NOT NULL default '',
+		sponsor_id integer NOT NULL REFERENCES friends(id),
+		created_at datetime NOT NULL
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE calendar_feeds (
+		friend_id     integer PRIMARY KEY REFERENCES friends(id),
+		token         text NOT NULL UNIQUE,
+		member_groups text NOT NULL default '[]',
+		open_fridays  bool NOT NULL default false
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE rsvps (
+		friday     datetime NOT NULL REFERENCES fridays(start_time),
+		friend_id  integer NOT NULL REFERENCES friends(id),
+		status     text NOT NULL default 'accepted',
+		created_at datetime NOT NULL default CURRENT_TIMESTAMP,
+		created_by integer REFERENCES friends(id),
+		PRIMARY KEY (friday, friend_id)
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE audit_log (
+		id           integer PRIMARY KEY AUTOINCREMENT,
+		created_at   datetime NOT NULL,
+		actor        text NOT NULL,
+		action       text NOT NULL,
+		friday       datetime,
+		target       text NOT NULL,
+		before_value text NOT NULL,
+		after_value  text NOT NULL,
+		source       text NOT NULL
 	)`
 	if _, err := a.db.Exec(stmt); err != nil {
 		return err
@@ -57,41 +264,76 @@ func (a *SQLAccessor) CreateTables() error {
 	if _, err := a.db.Exec(stmt); err != nil {
 		return err
 	}
+	stmt = `CREATE TABLE series (
+		id            integer PRIMARY KEY AUTOINCREMENT,
+		name          text NOT NULL UNIQUE,
+		description   text NOT NULL default '',
+		invited_group text,
+		max_guests    int NOT NULL default 10,
+		recurrence    text NOT NULL default '',
+		calendar_id   text NOT NULL default ''
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE venues (
+		id       integer PRIMARY KEY AUTOINCREMENT,
+		name     text NOT NULL UNIQUE,
+		address  text NOT NULL default '',
+		capacity int NOT NULL default 0,
+		notes    text NOT NULL default '',
+		ovens    int NOT NULL default 0
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE blackouts (
+		day    text NOT NULL PRIMARY KEY,
+		reason text NOT NULL default ''
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
+	stmt = `CREATE TABLE settings (
+		name  text NOT NULL PRIMARY KEY,
+		value text NOT NULL
+	)`
+	if _, err := a.db.Exec(stmt); err != nil {
+		return err
+	}
 	stmt = `CREATE TABLE app_versions (
-		name    text NOT NULL PRIMARY KEY,
-		version int NOT NULL
+		name     text NOT NULL PRIMARY KEY,
+		version  int NOT NULL,
+		checksum text
 	)`
 	if _, err := a.db.Exec(stmt); err != nil {
 		return err
 	}
-	_, err := a.db.Exec(`INSERT INTO app_versions (name, version) VALUES ('schema', 6)`)
-	return err
+	// the tables are already at the newest version
+	return a.Migrator().Stamp()
 }
 
-func (a *SQLAccessor) PatchTables() error {
-	var schemaVersion int
-	if err := a.db.QueryRow("SELECT version FROM app_versions WHERE name='schema'").Scan(&schemaVersion); err != nil {
-		// minimum patch 2 is required
-		return err
-	}
-	// apply missing patches
-	for p := schemaVersion + 1; p < len(AllPatches); p++ {
-		if err := AllPatches[p](a); err != nil {
+func (a *SQLAccessor) DropTables() error {
+	for _, table := range []string{"audit_log", "rsvps", "friday_hosts", "plus_ones", "calendar_feeds", "friends", "fridays", "series", "venues", "blackouts", "settings", "versions", "app_versions"} {
+		if _, err := a.db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
 			return err
 		}
 	}
-	// save new schema version
-	stmt, err := a.db.Prepare("UPDATE app_versions SET version=? WHERE name='schema'")
-	if err != nil {
-		return nil
-	}
-	_, err = stmt.Exec(len(AllPatches) - 1)
-	return err
+	return nil
+}
+
+// Migrator manages the migrations of the SQLite database
+func (a *SQLAccessor) Migrator() *Migrator {
+	return NewMigrator(a.db, SQLiteMigrations)
+}
+
+func (a *SQLAccessor) PatchTables() error {
+	return a.Migrator().Up()
 }
 
 func (a *SQLAccessor) GetFriendByID(ID string) (Friend, error) {
 	friend := Friend{}
-	stmt, err := a.db.Prepare("select email, name from friends where id = ?")
+	stmt, err := a.db.Prepare("select email, COALESCE(name, '') from friends where id = ?")
 	if err != nil {
 		return friend, err
 	}
@@ -102,7 +344,7 @@ func (a *SQLAccessor) GetFriendByID(ID string) (Friend, error) {
 
 func (a *SQLAccessor) GetFriendByEmail(email string) (Friend, error) {
 	friend := Friend{}
-	stmt, err := a.db.Prepare("select id, name from friends where email = ?")
+	stmt, err := a.db.Prepare("select id, COALESCE(name, '') from friends where email = ?")
 	if err != nil {
 		return friend, err
 	}
@@ -119,8 +361,8 @@ func (a *SQLAccessor) GetUpcomingFridays(daysAhead int) ([]Friday, error) {
 
 func (a *SQLAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([]Friday, error) {
 	before := after.AddDate(0, 0, daysAhead)
-	stmt, err := a.db.Prepare(`SELECT start_time, invited_group, details, invited, max_guests, enabled FROM fridays 
-		WHERE start_time <= ? AND start_time >= ?`)
+	stmt, err := a.db.Prepare(`SELECT ` + sqlFridayColumns + ` FROM fridays
+		WHERE start_time <= ? AND start_time >= ? ORDER BY start_time`)
 	if err != nil {
 		return nil, err
 	}
@@ -131,15 +373,10 @@ func (a *SQLAccessor) GetUpcomingFridaysAfter(after time.Time, daysAhead int) ([
 	defer rows.Close()
 	result := make([]Friday, 0)
 	for rows.Next() {
-		var friday Friday
-		var rawInvited string
-		err = rows.Scan(&friday.Date, &friday.Group, &friday.Details, &rawInvited, &friday.MaxGuests, &friday.Enabled)
+		friday, err := scanFriday(rows)
 		if err != nil {
 			return nil, err
 		}
-		if err = json.Unmarshal([]byte(rawInvited), &friday.Guests); err != nil {
-			return nil, err
-		}
 		result = append(result, friday)
 	}
 	return result, nil
@@ -154,6 +391,25 @@ func (a *SQLAccessor) AddFriend(email, name string) error {
 	return err
 }
 
+func (a *SQLAccessor) ListFriends() ([]Friend, error) {
+	rows, err := a.db.Query("SELECT id, email, COALESCE(name, '') FROM friends ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make([]Friend, 0)
+	for rows.Next() {
+		var id int64
+		friend := Friend{}
+		if err = rows.Scan(&id, &friend.Email, &friend.Name); err != nil {
+			return nil, err
+		}
+		friend.ID = strconv.FormatInt(id, 10)
+		res = append(res, friend)
+	}
+	return res, rows.Err()
+}
+
 func (a *SQLAccessor) DoesFridayExist(date time.Time) (bool, error) {
 	stmt, err := a.db.Prepare("SELECT COUNT(*) FROM fridays WHERE start_time = ?")
 	if err != nil {
@@ -196,12 +452,11 @@ func (a *SQLAccessor) ListFridays() ([]Friday, error) {
 	if err != nil {
 		return nil, err
 	}
-	loc, _ := time.LoadLocation("America/New_York")
 	res := make([]Friday, 0)
 	for rows.Next() {
 		f := Friday{}
 		err = rows.Scan(&f.Date)
-		f.Date = f.Date.In(loc)
+		f.Date = f.Date.In(a.Location)
 		if err != nil {
 			return nil, err
 		}
@@ -211,22 +466,28 @@ func (a *SQLAccessor) ListFridays() ([]Friday, error) {
 }
 
 func (a *SQLAccessor) GetFriday(date time.Time) (Friday, error) {
-	stmt, err := a.db.Prepare("select start_time, invited_group, details, invited, max_guests, enabled from fridays where start_time = ?")
+	stmt, err := a.db.Prepare("select " + sqlFridayColumns + " from fridays where start_time = ?")
 	if err != nil {
 		return Friday{}, err
 	}
-	var friday Friday
-	var rawInvited string
-	err = stmt.QueryRow(date).Scan(&friday.Date, &friday.Group, &friday.Details, &rawInvited, &friday.MaxGuests, &friday.Enabled)
-	if err != nil {
-		return friday, err
-	}
-	err = json.Unmarshal([]byte(rawInvited), &friday.Guests)
-	return friday, err
+	return scanFriday(stmt.QueryRow(date))
 }
 
 func (a *SQLAccessor) RemoveFriday(date time.Time) error {
-	stmt, err := a.db.Prepare("delete from fridays where start_time = ?")
+	stmt, err := a.db.Prepare("delete from rsvps where friday = ?")
+	if err != nil {
+		return err
+	}
+	if _, err = stmt.Exec(date); err != nil {
+		return err
+	}
+	if _, err = a.db.Exec("DELETE FROM friday_hosts WHERE friday = ?", date); err != nil {
+		return err
+	}
+	if _, err = a.db.Exec("DELETE FROM plus_ones WHERE friday = ?", date); err != nil {
+		return err
+	}
+	stmt, err = a.db.Prepare("delete from fridays where start_time = ?")
 	if err != nil {
 		return err
 	}
@@ -235,83 +496,253 @@ func (a *SQLAccessor) RemoveFriday(date time.Time) error {
 }
 
 func (a *SQLAccessor) UpdateFriday(friday Friday) error {
-	stmt, err := a.db.Prepare("UPDATE fridays SET invited_group=?, details=?, max_guests=?, enabled=? WHERE start_time=?")
+	stmt, err := a.db.Prepare(`UPDATE fridays SET invited_group=?, details=?, max_guests=?, enabled=?, starts_at=?,
+		duration_minutes=?, series_id=?, rsvp_opens_minutes=?, rsvp_closes_minutes=?, decline_cutoff_minutes=?,
+		venue_id=?, sequence=sequence+1 WHERE start_time=?`)
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(friday.Group, friday.Details, friday.MaxGuests, friday.Enabled, friday.Date)
+	start, duration := fridayTimes(friday)
+	opens, closes, declineCutoff := fridayPolicy(friday)
+	_, err = stmt.Exec(friday.Group, friday.Details, friday.MaxGuests, friday.Enabled, start, duration,
+		fridaySeries(friday), opens, closes, declineCutoff, fridayVenue(friday), friday.Date)
 	return err
 }
 
-func (a *SQLAccessor) AddFriendToFriday(email string, friday Friday) error {
-	var invited []string
-	stmt, err := a.db.Prepare("SELECT invited FROM fridays WHERE start_time = ?")
+func (a *SQLAccessor) AddFriendToFriday(email string, friday Friday, createdBy string) error {
+	// the capacity check and the insert must see the same guest list
+	tx, err := a.db.Begin()
 	if err != nil {
 		return err
 	}
-	var rawInvited string
-	err = stmt.QueryRow(friday.Date).Scan(&rawInvited)
+	defer tx.Rollback()
+
+	var maxGuests int
+	if err = tx.QueryRow("SELECT max_guests FROM fridays WHERE start_time = ?", friday.Date).Scan(&maxGuests); err != nil {
+		return err
+	}
+	var status string
+	err = tx.QueryRow(`SELECT rsvps.status FROM rsvps JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = ? AND friends.email = ?`, friday.Date, email).Scan(&status)
+	if err == nil && status == RSVPAccepted {
+		// already invited
+		return nil
+	} else if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	var numGuests int
+	err = tx.QueryRow("SELECT "+sqlGuestCount, friday.Date, friday.Date).Scan(&numGuests)
 	if err != nil {
 		return err
 	}
-	if err = json.Unmarshal([]byte(rawInvited), &invited); err != nil {
+	if numGuests >= maxGuests {
+		return ErrFridayIsFull
+	}
+
+	// guests do not need to have logged in before to be invited
+	if _, err = tx.Exec("INSERT INTO friends (email) VALUES (?) ON CONFLICT (email) DO NOTHING", email); err != nil {
 		return err
 	}
-	// ensure uniqueness
-	for _, guest := range invited {
-		if guest == email {
-			// already invited
-			return nil
-		}
+	var creator *string
+	if len(createdBy) > 0 && createdBy != email {
+		creator = &createdBy
 	}
-	// not invited yet
-	stmt, err = a.db.Prepare("UPDATE fridays SET invited = json_insert(invited, '$[#]', ?) WHERE start_time = ?")
+	// accepting after declining counts as a new RSVP
+	_, err = tx.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at, created_by)
+		SELECT ?, id, 'accepted', ?, (SELECT id FROM friends WHERE email = ?) FROM friends WHERE email = ?
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=excluded.created_by`,
+		friday.Date, time.Now(), creator, email)
 	if err != nil {
 		return err
 	}
-	_, err = stmt.Exec(email, friday.Date)
-	return err
+	return tx.Commit()
 }
 
 func (a *SQLAccessor) RemoveFriendFromFriday(email string, date time.Time) error {
-	var invited []string
-	stmt, err := a.db.Prepare("SELECT invited FROM fridays WHERE start_time = ?")
+	stmt, err := a.db.Prepare(`UPDATE rsvps SET status = 'declined'
+		WHERE friday = ? AND friend_id = (SELECT id FROM friends WHERE email = ?)`)
 	if err != nil {
 		return err
 	}
-	var rawInvited string
-	err = stmt.QueryRow(date).Scan(&rawInvited)
+	_, err = stmt.Exec(date, email)
+	return err
+}
+
+func (a *SQLAccessor) SetFridayHosts(date time.Time, host string, coHosts []string) error {
+	tx, err := a.db.Begin()
 	if err != nil {
 		return err
 	}
-	if err = json.Unmarshal([]byte(rawInvited), &invited); err != nil {
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("DELETE FROM friday_hosts WHERE friday = ?", date); err != nil {
 		return err
 	}
-
-	found := false
-	for i, guest := range invited {
-		if guest == email {
-			invited[i] = invited[len(invited)-1]
-			found = true
-			break
+	insert := `INSERT INTO friday_hosts (friday, friend_id, role) SELECT ?, id, ? FROM friends WHERE email = ?
+		ON CONFLICT (friday, friend_id) DO NOTHING`
+	if len(host) > 0 {
+		if _, err = tx.Exec(insert, date, FridayHostOwner, host); err != nil {
+			return err
 		}
 	}
-	if !found {
-		// not invited yet
-		return nil
+	for _, email := range coHosts {
+		if _, err = tx.Exec(insert, date, FridayCoHost, email); err != nil {
+			return err
+		}
 	}
-	updatedInvited, err := json.Marshal(invited[:len(invited)-1])
-	if err != nil {
+	return tx.Commit()
+}
+
+func (a *SQLAccessor) AddFriendAsTentative(email string, date time.Time) error {
+	var startTime time.Time
+	if err := a.db.QueryRow("SELECT start_time FROM fridays WHERE start_time = ?", date).Scan(&startTime); err != nil {
 		return err
 	}
-	stmt, err = a.db.Prepare("UPDATE fridays SET invited = ? WHERE start_time = ?")
+	if _, err := a.db.Exec("INSERT INTO friends (email) VALUES (?) ON CONFLICT (email) DO NOTHING", email); err != nil {
+		return err
+	}
+	_, err := a.db.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at)
+		SELECT ?, id, 'tentative', ? FROM friends WHERE email = ?
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=NULL
+		WHERE status != 'tentative'`, date, time.Now(), email)
+	return err
+}
+
+func (a *SQLAccessor) AddPlusOne(date time.Time, plusOne PlusOne) (int64, error) {
+	// the capacity check and the insert must see the same guest list
+	tx, err := a.db.Begin()
 	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var maxGuests, numGuests int
+	err = tx.QueryRow(`SELECT max_guests, `+sqlGuestCount+` FROM fridays WHERE start_time = ?`, date, date,
+		date).Scan(&maxGuests, &numGuests)
+	if err != nil {
+		return 0, err
+	}
+	if numGuests >= maxGuests {
+		return 0, ErrFridayIsFull
+	}
+	res, err := tx.Exec(`INSERT INTO plus_ones (friday, name, email, sponsor_id, created_at)
+		SELECT ?, ?, ?, id, ? FROM friends WHERE email = ?`, date, plusOne.Name, plusOne.Email, time.Now(),
+		plusOne.Sponsor)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		// the sponsor is not a friend
+		return 0, sql.ErrNoRows
+	}
+	ID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return ID, tx.Commit()
+}
+
+func (a *SQLAccessor) RemovePlusOne(date time.Time, ID int64) error {
+	_, err := a.db.Exec("DELETE FROM plus_ones WHERE friday = ? AND id = ?", date, ID)
+	return err
+}
+
+func (a *SQLAccessor) AddFriendToWaitlist(email string, date time.Time) error {
+	stmt, err := a.db.Prepare("SELECT start_time FROM fridays WHERE start_time = ?")
+	if err != nil {
+		return err
+	}
+	var startTime time.Time
+	if err = stmt.QueryRow(date).Scan(&startTime); err != nil {
+		return err
+	}
+	if _, err = a.db.Exec("INSERT INTO friends (email) VALUES (?) ON CONFLICT (email) DO NOTHING", email); err != nil {
 		return err
 	}
-	_, err = stmt.Exec(string(updatedInvited), date)
+	// accepted guests stay accepted and waitlisted guests keep their place in line
+	_, err = a.db.Exec(`INSERT INTO rsvps (friday, friend_id, status, created_at)
+		SELECT ?, id, 'waitlisted', ? FROM friends WHERE email = ?
+		ON CONFLICT (friday, friend_id) DO UPDATE SET
+			status=excluded.status, created_at=excluded.created_at, created_by=NULL
+		WHERE status = 'declined'`, date, time.Now(), email)
 	return err
 }
 
+func (a *SQLAccessor) PromoteFromWaitlist(date time.Time) ([]string, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var maxGuests, numGuests int
+	err = tx.QueryRow(`SELECT max_guests, `+sqlGuestCount+` FROM fridays WHERE start_time = ?`, date, date,
+		date).Scan(&maxGuests, &numGuests)
+	if err != nil {
+		return nil, err
+	}
+	promoted := make([]string, 0)
+	if numGuests >= maxGuests {
+		return promoted, nil
+	}
+	rows, err := tx.Query(`SELECT friends.id, friends.email FROM rsvps JOIN friends ON friends.id = rsvps.friend_id
+		WHERE rsvps.friday = ? AND rsvps.status = 'waitlisted'
+		ORDER BY rsvps.created_at, rsvps.rowid LIMIT ?`, date, maxGuests-numGuests)
+	if err != nil {
+		return nil, err
+	}
+	friendIDs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		var email string
+		if err = rows.Scan(&id, &email); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		friendIDs = append(friendIDs, id)
+		promoted = append(promoted, email)
+	}
+	rows.Close()
+	for _, id := range friendIDs {
+		if _, err = tx.Exec("UPDATE rsvps SET status = 'accepted' WHERE friday = ? AND friend_id = ?", date, id); err != nil {
+			return nil, err
+		}
+	}
+	return promoted, tx.Commit()
+}
+
+func (a *SQLAccessor) GetRSVPs(date time.Time) ([]RSVP, error) {
+	stmt, err := a.db.Prepare(`SELECT rsvps.friday, friends.email, friends.name, rsvps.status, rsvps.created_at, creators.email
+		FROM rsvps
+		JOIN friends ON friends.id = rsvps.friend_id
+		LEFT JOIN friends AS creators ON creators.id = rsvps.created_by
+		WHERE rsvps.friday = ? ORDER BY rsvps.created_at, rsvps.rowid`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]RSVP, 0)
+	for rows.Next() {
+		var rsvp RSVP
+		var name, createdBy sql.NullString
+		if err = rows.Scan(&rsvp.Friday, &rsvp.Email, &name, &rsvp.Status, &rsvp.CreatedAt, &createdBy); err != nil {
+			return nil, err
+		}
+		rsvp.Name = name.String
+		rsvp.CreatedBy = createdBy.String
+		result = append(result, rsvp)
+	}
+	return result, rows.Err()
+}
+
 func (a *SQLAccessor) GetPreferences(email string) (Preferences, error) {
 	var prefs Preferences
 	stmt, err := a.db.Prepare("SELECT preferences FROM friends WHERE email=?")
@@ -339,3 +770,228 @@ func (a *SQLAccessor) SetPreferences(email string, prefs Preferences) error {
 	_, err = stmt.Exec(rawPrefs, email)
 	return err
 }
+
+// sqlCalendarFeedColumns are the columns of the calendar_feeds table read by scanCalendarFeed
+const sqlCalendarFeedColumns = `friends.email, calendar_feeds.token, calendar_feeds.member_groups,
+	calendar_feeds.open_fridays FROM calendar_feeds JOIN friends ON friends.id = calendar_feeds.friend_id`
+
+func scanCalendarFeed(row rowScanner) (CalendarFeed, error) {
+	var feed CalendarFeed
+	var rawGroups string
+	if err := row.Scan(&feed.Email, &feed.Token, &rawGroups, &feed.OpenFridays); err != nil {
+		return feed, err
+	}
+	err := json.Unmarshal([]byte(rawGroups), &feed.Groups)
+	return feed, err
+}
+
+// calendarFeedGroups is the value of the member_groups column, which is never null
+func calendarFeedGroups(feed CalendarFeed) (string, error) {
+	groups := feed.Groups
+	if groups == nil {
+		groups = []string{}
+	}
+	rawGroups, err := json.Marshal(groups)
+	return string(rawGroups), err
+}
+
+func (a *SQLAccessor) GetCalendarFeed(email string) (CalendarFeed, error) {
+	return scanCalendarFeed(a.db.QueryRow("SELECT "+sqlCalendarFeedColumns+" WHERE friends.email = ?", email))
+}
+
+func (a *SQLAccessor) GetCalendarFeedByToken(token string) (CalendarFeed, error) {
+	return scanCalendarFeed(a.db.QueryRow("SELECT "+sqlCalendarFeedColumns+" WHERE calendar_feeds.token = ?", token))
+}
+
+func (a *SQLAccessor) SetCalendarFeed(feed CalendarFeed) error {
+	rawGroups, err := calendarFeedGroups(feed)
+	if err != nil {
+		return err
+	}
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec("INSERT INTO friends (email) VALUES (?) ON CONFLICT (email) DO NOTHING", feed.Email); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO calendar_feeds (friend_id, token, member_groups, open_fridays)
+		SELECT id, ?, ?, ? FROM friends WHERE email = ?
+		ON CONFLICT (friend_id) DO UPDATE SET
+			token=excluded.token, member_groups=excluded.member_groups, open_fridays=excluded.open_fridays`,
+		feed.Token, rawGroups, feed.OpenFridays, feed.Email)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (a *SQLAccessor) AddAuditEntry(entry AuditEntry) error {
+	stmt, err := a.db.Prepare(`INSERT INTO audit_log
+		(created_at, actor, action, friday, target, before_value, after_value, source) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	var friday *time.Time
+	if !entry.Friday.IsZero() {
+		friday = &entry.Friday
+	}
+	_, err = stmt.Exec(entry.Time, entry.Actor, entry.Action, friday, entry.Target, entry.Before, entry.After, entry.Source)
+	return err
+}
+
+func (a *SQLAccessor) ListAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	query := `SELECT id, created_at, actor, action, friday, target, before_value, after_value, source FROM audit_log
+		WHERE true`
+	args := make([]any, 0)
+	if !filter.Friday.IsZero() {
+		query += " AND friday = ?"
+		args = append(args, filter.Friday)
+	}
+	if len(filter.Target) > 0 {
+		query += " AND target = ?"
+		args = append(args, filter.Target)
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, filter.Offset)
+	}
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]AuditEntry, 0)
+	for rows.Next() {
+		var entry AuditEntry
+		var friday sql.NullTime
+		err = rows.Scan(&entry.ID, &entry.Time, &entry.Actor, &entry.Action, &friday, &entry.Target, &entry.Before,
+			&entry.After, &entry.Source)
+		if err != nil {
+			return nil, err
+		}
+		entry.Friday = friday.Time
+		result = append(result, entry)
+	}
+	return result, rows.Err()
+}
+
+func (a *SQLAccessor) GetSetting(name string) (string, error) {
+	var value string
+	err := a.db.QueryRow("SELECT value FROM settings WHERE name = ?", name).Scan(&value)
+	return value, err
+}
+
+func (a *SQLAccessor) SetSetting(name, value string) error {
+	_, err := a.db.Exec(`INSERT INTO settings (name, value) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET value = excluded.value`, name, value)
+	return err
+}
+
+func (a *SQLAccessor) ListSeries() ([]Series, error) {
+	rows, err := a.db.Query("SELECT " + sqlSeriesColumns + " FROM series ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Series, 0)
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, series)
+	}
+	return result, rows.Err()
+}
+
+func (a *SQLAccessor) GetSeries(ID int64) (Series, error) {
+	return scanSeries(a.db.QueryRow("SELECT "+sqlSeriesColumns+" FROM series WHERE id = ?", ID))
+}
+
+func (a *SQLAccessor) AddSeries(series Series) (int64, error) {
+	res, err := a.db.Exec(`INSERT INTO series (name, description, invited_group, max_guests, recurrence, calendar_id)
+		VALUES (?, ?, ?, ?, ?, ?)`, series.Name, series.Description, series.Group, series.MaxGuests, series.Recurrence,
+		series.CalendarID)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (a *SQLAccessor) UpdateSeries(series Series) error {
+	_, err := a.db.Exec(`UPDATE series SET name=?, description=?, invited_group=?, max_guests=?, recurrence=?,
+		calendar_id=? WHERE id=?`, series.Name, series.Description, series.Group, series.MaxGuests, series.Recurrence,
+		series.CalendarID, series.ID)
+	return err
+}
+
+func (a *SQLAccessor) ListVenues() ([]Venue, error) {
+	rows, err := a.db.Query("SELECT " + sqlVenueColumns + " FROM venues ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Venue, 0)
+	for rows.Next() {
+		venue, err := scanVenue(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, venue)
+	}
+	return result, rows.Err()
+}
+
+func (a *SQLAccessor) GetVenue(ID int64) (Venue, error) {
+	return scanVenue(a.db.QueryRow("SELECT "+sqlVenueColumns+" FROM venues WHERE id = ?", ID))
+}
+
+func (a *SQLAccessor) AddVenue(venue Venue) (int64, error) {
+	res, err := a.db.Exec(`INSERT INTO venues (name, address, capacity, notes, ovens) VALUES (?, ?, ?, ?, ?)`,
+		venue.Name, venue.Address, venue.Capacity, venue.Notes, venue.Ovens)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (a *SQLAccessor) UpdateVenue(venue Venue) error {
+	_, err := a.db.Exec(`UPDATE venues SET name=?, address=?, capacity=?, notes=?, ovens=? WHERE id=?`,
+		venue.Name, venue.Address, venue.Capacity, venue.Notes, venue.Ovens, venue.ID)
+	return err
+}
+
+func (a *SQLAccessor) ListBlackouts() ([]Blackout, error) {
+	rows, err := a.db.Query("SELECT day, reason FROM blackouts ORDER BY day")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]Blackout, 0)
+	for rows.Next() {
+		var blackout Blackout
+		if err = rows.Scan(&blackout.Day, &blackout.Reason); err != nil {
+			return nil, err
+		}
+		result = append(result, blackout)
+	}
+	return result, rows.Err()
+}
+
+func (a *SQLAccessor) AddBlackout(blackout Blackout) error {
+	_, err := a.db.Exec(`INSERT INTO blackouts (day, reason) VALUES (?, ?)
+		ON CONFLICT (day) DO UPDATE SET reason = excluded.reason`, blackout.Day, blackout.Reason)
+	return err
+}
+
+func (a *SQLAccessor) RemoveBlackout(day string) error {
+	_, err := a.db.Exec("DELETE FROM blackouts WHERE day = ?", day)
+	return err
+}
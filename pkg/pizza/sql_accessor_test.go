@@ -0,0 +1,43 @@
+package pizza_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mpoegel/rsvp.pizza/pkg/pizza"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteMigrations_Rsvps(t *testing.T) {
+	// GIVEN
+	sqlfile := filepath.Join(t.TempDir(), "pizza.db")
+	db, err := sql.Open("sqlite3", sqlfile)
+	require.Nil(t, err)
+	_, err = db.Exec(`CREATE TABLE friends (id integer PRIMARY KEY AUTOINCREMENT, email text NOT NULL UNIQUE, name text, preferences text default "{}");
+		CREATE TABLE fridays (start_time datetime NOT NULL PRIMARY KEY, invited_group text, details text, invited text default "[]", max_guests int default 10, enabled bool default true);
+		CREATE TABLE app_versions (name text NOT NULL PRIMARY KEY, version int NOT NULL);
+		INSERT INTO app_versions (name, version) VALUES ('schema', 6);
+		INSERT INTO friends (email, name) VALUES ('foo@bar.com', 'Foo');`)
+	require.Nil(t, err)
+	loc, _ := time.LoadLocation("America/New_York")
+	f1 := time.Date(2023, 12, 22, 17, 30, 0, 0, loc)
+	_, err = db.Exec(`INSERT INTO fridays (start_time, invited) VALUES (?, '["foo@bar.com","baz@bar.com"]')`, f1)
+	require.Nil(t, err)
+	require.Nil(t, db.Close())
+
+	// WHEN
+	accessor, err := pizza.NewSQLAccessor(sqlfile, false)
+	require.Nil(t, err)
+	defer accessor.Close()
+	friday, err := accessor.GetFriday(f1)
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"foo@bar.com", "baz@bar.com"}, friday.Guests)
+	friend, err := accessor.GetFriendByEmail("baz@bar.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "baz@bar.com", friend.Email)
+}
@@ -0,0 +1,182 @@
+package pizza
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	jsonapi "github.com/hashicorp/jsonapi"
+	api "github.com/mpoegel/rsvp.pizza/pkg/api"
+)
+
+// fridayVenue is the venue that the host picked for the friday, if they picked one that still exists
+func (s *Server) fridayVenue(friday Friday) (Venue, bool) {
+	if friday.VenueID == 0 {
+		return Venue{}, false
+	}
+	venue, err := s.store.GetVenue(friday.VenueID)
+	if err != nil {
+		slog.Warn("failed to get venue", "venueID", friday.VenueID, "error", err)
+		return Venue{}, false
+	}
+	return venue, true
+}
+
+// eventLocation is the location of the friday's calendar event, which is empty when it has no venue
+func (s *Server) eventLocation(friday Friday) string {
+	venue, ok := s.fridayVenue(friday)
+	if !ok {
+		return ""
+	}
+	if len(venue.Address) == 0 {
+		return venue.Name
+	}
+	return venue.Name + ", " + venue.Address
+}
+
+// listVenues returns the venues that hosts can pick from, which is empty when they cannot be listed
+func (s *Server) listVenues() []Venue {
+	venues, err := s.store.ListVenues()
+	if err != nil {
+		slog.Error("failed to list venues", "error", err)
+		return []Venue{}
+	}
+	return venues
+}
+
+func apiVenue(venue Venue) *api.Venue {
+	return &api.Venue{
+		ID:       strconv.FormatInt(venue.ID, 10),
+		Name:     venue.Name,
+		Address:  venue.Address,
+		Capacity: venue.Capacity,
+		Notes:    venue.Notes,
+		Ovens:    venue.Ovens,
+	}
+}
+
+// apiFridayVenue is the venue relation of the friday, which is nil when it has no venue
+func (s *Server) apiFridayVenue(friday Friday) *api.Venue {
+	venue, ok := s.fridayVenue(friday)
+	if !ok {
+		return nil
+	}
+	return apiVenue(venue)
+}
+
+func (s *Server) HandleAPIVenue(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := s.CheckAuthorization(r)
+	if !ok {
+		WriteAPIErrorStatus(errors.New("not authorized"), http.StatusUnauthorized, w)
+		return
+	}
+
+	if r.Header.Get("Accept") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("must accept %s", jsonapi.MediaType), http.StatusNotAcceptable, w)
+		return
+	}
+
+	venueID := r.PathValue("ID")
+	var venue Venue
+	if len(venueID) > 0 {
+		ID, err := strconv.ParseInt(venueID, 10, 64)
+		if err != nil {
+			WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+			return
+		}
+		if venue, err = s.store.GetVenue(ID); err != nil {
+			WriteAPIErrorStatus(fmt.Errorf("no matching venue found with ID '%s'", venueID), http.StatusNotFound, w)
+			return
+		}
+	}
+
+	if r.Method == http.MethodGet {
+		var err error
+		w.Header().Set("Content-Type", jsonapi.MediaType)
+		w.WriteHeader(http.StatusOK)
+		if len(venueID) > 0 {
+			err = jsonapi.MarshalPayload(w, apiVenue(venue))
+		} else {
+			res := make([]*api.Venue, 0)
+			for _, venue := range s.listVenues() {
+				res = append(res, apiVenue(venue))
+			}
+			err = jsonapi.MarshalPayload(w, res)
+		}
+		if err != nil {
+			slog.Warn("api marshal payload", "error", err)
+			WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+		}
+		return
+	}
+
+	if r.Header.Get("Content-Type") != jsonapi.MediaType {
+		WriteAPIErrorStatus(fmt.Errorf("unsupported media type '%s'", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType, w)
+		return
+	}
+	if !isAdmin(&accessToken.Claims) {
+		WriteAPIErrorStatus(errors.New("only hosts can change venues"), http.StatusForbidden, w)
+		return
+	}
+	payload, err := api.UnmarshalVenue(r.Body)
+	if err != nil {
+		WriteAPIErrorStatus(err, http.StatusBadRequest, w)
+		return
+	}
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		WriteAPIErrorStatus(errors.New("venue must have a name"), http.StatusBadRequest, w)
+		return
+	}
+	if payload.Capacity < 0 || payload.Ovens < 0 {
+		WriteAPIErrorStatus(errors.New("capacity and ovens must not be negative"), http.StatusBadRequest, w)
+		return
+	}
+	for _, other := range s.listVenues() {
+		if other.Name == name && (len(venueID) == 0 || other.ID != venue.ID) {
+			WriteAPIErrorStatus(fmt.Errorf("a venue named '%s' already exists", other.Name), http.StatusConflict, w)
+			return
+		}
+	}
+
+	before := ""
+	if len(venueID) > 0 {
+		before = venueSettings(venue)
+	}
+	venue.Name = name
+	venue.Address = strings.TrimSpace(payload.Address)
+	venue.Capacity = payload.Capacity
+	venue.Notes = payload.Notes
+	venue.Ovens = payload.Ovens
+
+	status := http.StatusOK
+	if len(venueID) > 0 {
+		err = s.store.UpdateVenue(venue)
+	} else {
+		status = http.StatusCreated
+		venue.ID, err = s.store.AddVenue(venue)
+	}
+	if err != nil {
+		slog.Error("failed to save venue", "error", err, "name", venue.Name)
+		WriteAPIErrorStatus(errors.New("database error"), http.StatusInternalServerError, w)
+		return
+	}
+	s.audit(AuditEntry{
+		Actor:  accessToken.Claims.Email,
+		Action: "venue",
+		Target: venue.Name,
+		Before: before,
+		After:  venueSettings(venue),
+		Source: AuditSourceAPI,
+	})
+
+	w.Header().Set("Content-Type", jsonapi.MediaType)
+	w.WriteHeader(status)
+	if err = jsonapi.MarshalPayload(w, apiVenue(venue)); err != nil {
+		slog.Warn("api marshal payload", "error", err)
+		WriteAPIError(errors.New("failed to compose response data"), http.StatusInternalServerError, w)
+	}
+}